@@ -0,0 +1,584 @@
+// Command gh-actions-parse validates, lints and formats GitHub Actions
+// workflow and action files. It can be used on its own or as a gh CLI
+// extension:
+//
+//	go build -o cmd/gh-actions-parse/gh-actions-parse ./cmd/gh-actions-parse
+//	gh extension install ./cmd/gh-actions-parse
+//	gh actions-parse validate
+//
+// Without arguments it checks the .github/workflows directory of the
+// repository containing the current directory.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/scagogogo/github-action-parser/pkg/github"
+	"github.com/scagogogo/github-action-parser/pkg/parser"
+)
+
+const usage = `Usage: gh actions-parse <command> [flags] [path...]
+
+Commands:
+  validate  check files against GitHub's specification
+  lint      report likely mistakes
+  fmt       print files in canonical style, or rewrite them with -w
+  snapshot  capture everything the files reference for offline use with
+            -snapshot; implies -remote
+  graph     print the call graph of the repository at path as JSON, or
+            with -format dot or mermaid
+  rules     list the rules of validate and lint, or print them as JSON
+            with -format json
+
+Findings of validate and lint are printed with -format:
+  text      one line per finding (the default)
+  json      versioned JSON document, also -json
+  jsonl     one JSON object per finding
+  github    workflow command annotations
+  markdown  job summary to append to $GITHUB_STEP_SUMMARY
+  badge     shields.io endpoint badge
+  html      self-contained report page
+The exit code is 1 when a finding is at least as severe as -fail-on:
+error (the default), warning, info, or none to never fail. To adopt the
+checks gradually, record the current findings with -write-baseline and
+pass the file with -baseline to report only new ones.
+
+Paths may be files or directories and default to the .github/workflows
+directory of the current repository.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command line and returns the exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	command := args[0]
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	asJSON := flags.Bool("json", false, "same as -format json")
+	write := flags.Bool("w", false, "fmt: write the result to the file instead of stdout")
+	remote := flags.Bool("remote", false, "fetch actions and reusable workflows of other repositories, authenticating like gh")
+	snapshot := flags.String("snapshot", "", "resolve actions and reusable workflows from a bundle captured with the snapshot command")
+	output := flags.String("o", "", "snapshot: write the bundle to a file instead of stdout")
+	configPath := flags.String("config", "", "validate, lint: project configuration, default "+parser.ConfigFileName+" at the repository root")
+	baselinePath := flags.String("baseline", "", "validate, lint: only report findings missing from this baseline file")
+	writeBaseline := flags.String("write-baseline", "", "validate, lint: record the current findings in this baseline file and exit")
+	failOn := flags.String("fail-on", "error", "validate, lint: lowest severity that fails, error, warning, info or none")
+	outputFormat := flags.String("format", "", "output format: text, json, jsonl, github, markdown, badge or html for findings, see parser.OutputSchema; json, dot or mermaid for graph; text or json for rules")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if *asJSON {
+		*outputFormat = "json"
+	}
+	if command == "rules" {
+		return listRules(*outputFormat, stdout, stderr)
+	}
+
+	paths := flags.Args()
+	root, err := repoRoot(".")
+	if len(paths) == 0 {
+		if err != nil {
+			fmt.Fprintf(stderr, "gh-actions-parse: %v\n", err)
+			return 2
+		}
+		paths = []string{filepath.Join(root, ".github", "workflows")}
+	}
+	if err != nil {
+		root = "."
+	}
+
+	switch command {
+	case "validate", "lint":
+		resolver, err := newResolver(root, *remote, *snapshot)
+		if err != nil {
+			fmt.Fprintf(stderr, "gh-actions-parse: %v\n", err)
+			return 2
+		}
+		c := checkConfig{root: root, resolver: resolver, format: *outputFormat}
+		if *failOn != "none" {
+			if c.threshold, err = parser.ParseSeverity(*failOn); err != nil {
+				fmt.Fprintf(stderr, "gh-actions-parse: -fail-on: %v\n", err)
+				return 2
+			}
+		}
+		if c.config, err = loadConfig(root, *configPath); err != nil {
+			fmt.Fprintf(stderr, "gh-actions-parse: %v\n", err)
+			return 2
+		}
+		if *baselinePath != "" {
+			if c.baseline, err = readBaseline(*baselinePath); err != nil {
+				fmt.Fprintf(stderr, "gh-actions-parse: %v\n", err)
+				return 2
+			}
+		}
+		c.writeBaseline = *writeBaseline
+		return check(command, paths, c, stdout, stderr)
+	case "snapshot":
+		resolver, err := newResolver(root, true, *snapshot)
+		if err != nil {
+			fmt.Fprintf(stderr, "gh-actions-parse: %v\n", err)
+			return 2
+		}
+		return captureSnapshot(paths, resolver, *output, stdout, stderr)
+	case "fmt":
+		return format(paths, *write, stdout, stderr)
+	case "graph":
+		dir := root
+		if flags.NArg() > 0 {
+			dir = flags.Arg(0)
+		}
+		var resolver parser.Resolver
+		if *remote || *snapshot != "" {
+			if resolver, err = newResolver(dir, *remote, *snapshot); err != nil {
+				fmt.Fprintf(stderr, "gh-actions-parse: %v\n", err)
+				return 2
+			}
+		}
+		return callGraph(dir, *outputFormat, resolver, stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "gh-actions-parse: unknown command %q\n\n%s", command, usage)
+		return 2
+	}
+}
+
+// newResolver resolves local references against the repository, then
+// references found in the snapshot file, then remote references if enabled
+func newResolver(root string, remote bool, snapshot string) (parser.Resolver, error) {
+	chain := parser.ChainResolver{parser.LocalResolver{Root: root}}
+	if snapshot != "" {
+		file, err := os.Open(snapshot)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		bundle, err := parser.ReadSnapshot(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", snapshot, err)
+		}
+		chain = append(chain, bundle)
+	}
+	if remote {
+		clientOpts := []github.Option{github.WithToken(githubToken())}
+		if dir, err := os.UserCacheDir(); err == nil {
+			clientOpts = append(clientOpts, github.WithCache(github.DiskCache{Dir: filepath.Join(dir, "gh-actions-parse")}))
+		}
+		chain = append(chain, github.Resolver{Client: github.NewClient(clientOpts...)})
+	}
+	return chain, nil
+}
+
+// captureSnapshot writes a snapshot of everything the files at paths
+// reference to output, or to stdout if output is empty
+func captureSnapshot(paths []string, resolver parser.Resolver, output string, stdout, stderr io.Writer) int {
+	files, err := parsePaths(paths)
+	if err == nil {
+		var bundle *parser.Snapshot
+		bundle, err = parser.CaptureSnapshot(files, resolver)
+		if err == nil {
+			for _, uses := range bundle.Missing {
+				fmt.Fprintf(stderr, "gh-actions-parse: warning: could not resolve %s\n", uses)
+			}
+			err = writeOutput(output, stdout, bundle.Write)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "gh-actions-parse: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+// callGraph prints the call graph of the workflows and actions of the
+// repository at dir, leaving out nested projects
+func callGraph(dir, format string, resolver parser.Resolver, stdout, stderr io.Writer) int {
+	projects, err := parser.ScanProjects(dir)
+	if err != nil {
+		fmt.Fprintf(stderr, "gh-actions-parse: %v\n", err)
+		return 2
+	}
+	var files parser.WorkflowSet
+	for _, project := range projects {
+		if project.Dir == "." {
+			files = project.Files
+		}
+	}
+	graph := parser.BuildCallGraph(files, parser.WithResolver(resolver))
+
+	switch format {
+	case "", "json":
+		err = parser.NewCallGraphOutput(graph).Write(stdout)
+	case "dot":
+		_, err = io.WriteString(stdout, graph.DOT())
+	case "mermaid":
+		_, err = io.WriteString(stdout, graph.Mermaid())
+	default:
+		err = fmt.Errorf("unknown graph format %q", format)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "gh-actions-parse: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+// listRules prints the rules of validate and lint
+func listRules(format string, stdout, stderr io.Writer) int {
+	var err error
+	switch format {
+	case "", "text":
+		for _, rule := range parser.Rules() {
+			if _, err = fmt.Fprintf(stdout, "%-24s %-8s %-12s %s\n", rule.ID, rule.Severity, rule.Category, rule.Description); err != nil {
+				break
+			}
+		}
+	case "json":
+		err = parser.NewRulesOutput(parser.Rules()).Write(stdout)
+	default:
+		err = fmt.Errorf("unknown rules format %q", format)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "gh-actions-parse: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+// writeOutput calls write with the file at path, or with stdout if path is
+// empty
+func writeOutput(path string, stdout io.Writer, write func(io.Writer) error) error {
+	if path == "" {
+		return write(stdout)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// loadConfig reads the project configuration at path, or the one at the
+// repository root if path is empty
+func loadConfig(root, path string) (*parser.Config, error) {
+	if path == "" {
+		return parser.LoadConfig(root)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parser.ParseConfig(file)
+}
+
+// repoPath returns the slash-separated path of a file relative to the
+// repository root, or the path itself if it is outside the repository
+func repoPath(root, path string) string {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	rel, err := filepath.Rel(absRoot, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// checkConfig holds the settings of the validate and lint commands
+type checkConfig struct {
+	// root is the repository root, which config paths are relative to
+	root     string
+	resolver parser.Resolver
+	config   *parser.Config
+	format   string
+	// threshold is the lowest severity failing the check, empty to never
+	// fail
+	threshold parser.Severity
+	// baseline suppresses accepted findings
+	baseline *parser.Baseline
+	// writeBaseline is the path to record the findings at instead of
+	// reporting them
+	writeBaseline string
+}
+
+// readBaseline reads the baseline file at path
+func readBaseline(path string) (*parser.Baseline, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parser.ReadBaseline(file)
+}
+
+// check validates or lints the files at paths and prints the findings. It
+// returns 1 if a finding is at least as severe as the threshold.
+func check(command string, paths []string, c checkConfig, stdout, stderr io.Writer) int {
+	format, threshold := c.format, c.threshold
+	reporter, ok := newFindingReporter(format, stdout)
+	if !ok {
+		fmt.Fprintf(stderr, "gh-actions-parse: unknown format %q for %s\n", format, command)
+		return 2
+	}
+	var parseOpts []parser.Option
+	if c.config != nil {
+		parseOpts = append(parseOpts, parser.WithExtensions(c.config.Extensions))
+	}
+	files, err := parsePaths(paths, parseOpts...)
+	if err != nil {
+		fmt.Fprintf(stderr, "gh-actions-parse: %v\n", err)
+		return 2
+	}
+
+	for path := range files {
+		if c.config.Ignored(repoPath(c.root, path)) {
+			delete(files, path)
+		}
+	}
+
+	opts := []parser.Option{parser.WithResolver(c.resolver), parser.WithConfig(c.config)}
+	findings := make(map[string][]parser.ValidationError)
+	for _, path := range files.Paths() {
+		if command == "validate" {
+			findings[path] = parser.NewValidator(opts...).Validate(files[path])
+		} else {
+			findings[path] = parser.NewLinter(opts...).Lint(files[path])
+			if c.config.IsRequiredWorkflow(repoPath(c.root, path)) {
+				findings[path] = append(findings[path], parser.CheckRequiredWorkflow(files[path])...)
+			}
+		}
+	}
+
+	for path, collisions := range parser.NameCollisionFindings(files) {
+		findings[path] = append(findings[path], collisions...)
+	}
+	for path := range findings {
+		findings[path] = c.config.Apply(findings[path])
+	}
+
+	// Baselines use paths relative to the repository root so they work
+	// from any directory
+	if c.baseline != nil || c.writeBaseline != "" {
+		byRepoPath := make(map[string][]parser.ValidationError, len(findings))
+		paths := make(map[string]string, len(findings))
+		for path, list := range findings {
+			byRepoPath[repoPath(c.root, path)] = list
+			paths[repoPath(c.root, path)] = path
+		}
+		if c.writeBaseline != "" {
+			baseline := parser.NewBaseline(byRepoPath)
+			if err := writeOutput(c.writeBaseline, stdout, baseline.Write); err != nil {
+				fmt.Fprintf(stderr, "gh-actions-parse: %v\n", err)
+				return 2
+			}
+			fmt.Fprintf(stderr, "%s: recorded %d findings in %s\n", command, len(baseline.Findings), c.writeBaseline)
+			return 0
+		}
+		for rel, list := range c.baseline.Filter(byRepoPath) {
+			findings[paths[rel]] = list
+		}
+	}
+
+	if format == "json" {
+		kind := parser.AnalysisLint
+		if command == "validate" {
+			kind = parser.AnalysisValidation
+		}
+		if err := parser.NewFindingsOutput(kind, findings).Write(stdout); err != nil {
+			fmt.Fprintf(stderr, "gh-actions-parse: %v\n", err)
+			return 2
+		}
+	}
+
+	if format == "markdown" {
+		summary := &parser.MarkdownSummary{
+			Title:        "gh actions-parse " + command,
+			Findings:     parser.LocateFindings(files, findings),
+			Dependencies: parser.Dependencies(files),
+			Permissions:  parser.EffectivePermissions(files),
+		}
+		if err := summary.Write(stdout); err != nil {
+			fmt.Fprintf(stderr, "gh-actions-parse: %v\n", err)
+			return 2
+		}
+	}
+	if format == "html" {
+		page := &parser.HTMLReport{
+			Title:        "gh actions-parse " + command,
+			Findings:     parser.LocateFindings(files, findings),
+			JobGraphs:    make(map[string]*parser.JobGraph),
+			Dependencies: parser.Dependencies(files),
+		}
+		for path, action := range files {
+			if graph, err := parser.BuildJobGraph(action); err == nil {
+				page.JobGraphs[path] = graph
+			}
+		}
+		if err := page.Write(stdout); err != nil {
+			fmt.Fprintf(stderr, "gh-actions-parse: %v\n", err)
+			return 2
+		}
+	}
+	report := parser.NewReport(findings)
+	if format == "badge" {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report.Badge()); err != nil {
+			fmt.Fprintf(stderr, "gh-actions-parse: %v\n", err)
+			return 2
+		}
+	}
+	if reporter != nil {
+		for _, f := range parser.LocateFindings(files, findings) {
+			if err := reporter.Report(f); err != nil {
+				fmt.Fprintf(stderr, "gh-actions-parse: %v\n", err)
+				return 2
+			}
+		}
+	}
+
+	if report.Total() > 0 && (format == "" || format == "text") {
+		fmt.Fprintf(stderr, "%s: %s\n", command, report)
+	}
+	if threshold != "" && !report.Pass(threshold) {
+		return 1
+	}
+	return 0
+}
+
+// findingReporter writes findings one at a time
+type findingReporter interface {
+	Report(f parser.LocatedFinding) error
+}
+
+// newFindingReporter returns the reporter for a -format value. It returns
+// nil for the formats written as a whole: json, markdown, badge and html, and false for unknown formats.
+func newFindingReporter(format string, w io.Writer) (findingReporter, bool) {
+	switch format {
+	case "", "text":
+		return textReporter{w: w}, true
+	case "json", "markdown", "badge", "html":
+		return nil, true
+	case "jsonl":
+		return parser.NewJSONLinesReporter(w), true
+	case "github":
+		return parser.NewAnnotationReporter(w), true
+	}
+	return nil, false
+}
+
+// textReporter writes findings as "file:line:column: severity: field:
+// message [rule]"
+type textReporter struct {
+	w io.Writer
+}
+
+// Report implements findingReporter
+func (r textReporter) Report(f parser.LocatedFinding) error {
+	location := f.File
+	if f.Line > 0 {
+		location = fmt.Sprintf("%s:%d:%d", f.File, f.Line, f.Column)
+	}
+	_, err := fmt.Fprintf(r.w, "%s: %s: %s: %s [%s]\n", location, f.Severity, f.Field, f.Message, f.Rule)
+	return err
+}
+
+// format prints the canonical form of each file, or rewrites the files that
+// are not formatted when write is set
+func format(paths []string, write bool, stdout, stderr io.Writer) int {
+	files, err := yamlFiles(paths)
+	if err != nil {
+		fmt.Fprintf(stderr, "gh-actions-parse: %v\n", err)
+		return 2
+	}
+
+	code := 0
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			var out []byte
+			out, err = parser.Format(data)
+			if err == nil && write && string(out) != string(data) {
+				err = os.WriteFile(path, out, 0o644)
+				fmt.Fprintln(stdout, path)
+			} else if err == nil && !write {
+				_, err = stdout.Write(out)
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "gh-actions-parse: %s: %v\n", path, err)
+			code = 1
+		}
+	}
+	return code
+}
+
+// parsePaths parses the files at paths with positions and opts, descending
+// into directories
+func parsePaths(paths []string, opts ...parser.Option) (parser.WorkflowSet, error) {
+	opts = append([]parser.Option{parser.WithPositions()}, opts...)
+	files := make(parser.WorkflowSet)
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			action, err := parser.ParseFile(path, opts...)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			files[path] = action
+			continue
+		}
+		dir, err := parser.ParseDir(path, opts...)
+		if err != nil {
+			return nil, err
+		}
+		for rel, action := range dir {
+			files[filepath.Join(path, rel)] = action
+		}
+	}
+	return files, nil
+}
+
+// yamlFiles lists the YAML files at paths, descending into directories
+func yamlFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if ext := filepath.Ext(p); !info.IsDir() && (ext == ".yml" || ext == ".yaml") {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scagogogo/github-action-parser/pkg/parser"
+)
+
+func writeWorkflow(t *testing.T, root, name, content string) string {
+	t.Helper()
+	dir := filepath.Join(root, ".github", "workflows")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRepoRoot(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeWorkflow(t, root, "ci.yml", "on: push\n")
+
+	got, err := repoRoot(filepath.Join(root, ".github", "workflows"))
+	if err != nil {
+		t.Fatalf("repoRoot failed: %v", err)
+	}
+	want, _ := filepath.EvalSymlinks(root)
+	if resolved, _ := filepath.EvalSymlinks(got); resolved != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
+
+func TestRunValidate(t *testing.T) {
+	root := t.TempDir()
+	path := writeWorkflow(t, root, "ci.yml", "on: push\njobs:\n  build:\n    steps:\n      - run: make\n")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"validate", path}, &stdout, &stderr)
+	if code != 1 {
+		t.Errorf("Expected exit code 1, got %d (%s)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "jobs.build") || !strings.Contains(stdout.String(), "[job-runner]") {
+		t.Errorf("Expected job-runner error, got %q", stdout.String())
+	}
+}
+
+func TestRunLintNameCollision(t *testing.T) {
+	root := t.TempDir()
+	workflow := "name: CI\non: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make\n"
+	writeWorkflow(t, root, "ci.yml", workflow)
+	writeWorkflow(t, root, "ci-old.yml", workflow)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"lint", filepath.Join(root, ".github", "workflows")}, &stdout, &stderr); code != 0 {
+		t.Errorf("Expected exit code 0, got %d (%s)", code, stderr.String())
+	}
+	if n := strings.Count(stdout.String(), "[workflow-name-collision]"); n != 2 {
+		t.Errorf("Expected a collision warning for both files, got %q", stdout.String())
+	}
+}
+
+func TestRunFormat(t *testing.T) {
+	root := t.TempDir()
+	path := writeWorkflow(t, root, "ci.yml", "jobs:\n    build:\n        runs-on: ubuntu-latest\non: push\n")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"fmt", "-w", path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d (%s)", code, stderr.String())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n" {
+		t.Errorf("Unexpected formatted file:\n%s", data)
+	}
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"bogus", "."}, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2, got %d", code)
+	}
+}
+
+func TestRunSnapshot(t *testing.T) {
+	root := t.TempDir()
+	path := writeWorkflow(t, root, "ci.yml", "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make\n")
+	bundle := filepath.Join(root, "bundle.json")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"snapshot", "-o", bundle, path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d (%s)", code, stderr.String())
+	}
+	if code := run([]string{"validate", "-snapshot", bundle, path}, &stdout, &stderr); code != 0 {
+		t.Errorf("Expected exit code 0, got %d (%s%s)", code, stdout.String(), stderr.String())
+	}
+}
+
+func TestRunGraph(t *testing.T) {
+	root := t.TempDir()
+	writeWorkflow(t, root, "ci.yml", "on: push\njobs:\n  build:\n    uses: ./.github/workflows/build.yml\n")
+	writeWorkflow(t, root, "build.yml", "on: workflow_call\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/checkout@v4\n")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"graph", "-format", "mermaid", root}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d (%s)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "flowchart LR") || !strings.Contains(stdout.String(), "actions/checkout@v4") {
+		t.Errorf("Expected a Mermaid call graph, got %q", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := run([]string{"graph", root}, &stdout, &stderr); code != 0 || !strings.Contains(stdout.String(), `"from": "./.github/workflows/ci.yml"`) {
+		t.Errorf("Expected a JSON call graph, got %d %q", code, stdout.String())
+	}
+	if code := run([]string{"graph", "-format", "svg", root}, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2 for an unknown format, got %d", code)
+	}
+}
+
+func TestRunRules(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"rules"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d (%s)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "branch-pinned-action") || !strings.Contains(stdout.String(), "security") {
+		t.Errorf("Expected a list of rules, got %q", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := run([]string{"rules", "-json"}, &stdout, &stderr); code != 0 || !strings.Contains(stdout.String(), `"kind": "rules"`) {
+		t.Errorf("Expected rules as JSON, got %d %q", code, stdout.String())
+	}
+}
+
+func TestRunValidateJSON(t *testing.T) {
+	root := t.TempDir()
+	path := writeWorkflow(t, root, "ci.yml", "on: push\njobs:\n  build:\n    steps:\n      - run: make\n")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"validate", "-json", path}, &stdout, &stderr); code != 1 {
+		t.Errorf("Expected exit code 1, got %d (%s)", code, stderr.String())
+	}
+	out, err := parser.ReadAnalysisOutput(&stdout)
+	if err != nil {
+		t.Fatalf("Expected versioned JSON output, got %v", err)
+	}
+	if out.Kind != parser.AnalysisValidation || len(out.Files) != 1 || out.Files[0].Path != path {
+		t.Errorf("Unexpected output %+v", out)
+	}
+}
+
+func TestRunValidateJSONLines(t *testing.T) {
+	root := t.TempDir()
+	path := writeWorkflow(t, root, "ci.yml", "on: push\njobs:\n  build:\n    steps:\n      - run: make\n")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"validate", "-format", "jsonl", path}, &stdout, &stderr); code != 1 {
+		t.Errorf("Expected exit code 1, got %d (%s)", code, stderr.String())
+	}
+	want := `{"file":"` + path + `","line":3,"column":3,"field":"jobs.build"`
+	if !strings.HasPrefix(stdout.String(), want) {
+		t.Errorf("Expected a JSON line starting with %s, got %q", want, stdout.String())
+	}
+
+	if code := run([]string{"validate", "-format", "xml", path}, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2 for an unknown format, got %d", code)
+	}
+}
+
+func TestRunLintFailOn(t *testing.T) {
+	root := t.TempDir()
+	workflow := "name: CI\non: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make\n"
+	writeWorkflow(t, root, "ci.yml", workflow)
+	writeWorkflow(t, root, "ci-old.yml", workflow)
+	dir := filepath.Join(root, ".github", "workflows")
+
+	for failOn, want := range map[string]int{"error": 0, "warning": 1, "none": 0, "fatal": 2} {
+		var stdout, stderr bytes.Buffer
+		if code := run([]string{"lint", "-fail-on", failOn, dir}, &stdout, &stderr); code != want {
+			t.Errorf("-fail-on %s: expected exit code %d, got %d (%s)", failOn, want, code, stderr.String())
+		}
+	}
+}
+
+func TestRunValidateAnnotations(t *testing.T) {
+	root := t.TempDir()
+	path := writeWorkflow(t, root, "ci.yml", "on: push\njobs:\n  build:\n    steps:\n      - run: make\n")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"validate", "-format", "github", path}, &stdout, &stderr); code != 1 {
+		t.Errorf("Expected exit code 1, got %d (%s)", code, stderr.String())
+	}
+	want := "::error file=" + path + ",line=3,col=3,title=job-runner::jobs.build: "
+	if !strings.HasPrefix(stdout.String(), want) {
+		t.Errorf("Expected an annotation starting with %s, got %q", want, stdout.String())
+	}
+}
+
+func TestRunLintMarkdown(t *testing.T) {
+	root := t.TempDir()
+	writeWorkflow(t, root, "ci.yml", "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/checkout@v4\n")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"lint", "-format", "markdown", filepath.Join(root, ".github", "workflows")}, &stdout, &stderr); code != 0 {
+		t.Errorf("Expected exit code 0, got %d (%s)", code, stderr.String())
+	}
+	for _, want := range []string{"## gh actions-parse lint", "### Dependencies", "### Permissions"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("Expected %q in summary, got:\n%s", want, stdout.String())
+		}
+	}
+}
+
+func TestRunValidateBadge(t *testing.T) {
+	root := t.TempDir()
+	path := writeWorkflow(t, root, "ci.yml", "on: push\njobs:\n  build:\n    steps:\n      - run: make\n")
+
+	var stdout, stderr bytes.Buffer
+	run([]string{"validate", "-format", "badge", path}, &stdout, &stderr)
+	var badge parser.Badge
+	if err := json.Unmarshal(stdout.Bytes(), &badge); err != nil {
+		t.Fatalf("Expected badge JSON, got %q (%v)", stdout.String(), err)
+	}
+	if badge.SchemaVersion != 1 || badge.Message != "1 issue" || badge.Color != "red" {
+		t.Errorf("Unexpected badge %+v", badge)
+	}
+}
+
+func TestRunLintHTML(t *testing.T) {
+	root := t.TempDir()
+	writeWorkflow(t, root, "ci.yml", "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/checkout@v4\n")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"lint", "-format", "html", filepath.Join(root, ".github", "workflows")}, &stdout, &stderr); code != 0 {
+		t.Errorf("Expected exit code 0, got %d (%s)", code, stderr.String())
+	}
+	for _, want := range []string{"<title>gh actions-parse lint</title>", `<div class="graph"><svg`, "<code>actions/checkout@v4</code>"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("Expected %q in report, got:\n%s", want, stdout.String())
+		}
+	}
+}
+
+func TestRunLintConfig(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	workflow := "name: CI\non: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: someone/tool@v1\n"
+	writeWorkflow(t, root, "ci.yml", workflow)
+	writeWorkflow(t, root, "legacy-ci.yml", workflow)
+	config := "rules:\n  workflow-name-collision: off\nignore: [.github/workflows/legacy-*.yml]\nallowed-owners: [actions]\n"
+	if err := os.WriteFile(filepath.Join(root, parser.ConfigFileName), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"lint"}, &stdout, &stderr); code != 1 {
+		t.Errorf("Expected exit code 1, got %d (%s)", code, stderr.String())
+	}
+	if out := stdout.String(); strings.Count(out, "[disallowed-action]") != 1 || strings.Contains(out, "legacy") || strings.Contains(out, "collision") {
+		t.Errorf("Expected the config to be applied, got %q", out)
+	}
+}
+
+func TestRunLintBaseline(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, ".github", "workflows")
+	writeWorkflow(t, root, "ci.yml", "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: someone/tool@main\n")
+	baseline := filepath.Join(root, "baseline.json")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"lint", "-fail-on", "warning", "-write-baseline", baseline, dir}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d (%s)", code, stderr.String())
+	}
+	if code := run([]string{"lint", "-fail-on", "warning", "-baseline", baseline, dir}, &stdout, &stderr); code != 0 {
+		t.Errorf("Expected baselined findings to pass, got %d (%s)", code, stdout.String())
+	}
+
+	writeWorkflow(t, root, "ci.yml", "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: someone/tool@main\n      - uses: someone/other@main\n")
+	stdout.Reset()
+	if code := run([]string{"lint", "-fail-on", "warning", "-baseline", baseline, dir}, &stdout, &stderr); code != 1 {
+		t.Errorf("Expected a new finding to fail, got %d", code)
+	}
+	if out := stdout.String(); strings.Contains(out, "someone/tool") || !strings.Contains(out, "someone/other") {
+		t.Errorf("Expected only the new finding, got %q", out)
+	}
+}
+
+func TestRunLintRequiredWorkflows(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	workflow := "on: push\njobs:\n  scan:\n    runs-on: ubuntu-latest\n    steps:\n      - run: scan\n"
+	writeWorkflow(t, root, "ci.yml", "name: CI\n"+workflow)
+	writeWorkflow(t, root, "scan.yml", "name: Scan\n"+workflow)
+	config := "required-workflows: [.github/workflows/scan.yml]\n"
+	if err := os.WriteFile(filepath.Join(root, parser.ConfigFileName), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"lint"}, &stdout, &stderr); code != 1 {
+		t.Errorf("Expected exit code 1, got %d (%s)", code, stderr.String())
+	}
+	if out := stdout.String(); strings.Count(out, "[required-workflow]") != 1 || !strings.Contains(out, "scan.yml") {
+		t.Errorf("Expected only scan.yml to be checked as a required workflow, got %q", out)
+	}
+}
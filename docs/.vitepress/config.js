@@ -20,6 +20,7 @@ export default defineConfig({
           { text: 'Getting Started', link: '/getting-started' },
           { text: 'API Reference', link: '/api/' },
           { text: 'Examples', link: '/examples/' },
+          { text: 'Rules', link: '/rules' },
           { text: 'GitHub', link: 'https://github.com/scagogogo/github-action-parser' }
         ],
         sidebar: {
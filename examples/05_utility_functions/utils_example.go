@@ -89,16 +89,7 @@ func parseDirectory(dirPath string) {
 		fmt.Printf("  名称: %s\n", action.Name)
 
 		// 检测文件类型
-		fileType := "未知类型"
-		if action.Runs.Using != "" {
-			fileType = fmt.Sprintf("GitHub Action (%s)", action.Runs.Using)
-		} else if len(action.Jobs) > 0 {
-			fileType = "GitHub Workflow"
-			if parser.IsReusableWorkflow(action) {
-				fileType = "可重用 GitHub Workflow"
-			}
-		}
-		fmt.Printf("  类型: %s\n", fileType)
+		fmt.Printf("  类型: %s\n", parser.DetectType(action))
 
 		// 显示关键统计信息
 		if len(action.Inputs) > 0 {
@@ -0,0 +1,577 @@
+// Package circleci converts CircleCI configuration files into GitHub Actions
+// workflows.
+//
+// The conversion is best effort: executors, jobs, common built-in steps and
+// workflows are mapped onto parser.ActionFile structures, while features
+// without a GitHub Actions equivalent (orbs, contexts, custom commands, ...)
+// are reported as TODO annotations for a human to finish the migration.
+package circleci
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/github-action-parser/pkg/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the subset of a .circleci/config.yml file understood by the
+// importer
+type Config struct {
+	Version   interface{}            `yaml:"version"`
+	Orbs      map[string]interface{} `yaml:"orbs"`
+	Executors map[string]Executor    `yaml:"executors"`
+	Commands  map[string]interface{} `yaml:"commands"`
+	Jobs      map[string]Job         `yaml:"jobs"`
+	Workflows map[string]interface{} `yaml:"workflows"`
+}
+
+// Executor describes the environment a CircleCI job runs in
+type Executor struct {
+	Docker           []DockerImage          `yaml:"docker"`
+	Machine          interface{}            `yaml:"machine"`
+	Macos            map[string]interface{} `yaml:"macos"`
+	ResourceClass    string                 `yaml:"resource_class"`
+	WorkingDirectory string                 `yaml:"working_directory"`
+	Environment      map[string]string      `yaml:"environment"`
+}
+
+// DockerImage is an image of a docker executor
+type DockerImage struct {
+	Image       string            `yaml:"image"`
+	Environment map[string]string `yaml:"environment"`
+}
+
+// Job is a CircleCI job definition
+type Job struct {
+	// Executor holds an inline executor definition
+	Executor `yaml:",inline"`
+
+	// ExecutorRef names a reusable executor, either as a string or as a
+	// mapping with a 'name' key
+	ExecutorRef interface{}            `yaml:"executor"`
+	Parameters  map[string]interface{} `yaml:"parameters"`
+	Parallelism int                    `yaml:"parallelism"`
+	Steps       []interface{}          `yaml:"steps"`
+}
+
+// TODO is a CircleCI feature that could not be mapped automatically
+type TODO struct {
+	// File is the name of the generated workflow file, empty for
+	// configuration-wide notes
+	File string `json:"file,omitempty"`
+	// Field is the path of the affected element in the generated workflow
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// String returns the TODO formatted for display
+func (t TODO) String() string {
+	location := strings.Trim(t.File+" "+t.Field, " ")
+	if location == "" {
+		return t.Message
+	}
+	return location + ": " + t.Message
+}
+
+// Result is the outcome of an import
+type Result struct {
+	// Workflows maps file names such as "build.yml" to the generated workflows
+	Workflows map[string]*parser.ActionFile
+	TODOs     []TODO
+}
+
+// ImportFile imports the CircleCI configuration at path
+func ImportFile(path string) (*Result, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return Import(file)
+}
+
+// Import converts a CircleCI configuration into GitHub Actions workflows
+func Import(r io.Reader) (*Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data: %w", err)
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal YAML: %w", err)
+	}
+
+	imp := &importer{
+		config: &config,
+		result: &Result{Workflows: make(map[string]*parser.ActionFile)},
+	}
+	imp.run()
+	return imp.result, nil
+}
+
+// importer holds the state of a single conversion
+type importer struct {
+	config *Config
+	result *Result
+	file   string
+}
+
+func (imp *importer) todo(field, format string, args ...interface{}) {
+	imp.result.TODOs = append(imp.result.TODOs, TODO{
+		File:    imp.file,
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (imp *importer) run() {
+	orbs := make([]string, 0, len(imp.config.Orbs))
+	for name := range imp.config.Orbs {
+		orbs = append(orbs, name)
+	}
+	sort.Strings(orbs)
+	for _, name := range orbs {
+		imp.todo("", "orb '%s' has no automatic mapping, replace its commands and jobs with equivalent actions", name)
+	}
+
+	names := make([]string, 0, len(imp.config.Workflows))
+	for name, def := range imp.config.Workflows {
+		if _, ok := def.(map[string]interface{}); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		// Configurations without workflows run every job on each push
+		jobNames := make([]string, 0, len(imp.config.Jobs))
+		for name := range imp.config.Jobs {
+			jobNames = append(jobNames, name)
+		}
+		sort.Strings(jobNames)
+		jobs := make([]interface{}, len(jobNames))
+		for i, name := range jobNames {
+			jobs[i] = name
+		}
+		imp.importWorkflow("ci", map[string]interface{}{"jobs": jobs})
+		return
+	}
+
+	for _, name := range names {
+		imp.importWorkflow(name, imp.config.Workflows[name].(map[string]interface{}))
+	}
+}
+
+// importWorkflow converts a single CircleCI workflow
+func (imp *importer) importWorkflow(name string, def map[string]interface{}) {
+	imp.file = fileName(name) + ".yml"
+	workflow := &parser.ActionFile{
+		Name: name,
+		On:   map[string]interface{}{"push": nil},
+		Jobs: make(map[string]parser.Job),
+		Defaults: map[string]interface{}{
+			"run": map[string]interface{}{"shell": "bash"},
+		},
+	}
+	imp.result.Workflows[imp.file] = workflow
+
+	if triggers, ok := def["triggers"].([]interface{}); ok {
+		var schedules []interface{}
+		for _, trigger := range triggers {
+			t, _ := trigger.(map[string]interface{})
+			schedule, _ := t["schedule"].(map[string]interface{})
+			if cron, ok := schedule["cron"].(string); ok {
+				schedules = append(schedules, map[string]interface{}{"cron": cron})
+			}
+		}
+		if len(schedules) > 0 {
+			workflow.On = map[string]interface{}{"schedule": schedules}
+		}
+	}
+
+	entries, _ := def["jobs"].([]interface{})
+	for _, entry := range entries {
+		jobName, settings := workflowJobEntry(entry)
+		jobID := jobName
+		if alias, ok := settings["name"].(string); ok {
+			jobID = alias
+		}
+		jobID = fileName(jobID)
+
+		job, ok := imp.config.Jobs[jobName]
+		if !ok {
+			imp.todo("jobs."+jobID, "job '%s' is not defined in the configuration (orb job?), migrate it manually", jobName)
+			workflow.Jobs[jobID] = parser.Job{RunsOn: "ubuntu-latest", Steps: []parser.Step{todoStep("orb job " + jobName)}}
+			continue
+		}
+
+		ghJob := imp.importJob(jobID, job)
+
+		if requires, ok := settings["requires"].([]interface{}); ok {
+			needs := make([]string, 0, len(requires))
+			for _, r := range requires {
+				if s, ok := r.(string); ok {
+					needs = append(needs, fileName(s))
+				}
+			}
+			ghJob.Needs = needs
+		}
+		if context, ok := settings["context"]; ok {
+			imp.todo("jobs."+jobID, "context %v provides secrets, store them as repository or environment secrets", context)
+		}
+		if filters, ok := settings["filters"].(map[string]interface{}); ok {
+			ghJob.If = imp.filterCondition(jobID, filters)
+		}
+		if matrix, ok := settings["matrix"].(map[string]interface{}); ok {
+			if params, ok := matrix["parameters"].(map[string]interface{}); ok {
+				ghJob.Strategy = map[string]interface{}{"matrix": params}
+				imp.todo("jobs."+jobID, "matrix parameters are available as ${{ matrix.<name> }}, update parameter references")
+			}
+		}
+		if settings["type"] == "approval" {
+			imp.todo("jobs."+jobID, "approval jobs map to environments with required reviewers")
+		}
+
+		workflow.Jobs[jobID] = ghJob
+	}
+}
+
+// filterCondition converts branch filters of a workflow job into an 'if'
+// condition
+func (imp *importer) filterCondition(jobID string, filters map[string]interface{}) string {
+	branches, _ := filters["branches"].(map[string]interface{})
+	var conditions []string
+	for _, b := range stringList(branches["only"]) {
+		conditions = append(conditions, fmt.Sprintf("github.ref == 'refs/heads/%s'", b))
+	}
+	condition := strings.Join(conditions, " || ")
+	for _, b := range stringList(branches["ignore"]) {
+		if condition != "" {
+			condition = "(" + condition + ") && "
+		}
+		condition += fmt.Sprintf("github.ref != 'refs/heads/%s'", b)
+	}
+	if _, ok := filters["tags"]; ok {
+		imp.todo("jobs."+jobID, "tag filters require a 'push: tags:' trigger")
+	}
+	return condition
+}
+
+// importJob converts a CircleCI job
+func (imp *importer) importJob(jobID string, job Job) parser.Job {
+	field := "jobs." + jobID
+	executor := job.Executor
+
+	switch e := job.ExecutorRef.(type) {
+	case string:
+		executor = imp.lookupExecutor(field, e, executor)
+	case map[string]interface{}:
+		if name, ok := e["name"].(string); ok {
+			executor = imp.lookupExecutor(field, name, executor)
+		}
+	}
+
+	ghJob := parser.Job{RunsOn: "ubuntu-latest"}
+
+	switch {
+	case len(executor.Docker) > 0:
+		primary := executor.Docker[0]
+		container := map[string]interface{}{"image": primary.Image}
+		if len(primary.Environment) > 0 {
+			container["env"] = primary.Environment
+		}
+		ghJob.Container = container
+
+		if len(executor.Docker) > 1 {
+			ghJob.Services = make(map[string]interface{})
+			for _, image := range executor.Docker[1:] {
+				service := map[string]interface{}{"image": image.Image}
+				if len(image.Environment) > 0 {
+					service["env"] = image.Environment
+				}
+				ghJob.Services[serviceName(image.Image)] = service
+			}
+			imp.todo(field+".services", "services are reachable by their service name instead of localhost when the job runs in a container")
+		}
+	case executor.Macos != nil:
+		ghJob.RunsOn = "macos-latest"
+		if xcode, ok := executor.Macos["xcode"]; ok {
+			imp.todo(field, "select Xcode %v on the runner (e.g. with sudo xcode-select)", xcode)
+		}
+	case executor.Machine != nil:
+		if machine, ok := executor.Machine.(map[string]interface{}); ok && machine["image"] != nil {
+			imp.todo(field+".runs-on", "machine image %v mapped to ubuntu-latest", machine["image"])
+		}
+	}
+
+	if executor.ResourceClass != "" {
+		imp.todo(field+".runs-on", "resource class '%s' may need a larger runner", executor.ResourceClass)
+	}
+	if executor.WorkingDirectory != "" {
+		ghJob.Defaults = map[string]interface{}{
+			"run": map[string]interface{}{"working-directory": executor.WorkingDirectory},
+		}
+	}
+	if len(executor.Environment) > 0 {
+		ghJob.Env = executor.Environment
+	}
+	if job.Parallelism > 1 {
+		imp.todo(field, "parallelism %d has no direct equivalent, split tests with a matrix instead", job.Parallelism)
+	}
+	if len(job.Parameters) > 0 {
+		imp.todo(field, "job parameters must be turned into matrix values, inputs or env")
+	}
+
+	for _, raw := range job.Steps {
+		stepField := fmt.Sprintf("%s.steps[%d]", field, len(ghJob.Steps))
+		if step, ok := imp.importStep(stepField, jobID, raw); ok {
+			ghJob.Steps = append(ghJob.Steps, step)
+		}
+	}
+
+	return ghJob
+}
+
+// lookupExecutor resolves a reusable executor by name
+func (imp *importer) lookupExecutor(field, name string, fallback Executor) Executor {
+	if executor, ok := imp.config.Executors[name]; ok {
+		return executor
+	}
+	imp.todo(field+".runs-on", "executor '%s' is not defined in the configuration (orb executor?)", name)
+	return fallback
+}
+
+// importStep converts a single CircleCI step, returning false when the step
+// has no equivalent and can be dropped
+func (imp *importer) importStep(field, jobID string, raw interface{}) (parser.Step, bool) {
+	name, args := stepEntry(raw)
+
+	switch name {
+	case "checkout":
+		return parser.Step{Uses: "actions/checkout@v4"}, true
+
+	case "run":
+		step := parser.Step{}
+		if command, ok := args[""].(string); ok {
+			step.Run = command
+			return step, true
+		}
+		step.Name, _ = args["name"].(string)
+		step.Run, _ = args["command"].(string)
+		step.WorkingDir, _ = args["working_directory"].(string)
+		step.Shell, _ = args["shell"].(string)
+		if env, ok := args["environment"].(map[string]interface{}); ok {
+			step.Env = make(map[string]string, len(env))
+			for k, v := range env {
+				step.Env[k] = fmt.Sprint(v)
+			}
+		}
+		if when, ok := args["when"].(string); ok {
+			switch when {
+			case "always":
+				step.If = "always()"
+			case "on_fail":
+				step.If = "failure()"
+			}
+		}
+		if args["background"] == true {
+			imp.todo(field, "background steps are not supported, start the process with '&' or use a service")
+		}
+		return step, true
+
+	case "restore_cache":
+		keys := stringList(args["keys"])
+		if key, ok := args["key"].(string); ok {
+			keys = append([]string{key}, keys...)
+		}
+		if len(keys) == 0 {
+			return todoStep("restore_cache without keys"), true
+		}
+		with := map[string]interface{}{"key": convertTemplate(keys[0])}
+		if len(keys) > 1 {
+			restore := make([]string, len(keys)-1)
+			for i, k := range keys[1:] {
+				restore[i] = convertTemplate(k)
+			}
+			with["restore-keys"] = strings.Join(restore, "\n")
+		}
+		imp.todo(field, "set 'path' to the paths saved by the matching save_cache step")
+		with["path"] = "TODO"
+		return parser.Step{Uses: "actions/cache/restore@v4", With: with}, true
+
+	case "save_cache":
+		return parser.Step{Uses: "actions/cache/save@v4", With: map[string]interface{}{
+			"key":  convertTemplate(fmt.Sprint(args["key"])),
+			"path": strings.Join(stringList(args["paths"]), "\n"),
+		}}, true
+
+	case "store_artifacts":
+		artifactPath := fmt.Sprint(args["path"])
+		artifactName, ok := args["destination"].(string)
+		if !ok {
+			artifactName = path.Base(artifactPath)
+		}
+		return parser.Step{Uses: "actions/upload-artifact@v4", With: map[string]interface{}{
+			"name": artifactName,
+			"path": artifactPath,
+		}}, true
+
+	case "store_test_results":
+		imp.todo(field, "test results are uploaded as an artifact, add a test reporter action for annotations")
+		return parser.Step{Uses: "actions/upload-artifact@v4", With: map[string]interface{}{
+			"name": "test-results-" + jobID,
+			"path": fmt.Sprint(args["path"]),
+		}}, true
+
+	case "persist_to_workspace":
+		root := fmt.Sprint(args["root"])
+		paths := stringList(args["paths"])
+		for i, p := range paths {
+			paths[i] = path.Join(root, p)
+		}
+		return parser.Step{Uses: "actions/upload-artifact@v4", With: map[string]interface{}{
+			"name": "workspace-" + jobID,
+			"path": strings.Join(paths, "\n"),
+		}}, true
+
+	case "attach_workspace":
+		return parser.Step{Uses: "actions/download-artifact@v4", With: map[string]interface{}{
+			"pattern":        "workspace-*",
+			"path":           fmt.Sprint(args["at"]),
+			"merge-multiple": true,
+		}}, true
+
+	case "setup_remote_docker":
+		// Docker is available on GitHub-hosted Linux runners
+		return parser.Step{}, false
+
+	case "add_ssh_keys":
+		imp.todo(field, "SSH keys must be stored as secrets and loaded, e.g. with webfactory/ssh-agent")
+		return todoStep("add_ssh_keys"), true
+	}
+
+	if strings.Contains(name, "/") {
+		imp.todo(field, "orb command '%s' has no automatic mapping", name)
+	} else if _, ok := imp.config.Commands[name]; ok {
+		imp.todo(field, "reusable command '%s' should become a composite action", name)
+	} else {
+		imp.todo(field, "step '%s' has no automatic mapping", name)
+	}
+	return todoStep(name), true
+}
+
+// templatePatterns converts CircleCI cache key templates into expressions
+var templatePatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`\{\{\s*checksum\s+"([^"]+)"\s*\}\}`), "${{ hashFiles('$1') }}"},
+	{regexp.MustCompile(`\{\{\s*\.Branch\s*\}\}`), "${{ github.ref_name }}"},
+	{regexp.MustCompile(`\{\{\s*\.Revision\s*\}\}`), "${{ github.sha }}"},
+	{regexp.MustCompile(`\{\{\s*arch\s*\}\}`), "${{ runner.os }}-${{ runner.arch }}"},
+	{regexp.MustCompile(`\{\{\s*\.Environment\.(\w+)\s*\}\}`), "${{ env.$1 }}"},
+	{regexp.MustCompile(`\{\{\s*epoch\s*\}\}`), "${{ github.run_id }}"},
+}
+
+// convertTemplate converts a CircleCI cache key template
+func convertTemplate(s string) string {
+	for _, t := range templatePatterns {
+		s = t.pattern.ReplaceAllString(s, t.replacement)
+	}
+	return s
+}
+
+// todoStep returns a placeholder step marking unmapped functionality
+func todoStep(what string) parser.Step {
+	return parser.Step{
+		Name: "TODO: migrate " + what,
+		Run:  fmt.Sprintf("echo 'TODO: migrate %s from CircleCI'", strings.ReplaceAll(what, "'", "")),
+	}
+}
+
+// stepEntry splits a step into its name and arguments. The plain form of
+// 'run' is returned with the command under the "" key.
+func stepEntry(raw interface{}) (string, map[string]interface{}) {
+	switch step := raw.(type) {
+	case string:
+		return step, map[string]interface{}{}
+	case map[string]interface{}:
+		for name, args := range step {
+			switch a := args.(type) {
+			case map[string]interface{}:
+				return name, a
+			case nil:
+				return name, map[string]interface{}{}
+			default:
+				return name, map[string]interface{}{"": a}
+			}
+		}
+	}
+	return "", map[string]interface{}{}
+}
+
+// workflowJobEntry splits a workflow job entry into the job name and its
+// settings
+func workflowJobEntry(entry interface{}) (string, map[string]interface{}) {
+	switch e := entry.(type) {
+	case string:
+		return e, map[string]interface{}{}
+	case map[string]interface{}:
+		for name, settings := range e {
+			s, _ := settings.(map[string]interface{})
+			if s == nil {
+				s = map[string]interface{}{}
+			}
+			return name, s
+		}
+	}
+	return "", map[string]interface{}{}
+}
+
+// stringList converts a string or list of strings
+func stringList(v interface{}) []string {
+	switch value := v.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		result := make([]string, 0, len(value))
+		for _, item := range value {
+			result = append(result, fmt.Sprint(item))
+		}
+		return result
+	}
+	return nil
+}
+
+// serviceName derives a service name from a docker image such as
+// "cimg/postgres:14.1"
+func serviceName(image string) string {
+	name := path.Base(image)
+	if colon := strings.Index(name, ":"); colon >= 0 {
+		name = name[:colon]
+	}
+	return name
+}
+
+// fileName converts a CircleCI name into an identifier usable as job ID and
+// file name
+func fileName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,106 @@
+package circleci
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/scagogogo/github-action-parser/pkg/parser"
+)
+
+func TestImportFile(t *testing.T) {
+	result, err := ImportFile("testdata/config.yml")
+	if err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+
+	if len(result.Workflows) != 2 {
+		t.Fatalf("Expected 2 workflows, got %d", len(result.Workflows))
+	}
+
+	workflow, ok := result.Workflows["build-and-deploy.yml"]
+	if !ok {
+		t.Fatalf("Expected build-and-deploy.yml, got %v", result.Workflows)
+	}
+	if errs := parser.NewValidator().Validate(workflow); len(errs) > 0 {
+		t.Errorf("Expected generated workflow to be valid, got %v", errs)
+	}
+
+	build := workflow.Jobs["build"]
+	container, _ := build.Container.(map[string]interface{})
+	if container["image"] != "cimg/node:20.11" {
+		t.Errorf("Expected primary image as container, got %v", build.Container)
+	}
+	if _, ok := build.Services["postgres"]; !ok {
+		t.Errorf("Expected secondary image as service, got %v", build.Services)
+	}
+	if len(build.Steps) != 7 {
+		t.Fatalf("Expected 7 steps, got %d", len(build.Steps))
+	}
+	if build.Steps[0].Uses != "actions/checkout@v4" {
+		t.Errorf("Expected checkout, got %+v", build.Steps[0])
+	}
+	if build.Steps[1].Uses != "actions/cache/restore@v4" || build.Steps[1].With["key"] != "deps-${{ hashFiles('package-lock.json') }}" {
+		t.Errorf("Unexpected restore_cache mapping %+v", build.Steps[1])
+	}
+	if build.Steps[4].Name != "Build" || build.Steps[4].Env["CI"] != "true" {
+		t.Errorf("Unexpected run mapping %+v", build.Steps[4])
+	}
+	if !strings.HasPrefix(build.Steps[6].Name, "TODO") {
+		t.Errorf("Expected a placeholder for the orb command, got %+v", build.Steps[6])
+	}
+
+	deploy := workflow.Jobs["deploy"]
+	if deploy.If != "github.ref == 'refs/heads/main'" {
+		t.Errorf("Expected branch filter as condition, got %q", deploy.If)
+	}
+	if needs := parser.JobNeeds(deploy); len(needs) != 1 || needs[0] != "test" {
+		t.Errorf("Expected deploy to need test, got %v", needs)
+	}
+
+	nightly := result.Workflows["nightly.yml"]
+	on, _ := nightly.On.(map[string]interface{})
+	if _, ok := on["schedule"]; !ok {
+		t.Errorf("Expected scheduled trigger, got %v", nightly.On)
+	}
+
+	expected := []string{"orb 'slack'", "parallelism 4", "context production", "orb command 'slack/notify'", "machine image"}
+	for _, want := range expected {
+		found := false
+		for _, todo := range result.TODOs {
+			if strings.Contains(todo.String(), want) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected a TODO mentioning %q", want)
+		}
+	}
+}
+
+func TestImportWithoutWorkflows(t *testing.T) {
+	config := `version: 2
+jobs:
+  build:
+    docker:
+      - image: golang:1.22
+    steps:
+      - checkout
+      - run: go test ./...
+`
+	result, err := Import(strings.NewReader(config))
+	if err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+	workflow, ok := result.Workflows["ci.yml"]
+	if !ok || len(workflow.Jobs) != 1 {
+		t.Fatalf("Expected a single ci.yml workflow with one job, got %v", result.Workflows)
+	}
+}
+
+func TestConvertTemplate(t *testing.T) {
+	got := convertTemplate(`v1-{{ .Branch }}-{{ checksum "go.sum" }}-{{ arch }}`)
+	expected := "v1-${{ github.ref_name }}-${{ hashFiles('go.sum') }}-${{ runner.os }}-${{ runner.arch }}"
+	if got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,106 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Unexpected Authorization header %q", got)
+		}
+	}))
+	defer server.Close()
+
+	resp, err := NewClient(WithBaseURL(server.URL), WithToken("secret")).Get(context.Background(), "/", nil)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestAppTokenSource(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	var exchanges int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/app/installations/42/access_tokens" {
+			atomic.AddInt32(&exchanges, 1)
+			verifyJWT(t, &key.PublicKey, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"token":      "installation-token",
+				"expires_at": time.Now().Add(time.Hour),
+			})
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer installation-token" {
+			t.Errorf("Unexpected Authorization header %q", got)
+		}
+	}))
+	defer server.Close()
+
+	ts, err := NewAppTokenSource(7, 42, pemKey, WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("NewAppTokenSource failed: %v", err)
+	}
+	c := NewClient(WithBaseURL(server.URL), WithTokenSource(ts))
+	for i := 0; i < 2; i++ {
+		resp, err := c.Get(context.Background(), "/repos/octo/private", nil)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+	if exchanges != 1 {
+		t.Errorf("Expected the installation token to be cached, got %d exchanges", exchanges)
+	}
+}
+
+func TestNewAppTokenSourceInvalidKey(t *testing.T) {
+	if _, err := NewAppTokenSource(1, 2, []byte("not a key")); err == nil {
+		t.Errorf("Expected error for invalid private key")
+	}
+}
+
+// verifyJWT checks the signature and issuer of an App JWT
+func verifyJWT(t *testing.T, key *rsa.PublicKey, jwt string) {
+	t.Helper()
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Fatalf("Malformed JWT %q", jwt)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		t.Errorf("Invalid JWT signature: %v", err)
+	}
+	claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var c struct {
+		Issuer string `json:"iss"`
+	}
+	if err := json.Unmarshal(claims, &c); err != nil || c.Issuer != "7" {
+		t.Errorf("Unexpected claims %s", claims)
+	}
+}
@@ -0,0 +1,142 @@
+package github
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CacheEntry is a cached response body with its ETag
+type CacheEntry struct {
+	ETag string `json:"etag"`
+	Body []byte `json:"body"`
+}
+
+// Cache stores responses to GET requests so they can be revalidated with
+// conditional requests. GitHub does not count 304 Not Modified responses
+// against the rate limit.
+type Cache interface {
+	Get(key string) (CacheEntry, bool)
+	Set(key string, entry CacheEntry)
+}
+
+// WithCache caches responses to GET requests that carry an ETag
+func WithCache(cache Cache) Option {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// MemoryCache is a Cache held in memory, for the lifetime of a process
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]CacheEntry
+}
+
+// NewMemoryCache creates an empty MemoryCache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]CacheEntry)}
+}
+
+// Get implements Cache
+func (m *MemoryCache) Get(key string) (CacheEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.entries[key]
+	return entry, ok
+}
+
+// Set implements Cache
+func (m *MemoryCache) Set(key string, entry CacheEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[key] = entry
+}
+
+// DiskCache is a Cache stored as one file per entry in a directory, so that
+// repeated scans can share it across runs. Entries that cannot be read or
+// written are treated as missing.
+type DiskCache struct {
+	Dir string
+}
+
+// Get implements Cache
+func (d DiskCache) Get(key string) (CacheEntry, bool) {
+	data, err := os.ReadFile(d.path(key))
+	if err != nil {
+		return CacheEntry{}, false
+	}
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return CacheEntry{}, false
+	}
+	return entry, true
+}
+
+// Set implements Cache
+func (d DiskCache) Set(key string, entry CacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(d.Dir, 0o755); err != nil {
+		return
+	}
+	// Write to a temporary file first so concurrent readers never see a
+	// partial entry
+	tmp, err := os.CreateTemp(d.Dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), d.path(key)); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+func (d DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// cacheKey identifies a GET request in the cache. The Accept header is part
+// of the key since it selects the representation.
+func cacheKey(req *http.Request) string {
+	return fmt.Sprintf("%s %s", req.Header.Get("Accept"), req.URL.String())
+}
+
+// useCache serves a 304 Not Modified response from the cache and stores
+// fresh responses that carry an ETag
+func (c *Client) useCache(key string, cached CacheEntry, hit bool, resp *http.Response) (*http.Response, error) {
+	switch {
+	case resp.StatusCode == http.StatusNotModified && hit:
+		resp.Body.Close()
+		resp.StatusCode = http.StatusOK
+		resp.Status = "200 OK (cached)"
+		resp.Body = io.NopCloser(bytes.NewReader(cached.Body))
+		resp.ContentLength = int64(len(cached.Body))
+	case resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != "":
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		c.cache.Set(key, CacheEntry{ETag: resp.Header.Get("ETag"), Body: body})
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return resp, nil
+}
@@ -0,0 +1,45 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClientCache(t *testing.T) {
+	var full, notModified int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&full, 1)
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("name: Setup\n"))
+	}))
+	defer server.Close()
+
+	for name, cache := range map[string]Cache{
+		"memory": NewMemoryCache(),
+		"disk":   DiskCache{Dir: t.TempDir()},
+	} {
+		full, notModified = 0, 0
+		for i := 0; i < 3; i++ {
+			// A new client per fetch, as in separate scheduled runs
+			c := NewClient(WithBaseURL(server.URL), WithCache(cache))
+			data, err := c.FetchFile(context.Background(), "octo", "tools", "action.yml", "main")
+			if err != nil {
+				t.Fatalf("%s: FetchFile failed: %v", name, err)
+			}
+			if string(data) != "name: Setup\n" {
+				t.Errorf("%s: unexpected content %q", name, data)
+			}
+		}
+		if full != 1 || notModified != 2 {
+			t.Errorf("%s: expected 1 full and 2 conditional responses, got %d and %d", name, full, notModified)
+		}
+	}
+}
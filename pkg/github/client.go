@@ -0,0 +1,322 @@
+// Package github fetches workflows and actions from the GitHub API for the
+// parser's remote features. All requests go through a shared Client that
+// honours GitHub's rate limits, so large organization scans back off instead
+// of getting the token blocked.
+package github
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scagogogo/github-action-parser/pkg/parser"
+)
+
+const (
+	// DefaultBaseURL is the URL of the public GitHub REST API
+	DefaultBaseURL = "https://api.github.com"
+	// DefaultMaxConcurrency is the default number of requests in flight
+	DefaultMaxConcurrency = 4
+	// DefaultMaxRetries is the default number of retries of a failed request
+	DefaultMaxRetries = 3
+	// DefaultMaxWait is the default longest time to wait for a rate limit to
+	// reset before giving up
+	DefaultMaxWait = 15 * time.Minute
+)
+
+// secondaryBackoff is the initial wait after hitting a secondary rate limit
+// without a Retry-After header, as recommended by GitHub
+const secondaryBackoff = time.Minute
+
+// RateLimitError is returned when a request is rate limited and the limit
+// does not reset within the maximum wait
+type RateLimitError struct {
+	ResetAt time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded until %s", e.ResetAt.Format(time.RFC3339))
+}
+
+// Client sends requests to the GitHub API, limiting the requests in flight,
+// waiting for exhausted rate limits to reset and retrying requests that hit
+// secondary rate limits or server errors
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	sem        chan struct{}
+	maxRetries int
+	maxWait    time.Duration
+	tokens     TokenSource
+	cache      Cache
+	tracer     parser.Tracer
+
+	mu      sync.Mutex
+	resetAt time.Time
+
+	now   func() time.Time
+	sleep func(context.Context, time.Duration) error
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithBaseURL sets the API URL, e.g. for GitHub Enterprise Server
+func WithBaseURL(url string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(url, "/")
+	}
+}
+
+// WithHTTPClient sets the http.Client used to send requests
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithMaxConcurrency limits the number of requests in flight
+func WithMaxConcurrency(n int) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.sem = make(chan struct{}, n)
+		}
+	}
+}
+
+// WithMaxRetries sets how often a request is retried after a secondary rate
+// limit or a server error
+func WithMaxRetries(n int) Option {
+	return func(c *Client) {
+		c.maxRetries = n
+	}
+}
+
+// WithMaxWait sets the longest time to wait for a rate limit to reset.
+// Requests that would wait longer fail with a *RateLimitError.
+func WithMaxWait(d time.Duration) Option {
+	return func(c *Client) {
+		c.maxWait = d
+	}
+}
+
+// WithTracer reports a span for every request, and for every lookup of the
+// Resolver using the client, to t
+func WithTracer(t parser.Tracer) Option {
+	return func(c *Client) {
+		c.tracer = t
+	}
+}
+
+// NewClient creates a new Client
+func NewClient(opts ...Option) *Client {
+	c := &Client{
+		baseURL:    DefaultBaseURL,
+		httpClient: http.DefaultClient,
+		sem:        make(chan struct{}, DefaultMaxConcurrency),
+		maxRetries: DefaultMaxRetries,
+		maxWait:    DefaultMaxWait,
+		now:        time.Now,
+		sleep:      sleepContext,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Get sends a GET request for an API path such as "/repos/owner/repo"
+func (c *Client) Get(ctx context.Context, path string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	return c.Do(req)
+}
+
+// Do sends a request. Responses other than rate limits and server errors,
+// including 404s, are returned to the caller. With a cache, GET requests are
+// revalidated using their ETag, and a 304 Not Modified response is returned
+// as 200 OK with the cached body.
+func (c *Client) Do(req *http.Request) (resp *http.Response, err error) {
+	ctx, span := c.startSpan(req.Context(), "github.Request")
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+		} else {
+			span.SetAttribute("status", resp.StatusCode)
+		}
+		span.End()
+	}()
+	span.SetAttribute("method", req.Method)
+	span.SetAttribute("path", req.URL.Path)
+	req = req.WithContext(ctx)
+
+	select {
+	case c.sem <- struct{}{}:
+		defer func() { <-c.sem }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/vnd.github+json")
+	}
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.tokens != nil && req.Header.Get("Authorization") == "" {
+		token, err := c.tokens.Token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	var key string
+	var cached CacheEntry
+	var hit bool
+	if c.cache != nil && req.Method == http.MethodGet {
+		key = cacheKey(req)
+		if cached, hit = c.cache.Get(key); hit {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := c.waitForReset(ctx); err != nil {
+			return nil, err
+		}
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+			req.Body = body
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		c.recordRateLimit(resp)
+
+		wait, retry := c.retryAfter(resp, attempt)
+		if !retry {
+			if key != "" {
+				return c.useCache(key, cached, hit, resp)
+			}
+			return resp, nil
+		}
+		if attempt >= c.maxRetries {
+			return resp, nil
+		}
+		resp.Body.Close()
+		if wait > c.maxWait {
+			return nil, &RateLimitError{ResetAt: c.now().Add(wait)}
+		}
+		if wait > 0 {
+			if err := c.sleep(ctx, wait); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// startSpan starts a span of the configured tracer
+func (c *Client) startSpan(ctx context.Context, name string) (context.Context, parser.Span) {
+	if c.tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return c.tracer.Start(ctx, name)
+}
+
+// noopSpan is used when no tracer has been configured
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(key string, value interface{}) {}
+func (noopSpan) RecordError(err error)                      {}
+func (noopSpan) End()                                       {}
+
+// waitForReset blocks while the primary rate limit is exhausted
+func (c *Client) waitForReset(ctx context.Context) error {
+	c.mu.Lock()
+	resetAt := c.resetAt
+	c.mu.Unlock()
+
+	wait := resetAt.Sub(c.now())
+	if wait <= 0 {
+		return nil
+	}
+	if wait > c.maxWait {
+		return &RateLimitError{ResetAt: resetAt}
+	}
+	return c.sleep(ctx, wait)
+}
+
+// recordRateLimit remembers when an exhausted primary rate limit resets
+func (c *Client) recordRateLimit(resp *http.Response) {
+	if resp.Header.Get("X-RateLimit-Remaining") != "0" {
+		return
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	c.resetAt = time.Unix(reset, 0)
+	c.mu.Unlock()
+}
+
+// retryAfter decides whether a response should be retried and how long to
+// wait before doing so
+func (c *Client) retryAfter(resp *http.Response, attempt int) (time.Duration, bool) {
+	switch {
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+		if s := resp.Header.Get("Retry-After"); s != "" {
+			if seconds, err := strconv.Atoi(s); err == nil {
+				return time.Duration(seconds) * time.Second, true
+			}
+		}
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			// waitForReset waits for the recorded reset before the retry
+			return 0, true
+		}
+		if resp.StatusCode == http.StatusForbidden && !isSecondaryRateLimit(resp) {
+			return 0, false
+		}
+		return secondaryBackoff << attempt, true
+	case resp.StatusCode >= 500:
+		return time.Second << attempt, true
+	}
+	return 0, false
+}
+
+// isSecondaryRateLimit reports whether a 403 response is a secondary rate
+// limit rather than a permission error. The body is preserved.
+func isSecondaryRateLimit(resp *http.Response) bool {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return err == nil && strings.Contains(strings.ToLower(string(body)), "secondary rate limit")
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
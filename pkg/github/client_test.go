@@ -0,0 +1,280 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/scagogogo/github-action-parser/pkg/parser"
+)
+
+// newTestClient creates a client for server that records sleeps instead of
+// sleeping
+func newTestClient(server *httptest.Server, slept *[]time.Duration, opts ...Option) *Client {
+	c := NewClient(append([]Option{WithBaseURL(server.URL)}, opts...)...)
+	now := time.Unix(1700000000, 0)
+	var mu sync.Mutex
+	c.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		mu.Lock()
+		defer mu.Unlock()
+		*slept = append(*slept, d)
+		now = now.Add(d)
+		return nil
+	}
+	return c
+}
+
+func TestClientWaitsForPrimaryRateLimit(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.Itoa(1700000000+30))
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	var slept []time.Duration
+	c := newTestClient(server, &slept)
+	resp, err := c.Get(context.Background(), "/rate", nil)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls != 2 {
+		t.Errorf("Expected success on the second call, got %d after %d calls", resp.StatusCode, calls)
+	}
+	if len(slept) != 1 || slept[0] != 30*time.Second {
+		t.Errorf("Expected to wait 30s for the reset, slept %v", slept)
+	}
+}
+
+func TestClientSecondaryRateLimitBackoff(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.Header().Set("Retry-After", "5")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message": "You have exceeded a secondary rate limit."}`))
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	var slept []time.Duration
+	c := newTestClient(server, &slept)
+	resp, err := c.Get(context.Background(), "/secondary", nil)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected success, got %d", resp.StatusCode)
+	}
+	if len(slept) != 2 || slept[0] != 5*time.Second || slept[1] != 2*time.Minute {
+		t.Errorf("Expected waits of 5s and 2m, slept %v", slept)
+	}
+}
+
+func TestClientDoesNotRetryPermissionErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message": "Resource not accessible by integration"}`))
+	}))
+	defer server.Close()
+
+	var slept []time.Duration
+	resp, err := newTestClient(server, &slept).Get(context.Background(), "/forbidden", nil)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden || calls != 1 || len(slept) != 0 {
+		t.Errorf("Expected a single 403 without retries, got %d after %d calls", resp.StatusCode, calls)
+	}
+}
+
+func TestClientMaxWait(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.Itoa(1700000000+3600))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	var slept []time.Duration
+	_, err := newTestClient(server, &slept, WithMaxWait(time.Minute)).Get(context.Background(), "/", nil)
+	var rateErr *RateLimitError
+	if !errors.As(err, &rateErr) {
+		t.Fatalf("Expected RateLimitError, got %v", err)
+	}
+	if !rateErr.ResetAt.Equal(time.Unix(1700000000+3600, 0)) {
+		t.Errorf("Unexpected reset time %v", rateErr.ResetAt)
+	}
+}
+
+func TestClientMaxConcurrency(t *testing.T) {
+	var inFlight, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+	}))
+	defer server.Close()
+
+	c := NewClient(WithBaseURL(server.URL), WithMaxConcurrency(2))
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if resp, err := c.Get(context.Background(), "/", nil); err == nil {
+				resp.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+	if peak > 2 {
+		t.Errorf("Expected at most 2 requests in flight, saw %d", peak)
+	}
+}
+
+func TestResolver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/octo/tools/contents/setup/action.yaml":
+			if r.URL.Query().Get("ref") != "v1" {
+				t.Errorf("Unexpected ref %q", r.URL.Query().Get("ref"))
+			}
+			w.Write([]byte("name: Setup\nruns:\n  using: composite\n  steps: []\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	r := Resolver{Client: NewClient(WithBaseURL(server.URL))}
+	action, err := r.Resolve("octo/tools/setup@v1")
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if action.Name != "Setup" {
+		t.Errorf("Expected action Setup, got %q", action.Name)
+	}
+
+	if _, err := r.Resolve("octo/tools/missing@v1"); !errors.Is(err, parser.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if _, err := r.Resolve("./local"); !errors.Is(err, parser.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for local reference, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := r.ResolveContext(ctx, "octo/tools/setup@v1"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+// spanKey is the context key of the recorded span
+type spanKey struct{}
+
+// recordingTracer records spans with the name of their parent span
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordingSpan
+}
+
+type recordingSpan struct {
+	name, parent string
+	attributes   map[string]interface{}
+	err          error
+	ended        bool
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, parser.Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	span := &recordingSpan{name: name, attributes: make(map[string]interface{})}
+	if parent, ok := ctx.Value(spanKey{}).(*recordingSpan); ok {
+		span.parent = parent.name
+	}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (s *recordingSpan) SetAttribute(key string, value interface{}) { s.attributes[key] = value }
+func (s *recordingSpan) RecordError(err error)                      { s.err = err }
+func (s *recordingSpan) End()                                       { s.ended = true }
+
+func TestResolverTracing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/octo/tools/contents/setup/action.yml" {
+			w.Write([]byte("name: Setup\nruns:\n  using: composite\n  steps: []\n"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	tracer := &recordingTracer{}
+	r := Resolver{Client: NewClient(WithBaseURL(server.URL), WithTracer(tracer))}
+	if _, err := r.Resolve("octo/tools/setup@v1"); err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if _, err := r.Resolve("octo/tools/missing@v1"); !errors.Is(err, parser.ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+
+	var resolves, requests int
+	for _, span := range tracer.spans {
+		if !span.ended {
+			t.Errorf("Expected span %s to be ended", span.name)
+		}
+		switch span.name {
+		case "github.Resolve":
+			resolves++
+		case "github.Request":
+			requests++
+			if span.parent != "github.Resolve" || span.attributes["method"] != http.MethodGet {
+				t.Errorf("Unexpected request span %+v", span)
+			}
+		}
+	}
+	// The missing action is looked up as action.yml and action.yaml
+	if resolves != 2 || requests != 3 {
+		t.Errorf("Expected 2 resolve and 3 request spans, got %d and %d", resolves, requests)
+	}
+	if tracer.spans[0].attributes["uses"] != "octo/tools/setup@v1" || tracer.spans[1].attributes["status"] != http.StatusOK {
+		t.Errorf("Unexpected attributes %+v, %+v", tracer.spans[0].attributes, tracer.spans[1].attributes)
+	}
+	if last := tracer.spans[len(tracer.spans)-3]; last.name != "github.Resolve" || !errors.Is(last.err, parser.ErrNotFound) {
+		t.Errorf("Expected the failed lookup to be recorded, got %+v", last)
+	}
+}
@@ -0,0 +1,99 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/scagogogo/github-action-parser/pkg/parser"
+)
+
+// FetchFile returns the content of a file in a repository at a ref. Missing
+// files fail with parser.ErrNotFound.
+func (c *Client) FetchFile(ctx context.Context, owner, repo, file, ref string) ([]byte, error) {
+	p := fmt.Sprintf("/repos/%s/%s/contents/%s?ref=%s",
+		url.PathEscape(owner), url.PathEscape(repo), escapePath(file), url.QueryEscape(ref))
+	resp, err := c.Get(ctx, p, http.Header{"Accept": {"application/vnd.github.raw+json"}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s/%s/%s@%s: %w", owner, repo, file, ref, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("failed to fetch %s/%s/%s@%s: %w", owner, repo, file, ref, parser.ErrNotFound)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("failed to fetch %s/%s/%s@%s: %s", owner, repo, file, ref, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s/%s/%s@%s: %w", owner, repo, file, ref, err)
+	}
+	return data, nil
+}
+
+// Resolver implements parser.Resolver for actions and reusable workflows in
+// other repositories, fetching them through a Client. Local references fail
+// with parser.ErrNotFound; combine it with parser.LocalResolver for those.
+type Resolver struct {
+	Client *Client
+	// Options are passed to parser.Parse
+	Options []parser.Option
+}
+
+// Resolve implements parser.Resolver
+func (r Resolver) Resolve(uses string) (*parser.ActionFile, error) {
+	return r.ResolveContext(context.Background(), uses)
+}
+
+// ResolveContext implements parser.ContextResolver, aborting the requests
+// when ctx is cancelled. Each lookup is reported as a span to the tracer of
+// the Client.
+func (r Resolver) ResolveContext(ctx context.Context, uses string) (action *parser.ActionFile, err error) {
+	ctx, span := r.Client.startSpan(ctx, "github.Resolve")
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+	span.SetAttribute("uses", uses)
+
+	ref, ok := parser.ParseActionRef(uses)
+	if !ok {
+		return nil, fmt.Errorf("cannot resolve %s remotely: %w", uses, parser.ErrNotFound)
+	}
+
+	files := []string{ref.Path}
+	if !ref.IsWorkflow() {
+		files = []string{path.Join(ref.Path, "action.yml"), path.Join(ref.Path, "action.yaml")}
+	}
+
+	for _, file := range files {
+		var data []byte
+		data, err = r.Client.FetchFile(ctx, ref.Owner, ref.Repo, file, ref.Ref)
+		if errors.Is(err, parser.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return parser.Parse(bytes.NewReader(data), r.Options...)
+	}
+	return nil, err
+}
+
+// escapePath escapes each segment of a slash-separated path
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
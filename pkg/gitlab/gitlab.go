@@ -0,0 +1,540 @@
+// Package gitlab translates GitHub Actions workflows into GitLab CI
+// configuration.
+//
+// The translation is best effort. Run steps become script lines, jobs keep
+// their dependencies through 'needs' and are placed into stages following the
+// waves of the job graph. Steps using actions have no GitLab equivalent; a few
+// well-known ones are mapped onto native keywords (cache, artifacts), and all
+// of them are reported so a migration team can scope the remaining work.
+package gitlab
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/scagogogo/github-action-parser/pkg/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is a GitLab CI configuration
+type Config struct {
+	Stages    []string          `yaml:"stages,omitempty"`
+	Variables map[string]string `yaml:"variables,omitempty"`
+	Workflow  *WorkflowRules    `yaml:"workflow,omitempty"`
+	Jobs      map[string]*Job   `yaml:",inline"`
+}
+
+// WorkflowRules controls when a pipeline is created
+type WorkflowRules struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule is a single GitLab rule
+type Rule struct {
+	If string `yaml:"if"`
+}
+
+// Job is a GitLab CI job
+type Job struct {
+	Stage        string            `yaml:"stage"`
+	Image        string            `yaml:"image,omitempty"`
+	Services     []Service         `yaml:"services,omitempty"`
+	Needs        []string          `yaml:"needs,omitempty"`
+	Variables    map[string]string `yaml:"variables,omitempty"`
+	Parallel     *Parallel         `yaml:"parallel,omitempty"`
+	Script       []string          `yaml:"script"`
+	Cache        []Cache           `yaml:"cache,omitempty"`
+	Artifacts    *Artifacts        `yaml:"artifacts,omitempty"`
+	Tags         []string          `yaml:"tags,omitempty"`
+	Timeout      string            `yaml:"timeout,omitempty"`
+	AllowFailure bool              `yaml:"allow_failure,omitempty"`
+}
+
+// Service is a service container of a job
+type Service struct {
+	Name  string `yaml:"name"`
+	Alias string `yaml:"alias,omitempty"`
+}
+
+// Parallel runs a job once per matrix combination
+type Parallel struct {
+	Matrix []map[string]interface{} `yaml:"matrix"`
+}
+
+// Cache configures a GitLab cache
+type Cache struct {
+	Key   string   `yaml:"key"`
+	Paths []string `yaml:"paths"`
+}
+
+// Artifacts configures the files kept after a job
+type Artifacts struct {
+	Name     string   `yaml:"name,omitempty"`
+	Paths    []string `yaml:"paths"`
+	ExpireIn string   `yaml:"expire_in,omitempty"`
+}
+
+// Finding describes part of a workflow that needs manual attention
+type Finding struct {
+	// Field is the path of the element in the GitHub workflow, e.g.
+	// "jobs.build.steps[2]"
+	Field string `json:"field"`
+	// Uses is the action reference for findings about action steps
+	Uses    string `json:"uses,omitempty"`
+	Message string `json:"message"`
+}
+
+// String returns the finding formatted for display
+func (f Finding) String() string {
+	return f.Field + ": " + f.Message
+}
+
+// Result is the outcome of an export
+type Result struct {
+	Config *Config
+	// GitHubOnly lists the steps that rely on GitHub-only actions
+	GitHubOnly []Finding
+	// TODOs lists other constructs that could not be translated exactly
+	TODOs []Finding
+}
+
+// YAML renders the GitLab CI configuration
+func (r *Result) YAML() ([]byte, error) {
+	data, err := yaml.Marshal(r.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	return data, nil
+}
+
+// pipelineSources maps GitHub events onto GitLab pipeline sources
+var pipelineSources = map[string]string{
+	"push":              "push",
+	"pull_request":      "merge_request_event",
+	"schedule":          "schedule",
+	"workflow_dispatch": "web",
+}
+
+// Export translates a GitHub Actions workflow into GitLab CI configuration
+func Export(workflow *parser.ActionFile) (*Result, error) {
+	graph, err := parser.BuildJobGraph(workflow)
+	if err != nil {
+		return nil, err
+	}
+	waves, err := graph.Waves()
+	if err != nil {
+		return nil, err
+	}
+
+	ex := &exporter{
+		result: &Result{Config: &Config{Jobs: make(map[string]*Job)}},
+	}
+	config := ex.result.Config
+
+	if len(workflow.Env) > 0 {
+		config.Variables = ex.translateMap("env", workflow.Env)
+	}
+	ex.exportTriggers(workflow)
+
+	ex.names = ex.jobNames(workflow)
+	for i, wave := range waves {
+		stage := fmt.Sprintf("stage-%d", i+1)
+		config.Stages = append(config.Stages, stage)
+		for _, jobID := range wave {
+			config.Jobs[ex.names[jobID]] = ex.exportJob(jobID, workflow.Jobs[jobID], stage)
+		}
+	}
+
+	return ex.result, nil
+}
+
+// exporter holds the state of a single export
+type exporter struct {
+	result *Result
+	// names maps GitHub job IDs onto GitLab job names
+	names map[string]string
+}
+
+// reservedJobNames are the top-level keywords of GitLab CI configuration,
+// which cannot be used as job names
+var reservedJobNames = map[string]bool{
+	"after_script":  true,
+	"before_script": true,
+	"cache":         true,
+	"default":       true,
+	"false":         true,
+	"image":         true,
+	"include":       true,
+	"nil":           true,
+	"services":      true,
+	"stages":        true,
+	"true":          true,
+	"types":         true,
+	"variables":     true,
+	"workflow":      true,
+}
+
+// jobNames picks the GitLab name of every job, renaming those that clash
+// with reserved keywords
+func (ex *exporter) jobNames(workflow *parser.ActionFile) map[string]string {
+	names := make(map[string]string, len(workflow.Jobs))
+	taken := make(map[string]bool, len(workflow.Jobs))
+	var reserved []string
+	for jobID := range workflow.Jobs {
+		if reservedJobNames[jobID] {
+			reserved = append(reserved, jobID)
+		} else {
+			names[jobID] = jobID
+			taken[jobID] = true
+		}
+	}
+	sort.Strings(reserved)
+	for _, jobID := range reserved {
+		name := jobID + "-job"
+		for i := 2; taken[name]; i++ {
+			name = fmt.Sprintf("%s-job-%d", jobID, i)
+		}
+		names[jobID] = name
+		taken[name] = true
+		ex.todo("jobs."+jobID, "renamed to '%s' because '%s' is a reserved GitLab keyword", name, jobID)
+	}
+	return names
+}
+
+func (ex *exporter) todo(field, format string, args ...interface{}) {
+	ex.result.TODOs = append(ex.result.TODOs, Finding{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (ex *exporter) githubOnly(field, uses, format string, args ...interface{}) {
+	ex.result.GitHubOnly = append(ex.result.GitHubOnly, Finding{Field: field, Uses: uses, Message: fmt.Sprintf(format, args...)})
+}
+
+// exportTriggers maps workflow triggers onto workflow rules
+func (ex *exporter) exportTriggers(workflow *parser.ActionFile) {
+	var events []string
+	switch on := workflow.On.(type) {
+	case string:
+		events = []string{on}
+	case []interface{}:
+		for _, e := range on {
+			events = append(events, fmt.Sprint(e))
+		}
+	case map[string]interface{}:
+		for e, config := range on {
+			events = append(events, e)
+			if config != nil && (e == "push" || e == "pull_request") {
+				ex.todo("on."+e, "branch and path filters must be rewritten as rules")
+			}
+		}
+	}
+	sort.Strings(events)
+
+	var rules []Rule
+	for _, event := range events {
+		source, ok := pipelineSources[event]
+		if !ok {
+			ex.todo("on."+event, "event '%s' has no GitLab pipeline source", event)
+			continue
+		}
+		rules = append(rules, Rule{If: fmt.Sprintf(`$CI_PIPELINE_SOURCE == "%s"`, source)})
+	}
+	if len(rules) > 0 {
+		ex.result.Config.Workflow = &WorkflowRules{Rules: rules}
+	}
+}
+
+// exportJob translates a single job
+func (ex *exporter) exportJob(jobID string, job parser.Job, stage string) *Job {
+	field := "jobs." + jobID
+	out := &Job{Stage: stage, Script: []string{}}
+
+	if job.Uses != "" {
+		ex.githubOnly(field, job.Uses, "reusable workflow calls must become GitLab includes or child pipelines")
+		out.Script = append(out.Script, fmt.Sprintf("echo 'TODO: call %s'", job.Uses))
+		return out
+	}
+
+	out.Image, out.Tags = ex.runner(field, job)
+	for _, name := range sortedKeys(job.Services) {
+		service := Service{Alias: name}
+		switch s := job.Services[name].(type) {
+		case string:
+			service.Name = s
+		case map[string]interface{}:
+			service.Name, _ = s["image"].(string)
+		}
+		out.Services = append(out.Services, service)
+	}
+
+	for _, need := range parser.JobNeeds(job) {
+		name, ok := ex.names[need]
+		if !ok {
+			ex.todo(field+".needs", "dropped the need on unknown job '%s'", need)
+			continue
+		}
+		out.Needs = append(out.Needs, name)
+	}
+	sort.Strings(out.Needs)
+	if len(job.Env) > 0 {
+		out.Variables = ex.translateMap(field+".env", job.Env)
+	}
+	if job.If != "" {
+		ex.todo(field+".if", "condition '%s' must be rewritten as rules", job.If)
+	}
+	if job.TimeoutMin > 0 {
+		out.Timeout = fmt.Sprintf("%d minutes", job.TimeoutMin)
+	}
+	if continueOnError, ok := job.ContinueOn.(bool); ok {
+		out.AllowFailure = continueOnError
+	}
+	if job.Strategy != nil {
+		out.Parallel = ex.matrix(field, job.Strategy)
+	}
+
+	for i, step := range job.Steps {
+		ex.exportStep(fmt.Sprintf("%s.steps[%d]", field, i), step, out)
+	}
+
+	return out
+}
+
+// runner picks an image and runner tags for the job
+func (ex *exporter) runner(field string, job parser.Job) (string, []string) {
+	switch c := job.Container.(type) {
+	case string:
+		return c, nil
+	case map[string]interface{}:
+		if image, ok := c["image"].(string); ok {
+			return image, nil
+		}
+	}
+
+	label := ""
+	switch r := job.RunsOn.(type) {
+	case string:
+		label = r
+	case []interface{}:
+		if len(r) > 0 {
+			label = fmt.Sprint(r[0])
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(label, "ubuntu"):
+		return "ubuntu:latest", nil
+	case strings.HasPrefix(label, "macos"), strings.HasPrefix(label, "windows"):
+		ex.todo(field+".runs-on", "'%s' requires a GitLab runner with a matching tag", label)
+		return "", []string{strings.SplitN(label, "-", 2)[0]}
+	default:
+		ex.todo(field+".runs-on", "runner '%v' requires a GitLab runner with a matching tag", job.RunsOn)
+		return "", []string{label}
+	}
+}
+
+// matrix translates a strategy matrix into parallel:matrix
+func (ex *exporter) matrix(field string, strategy map[string]interface{}) *Parallel {
+	matrix, ok := strategy["matrix"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	dims := make(map[string]interface{})
+	for key, values := range matrix {
+		if key == "include" || key == "exclude" {
+			ex.todo(field+".strategy.matrix."+key, "matrix %s entries must be listed as separate parallel:matrix items", key)
+			continue
+		}
+		if list, ok := values.([]interface{}); ok {
+			dims[key] = list
+		} else {
+			ex.todo(field+".strategy.matrix."+key, "dynamic matrix values cannot be translated")
+		}
+	}
+	if len(dims) == 0 {
+		return nil
+	}
+	return &Parallel{Matrix: []map[string]interface{}{dims}}
+}
+
+// exportStep translates a step into script lines or job keywords
+func (ex *exporter) exportStep(field string, step parser.Step, out *Job) {
+	if step.If != "" {
+		ex.todo(field+".if", "step conditions must be rewritten as shell conditions")
+	}
+
+	if step.Run != "" {
+		run := strings.TrimRight(ex.translate(field, step.Run), "\n")
+		if step.WorkingDir == "" && len(step.Env) == 0 {
+			out.Script = append(out.Script, run)
+			return
+		}
+		// Steps with their own directory or env run in a subshell, so
+		// neither leaks into the steps after them
+		lines := []string{"("}
+		if step.WorkingDir != "" {
+			lines = append(lines, "cd "+ex.shellWord(field, step.WorkingDir))
+		}
+		for _, name := range sortedKeys(step.Env) {
+			lines = append(lines, fmt.Sprintf("export %s=%s", name, ex.shellWord(field, step.Env[name])))
+		}
+		lines = append(lines, run, ")")
+		out.Script = append(out.Script, strings.Join(lines, "\n"))
+		return
+	}
+
+	if step.Uses == "" {
+		return
+	}
+	action := step.Uses
+	if at := strings.Index(action, "@"); at >= 0 {
+		action = action[:at]
+	}
+
+	switch action {
+	case "actions/checkout":
+		// GitLab checks out the repository before every job
+	case "actions/cache", "actions/cache/restore", "actions/cache/save":
+		out.Cache = append(out.Cache, Cache{
+			Key:   ex.translate(field, fmt.Sprint(step.With["key"])),
+			Paths: lines(step.With["path"]),
+		})
+		ex.githubOnly(field, step.Uses, "mapped to 'cache', check that the key is still meaningful")
+	case "actions/upload-artifact":
+		if out.Artifacts == nil {
+			out.Artifacts = &Artifacts{}
+		}
+		out.Artifacts.Paths = append(out.Artifacts.Paths, lines(step.With["path"])...)
+		if days, ok := step.With["retention-days"]; ok {
+			out.Artifacts.ExpireIn = fmt.Sprintf("%v days", days)
+		}
+		ex.githubOnly(field, step.Uses, "mapped to 'artifacts'")
+	case "actions/download-artifact":
+		ex.githubOnly(field, step.Uses, "artifacts of jobs listed in 'needs' are downloaded automatically")
+	default:
+		ex.githubOnly(field, step.Uses, "action has no GitLab equivalent, reimplement it as script lines or pick a suitable image")
+		out.Script = append(out.Script, fmt.Sprintf("echo 'TODO: replace %s'", step.Uses))
+	}
+}
+
+// expressionTranslations maps expression contexts onto GitLab variables
+// holding the same value. Contexts whose GitLab counterpart differs in form,
+// such as github.ref (a full ref) or runner.os, are left untranslated and
+// reported.
+var expressionTranslations = map[string]string{
+	"github.sha":        "$CI_COMMIT_SHA",
+	"github.ref_name":   "$CI_COMMIT_REF_NAME",
+	"github.repository": "$CI_PROJECT_PATH",
+	"github.workspace":  "$CI_PROJECT_DIR",
+	"github.run_id":     "$CI_PIPELINE_ID",
+	"github.actor":      "$GITLAB_USER_LOGIN",
+}
+
+// simpleExpression matches an expression consisting of a single reference
+var simpleExpression = regexp.MustCompile(`\$\{\{\s*([A-Za-z_][\w-]*(?:\.[A-Za-z_][\w-]*)+)\s*\}\}`)
+
+// translate rewrites ${{ }} expressions into GitLab variables where possible
+func (ex *exporter) translate(field, s string) string {
+	var b strings.Builder
+	ex.translateParts(field, s, func(text string) { b.WriteString(text) }, func(variable string) { b.WriteString(variable) })
+	return b.String()
+}
+
+// shellWord translates s like translate and quotes it as a single shell
+// word: text is single-quoted and translated variables are expanded
+func (ex *exporter) shellWord(field, s string) string {
+	var b strings.Builder
+	ex.translateParts(field, s, func(text string) {
+		b.WriteString("'" + strings.ReplaceAll(text, "'", `'\''`) + "'")
+	}, func(variable string) {
+		b.WriteString(`"` + variable + `"`)
+	})
+	if b.Len() == 0 {
+		return "''"
+	}
+	return b.String()
+}
+
+// translateParts splits s into text and GitLab variables translated from
+// its expressions, passing each to the matching callback. Expressions that
+// cannot be translated are kept as text and reported.
+func (ex *exporter) translateParts(field, s string, text, variable func(string)) {
+	var untranslated []string
+	emit := func(t string) {
+		if t == "" {
+			return
+		}
+		untranslated = append(untranslated, parser.ExtractExpressions(t)...)
+		text(t)
+	}
+	last := 0
+	for _, m := range simpleExpression.FindAllStringSubmatchIndex(s, -1) {
+		emit(s[last:m[0]])
+		last = m[1]
+		if v, ok := ex.translateReference(field, s[m[2]:m[3]]); ok {
+			variable(v)
+		} else {
+			emit(s[m[0]:m[1]])
+		}
+	}
+	emit(s[last:])
+	if len(untranslated) > 0 {
+		ex.todo(field, "expressions could not be translated: %s", strings.Join(untranslated, ", "))
+	}
+}
+
+// translateReference returns the GitLab variable holding the value of a
+// context reference
+func (ex *exporter) translateReference(field, ref string) (string, bool) {
+	parts := strings.SplitN(ref, ".", 2)
+	switch parts[0] {
+	case "env", "matrix":
+		return "$" + parts[1], true
+	case "secrets", "vars":
+		ex.todo(field, "define CI/CD variable %s", parts[1])
+		return "$" + parts[1], true
+	}
+	v, ok := expressionTranslations[ref]
+	return v, ok
+}
+
+// translateMap translates every value of a variables map
+func (ex *exporter) translateMap(field string, m map[string]string) map[string]string {
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		result[k] = ex.translate(field, v)
+	}
+	return result
+}
+
+// lines splits a multi-line 'with' value into its non-empty lines
+func lines(v interface{}) []string {
+	var result []string
+	for _, line := range strings.Split(fmt.Sprint(v), "\n") {
+		if line = strings.TrimSpace(line); line != "" && line != "<nil>" {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+// sortedKeys returns the keys of a map in a stable order
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch value := m.(type) {
+	case map[string]string:
+		for k := range value {
+			keys = append(keys, k)
+		}
+	case parser.EnvMap:
+		for k := range value {
+			keys = append(keys, k)
+		}
+	case map[string]interface{}:
+		for k := range value {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,260 @@
+package gitlab
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/scagogogo/github-action-parser/pkg/parser"
+	"gopkg.in/yaml.v3"
+)
+
+func exportFixture(t *testing.T) *Result {
+	t.Helper()
+	workflow, err := parser.ParseFile("testdata/ci.yml")
+	if err != nil {
+		t.Fatalf("Failed to parse workflow: %v", err)
+	}
+	result, err := Export(workflow)
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+	return result
+}
+
+func TestExport(t *testing.T) {
+	result := exportFixture(t)
+	config := result.Config
+
+	if strings.Join(config.Stages, ",") != "stage-1,stage-2,stage-3" {
+		t.Errorf("Expected a stage per wave, got %v", config.Stages)
+	}
+	if config.Variables["NODE_ENV"] != "test" {
+		t.Errorf("Expected workflow env as variables, got %v", config.Variables)
+	}
+	if config.Workflow == nil || len(config.Workflow.Rules) != 3 {
+		t.Fatalf("Expected 3 workflow rules, got %+v", config.Workflow)
+	}
+
+	lint := config.Jobs["lint"]
+	if lint.Stage != "stage-1" || lint.Image != "ubuntu:latest" {
+		t.Errorf("Unexpected lint job %+v", lint)
+	}
+	if len(lint.Script) != 2 || !strings.Contains(lint.Script[0], "actions/setup-node@v4") || lint.Script[1] != "npm run lint" {
+		t.Errorf("Expected a placeholder for setup-node followed by the script, got %v", lint.Script)
+	}
+
+	test := config.Jobs["test"]
+	if test.Image != "node:20" || len(test.Services) != 1 || test.Services[0].Name != "postgres:16" {
+		t.Errorf("Expected container image and services, got %+v", test)
+	}
+	if len(test.Needs) != 1 || test.Needs[0] != "lint" {
+		t.Errorf("Expected needs [lint], got %v", test.Needs)
+	}
+	if test.Parallel == nil || len(test.Parallel.Matrix) != 1 {
+		t.Errorf("Expected parallel matrix, got %+v", test.Parallel)
+	}
+	if test.Timeout != "30 minutes" {
+		t.Errorf("Expected timeout, got %q", test.Timeout)
+	}
+	if len(test.Cache) != 1 || test.Cache[0].Paths[0] != "~/.npm" {
+		t.Errorf("Expected cache mapping, got %+v", test.Cache)
+	}
+	if test.Artifacts == nil || len(test.Artifacts.Paths) != 2 || test.Artifacts.ExpireIn != "5 days" {
+		t.Errorf("Expected artifacts mapping, got %+v", test.Artifacts)
+	}
+	script := strings.Join(test.Script, "\n")
+	for _, want := range []string{"(\ncd 'app'\n", `export TOKEN="$NPM_TOKEN"`, "npm test -- --shard=$shard\n)"} {
+		if !strings.Contains(script, want) {
+			t.Errorf("Expected script to contain %q, got:\n%s", want, script)
+		}
+	}
+
+	deploy := config.Jobs["deploy"]
+	if !deploy.AllowFailure || len(deploy.Tags) != 1 || deploy.Tags[0] != "macos" {
+		t.Errorf("Unexpected deploy job %+v", deploy)
+	}
+	if deploy.Script[0] != "./deploy.sh $CI_COMMIT_SHA" {
+		t.Errorf("Expected translated expression, got %v", deploy.Script)
+	}
+}
+
+func TestExportReportsGitHubOnlySteps(t *testing.T) {
+	result := exportFixture(t)
+
+	uses := make(map[string]string)
+	for _, f := range result.GitHubOnly {
+		uses[f.Field] = f.Uses
+	}
+	if uses["jobs.lint.steps[1]"] != "actions/setup-node@v4" {
+		t.Errorf("Expected setup-node to be reported, got %v", result.GitHubOnly)
+	}
+	if uses["jobs.test.steps[1]"] != "actions/cache@v4" {
+		t.Errorf("Expected cache to be reported, got %v", result.GitHubOnly)
+	}
+	if _, ok := uses["jobs.lint.steps[0]"]; ok {
+		t.Errorf("Expected checkout not to be reported")
+	}
+
+	todos := make(map[string]bool)
+	for _, f := range result.TODOs {
+		todos[f.Field] = true
+	}
+	for _, field := range []string{"on.push", "jobs.deploy.if", "jobs.deploy.runs-on", "jobs.test.steps[2]"} {
+		if !todos[field] {
+			t.Errorf("Expected a TODO for %s, got %v", field, result.TODOs)
+		}
+	}
+}
+
+func TestResultYAML(t *testing.T) {
+	data, err := exportFixture(t).YAML()
+	if err != nil {
+		t.Fatalf("Failed to render YAML: %v", err)
+	}
+
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Generated YAML is invalid: %v", err)
+	}
+	for _, key := range []string{"stages", "variables", "workflow", "lint", "test", "deploy"} {
+		if _, ok := doc[key]; !ok {
+			t.Errorf("Expected top-level key %q in:\n%s", key, data)
+		}
+	}
+}
+
+func TestExportRejectsActions(t *testing.T) {
+	action := &parser.ActionFile{Name: "action"}
+	if _, err := Export(action); !errors.Is(err, parser.ErrNotAWorkflow) {
+		t.Errorf("Expected ErrNotAWorkflow, got %v", err)
+	}
+}
+
+func TestExportRenamesReservedJobs(t *testing.T) {
+	workflow, err := parser.Parse(strings.NewReader(`
+on: push
+jobs:
+  stages:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+  stages-job:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+  test:
+    needs: [stages, missing]
+    runs-on: ubuntu-latest
+    steps:
+      - run: make test
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := Export(workflow)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jobs := result.Config.Jobs
+	if _, ok := jobs["stages"]; ok {
+		t.Error("Expected the job named after a reserved keyword to be renamed")
+	}
+	if jobs["stages-job-2"] == nil || jobs["stages-job"] == nil {
+		t.Errorf("Expected jobs stages-job and stages-job-2, got %v", jobs)
+	}
+	if needs := jobs["test"].Needs; len(needs) != 1 || needs[0] != "stages-job-2" {
+		t.Errorf("Expected needs [stages-job-2], got %v", needs)
+	}
+
+	todos := make(map[string]bool)
+	for _, f := range result.TODOs {
+		todos[f.Field] = true
+	}
+	for _, field := range []string{"jobs.stages", "jobs.test.needs"} {
+		if !todos[field] {
+			t.Errorf("Expected a TODO for %s, got %v", field, result.TODOs)
+		}
+	}
+}
+
+func TestExportQuotesEnvironment(t *testing.T) {
+	workflow, err := parser.Parse(strings.NewReader(`
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - env:
+          GREETING: it's $HOME "${{ matrix.os }}"
+          EMPTY: ""
+          REF: ${{ github.ref }}
+          OS: ${{ runner.os }}
+        run: make
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := Export(workflow)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	script := strings.Join(result.Config.Jobs["build"].Script, "\n")
+	for _, want := range []string{
+		`export EMPTY=''`,
+		`export GREETING='it'\''s $HOME "'"$os"'"'`,
+		`export OS='${{ runner.os }}'`,
+		`export REF='${{ github.ref }}'`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("Expected script to contain %q, got:\n%s", want, script)
+		}
+	}
+	var messages []string
+	for _, f := range result.TODOs {
+		messages = append(messages, f.Message)
+	}
+	for _, expr := range []string{"github.ref", "runner.os"} {
+		if !strings.Contains(strings.Join(messages, "\n"), expr) {
+			t.Errorf("Expected a TODO for %s, got %v", expr, messages)
+		}
+	}
+}
+
+func TestExportStepSubshell(t *testing.T) {
+	workflow, err := parser.Parse(strings.NewReader(`
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - working-directory: my app/${{ github.ref_name }}
+        env:
+          TOKEN: secret
+        run: make
+      - run: make test
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := Export(workflow)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	script := result.Config.Jobs["build"].Script
+	want := []string{
+		"(\ncd 'my app/'\"$CI_COMMIT_REF_NAME\"\nexport TOKEN='secret'\nmake\n)",
+		"make test",
+	}
+	if len(script) != len(want) {
+		t.Fatalf("Expected %d script entries, got %q", len(want), script)
+	}
+	for i := range want {
+		if script[i] != want[i] {
+			t.Errorf("Expected script entry %d to be %q, got %q", i, want[i], script[i])
+		}
+	}
+}
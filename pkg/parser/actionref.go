@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// RefPinning describes how firmly a ref pins an action or reusable workflow
+type RefPinning string
+
+const (
+	// PinningSHA is a full commit SHA, which cannot change
+	PinningSHA RefPinning = "sha"
+	// PinningTag is a tag such as v4 or v4.1.2, which maintainers may move
+	PinningTag RefPinning = "tag"
+	// PinningBranch is a branch, which changes with every push
+	PinningBranch RefPinning = "branch"
+)
+
+// commitSHAPattern matches a full commit SHA
+var commitSHAPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// movingBranches are branch names commonly used as action refs. Unlike tags
+// and commit SHAs they change with every push to the action's repository.
+var movingBranches = map[string]bool{
+	"main":    true,
+	"master":  true,
+	"develop": true,
+	"dev":     true,
+	"trunk":   true,
+}
+
+// ActionRef is a reference to an action or reusable workflow in another
+// repository, as in 'uses: owner/repo/path@ref'
+type ActionRef struct {
+	Owner string `json:"owner"`
+	Repo  string `json:"repo"`
+	// Path is the directory of the action or the file of the reusable
+	// workflow within the repository, empty for actions at the root
+	Path string `json:"path,omitempty"`
+	Ref  string `json:"ref"`
+}
+
+// ParseActionRef parses a remote 'uses' value. It reports false for local
+// references ("./path"), Docker images ("docker://image") and malformed
+// values.
+func ParseActionRef(uses string) (ActionRef, bool) {
+	if strings.HasPrefix(uses, "./") || strings.HasPrefix(uses, "docker://") {
+		return ActionRef{}, false
+	}
+	name, ref, ok := strings.Cut(uses, "@")
+	if !ok || ref == "" {
+		return ActionRef{}, false
+	}
+	parts := strings.SplitN(name, "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return ActionRef{}, false
+	}
+	r := ActionRef{Owner: parts[0], Repo: parts[1], Ref: ref}
+	if len(parts) == 3 {
+		r.Path = strings.Trim(parts[2], "/")
+	}
+	return r, true
+}
+
+// IsWorkflow reports whether the reference is to a reusable workflow file
+func (r ActionRef) IsWorkflow() bool {
+	return strings.HasSuffix(r.Path, ".yml") || strings.HasSuffix(r.Path, ".yaml")
+}
+
+// String returns the reference in 'uses' form
+func (r ActionRef) String() string {
+	name := r.Owner + "/" + r.Repo
+	if r.Path != "" {
+		name += "/" + r.Path
+	}
+	return name + "@" + r.Ref
+}
+
+// Pinning classifies the ref. Without access to the repository, refs other
+// than commit SHAs and the usual branch names are assumed to be tags.
+func (r ActionRef) Pinning() RefPinning {
+	switch {
+	case commitSHAPattern.MatchString(r.Ref):
+		return PinningSHA
+	case movingBranches[r.Ref]:
+		return PinningBranch
+	}
+	return PinningTag
+}
@@ -0,0 +1,52 @@
+package parser
+
+import "testing"
+
+func TestParseActionRef(t *testing.T) {
+	tests := []struct {
+		uses     string
+		expected ActionRef
+		ok       bool
+	}{
+		{"actions/checkout@v4", ActionRef{Owner: "actions", Repo: "checkout", Ref: "v4"}, true},
+		{"github/codeql-action/init@v3", ActionRef{Owner: "github", Repo: "codeql-action", Path: "init", Ref: "v3"}, true},
+		{"octo/repo/.github/workflows/ci.yml@main", ActionRef{Owner: "octo", Repo: "repo", Path: ".github/workflows/ci.yml", Ref: "main"}, true},
+		{"./.github/actions/setup", ActionRef{}, false},
+		{"docker://alpine:3.19", ActionRef{}, false},
+		{"actions/checkout", ActionRef{}, false},
+		{"checkout@v4", ActionRef{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseActionRef(tt.uses)
+		if ok != tt.ok || got != tt.expected {
+			t.Errorf("ParseActionRef(%q) = %+v, %v; expected %+v, %v", tt.uses, got, ok, tt.expected, tt.ok)
+			continue
+		}
+		if ok && got.String() != tt.uses {
+			t.Errorf("String() = %q, expected %q", got.String(), tt.uses)
+		}
+	}
+	if r, _ := ParseActionRef("octo/repo/.github/workflows/ci.yml@main"); !r.IsWorkflow() {
+		t.Errorf("Expected workflow reference")
+	}
+}
+
+func TestActionRefPinning(t *testing.T) {
+	tests := map[string]RefPinning{
+		"actions/checkout@b4ffde65f46336ab88eb53be808477a3936bae11": PinningSHA,
+		"actions/checkout@v4":                            PinningTag,
+		"actions/checkout@main":                          PinningBranch,
+		"octo/workflows/.github/workflows/ci.yml@master": PinningBranch,
+		"octo/workflows/.github/workflows/ci.yml@v1.2.3": PinningTag,
+		"actions/checkout@b4ffde65":                      PinningTag,
+	}
+	for uses, want := range tests {
+		ref, ok := ParseActionRef(uses)
+		if !ok {
+			t.Fatalf("ParseActionRef(%q) failed", uses)
+		}
+		if got := ref.Pinning(); got != want {
+			t.Errorf("%s: Pinning() = %s, want %s", uses, got, want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// activityTypes are the documented activity types of the events filtered
+// with 'types'
+var activityTypes = map[string]map[string]bool{
+	"issues": {
+		"opened": true, "edited": true, "deleted": true, "transferred": true, "pinned": true, "unpinned": true,
+		"closed": true, "reopened": true, "assigned": true, "unassigned": true, "labeled": true, "unlabeled": true,
+		"locked": true, "unlocked": true, "milestoned": true, "demilestoned": true, "typed": true, "untyped": true,
+	},
+	"issue_comment": {"created": true, "edited": true, "deleted": true},
+	"merge_group":   mergeGroupTypes,
+	"pull_request":  pullRequestTypes,
+	// pull_request_target has the types of pull_request
+	"pull_request_target": pullRequestTypes,
+	"pull_request_review": {"submitted": true, "edited": true, "dismissed": true},
+}
+
+// activityTypeEvents are the events of activityTypes in a stable order
+var activityTypeEvents = []string{"issue_comment", "issues", "merge_group", "pull_request", "pull_request_review", "pull_request_target"}
+
+// pullRequestTypes are the activity types of the pull request events
+var pullRequestTypes = map[string]bool{
+	"assigned": true, "unassigned": true, "labeled": true, "unlabeled": true, "opened": true, "edited": true,
+	"closed": true, "reopened": true, "synchronize": true, "converted_to_draft": true, "ready_for_review": true,
+	"locked": true, "unlocked": true, "review_requested": true, "review_request_removed": true,
+	"auto_merge_enabled": true, "auto_merge_disabled": true, "milestoned": true, "demilestoned": true,
+	"enqueued": true, "dequeued": true,
+}
+
+// ActivityTypes returns the 'types' filters of the issues, issue_comment,
+// merge_group, pull_request, pull_request_target and pull_request_review
+// triggers of a workflow, keyed by event. Events without types are omitted.
+func ActivityTypes(action *ActionFile) (map[string][]string, error) {
+	result := make(map[string][]string)
+	for _, event := range activityTypeEvents {
+		config, ok, err := triggerConfig(action, event)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		types, err := stringList(config["types"])
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s.types: %w", event, err)
+		}
+		if len(types) > 0 {
+			result[event] = types
+		}
+	}
+	return result, nil
+}
+
+// validateActivityTypes reports activity types GitHub never sends,
+// suggesting the closest documented type
+func (v *Validator) validateActivityTypes(action *ActionFile) {
+	types, err := ActivityTypes(action)
+	if err != nil {
+		v.addError("activity-types", "on", err.Error())
+		return
+	}
+	for _, event := range activityTypeEvents {
+		for _, typ := range unknownTypes(types[event], activityTypes[event]) {
+			message := fmt.Sprintf("Unknown %s type '%s'", event, typ)
+			if closest := closestName(typ, sortedSet(activityTypes[event])); closest != "" {
+				message += fmt.Sprintf(", did you mean '%s'?", closest)
+			} else {
+				message += ", expected one of " + strings.Join(sortedSet(activityTypes[event]), ", ")
+			}
+			v.addError("activity-types", fmt.Sprintf("on.%s.types", event), message)
+		}
+	}
+}
+
+// closestName returns the candidate nearest to name by edit distance, or ""
+// if none is close enough to be a likely typo
+func closestName(name string, candidates []string) string {
+	best, bestDistance := "", len(name)/3+1
+	for _, candidate := range candidates {
+		if d := editDistance(strings.ToLower(name), candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if d := previous[j] + 1; d < current[j] {
+				current[j] = d
+			}
+			if d := current[j-1] + 1; d < current[j] {
+				current[j] = d
+			}
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
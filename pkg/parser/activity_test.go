@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestActivityTypes(t *testing.T) {
+	workflow := mustParse(t, `
+on:
+  push:
+  issues:
+    types: opened
+  pull_request:
+    types: [opened, synchronized, reopen]
+  pull_request_review:
+  issue_comment:
+    types: [created, commented]
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+`)
+	types, err := ActivityTypes(workflow)
+	if err != nil {
+		t.Fatalf("ActivityTypes() error = %v", err)
+	}
+	want := map[string][]string{
+		"issues":        {"opened"},
+		"pull_request":  {"opened", "synchronized", "reopen"},
+		"issue_comment": {"created", "commented"},
+	}
+	if !reflect.DeepEqual(types, want) {
+		t.Errorf("ActivityTypes() = %v, want %v", types, want)
+	}
+
+	var messages []string
+	for _, e := range NewValidator().Validate(workflow) {
+		if e.Rule == "activity-types" {
+			messages = append(messages, e.Field+": "+e.Message)
+		}
+	}
+	wantMessages := []string{
+		"on.issue_comment.types: Unknown issue_comment type 'commented', expected one of created, deleted, edited",
+		"on.pull_request.types: Unknown pull_request type 'synchronized', did you mean 'synchronize'?",
+		"on.pull_request.types: Unknown pull_request type 'reopen', did you mean 'reopened'?",
+	}
+	if !reflect.DeepEqual(messages, wantMessages) {
+		t.Errorf("Unexpected findings:\n%v\nwant\n%v", messages, wantMessages)
+	}
+
+	if _, err := ActivityTypes(mustParse(t, "on:\n  issues:\n    types: [{a: b}]\n")); err == nil {
+		t.Error("Expected an error for non-string types")
+	}
+}
+
+func TestClosestName(t *testing.T) {
+	candidates := []string{"created", "deleted", "edited"}
+	tests := map[string]string{"create": "created", "Edited": "edited", "commented": "", "x": ""}
+	for name, want := range tests {
+		if got := closestName(name, candidates); got != want {
+			t.Errorf("closestName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
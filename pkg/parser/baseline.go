@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// BaselineVersion is the version of the baseline file format
+const BaselineVersion = 1
+
+// Baseline records the findings accepted when adopting the tool on an
+// existing project, so that only new findings fail CI. Findings are matched
+// by file, rule, field and message, not by line, so editing unrelated parts
+// of a file keeps its baseline valid.
+type Baseline struct {
+	Version int `json:"version"`
+	// Findings are sorted by file, rule, field and message
+	Findings []BaselineEntry `json:"findings"`
+}
+
+// BaselineEntry is an accepted finding
+type BaselineEntry struct {
+	File    string `json:"file"`
+	Rule    string `json:"rule,omitempty"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// NewBaseline records findings keyed by file path
+func NewBaseline(findings map[string][]ValidationError) *Baseline {
+	b := &Baseline{Version: BaselineVersion, Findings: make([]BaselineEntry, 0)}
+	for file, list := range findings {
+		for _, f := range list {
+			b.Findings = append(b.Findings, baselineEntry(file, f))
+		}
+	}
+	sort.Slice(b.Findings, func(i, j int) bool {
+		x, y := b.Findings[i], b.Findings[j]
+		if x.File != y.File {
+			return x.File < y.File
+		}
+		if x.Rule != y.Rule {
+			return x.Rule < y.Rule
+		}
+		if x.Field != y.Field {
+			return x.Field < y.Field
+		}
+		return x.Message < y.Message
+	})
+	return b
+}
+
+// ReadBaseline reads a baseline written by Write, rejecting other versions
+func ReadBaseline(r io.Reader) (*Baseline, error) {
+	var b Baseline
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, fmt.Errorf("failed to decode baseline: %w", err)
+	}
+	if b.Version != BaselineVersion {
+		return nil, fmt.Errorf("unsupported baseline version %d", b.Version)
+	}
+	return &b, nil
+}
+
+// Write writes the baseline as indented JSON
+func (b *Baseline) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(b); err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+	return nil
+}
+
+// Filter returns the findings, keyed by file path, that are not in the
+// baseline. A finding recorded once suppresses a single occurrence, so a
+// repeated mistake is still reported.
+func (b *Baseline) Filter(findings map[string][]ValidationError) map[string][]ValidationError {
+	accepted := make(map[BaselineEntry]int, len(b.Findings))
+	for _, entry := range b.Findings {
+		accepted[entry]++
+	}
+	result := make(map[string][]ValidationError, len(findings))
+	for file, list := range findings {
+		result[file] = make([]ValidationError, 0)
+		for _, f := range list {
+			entry := baselineEntry(file, f)
+			if accepted[entry] > 0 {
+				accepted[entry]--
+				continue
+			}
+			result[file] = append(result[file], f)
+		}
+	}
+	return result
+}
+
+// baselineEntry returns the entry matching a finding in file
+func baselineEntry(file string, f ValidationError) BaselineEntry {
+	return BaselineEntry{File: file, Rule: f.Rule, Field: f.Field, Message: f.Message}
+}
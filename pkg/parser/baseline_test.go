@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBaseline(t *testing.T) {
+	old := map[string][]ValidationError{
+		"ci.yml": {
+			{Field: "jobs.build.steps[0].uses", Message: "unpinned", Rule: "branch-pinned-action"},
+			{Field: "jobs.build.steps[0].uses", Message: "unpinned", Rule: "branch-pinned-action"},
+		},
+		"lint.yml": nil,
+	}
+	var buf bytes.Buffer
+	if err := NewBaseline(old).Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	baseline, err := ReadBaseline(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(baseline.Findings) != 2 {
+		t.Fatalf("Expected 2 entries, got %+v", baseline.Findings)
+	}
+
+	current := map[string][]ValidationError{
+		"ci.yml": {
+			{Field: "jobs.build.steps[0].uses", Message: "unpinned", Rule: "branch-pinned-action"},
+			{Field: "jobs.build.steps[0].uses", Message: "unpinned", Rule: "branch-pinned-action"},
+			{Field: "jobs.build.steps[0].uses", Message: "unpinned", Rule: "branch-pinned-action"},
+			{Field: "jobs.build", Message: "new", Rule: "job-runner"},
+		},
+		"lint.yml": {{Field: "on", Message: "new"}},
+	}
+	got := baseline.Filter(current)
+	want := map[string][]ValidationError{
+		"ci.yml": {
+			{Field: "jobs.build.steps[0].uses", Message: "unpinned", Rule: "branch-pinned-action"},
+			{Field: "jobs.build", Message: "new", Rule: "job-runner"},
+		},
+		"lint.yml": {{Field: "on", Message: "new"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Filter() = %+v, want %+v", got, want)
+	}
+
+	if _, err := ReadBaseline(strings.NewReader(`{"version": 2}`)); err == nil {
+		t.Error("Expected an error for an unsupported version")
+	}
+}
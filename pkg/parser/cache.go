@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// cacheAction returns the action of an actions/cache step: "" for
+// actions/cache, "restore" or "save", and false for other steps
+func cacheAction(uses string) (string, bool) {
+	ref, ok := ParseActionRef(uses)
+	if !ok || !strings.EqualFold(ref.Owner, "actions") || !strings.EqualFold(ref.Repo, "cache") {
+		return "", false
+	}
+	switch path := strings.ToLower(ref.Path); path {
+	case "", "restore", "save":
+		return path, true
+	}
+	return "", false
+}
+
+// cacheLines returns the non-empty lines of a multi-line input of
+// actions/cache, such as path or restore-keys
+func cacheLines(v interface{}) []string {
+	s, ok := v.(string)
+	if !ok {
+		return nil
+	}
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// lintCacheSteps checks the actions/cache steps of a job or composite
+// action: keys should change with the cached dependencies, restore keys
+// should be prefixes of the key, and a path should be saved by a single
+// step
+func (l *Linter) lintCacheSteps(field string, steps []Step) {
+	savedBy := make(map[string]string)
+	for i, step := range steps {
+		action, ok := cacheAction(step.Uses)
+		if !ok {
+			continue
+		}
+		stepField := fmt.Sprintf("%s[%d]", field, i)
+
+		key, _ := step.With["key"].(string)
+		if key != "" && !strings.Contains(key, "hashFiles(") && !computedCacheKey(key) {
+			l.addIssue("cache-keys", SeverityWarning, stepField+".with.key",
+				fmt.Sprintf("Cache key '%s' does not use hashFiles, so the cache is not refreshed when the cached dependencies change", key))
+		}
+		if action != "save" {
+			for _, restoreKey := range cacheLines(step.With["restore-keys"]) {
+				if key != "" && !strings.HasPrefix(key, restoreKey) {
+					l.addIssue("cache-keys", SeverityWarning, stepField+".with.restore-keys",
+						fmt.Sprintf("Restore key '%s' is not a prefix of the key '%s', so it restores caches saved for other keys", restoreKey, key))
+				}
+			}
+		}
+		if action == "restore" {
+			continue
+		}
+		for _, path := range cacheLines(step.With["path"]) {
+			if previous, ok := savedBy[path]; ok {
+				l.addIssue("cache-keys", SeverityWarning, stepField+".with.path",
+					fmt.Sprintf("Path '%s' is already cached by %s; the caches overwrite each other", path, previous))
+				continue
+			}
+			savedBy[path] = stepField
+		}
+	}
+}
+
+// computedCacheKey reports whether a cache key is taken from step or job
+// outputs, which usually hold a hash computed by an earlier step
+func computedCacheKey(key string) bool {
+	for _, ref := range ExtractContextReferences(key) {
+		if ref.Context == "steps" || ref.Context == "needs" {
+			return true
+		}
+	}
+	return false
+}
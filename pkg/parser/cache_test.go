@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLintCacheSteps(t *testing.T) {
+	action := mustParse(t, `
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/cache@v4
+        with:
+          path: ~/.npm
+          key: ${{ runner.os }}-npm-${{ hashFiles('**/package-lock.json') }}
+          restore-keys: |
+            ${{ runner.os }}-npm-
+            npm-
+      - uses: actions/cache@v4
+        with:
+          path: |
+            ~/.npm
+            ~/.cache/pip
+          key: ${{ runner.os }}-deps
+      - uses: actions/cache/restore@v4
+        with:
+          path: ~/.cache/pip
+          key: ${{ steps.hash.outputs.value }}
+`)
+	var messages []string
+	for _, issue := range NewLinter().Lint(action) {
+		if issue.Rule == "cache-keys" {
+			messages = append(messages, issue.Field+": "+issue.Message)
+		}
+	}
+	want := []string{
+		"jobs.build.steps[0].with.restore-keys: Restore key 'npm-'",
+		"jobs.build.steps[1].with.key: Cache key '${{ runner.os }}-deps' does not use hashFiles",
+		"jobs.build.steps[1].with.path: Path '~/.npm' is already cached by jobs.build.steps[0]",
+	}
+	if len(messages) != len(want) {
+		t.Fatalf("Expected %d cache findings, got %v", len(want), messages)
+	}
+	for i, prefix := range want {
+		if !strings.HasPrefix(messages[i], prefix) {
+			t.Errorf("Expected finding %q, got %q", prefix, messages[i])
+		}
+	}
+}
+
+func TestCacheAction(t *testing.T) {
+	tests := map[string]bool{
+		"actions/cache@v4":         true,
+		"actions/cache/save@v4":    true,
+		"actions/cache/restore@v4": true,
+		"actions/checkout@v4":      false,
+		"octo/cache@v1":            false,
+		"./actions/cache":          false,
+	}
+	for uses, want := range tests {
+		if _, got := cacheAction(uses); got != want {
+			t.Errorf("cacheAction(%q) = %v, want %v", uses, got, want)
+		}
+	}
+}
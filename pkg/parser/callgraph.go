@@ -0,0 +1,181 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CallGraph describes which workflows, reusable workflows and actions call
+// each other across a repository
+type CallGraph struct {
+	Nodes []CallNode `json:"nodes"`
+	Edges []CallEdge `json:"edges"`
+}
+
+// CallNode is a workflow or action in a CallGraph
+type CallNode struct {
+	// ID is the 'uses' reference of the node: "./path" for files of the
+	// repository, e.g. "./.github/workflows/ci.yml", and the reference as
+	// written otherwise, e.g. "actions/checkout@v4" or "docker://alpine:3"
+	ID   string         `json:"id"`
+	Kind DependencyKind `json:"kind"`
+	// Type is the detected type of files of the repository and of resolved
+	// references, and FileTypeUnknown otherwise
+	Type FileType `json:"type"`
+	// Version is the ref of an action or reusable workflow of another
+	// repository, or the tag or digest of a Docker image
+	Version string `json:"version,omitempty"`
+	// File is the path of the node in the WorkflowSet, if it is part of it
+	File string `json:"file,omitempty"`
+}
+
+// CallEdge points from a workflow or action to a node it uses
+type CallEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Job is the calling job, empty for the steps of an action
+	Job string `json:"job,omitempty"`
+	// Field is the 'uses' field making the call, e.g.
+	// "jobs.build.steps[1].uses"
+	Field string `json:"field"`
+}
+
+// BuildCallGraph builds the call graph of a set keyed by paths relative to
+// the repository root, as returned by ParseDir of the root. Nodes and edges
+// are sorted. With WithResolver, actions and reusable workflows of other
+// repositories are resolved and their own calls added as well; references
+// that cannot be resolved remain leaves.
+func BuildCallGraph(set WorkflowSet, opts ...Option) *CallGraph {
+	o := newOptions(opts)
+	b := &callGraphBuilder{opts: o, resolver: o.resolver, nodes: make(map[string]*CallNode), byKey: make(map[string]string)}
+	for file := range set {
+		b.byKey[localFileKey(file)] = file
+	}
+	for _, file := range sortedFiles(set) {
+		id := localFileKey(file)
+		b.nodes[id] = &CallNode{ID: id, Kind: DependencyLocal, Type: DetectType(set[file]), File: file}
+	}
+	for _, file := range sortedFiles(set) {
+		b.addCalls(localFileKey(file), set[file])
+	}
+
+	graph := &CallGraph{Nodes: make([]CallNode, 0, len(b.nodes)), Edges: b.edges}
+	for _, node := range b.nodes {
+		graph.Nodes = append(graph.Nodes, *node)
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		return graph.Nodes[i].ID < graph.Nodes[j].ID
+	})
+	sort.SliceStable(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.Field < b.Field
+	})
+	if graph.Edges == nil {
+		graph.Edges = make([]CallEdge, 0)
+	}
+	return graph
+}
+
+// callGraphBuilder collects the nodes and edges of a CallGraph
+type callGraphBuilder struct {
+	opts     *options
+	resolver Resolver
+	nodes    map[string]*CallNode
+	edges    []CallEdge
+	// byKey maps the local references of the set's files to their paths
+	byKey map[string]string
+}
+
+// addCalls adds the edges from the node id to everything action uses
+func (b *callGraphBuilder) addCalls(id string, action *ActionFile) {
+	for i, step := range action.Runs.Steps {
+		b.addCall(id, "", fmt.Sprintf("runs.steps[%d].uses", i), step.Uses)
+	}
+	for _, jobID := range sortedJobIDs(action) {
+		job := action.Jobs[jobID]
+		b.addCall(id, jobID, fmt.Sprintf("jobs.%s.uses", jobID), job.Uses)
+		for i, step := range job.Steps {
+			b.addCall(id, jobID, fmt.Sprintf("jobs.%s.steps[%d].uses", jobID, i), step.Uses)
+		}
+	}
+}
+
+// addCall adds an edge for a 'uses' value, creating its node on first use
+func (b *callGraphBuilder) addCall(from, job, field, uses string) {
+	if uses == "" {
+		return
+	}
+	to := uses
+	if strings.HasPrefix(uses, "./") {
+		to = normalizeLocalUses(uses)
+	}
+	b.edges = append(b.edges, CallEdge{From: from, To: to, Job: job, Field: field})
+	if _, ok := b.nodes[to]; ok {
+		return
+	}
+
+	node := &CallNode{ID: to, Type: FileTypeUnknown}
+	b.nodes[to] = node
+	switch {
+	case strings.HasPrefix(uses, "./"):
+		// A local reference to a file outside the set
+		node.Kind = DependencyLocal
+		return
+	case strings.HasPrefix(uses, "docker://"):
+		node.Kind = DependencyDocker
+		if image, err := ParseImageRef(uses); err == nil {
+			node.Version = image.Tag
+			if image.Digest != "" {
+				node.Version = image.Digest
+			}
+		}
+		return
+	}
+	ref, ok := ParseActionRef(uses)
+	if !ok {
+		return
+	}
+	node.Kind, node.Version = DependencyAction, ref.Ref
+	if ref.IsWorkflow() {
+		node.Kind = DependencyReusableWorkflow
+	}
+	if b.resolver == nil {
+		return
+	}
+	if resolved, err := b.opts.resolve(b.resolver, uses); err == nil && resolved != nil {
+		node.Type = DetectType(resolved)
+		b.addCalls(to, resolved)
+	}
+}
+
+// DOT renders the graph in the Graphviz DOT language
+func (g *CallGraph) DOT() string {
+	return g.view().dot("calls")
+}
+
+// Mermaid renders the graph as a Mermaid flowchart
+func (g *CallGraph) Mermaid() string {
+	return g.view().mermaid()
+}
+
+// view converts the graph for the exporters
+func (g *CallGraph) view() graphView {
+	var v graphView
+	for _, node := range g.Nodes {
+		label := node.ID
+		if node.Type != FileTypeUnknown {
+			label += "\n" + string(node.Type)
+		} else if node.Kind != "" {
+			label += "\n" + string(node.Kind)
+		}
+		v.nodes = append(v.nodes, viewNode{id: node.ID, label: label})
+	}
+	for _, edge := range g.Edges {
+		v.edges = append(v.edges, viewEdge{from: edge.From, to: edge.To, label: strings.TrimSuffix(edge.Field, ".uses")})
+	}
+	return v
+}
@@ -0,0 +1,121 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildCallGraph(t *testing.T) {
+	set := WorkflowSet{
+		".github/workflows/ci.yml": mustParse(t, `
+on: push
+jobs:
+  build:
+    uses: ./.github/workflows/build.yml
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: docker://alpine:3.19
+`),
+		".github/workflows/build.yml": mustParse(t, `
+on: workflow_call
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: ./.github/actions/setup
+`),
+		".github/actions/setup/action.yml": mustParse(t, `
+name: Setup
+description: Setup
+runs:
+  using: composite
+  steps:
+    - uses: octo/setup-tool@v2
+`),
+	}
+	resolver := mapResolver{"octo/setup-tool@v2": mustParse(t, `
+name: Setup tool
+description: Setup tool
+runs:
+  using: composite
+  steps:
+    - uses: actions/cache@v4
+`)}
+
+	graph := BuildCallGraph(set, WithResolver(resolver))
+
+	wantNodes := []CallNode{
+		{ID: "./.github/actions/setup", Kind: DependencyLocal, Type: FileTypeCompositeAction, File: ".github/actions/setup/action.yml"},
+		{ID: "./.github/workflows/build.yml", Kind: DependencyLocal, Type: FileTypeReusableWorkflow, File: ".github/workflows/build.yml"},
+		{ID: "./.github/workflows/ci.yml", Kind: DependencyLocal, Type: FileTypeWorkflow, File: ".github/workflows/ci.yml"},
+		{ID: "actions/cache@v4", Kind: DependencyAction, Type: FileTypeUnknown, Version: "v4"},
+		{ID: "actions/checkout@v4", Kind: DependencyAction, Type: FileTypeUnknown, Version: "v4"},
+		{ID: "docker://alpine:3.19", Kind: DependencyDocker, Type: FileTypeUnknown, Version: "3.19"},
+		{ID: "octo/setup-tool@v2", Kind: DependencyAction, Type: FileTypeCompositeAction, Version: "v2"},
+	}
+	if !reflect.DeepEqual(graph.Nodes, wantNodes) {
+		t.Errorf("Nodes = %+v, want %+v", graph.Nodes, wantNodes)
+	}
+	wantEdges := []CallEdge{
+		{From: "./.github/actions/setup", To: "octo/setup-tool@v2", Field: "runs.steps[0].uses"},
+		{From: "./.github/workflows/build.yml", To: "./.github/actions/setup", Job: "build", Field: "jobs.build.steps[0].uses"},
+		{From: "./.github/workflows/ci.yml", To: "./.github/workflows/build.yml", Job: "build", Field: "jobs.build.uses"},
+		{From: "./.github/workflows/ci.yml", To: "actions/checkout@v4", Job: "lint", Field: "jobs.lint.steps[0].uses"},
+		{From: "./.github/workflows/ci.yml", To: "docker://alpine:3.19", Job: "lint", Field: "jobs.lint.steps[1].uses"},
+		{From: "octo/setup-tool@v2", To: "actions/cache@v4", Field: "runs.steps[0].uses"},
+	}
+	if !reflect.DeepEqual(graph.Edges, wantEdges) {
+		t.Errorf("Edges = %+v, want %+v", graph.Edges, wantEdges)
+	}
+
+	dot := graph.DOT()
+	for _, want := range []string{
+		"digraph calls {",
+		`"./.github/workflows/ci.yml" [label="./.github/workflows/ci.yml\nworkflow"];`,
+		`"./.github/workflows/ci.yml" -> "./.github/workflows/build.yml" [label="jobs.build"];`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT() = %s, want it to contain %s", dot, want)
+		}
+	}
+	mermaid := graph.Mermaid()
+	for _, want := range []string{
+		"flowchart LR\n",
+		"\tn2[\"./.github/workflows/ci.yml<br/>workflow\"]\n",
+		"\tn2 -->|\"jobs.build\"| n1\n",
+	} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("Mermaid() = %s, want it to contain %q", mermaid, want)
+		}
+	}
+}
+
+func TestJobGraphExport(t *testing.T) {
+	graph, err := BuildJobGraph(mustParse(t, `
+on: push
+jobs:
+  build:
+    name: Build "app"
+    runs-on: ubuntu-latest
+  test:
+    needs: build
+    runs-on: ubuntu-latest
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantDOT := "digraph jobs {\n\trankdir=LR;\n" +
+		"\t\"build\" [label=\"build\\nBuild \\\"app\\\"\"];\n" +
+		"\t\"test\" [label=\"test\"];\n" +
+		"\t\"build\" -> \"test\";\n}\n"
+	if got := graph.DOT(); got != wantDOT {
+		t.Errorf("DOT() = %q, want %q", got, wantDOT)
+	}
+	wantMermaid := "flowchart LR\n\tn0[\"build<br/>Build #quot;app#quot;\"]\n\tn1[\"test\"]\n\tn0 --> n1\n"
+	if got := graph.Mermaid(); got != wantMermaid {
+		t.Errorf("Mermaid() = %q, want %q", got, wantMermaid)
+	}
+}
@@ -0,0 +1,150 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxReusableDepth is the nesting limit GitHub imposes on reusable workflows
+const maxReusableDepth = 4
+
+// StatusCheckNames returns the names of the checks a workflow reports, as
+// needed when configuring required status checks in branch protection.
+//
+// A check is named after its job, or the job ID if the job has no name. Jobs
+// with a matrix report one check per combination, either by substituting
+// matrix values into the name or by appending them in parentheses, in the
+// order the matrix defines its keys; keys only added by 'include' follow in
+// alphabetical order, as do all keys of workflows built in code rather than
+// parsed. Jobs calling a reusable workflow report the checks of the called
+// workflow prefixed with "<caller> / "; resolver loads called workflows and
+// may be nil if the workflow calls none. Names containing other expressions
+// are returned unevaluated.
+func StatusCheckNames(workflow *ActionFile, resolver Resolver) ([]string, error) {
+	return statusCheckNames(workflow, resolver, 0)
+}
+
+func statusCheckNames(workflow *ActionFile, resolver Resolver, depth int) ([]string, error) {
+	if workflow.Jobs == nil {
+		return nil, ErrNotAWorkflow
+	}
+
+	var names []string
+	for _, jobID := range sortedJobIDs(workflow) {
+		job := workflow.Jobs[jobID]
+		jobNames, err := jobCheckNames(jobID, job, workflow.matrixOrder[jobID])
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand job %s: %w", jobID, err)
+		}
+
+		if job.Uses == "" {
+			names = append(names, jobNames...)
+			continue
+		}
+
+		if depth+1 >= maxReusableDepth {
+			return nil, fmt.Errorf("job %s: reusable workflows are nested more than %d levels deep", jobID, maxReusableDepth)
+		}
+		if resolver == nil {
+			return nil, fmt.Errorf("job %s: cannot resolve %s without a resolver", jobID, job.Uses)
+		}
+		called, err := resolver.Resolve(job.Uses)
+		if err != nil {
+			return nil, fmt.Errorf("job %s: failed to resolve %s: %w", jobID, job.Uses, err)
+		}
+		calledNames, err := statusCheckNames(called, resolver, depth+1)
+		if err != nil {
+			return nil, fmt.Errorf("job %s: %w", jobID, err)
+		}
+		for _, prefix := range jobNames {
+			for _, name := range calledNames {
+				names = append(names, prefix+" / "+name)
+			}
+		}
+	}
+	return names, nil
+}
+
+// jobCheckNames returns the names of the check runs of a single job, whose
+// matrix keys are listed in source order by order
+func jobCheckNames(jobID string, job Job, order []string) ([]string, error) {
+	name := job.Name
+	if name == "" {
+		name = jobID
+	}
+
+	combinations, err := MatrixCombinations(job.Strategy)
+	if err != nil {
+		return nil, err
+	}
+	if len(combinations) == 0 {
+		return []string{name}, nil
+	}
+
+	usesMatrix := false
+	for _, ref := range ExtractContextReferences(name) {
+		if ref.Context == "matrix" {
+			usesMatrix = true
+			break
+		}
+	}
+
+	names := make([]string, 0, len(combinations))
+	for _, c := range combinations {
+		if usesMatrix {
+			names = append(names, substituteMatrix(name, c))
+			continue
+		}
+		values := make([]string, 0, len(c))
+		for _, k := range combinationKeys(c, order) {
+			values = append(values, formatMatrixValue(c[k]))
+		}
+		names = append(names, fmt.Sprintf("%s (%s)", name, strings.Join(values, ", ")))
+	}
+	return names, nil
+}
+
+// combinationKeys returns the keys of a matrix combination, those in order
+// first
+func combinationKeys(combination map[string]interface{}, order []string) []string {
+	keys := make([]string, 0, len(combination))
+	listed := make(map[string]bool, len(order))
+	for _, k := range order {
+		if _, ok := combination[k]; ok && !listed[k] {
+			keys = append(keys, k)
+		}
+		listed[k] = true
+	}
+	var rest []string
+	for k := range combination {
+		if !listed[k] {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
+
+// substituteMatrix replaces expressions consisting of a single matrix
+// reference with the value from the combination
+func substituteMatrix(s string, combination map[string]interface{}) string {
+	return expressionPattern.ReplaceAllStringFunc(s, func(expr string) string {
+		inner := strings.TrimSpace(expr[3 : len(expr)-2])
+		if !strings.HasPrefix(inner, "matrix.") {
+			return expr
+		}
+		var value interface{} = combination
+		for _, key := range strings.Split(strings.TrimPrefix(inner, "matrix."), ".") {
+			m, ok := value.(map[string]interface{})
+			if !ok {
+				return expr
+			}
+			if value, ok = m[key]; !ok {
+				// Missing matrix values evaluate to an empty string
+				return ""
+			}
+		}
+		return formatMatrixValue(value)
+	})
+}
@@ -0,0 +1,133 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// mapResolver resolves references from a fixed set of files
+type mapResolver map[string]*ActionFile
+
+func (r mapResolver) Resolve(uses string) (*ActionFile, error) {
+	if action, ok := r[uses]; ok {
+		return action, nil
+	}
+	return nil, ErrNotFound
+}
+
+func TestStatusCheckNames(t *testing.T) {
+	workflow := mustParse(t, `
+on: push
+jobs:
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make lint
+  test:
+    name: Test
+    runs-on: ubuntu-latest
+    strategy:
+      matrix:
+        os: [ubuntu, windows]
+        node: [18, 20]
+        exclude:
+          - os: windows
+            node: 18
+    steps:
+      - run: make test
+  build:
+    name: Build on ${{ matrix.os }}
+    runs-on: ${{ matrix.os }}
+    strategy:
+      matrix:
+        os: [linux, macos]
+    steps:
+      - run: make
+  release:
+    uses: ./.github/workflows/release.yml
+`)
+	resolver := mapResolver{
+		"./.github/workflows/release.yml": mustParse(t, `
+on: workflow_call
+jobs:
+  publish:
+    name: Publish
+    runs-on: ubuntu-latest
+    steps:
+      - run: make publish
+`),
+	}
+
+	names, err := StatusCheckNames(workflow, resolver)
+	if err != nil {
+		t.Fatalf("Failed to compute check names: %v", err)
+	}
+
+	want := []string{
+		"Build on linux",
+		"Build on macos",
+		"lint",
+		"release / Publish",
+		"Test (ubuntu, 18)",
+		"Test (ubuntu, 20)",
+		"Test (windows, 20)",
+	}
+	if strings.Join(names, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected %v, got %v", want, names)
+	}
+}
+
+func TestStatusCheckNamesKeyOrder(t *testing.T) {
+	source := `
+on: push
+jobs:
+  test:
+    strategy:
+      matrix:
+        version: [1]
+        os: [linux]
+        arch: [arm64]
+        include:
+          - os: linux
+            debug: true
+    steps:
+      - run: make test
+`
+	for _, opts := range [][]Option{nil, {WithStrict()}, {WithPositions()}} {
+		workflow, err := Parse(strings.NewReader(source), opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names, err := StatusCheckNames(workflow, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "test (1, linux, arm64, true)"; len(names) != 1 || names[0] != want {
+			t.Errorf("Expected [%s], got %v", want, names)
+		}
+	}
+}
+
+func TestStatusCheckNamesUnresolved(t *testing.T) {
+	workflow := mustParse(t, "on: push\njobs:\n  call:\n    uses: octo/repo/.github/workflows/ci.yml@v1\n")
+
+	if _, err := StatusCheckNames(workflow, nil); err == nil {
+		t.Error("Expected an error without a resolver")
+	}
+	if _, err := StatusCheckNames(workflow, LocalResolver{Root: "testdata"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a remote workflow, got %v", err)
+	}
+}
+
+func TestLocalResolver(t *testing.T) {
+	workflow := mustParse(t, "on: push\njobs:\n  call:\n    uses: ./reusable-workflow.yml\n")
+
+	names, err := StatusCheckNames(workflow, LocalResolver{Root: "testdata"})
+	if err != nil {
+		t.Fatalf("Failed to compute check names: %v", err)
+	}
+	if len(names) != 1 || names[0] != "call / Build Project" {
+		t.Errorf("Expected [call / Build Project], got %v", names)
+	}
+}
@@ -0,0 +1,168 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+)
+
+// semanticDefaults lists fields whose value, when equal to GitHub's default,
+// means the same as leaving the field out. Patterns use '*' for any map key
+// and '[*]' for any list index.
+var semanticDefaults = map[string]string{
+	"jobs.*.continue-on-error":          "false",
+	"jobs.*.timeout-minutes":            "360",
+	"jobs.*.strategy.fail-fast":         "true",
+	"jobs.*.steps[*].continue-on-error": "false",
+	"inputs.*.required":                 "false",
+	"inputs.*.deprecated":               "false",
+}
+
+// listIndex matches list indices in field paths
+var listIndex = regexp.MustCompile(`\[\d+\]`)
+
+// isDefaultValue reports whether value is the default for the field at path
+func isDefaultValue(path string, value interface{}) bool {
+	str, ok := value.(string)
+	if !ok {
+		return false
+	}
+
+	pattern := listIndex.ReplaceAllString(path, "[*]")
+	for defaultPath, defaultValue := range semanticDefaults {
+		if str == defaultValue && matchFieldPattern(defaultPath, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFieldPattern matches a field path against a pattern where '*' stands
+// for a single path segment
+func matchFieldPattern(pattern, path string) bool {
+	patternParts := splitFieldPath(pattern)
+	pathParts := splitFieldPath(path)
+	if len(patternParts) != len(pathParts) {
+		return false
+	}
+	for i := range patternParts {
+		if patternParts[i] != "*" && patternParts[i] != pathParts[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// splitFieldPath splits a field path on dots, keeping list indices attached
+// to their segment
+func splitFieldPath(path string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(path); i++ {
+		if path[i] == '.' {
+			parts = append(parts, path[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, path[start:])
+}
+
+// DifferenceKind tells whether a field was added, removed or changed
+type DifferenceKind string
+
+const (
+	// DifferenceAdded is a field only present in the second ActionFile
+	DifferenceAdded DifferenceKind = "added"
+	// DifferenceRemoved is a field only present in the first ActionFile
+	DifferenceRemoved DifferenceKind = "removed"
+	// DifferenceChanged is a field present in both with different values
+	DifferenceChanged DifferenceKind = "changed"
+)
+
+// Difference describes a field that differs between two ActionFiles. Old
+// is unset for added fields and New for removed ones; otherwise either may
+// be nil, as for bare triggers such as 'on: push'.
+type Difference struct {
+	Path string         `json:"path"`
+	Kind DifferenceKind `json:"kind"`
+	Old  interface{}    `json:"old,omitempty"`
+	New  interface{}    `json:"new,omitempty"`
+}
+
+// String returns a human readable description of the difference
+func (d Difference) String() string {
+	switch d.Kind {
+	case DifferenceAdded:
+		if d.New == nil {
+			return fmt.Sprintf("%s: added", d.Path)
+		}
+		return fmt.Sprintf("%s: added %v", d.Path, d.New)
+	case DifferenceRemoved:
+		if d.Old == nil {
+			return fmt.Sprintf("%s: removed", d.Path)
+		}
+		return fmt.Sprintf("%s: removed %v", d.Path, d.Old)
+	default:
+		return fmt.Sprintf("%s: %v -> %v", d.Path, d.Old, d.New)
+	}
+}
+
+// Equal reports whether two ActionFiles are semantically equal. Equivalent
+// YAML spellings compare equal: a single string and a one-element list,
+// quoted and unquoted scalars, the string, list and mapping forms of 'on',
+// and fields explicitly set to their default value versus leaving them out.
+func Equal(a, b *ActionFile) bool {
+	return len(Diff(a, b)) == 0
+}
+
+// Diff returns the semantic differences between two ActionFiles, sorted by
+// field path. It uses the same notion of equivalence as Equal.
+func Diff(a, b *ActionFile) []Difference {
+	treeA, errA := normalizedTree(a)
+	treeB, errB := normalizedTree(b)
+	if errA != nil || errB != nil {
+		return []Difference{{Path: "", Kind: DifferenceChanged, Old: a, New: b}}
+	}
+
+	var diffs []Difference
+	diffValues("", treeA, treeB, &diffs)
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Path < diffs[j].Path
+	})
+	return diffs
+}
+
+// diffValues recursively compares two normalized trees
+func diffValues(path string, a, b interface{}, diffs *[]Difference) {
+	mapA, okA := a.(map[string]interface{})
+	mapB, okB := b.(map[string]interface{})
+	if okA && okB {
+		for k, v := range mapA {
+			if other, ok := mapB[k]; ok {
+				diffValues(joinPath(path, k), v, other, diffs)
+			} else {
+				*diffs = append(*diffs, Difference{Path: joinPath(path, k), Kind: DifferenceRemoved, Old: v})
+			}
+		}
+		for k, v := range mapB {
+			if _, ok := mapA[k]; !ok {
+				*diffs = append(*diffs, Difference{Path: joinPath(path, k), Kind: DifferenceAdded, New: v})
+			}
+		}
+		return
+	}
+
+	listA, okA := a.([]interface{})
+	listB, okB := b.([]interface{})
+	if okA && okB && len(listA) == len(listB) {
+		for i := range listA {
+			diffValues(fmt.Sprintf("%s[%d]", path, i), listA[i], listB[i], diffs)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		*diffs = append(*diffs, Difference{Path: path, Kind: DifferenceChanged, Old: a, New: b})
+	}
+}
@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func mustParse(t *testing.T, doc string) *ActionFile {
+	t.Helper()
+	action, err := Parse(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	return action
+}
+
+func TestEqualEquivalentSpellings(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{
+			"single trigger forms",
+			"on: push\njobs: {}\n",
+			"on: [push]\njobs: {}\n",
+		},
+		{
+			"trigger list and mapping",
+			"on: [push, pull_request]\n",
+			"on:\n  push:\n  pull_request: {}\n",
+		},
+		{
+			"needs string and list",
+			"jobs:\n  b:\n    needs: a\n",
+			"jobs:\n  b:\n    needs: [a]\n",
+		},
+		{
+			"quoted scalars",
+			"jobs:\n  a:\n    strategy:\n      max-parallel: 2\n",
+			"jobs:\n  a:\n    strategy:\n      max-parallel: '2'\n",
+		},
+		{
+			"explicit defaults",
+			"jobs:\n  a:\n    runs-on: x\n",
+			"jobs:\n  a:\n    runs-on: x\n    continue-on-error: false\n    timeout-minutes: 360\n    strategy:\n      fail-fast: true\n",
+		},
+	}
+
+	for _, tt := range tests {
+		a, b := mustParse(t, tt.a), mustParse(t, tt.b)
+		if !Equal(a, b) {
+			t.Errorf("%s: expected documents to be equal, got differences %v", tt.name, Diff(a, b))
+		}
+		if Fingerprint(a) != Fingerprint(b) {
+			t.Errorf("%s: expected equal documents to have the same fingerprint", tt.name)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := mustParse(t, `name: CI
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    timeout-minutes: 10
+    steps:
+      - run: make
+      - run: make test
+`)
+	b := mustParse(t, `name: CI
+on: [push, pull_request]
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+      - run: make check
+`)
+
+	diffs := Diff(a, b)
+	expected := []string{
+		"jobs.build.steps[1].run: make test -> make check",
+		"jobs.build.timeout-minutes: removed 10",
+		"on.pull_request: added",
+	}
+	if len(diffs) != len(expected) {
+		t.Fatalf("Expected %d differences, got %d: %v", len(expected), len(diffs), diffs)
+	}
+	for i, diff := range diffs {
+		if diff.String() != expected[i] {
+			t.Errorf("Expected difference %q, got %q", expected[i], diff.String())
+		}
+	}
+
+	if Equal(a, b) {
+		t.Errorf("Expected documents not to be equal")
+	}
+}
+
+func TestDiffBareTriggers(t *testing.T) {
+	a := mustParse(t, "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make\n")
+	b := mustParse(t, "on: pull_request\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make\n")
+
+	diffs := Diff(a, b)
+	if len(diffs) != 2 {
+		t.Fatalf("Expected 2 differences, got %v", diffs)
+	}
+	if diffs[0].Path != "on.pull_request" || diffs[0].Kind != DifferenceAdded || diffs[0].String() != "on.pull_request: added" {
+		t.Errorf("Expected pull_request to be added, got %+v", diffs[0])
+	}
+	if diffs[1].Path != "on.push" || diffs[1].Kind != DifferenceRemoved || diffs[1].String() != "on.push: removed" {
+		t.Errorf("Expected push to be removed, got %+v", diffs[1])
+	}
+}
@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Concurrency is the concurrency setting of a workflow or job
+type Concurrency struct {
+	Group string `json:"group"`
+	// CancelInProgress is a boolean or an expression
+	CancelInProgress interface{} `json:"cancel-in-progress,omitempty"`
+}
+
+// ParseConcurrency interprets a 'concurrency' value, which is either a group
+// name or a mapping with 'group' and 'cancel-in-progress'. It returns nil if
+// the value is unset.
+func ParseConcurrency(v interface{}) (*Concurrency, error) {
+	switch value := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return &Concurrency{Group: value}, nil
+	default:
+		m, err := MapOfStringInterface(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid concurrency: %w", err)
+		}
+		group, _ := m["group"].(string)
+		if group == "" {
+			return nil, fmt.Errorf("concurrency must specify a group")
+		}
+		return &Concurrency{Group: group, CancelInProgress: m["cancel-in-progress"]}, nil
+	}
+}
+
+// DefaultConcurrencyContext holds the sample context values concurrency
+// groups are evaluated with by ConcurrencyCollisions. github.workflow and
+// github.job are set to the workflow's name and the job's ID.
+var DefaultConcurrencyContext = map[string]string{
+	"github.base_ref":   "",
+	"github.event_name": "push",
+	"github.head_ref":   "",
+	"github.ref":        "refs/heads/main",
+	"github.ref_name":   "main",
+	"github.repository": "owner/repo",
+	"github.run_id":     "1",
+	"github.sha":        "0000000000000000000000000000000000000000",
+}
+
+// ConcurrencyUse is a workflow or job setting a concurrency group
+type ConcurrencyUse struct {
+	File string `json:"file"`
+	// JobID is empty for workflow-level concurrency
+	JobID string `json:"job,omitempty"`
+	// Expression is the group as written
+	Expression string `json:"expression"`
+}
+
+// ConcurrencyIssueKind classifies a ConcurrencyIssue
+type ConcurrencyIssueKind string
+
+const (
+	// ConcurrencyShared is a group shared by several workflows, so that runs
+	// of one cancel or queue behind runs of the others
+	ConcurrencyShared ConcurrencyIssueKind = "shared"
+	// ConcurrencyConstant is a group without expressions, so that all runs
+	// of the workflow or job, on every branch, run one at a time
+	ConcurrencyConstant ConcurrencyIssueKind = "constant"
+)
+
+// ConcurrencyIssue is a concurrency group that likely serializes unrelated
+// runs
+type ConcurrencyIssue struct {
+	Kind ConcurrencyIssueKind `json:"kind"`
+	// Group is the group as evaluated with the sample context
+	Group string           `json:"group"`
+	Uses  []ConcurrencyUse `json:"uses"`
+}
+
+// ConcurrencyCollisions evaluates the concurrency groups of a directory of
+// workflows, as returned by ParseDir, with sample context values and reports
+// groups shared by several workflows and constant groups. values override
+// DefaultConcurrencyContext, e.g. to set github.head_ref for pull requests.
+// Groups depending on values that are not known cannot be compared and are
+// skipped, as are invalid concurrency settings, which the Validator reports.
+func ConcurrencyCollisions(workflows map[string]*ActionFile, values map[string]string) []ConcurrencyIssue {
+	byGroup := make(map[string][]ConcurrencyUse)
+	var issues []ConcurrencyIssue
+	add := func(file, jobID string, setting interface{}, context map[string]string) {
+		concurrency, err := ParseConcurrency(setting)
+		if err != nil || concurrency == nil {
+			return
+		}
+		use := ConcurrencyUse{File: file, JobID: jobID, Expression: concurrency.Group}
+		if len(ExtractExpressions(concurrency.Group)) == 0 {
+			issues = append(issues, ConcurrencyIssue{Kind: ConcurrencyConstant, Group: concurrency.Group, Uses: []ConcurrencyUse{use}})
+		}
+		if group, ok := evaluateSample(concurrency.Group, context); ok {
+			byGroup[group] = append(byGroup[group], use)
+		}
+	}
+
+	for _, file := range sortedFiles(workflows) {
+		workflow := workflows[file]
+		context := make(map[string]string, len(DefaultConcurrencyContext)+len(values)+2)
+		for k, v := range DefaultConcurrencyContext {
+			context[k] = v
+		}
+		context["github.workflow"] = workflow.Name
+		if workflow.Name == "" {
+			context["github.workflow"] = file
+		}
+		for k, v := range values {
+			context[k] = v
+		}
+
+		add(file, "", workflow.Concurrency, context)
+		for _, jobID := range sortedJobIDs(workflow) {
+			context["github.job"] = jobID
+			add(file, jobID, workflow.Jobs[jobID].ConcurrencyKey, context)
+		}
+	}
+
+	groups := make([]string, 0, len(byGroup))
+	for group := range byGroup {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		uses := byGroup[group]
+		files := make(map[string]bool)
+		for _, use := range uses {
+			files[use.File] = true
+		}
+		if len(files) > 1 {
+			issues = append(issues, ConcurrencyIssue{Kind: ConcurrencyShared, Group: group, Uses: uses})
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Kind != issues[j].Kind {
+			return issues[i].Kind > issues[j].Kind
+		}
+		return issues[i].Group < issues[j].Group
+	})
+	return issues
+}
+
+// evaluateSample substitutes the ${{ }} expressions of s using context
+// values. Only context references, string literals and '||' fallbacks are
+// understood; it reports false if any expression cannot be evaluated.
+func evaluateSample(s string, context map[string]string) (string, bool) {
+	ok := true
+	result := expressionPattern.ReplaceAllStringFunc(s, func(match string) string {
+		expr := strings.TrimSpace(expressionPattern.FindStringSubmatch(match)[1])
+		for _, operand := range strings.Split(expr, "||") {
+			operand = strings.TrimSpace(operand)
+			if len(operand) >= 2 && operand[0] == '\'' && operand[len(operand)-1] == '\'' {
+				return strings.ReplaceAll(operand[1:len(operand)-1], "''", "'")
+			}
+			value, known := context[operand]
+			if !known {
+				ok = false
+				return match
+			}
+			if value != "" {
+				return value
+			}
+		}
+		return ""
+	})
+	return result, ok
+}
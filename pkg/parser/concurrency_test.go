@@ -0,0 +1,126 @@
+package parser
+
+import "testing"
+
+func TestParseConcurrency(t *testing.T) {
+	action := mustParse(t, `
+on: push
+concurrency:
+  group: ci-${{ github.ref }}
+  cancel-in-progress: true
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    concurrency: production
+    steps:
+      - run: ./deploy.sh
+  broken:
+    runs-on: ubuntu-latest
+    concurrency:
+      cancel-in-progress: true
+    steps:
+      - run: echo
+`)
+
+	c, err := ParseConcurrency(action.Concurrency)
+	if err != nil || c.Group != "ci-${{ github.ref }}" || c.CancelInProgress != true {
+		t.Errorf("Unexpected workflow concurrency %+v (%v)", c, err)
+	}
+	c, err = ParseConcurrency(action.Jobs["deploy"].ConcurrencyKey)
+	if err != nil || c.Group != "production" {
+		t.Errorf("Unexpected job concurrency %+v (%v)", c, err)
+	}
+	if c, err := ParseConcurrency(nil); c != nil || err != nil {
+		t.Errorf("Expected nil for unset concurrency, got %+v (%v)", c, err)
+	}
+
+	errs := NewValidator().Validate(action)
+	if len(errs) != 1 || errs[0].Rule != "concurrency" || errs[0].Field != "jobs.broken.concurrency" {
+		t.Errorf("Unexpected validation errors: %v", errs)
+	}
+}
+
+func TestConcurrencyCollisions(t *testing.T) {
+	ci := mustParse(t, `
+name: CI
+on: push
+concurrency: ${{ github.workflow }}-${{ github.head_ref || github.ref }}
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make test
+`)
+	lint := mustParse(t, `
+name: Lint
+on: push
+concurrency: ${{ github.workflow }}-${{ github.ref }}
+jobs:
+  lint:
+    runs-on: ubuntu-latest
+    concurrency: checks-${{ github.ref }}
+    steps:
+      - run: make lint
+`)
+	release := mustParse(t, `
+name: Release
+on: push
+jobs:
+  publish:
+    runs-on: ubuntu-latest
+    concurrency: checks-${{ github.ref }}
+    steps:
+      - run: make publish
+  deploy:
+    runs-on: ubuntu-latest
+    concurrency: production
+    steps:
+      - run: make deploy
+  pr:
+    runs-on: ubuntu-latest
+    concurrency: pr-${{ github.event.pull_request.number }}
+    steps:
+      - run: make preview
+`)
+
+	issues := ConcurrencyCollisions(map[string]*ActionFile{
+		"ci.yml":      ci,
+		"lint.yml":    lint,
+		"release.yml": release,
+	}, nil)
+	if len(issues) != 2 {
+		t.Fatalf("Expected 2 issues, got %+v", issues)
+	}
+
+	shared := issues[0]
+	if shared.Kind != ConcurrencyShared || shared.Group != "checks-refs/heads/main" || len(shared.Uses) != 2 {
+		t.Errorf("Unexpected shared group issue %+v", shared)
+	} else if shared.Uses[0].File != "lint.yml" || shared.Uses[1].JobID != "publish" {
+		t.Errorf("Unexpected uses %+v", shared.Uses)
+	}
+
+	constant := issues[1]
+	if constant.Kind != ConcurrencyConstant || constant.Group != "production" || constant.Uses[0].JobID != "deploy" {
+		t.Errorf("Unexpected constant group issue %+v", constant)
+	}
+}
+
+func TestEvaluateSample(t *testing.T) {
+	context := map[string]string{"github.ref": "refs/heads/main", "github.head_ref": ""}
+	tests := []struct {
+		input string
+		want  string
+		ok    bool
+	}{
+		{"ci-${{ github.ref }}", "ci-refs/heads/main", true},
+		{"${{ github.head_ref || github.ref }}", "refs/heads/main", true},
+		{"${{ github.head_ref || 'default' }}", "default", true},
+		{"${{ github.event.number }}", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := evaluateSample(tt.input, context)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("evaluateSample(%q) = %q, %v; want %q, %v", tt.input, got, ok, tt.want, tt.ok)
+		}
+	}
+}
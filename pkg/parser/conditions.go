@@ -0,0 +1,40 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// stringLiteralPattern matches an expression consisting of a single
+// non-empty string literal
+var stringLiteralPattern = regexp.MustCompile(`^'(?:[^']|'')+'$`)
+
+// ConstantCondition returns why an 'if' condition can never be false, or an
+// empty string. A condition with text outside of its ${{ }} expression, such
+// as "${{ false }} && x", is evaluated as a string, and like a non-empty
+// string literal it is always true.
+func ConstantCondition(condition string) string {
+	s := strings.TrimSpace(condition)
+	if strings.Contains(s, "${{") {
+		loc := expressionPattern.FindAllStringIndex(s, -1)
+		if len(loc) != 1 || loc[0][0] != 0 || loc[0][1] != len(s) {
+			return "the condition has text outside of ${{ }}, so it is evaluated as a non-empty string, which is always true; wrap the whole condition in ${{ }} or drop the delimiters"
+		}
+		s = ExtractExpressions(s)[0]
+	}
+	if stringLiteralPattern.MatchString(s) {
+		return fmt.Sprintf("the condition is the string %s, which is always true", s)
+	}
+	return ""
+}
+
+// lintCondition reports an 'if' condition that can never be false
+func (l *Linter) lintCondition(field, condition string) {
+	if condition == "" {
+		return
+	}
+	if reason := ConstantCondition(condition); reason != "" {
+		l.addIssue("constant-condition", SeverityWarning, field, fmt.Sprintf("Condition '%s' is always true: %s", condition, reason))
+	}
+}
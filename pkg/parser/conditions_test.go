@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestConstantCondition(t *testing.T) {
+	tests := map[string]bool{
+		"github.ref == 'refs/heads/main'":        false,
+		"${{ github.ref == 'refs/heads/main' }}": false,
+		"${{ false }}":                           false,
+		"''":                                     false,
+		"${{ false }} && github.event_name == 'push'": true,
+		"${{ matrix.os }} == 'ubuntu-latest'":         true,
+		"${{ always() }} ":                            false,
+		"'main'":                                      true,
+		"${{ 'it''s' }}":                              true,
+	}
+	for condition, want := range tests {
+		if got := ConstantCondition(condition) != ""; got != want {
+			t.Errorf("ConstantCondition(%q) reported %v, want %v", condition, got, want)
+		}
+	}
+}
+
+func TestLintConstantCondition(t *testing.T) {
+	workflow := mustParse(t, `
+on: push
+jobs:
+  deploy:
+    if: ${{ github.ref == 'refs/heads/main' }} && ${{ github.event_name == 'push' }}
+    runs-on: ubuntu-latest
+    steps:
+      - run: ./deploy.sh
+        if: "'false'"
+      - run: ./notify.sh
+        if: failure()
+`)
+	var found []string
+	for _, issue := range NewLinter().Lint(workflow) {
+		if issue.Rule == "constant-condition" {
+			found = append(found, issue.Field)
+			if !strings.Contains(issue.Message, "always true") {
+				t.Errorf("Unexpected message %q", issue.Message)
+			}
+		}
+	}
+	if want := "[jobs.deploy.if jobs.deploy.steps[0].if]"; fmt.Sprint(found) != want {
+		t.Errorf("Expected %s, got %v", want, found)
+	}
+}
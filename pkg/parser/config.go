@@ -0,0 +1,191 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName is the name of the project configuration file, read from
+// the repository root
+const ConfigFileName = ".gha-parser.yml"
+
+// SeverityOff disables a rule in Config.Rules
+const SeverityOff = "off"
+
+// Config is the project configuration read from ConfigFileName:
+//
+//	rules:
+//	  branch-pinned-action: error
+//	  deprecated-command: off
+//	ignore:
+//	  - .github/workflows/legacy-*.yml
+//	pinning: sha
+//	allowed-owners: [actions, my-org]
+//	allowed-runners: [ubuntu-latest, self-hosted]
+//	require-timeouts: true
+//	extensions:
+//	  root: allow
+//	  step: reject
+type Config struct {
+	// Rules overrides the severity of rules by ID with error, warning, info
+	// or off
+	Rules map[string]string `yaml:"rules,omitempty" json:"rules,omitempty"`
+	// Ignore lists files that are not checked, as .gitignore patterns
+	// relative to the repository root
+	Ignore []string `yaml:"ignore,omitempty" json:"ignore,omitempty"`
+	// Pinning is the weakest pinning accepted for actions and reusable
+	// workflows of other repositories: tag (the default) or sha. Branches
+	// are always reported.
+	Pinning RefPinning `yaml:"pinning,omitempty" json:"pinning,omitempty"`
+	// AllowedOwners limits actions and reusable workflows of other
+	// repositories to these owners; empty allows all
+	AllowedOwners []string `yaml:"allowed-owners,omitempty" json:"allowedOwners,omitempty"`
+	// AllowedRunners limits runner labels to these; empty allows all
+	AllowedRunners []string `yaml:"allowed-runners,omitempty" json:"allowedRunners,omitempty"`
+	// Extensions sets the policy for x- prefixed keys by level, passed to
+	// the parser with WithExtensions
+	Extensions map[ExtensionLevel]ExtensionPolicy `yaml:"extensions,omitempty" json:"extensions,omitempty"`
+	// GHES is the GitHub Enterprise Server release the workflows run on,
+	// such as "3.12"; features it lacks are reported. Empty targets
+	// github.com.
+	GHES string `yaml:"ghes,omitempty" json:"ghes,omitempty"`
+	// RequiredWorkflows lists the workflows enforced across the
+	// organization by rulesets, as .gitignore patterns relative to the
+	// repository root; they are checked with CheckRequiredWorkflow
+	RequiredWorkflows []string `yaml:"required-workflows,omitempty" json:"requiredWorkflows,omitempty"`
+	// RequireTimeouts reports jobs without timeout-minutes, which run for
+	// up to six hours when they hang
+	RequireTimeouts bool `yaml:"require-timeouts,omitempty" json:"requireTimeouts,omitempty"`
+	// TimeoutRunLines reports run steps of at least this many lines
+	// without timeout-minutes; zero disables the check
+	TimeoutRunLines int `yaml:"timeout-run-lines,omitempty" json:"timeoutRunLines,omitempty"`
+}
+
+// ParseConfig reads a project configuration. Unknown keys, severities and
+// pinning levels are rejected so that typos do not silently disable a
+// policy.
+func ParseConfig(r io.Reader) (*Config, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	var c Config
+	if err := decoder.Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	for _, rule := range sortedKeys(c.Rules) {
+		if severity := c.Rules[rule]; severity != SeverityOff {
+			if _, err := ParseSeverity(severity); err != nil {
+				return nil, fmt.Errorf("invalid config for rule %s: %w", rule, err)
+			}
+		}
+	}
+	for level, policy := range c.Extensions {
+		if err := parseExtensionPolicy(level, policy); err != nil {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+	}
+	if c.GHES != "" {
+		if _, err := parseGHESVersion(c.GHES); err != nil {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+	}
+	if c.TimeoutRunLines < 0 {
+		return nil, fmt.Errorf("invalid timeout-run-lines %d in config, expected a positive number of lines", c.TimeoutRunLines)
+	}
+	switch c.Pinning {
+	case "", PinningTag, PinningSHA:
+	default:
+		return nil, fmt.Errorf("invalid pinning %q in config, expected %s or %s", c.Pinning, PinningTag, PinningSHA)
+	}
+	return &c, nil
+}
+
+// Ignored reports whether the file at the slash-separated path rel,
+// relative to the repository root, matches one of the Ignore patterns
+func (c *Config) Ignored(rel string) bool {
+	if c == nil {
+		return false
+	}
+	return matchesIgnorePatterns(c.Ignore, rel)
+}
+
+// IsRequiredWorkflow reports whether the file at the slash-separated path
+// rel, relative to the repository root, matches one of the
+// RequiredWorkflows patterns
+func (c *Config) IsRequiredWorkflow(rel string) bool {
+	if c == nil {
+		return false
+	}
+	return matchesIgnorePatterns(c.RequiredWorkflows, rel)
+}
+
+// matchesIgnorePatterns reports whether rel matches a list of .gitignore
+// patterns
+func matchesIgnorePatterns(patterns []string, rel string) bool {
+	var ignore gitignore
+	for _, pattern := range patterns {
+		if rule, ok := parseIgnoreRule("", pattern); ok {
+			ignore.rules = append(ignore.rules, rule)
+		}
+	}
+	return ignore.ignored(strings.TrimPrefix(rel, "./"), false)
+}
+
+// allowsOwner reports whether actions of owner may be used
+func (c *Config) allowsOwner(owner string) bool {
+	if c == nil || len(c.AllowedOwners) == 0 {
+		return true
+	}
+	for _, allowed := range c.AllowedOwners {
+		if strings.EqualFold(allowed, owner) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsRunner reports whether jobs may request the runner label
+func (c *Config) allowsRunner(label string) bool {
+	if c == nil || len(c.AllowedRunners) == 0 {
+		return true
+	}
+	for _, allowed := range c.AllowedRunners {
+		if strings.EqualFold(allowed, label) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply overrides the severities of findings according to Rules, dropping
+// the findings of disabled rules. The Validator and Linter apply it with
+// WithConfig.
+func (c *Config) Apply(findings []ValidationError) []ValidationError {
+	if c == nil || len(c.Rules) == 0 {
+		return findings
+	}
+	result := findings[:0]
+	for _, finding := range findings {
+		if severity, ok := c.Rules[finding.Rule]; ok {
+			if severity == SeverityOff {
+				continue
+			}
+			finding.Severity = Severity(severity)
+		}
+		result = append(result, finding)
+	}
+	return result
+}
+
+// WithConfig applies a project configuration to the Validator and Linter:
+// rule severities, the pinning policy, the allowed owners and runners, the
+// required timeouts and the GitHub Enterprise Server release.
+// Ignore patterns are left to the caller, see Config.Ignored.
+func WithConfig(c *Config) Option {
+	return func(o *options) {
+		o.config = c
+	}
+}
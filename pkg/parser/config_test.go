@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	c, err := ParseConfig(strings.NewReader(`
+rules:
+  branch-pinned-action: error
+  deprecated-command: off
+ignore:
+  - .github/workflows/legacy-*.yml
+pinning: sha
+allowed-owners: [actions]
+allowed-runners: [ubuntu-latest]
+extensions:
+  job: allow
+required-workflows: [.github/workflows/org-*.yml]
+require-timeouts: true
+timeout-run-lines: 30
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Pinning != PinningSHA || c.Rules["deprecated-command"] != SeverityOff || len(c.AllowedOwners) != 1 ||
+		c.Extensions[ExtensionLevelJob] != ExtensionAllow || !c.RequireTimeouts || c.TimeoutRunLines != 30 {
+		t.Errorf("Unexpected config %+v", c)
+	}
+	if !c.Ignored(".github/workflows/legacy-ci.yml") || c.Ignored(".github/workflows/ci.yml") {
+		t.Error("Unexpected result of Ignored")
+	}
+	if !c.IsRequiredWorkflow(".github/workflows/org-scan.yml") || c.IsRequiredWorkflow(".github/workflows/ci.yml") {
+		t.Error("Unexpected result of IsRequiredWorkflow")
+	}
+
+	if c, err := ParseConfig(strings.NewReader("")); err != nil || c == nil {
+		t.Errorf("Expected an empty config, got %+v (%v)", c, err)
+	}
+	for _, invalid := range []string{"rules:\n  x: fatal\n", "pinning: branch\n", "allowed_owners: [actions]\n",
+		"extensions:\n  job: warn\n", "extensions:\n  service: allow\n", "ghes: latest\n",
+		"timeout-run-lines: -1\n"} {
+		if _, err := ParseConfig(strings.NewReader(invalid)); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestLintWithConfig(t *testing.T) {
+	action := mustParse(t, `
+on: push
+jobs:
+  build:
+    runs-on: windows-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: someone/tool@main
+      - run: echo "::set-output name=x::y"
+`)
+	config := &Config{
+		Rules:          map[string]string{"branch-pinned-action": "error", "deprecated-command": SeverityOff},
+		Pinning:        PinningSHA,
+		AllowedOwners:  []string{"actions"},
+		AllowedRunners: []string{"ubuntu-latest"},
+	}
+	got := make(map[string]Severity)
+	for _, issue := range NewLinter(WithConfig(config)).Lint(action) {
+		got[issue.Rule+" "+issue.Field] = issue.Severity
+	}
+	want := map[string]Severity{
+		"sha-pinning jobs.build.steps[0].uses":          SeverityWarning,
+		"disallowed-action jobs.build.steps[1].uses":    SeverityError,
+		"branch-pinned-action jobs.build.steps[1].uses": SeverityError,
+		"disallowed-runner jobs.build.runs-on":          SeverityError,
+	}
+	for key, severity := range want {
+		if got[key] != severity {
+			t.Errorf("Expected %s with severity %s, got %v", key, severity, got)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("Unexpected issues %v", got)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	root := t.TempDir()
+	if c, err := LoadConfig(root); err != nil || c == nil {
+		t.Errorf("Expected an empty config without a file, got %+v (%v)", c, err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ConfigFileName), []byte("pinning: sha\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := LoadConfig(root); err != nil || c.Pinning != PinningSHA {
+		t.Errorf("Unexpected config %+v (%v)", c, err)
+	}
+}
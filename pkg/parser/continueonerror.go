@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// riskyPurposes classify the jobs and steps whose failures should not be
+// swallowed by continue-on-error, by the words of their names and actions
+var riskyPurposes = []struct {
+	purpose string
+	pattern *regexp.Regexp
+}{
+	{"tests", regexp.MustCompile(`(?:^|[^a-z])(?:tests?|testing|specs?|e2e|pytest|jest|vitest|mocha|rspec|cypress|playwright)(?:[^a-z]|$)`)},
+	{"a security scan", regexp.MustCompile(`(?:^|[^a-z])(?:security|scan|scanning|codeql|snyk|trivy|grype|semgrep|gitleaks|sast|audit|dependency-review)(?:[^a-z]|$)`)},
+	{"a deployment", regexp.MustCompile(`(?:^|[^a-z])(?:deploy|deploys|deployment|release|publish)(?:[^a-z]|$)`)},
+}
+
+// riskyPurpose returns what the names of a job or step suggest it does,
+// or an empty string if none is risky
+func riskyPurpose(names ...string) string {
+	for _, risky := range riskyPurposes {
+		for _, name := range names {
+			if risky.pattern.MatchString(strings.ToLower(name)) {
+				return risky.purpose
+			}
+		}
+	}
+	return ""
+}
+
+// actionName strips the ref from a 'uses' value
+func actionName(uses string) string {
+	name, _, _ := strings.Cut(uses, "@")
+	return name
+}
+
+// lintContinueOnError reports continue-on-error: true on jobs and steps that
+// look like tests, security scans or deployments, and on jobs other jobs
+// need, where a swallowed failure lets the pipeline pass anyway. Values
+// computed by expressions are skipped.
+func (l *Linter) lintContinueOnError(action *ActionFile) {
+	neededBy := make(map[string]string)
+	for _, jobID := range sortedJobIDs(action) {
+		for _, need := range JobNeeds(action.Jobs[jobID]) {
+			if _, ok := neededBy[need]; !ok {
+				neededBy[need] = jobID
+			}
+		}
+	}
+
+	for _, jobID := range sortedJobIDs(action) {
+		job := action.Jobs[jobID]
+		if job.ContinueOn == true {
+			field := fmt.Sprintf("jobs.%s.continue-on-error", jobID)
+			if purpose := riskyPurpose(jobID, job.Name, actionName(job.Uses)); purpose != "" {
+				l.addIssue("risky-continue-on-error", SeverityWarning, field,
+					fmt.Sprintf("Job '%s' looks like %s, but continue-on-error: true lets the workflow pass when it fails", jobID, purpose))
+			} else if dependent, ok := neededBy[jobID]; ok {
+				l.addIssue("risky-continue-on-error", SeverityWarning, field,
+					fmt.Sprintf("Job '%s' gates '%s' through needs, but continue-on-error: true lets '%s' run when it fails", jobID, dependent, dependent))
+			}
+		}
+		for i, step := range job.Steps {
+			if step.ContinueOn != true {
+				continue
+			}
+			if purpose := riskyPurpose(step.ID, step.Name, actionName(step.Uses)); purpose != "" {
+				l.addIssue("risky-continue-on-error", SeverityWarning, fmt.Sprintf("jobs.%s.steps[%d].continue-on-error", jobID, i),
+					fmt.Sprintf("The step looks like %s, but continue-on-error: true lets the job pass when it fails", purpose))
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLintContinueOnError(t *testing.T) {
+	workflow := mustParse(t, `on: push
+jobs:
+  unit-tests:
+    runs-on: ubuntu-latest
+    continue-on-error: true
+    steps:
+      - run: go test ./...
+  build:
+    runs-on: ubuntu-latest
+    continue-on-error: true
+    steps:
+      - name: Run Trivy
+        uses: aquasecurity/trivy-action@0.20.0
+        continue-on-error: true
+      - name: Upload coverage
+        uses: codecov/codecov-action@v4
+        continue-on-error: true
+      - id: latest
+        run: ./fetch-latest.sh
+        continue-on-error: true
+  ship:
+    needs: build
+    uses: org/pipelines/.github/workflows/deploy.yml@v1
+    continue-on-error: true
+  experimental:
+    runs-on: ubuntu-latest
+    continue-on-error: ${{ matrix.experimental }}
+    strategy:
+      matrix:
+        experimental: [true, false]
+    steps:
+      - run: make test
+`)
+	var fields []string
+	for _, issue := range NewLinter().Lint(workflow) {
+		if issue.Rule == "risky-continue-on-error" {
+			fields = append(fields, issue.Field)
+		}
+	}
+	want := []string{
+		"jobs.build.continue-on-error",
+		"jobs.build.steps[0].continue-on-error",
+		"jobs.ship.continue-on-error",
+		"jobs.unit-tests.continue-on-error",
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("Expected issues on %v, got %v", want, fields)
+	}
+}
+
+func TestRiskyPurpose(t *testing.T) {
+	tests := map[string]string{
+		"Run unit_tests":            "tests",
+		"github/codeql-action/init": "a security scan",
+		"Publish to npm":            "a deployment",
+		"latest":                    "",
+		"Contest results":           "",
+		"Prerelease notes":          "",
+	}
+	for name, want := range tests {
+		if got := riskyPurpose(name); got != want {
+			t.Errorf("riskyPurpose(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DependencyKind classifies a 'uses' reference
+type DependencyKind string
+
+const (
+	// DependencyAction is an action in another repository
+	DependencyAction DependencyKind = "action"
+	// DependencyReusableWorkflow is a reusable workflow in another repository
+	DependencyReusableWorkflow DependencyKind = "reusable-workflow"
+	// DependencyLocal is an action or reusable workflow in the same repository
+	DependencyLocal DependencyKind = "local"
+	// DependencyDocker is a Docker image run with 'uses: docker://image'
+	DependencyDocker DependencyKind = "docker"
+)
+
+// DependencyLocation is a place a dependency is referenced from
+type DependencyLocation struct {
+	File  string `json:"file"`
+	Field string `json:"field"`
+}
+
+// Dependency is an action, reusable workflow or Docker image referenced by
+// a set of workflows
+type Dependency struct {
+	Uses string         `json:"uses"`
+	Kind DependencyKind `json:"kind"`
+	// Action is set for actions and reusable workflows of other repositories
+	Action *ActionRef `json:"action,omitempty"`
+	// Image is set for Docker images
+	Image     *ImageRef            `json:"image,omitempty"`
+	Locations []DependencyLocation `json:"locations"`
+}
+
+// Dependencies lists the dependencies of a directory of workflows and
+// actions, as returned by ParseDir, sorted by their 'uses' value. Malformed
+// references are skipped.
+func Dependencies(workflows map[string]*ActionFile) []Dependency {
+	byUses := make(map[string]*Dependency)
+	add := func(file, field, uses string) {
+		if uses == "" {
+			return
+		}
+		dep, ok := byUses[uses]
+		if !ok {
+			dep = &Dependency{Uses: uses}
+			switch {
+			case strings.HasPrefix(uses, "docker://"):
+				image, err := ParseImageRef(uses)
+				if err != nil {
+					return
+				}
+				dep.Kind, dep.Image = DependencyDocker, &image
+			case strings.HasPrefix(uses, "./"):
+				dep.Kind = DependencyLocal
+			default:
+				ref, ok := ParseActionRef(uses)
+				if !ok {
+					return
+				}
+				dep.Kind, dep.Action = DependencyAction, &ref
+				if ref.IsWorkflow() {
+					dep.Kind = DependencyReusableWorkflow
+				}
+			}
+			byUses[uses] = dep
+		}
+		dep.Locations = append(dep.Locations, DependencyLocation{File: file, Field: field})
+	}
+
+	for _, file := range sortedFiles(workflows) {
+		action := workflows[file]
+		for i, step := range action.Runs.Steps {
+			add(file, fmt.Sprintf("runs.steps[%d].uses", i), step.Uses)
+		}
+		for _, jobID := range sortedJobIDs(action) {
+			job := action.Jobs[jobID]
+			add(file, fmt.Sprintf("jobs.%s.uses", jobID), job.Uses)
+			for i, step := range job.Steps {
+				add(file, fmt.Sprintf("jobs.%s.steps[%d].uses", jobID, i), step.Uses)
+			}
+		}
+	}
+
+	result := make([]Dependency, 0, len(byUses))
+	for _, dep := range byUses {
+		result = append(result, *dep)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Uses < result[j].Uses
+	})
+	return result
+}
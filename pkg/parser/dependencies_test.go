@@ -0,0 +1,53 @@
+package parser
+
+import "testing"
+
+func TestDependencies(t *testing.T) {
+	ci := mustParse(t, `
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: docker://alpine:3.19
+      - uses: ./.github/actions/setup
+  deploy:
+    uses: octo/workflows/.github/workflows/deploy.yml@v1
+`)
+	release := mustParse(t, `
+on: push
+jobs:
+  release:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+`)
+
+	deps := Dependencies(map[string]*ActionFile{"ci.yml": ci, "release.yml": release})
+	want := []struct {
+		uses      string
+		kind      DependencyKind
+		locations int
+	}{
+		{"./.github/actions/setup", DependencyLocal, 1},
+		{"actions/checkout@v4", DependencyAction, 2},
+		{"docker://alpine:3.19", DependencyDocker, 1},
+		{"octo/workflows/.github/workflows/deploy.yml@v1", DependencyReusableWorkflow, 1},
+	}
+	if len(deps) != len(want) {
+		t.Fatalf("Expected %d dependencies, got %+v", len(want), deps)
+	}
+	for i, w := range want {
+		dep := deps[i]
+		if dep.Uses != w.uses || dep.Kind != w.kind || len(dep.Locations) != w.locations {
+			t.Errorf("Dependency %d = %+v, want %s (%s) at %d locations", i, dep, w.uses, w.kind, w.locations)
+		}
+	}
+	if deps[2].Image == nil || deps[2].Image.Tag != "3.19" {
+		t.Errorf("Expected parsed image for docker dependency, got %+v", deps[2].Image)
+	}
+	if loc := deps[1].Locations[1]; loc.File != "release.yml" || loc.Field != "jobs.release.steps[0].uses" {
+		t.Errorf("Unexpected location %+v", loc)
+	}
+}
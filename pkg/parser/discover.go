@@ -0,0 +1,97 @@
+//go:build !js
+
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+)
+
+// LocalAction is an action defined in a repository
+type LocalAction struct {
+	// Uses is the reference to the action from the same repository, e.g.
+	// "./.github/actions/setup"
+	Uses string `json:"uses"`
+	// Path is the path of the metadata file relative to the repository root
+	Path   string  `json:"path"`
+	Action *Action `json:"action"`
+}
+
+// DiscoverActions finds the action.yml and action.yaml files of the
+// repository at root, in .github/actions and anywhere else, sorted by their
+// 'uses' reference. Workflows are not included. DefaultSkipDirs are skipped
+// unless WithSkipDirs sets another list, and metadata files that are not
+// actions fail with ErrNotAnAction. Errors are handled like in ParseDir,
+// including WithContinueOnError.
+func DiscoverActions(root string, opts ...Option) ([]LocalAction, error) {
+	o := newOptions(append([]Option{WithSkipDirs(DefaultSkipDirs...)}, opts...))
+	filter := newWalkFilter(root, o)
+	progress := newProgressTracker(o)
+	var failures []*FileError
+	var paths []string
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err := o.ctx.Err(); err != nil {
+			return err
+		}
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				err = classify(ErrNotFound, err)
+			}
+			if o.continueOnError && path != root {
+				failures = append(failures, &FileError{Path: relPath(root, path), Err: err})
+				progress.failed(relPath(root, path), err)
+				return nil
+			}
+			return err
+		}
+		if skip, err := filter.skip(path, entry); err != nil || skip {
+			if err == nil && entry.IsDir() {
+				err = filepath.SkipDir
+			}
+			return err
+		}
+		if name := entry.Name(); !entry.IsDir() && (name == "action.yml" || name == "action.yaml") {
+			paths = append(paths, path)
+			progress.discovered(relPath(root, path), entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	}
+
+	var result []LocalAction
+	for _, path := range paths {
+		if err := o.ctx.Err(); err != nil {
+			return nil, fmt.Errorf("failed to walk directory: %w", err)
+		}
+		rel := relPath(root, path)
+		file, err := parseFile(path, o)
+		var action *Action
+		if err == nil {
+			action, err = file.AsAction()
+		}
+		if err != nil {
+			progress.failed(rel, err)
+			if o.continueOnError {
+				failures = append(failures, &FileError{Path: rel, Err: err})
+				continue
+			}
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		result = append(result, LocalAction{Uses: localFileKey(rel), Path: rel, Action: action})
+		progress.parsed(rel, file)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Uses < result[j].Uses
+	})
+
+	if len(failures) > 0 {
+		return result, &DirError{Errors: failures}
+	}
+	return result, nil
+}
@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiscoverActions(t *testing.T) {
+	root := t.TempDir()
+	action := "name: a\ndescription: b\nruns:\n  using: composite\n  steps:\n    - run: echo\n      shell: bash\n"
+	files := map[string]string{
+		".github/actions/setup/action.yml":   action,
+		".github/actions/deploy/action.yaml": action,
+		"action.yml":                         action,
+		"tools/lint/action.yml":              action,
+		".github/workflows/ci.yml":           "on: push\njobs: {}\n",
+		"node_modules/x/action.yml":          action,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	actions, err := DiscoverActions(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ uses, path string }{
+		{"./", "action.yml"},
+		{"./.github/actions/deploy", ".github/actions/deploy/action.yaml"},
+		{"./.github/actions/setup", ".github/actions/setup/action.yml"},
+		{"./tools/lint", "tools/lint/action.yml"},
+	}
+	if len(actions) != len(want) {
+		t.Fatalf("DiscoverActions() returned %d actions, want %d: %+v", len(actions), len(want), actions)
+	}
+	for i, w := range want {
+		got := actions[i]
+		if got.Uses != w.uses || filepath.ToSlash(got.Path) != w.path || got.Action.Type != FileTypeCompositeAction {
+			t.Errorf("actions[%d] = {%s %s %s}, want {%s %s}", i, got.Uses, got.Path, got.Action.Type, w.uses, w.path)
+		}
+	}
+
+	// A workflow named action.yml is reported
+	if err := os.WriteFile(filepath.Join(root, "tools", "lint", "action.yml"), []byte("on: push\njobs: {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DiscoverActions(root); !errors.Is(err, ErrNotAnAction) {
+		t.Errorf("DiscoverActions() error = %v, want ErrNotAnAction", err)
+	}
+	actions, err = DiscoverActions(root, WithContinueOnError())
+	var dirErr *DirError
+	if !errors.As(err, &dirErr) || len(dirErr.Errors) != 1 || len(actions) != 3 {
+		t.Errorf("DiscoverActions(WithContinueOnError) = %d actions, %v", len(actions), err)
+	}
+}
@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DispatchInput is an input of a manually triggered workflow
+type DispatchInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Type is string when the input declares none
+	Type     string `json:"type"`
+	Required bool   `json:"required,omitempty"`
+	Default  string `json:"default,omitempty"`
+	// Options are the choices of choice inputs
+	Options []string `json:"options,omitempty"`
+}
+
+// DispatchWorkflow is a workflow that can be triggered by hand
+type DispatchWorkflow struct {
+	File string `json:"file"`
+	Name string `json:"name,omitempty"`
+	// Inputs are sorted by name
+	Inputs []DispatchInput `json:"inputs"`
+}
+
+// DispatchInventory lists which workflows of a directory can be triggered
+// by hand, from the Actions tab, the API or 'gh workflow run'
+type DispatchInventory struct {
+	// Workflows are the workflows triggered by workflow_dispatch, sorted by
+	// path
+	Workflows []DispatchWorkflow `json:"workflows"`
+	// NotDispatchable are the sorted paths of the other workflows
+	NotDispatchable []string `json:"notDispatchable,omitempty"`
+}
+
+// ManualTriggers builds the dispatch inventory of a directory of workflows,
+// as returned by ParseDir. Actions are skipped, as are invalid input
+// definitions, which the Validator reports.
+func ManualTriggers(workflows map[string]*ActionFile) *DispatchInventory {
+	inventory := &DispatchInventory{Workflows: make([]DispatchWorkflow, 0)}
+	for _, file := range sortedFiles(workflows) {
+		action := workflows[file]
+		if !DetectType(action).IsWorkflow() {
+			continue
+		}
+		config, ok, err := triggerConfig(action, "workflow_dispatch")
+		if !ok {
+			inventory.NotDispatchable = append(inventory.NotDispatchable, file)
+			continue
+		}
+		workflow := DispatchWorkflow{File: file, Name: action.Name, Inputs: make([]DispatchInput, 0)}
+		if err == nil {
+			workflow.Inputs = dispatchInputs(config["inputs"])
+		}
+		inventory.Workflows = append(inventory.Workflows, workflow)
+	}
+	return inventory
+}
+
+// dispatchInputs converts the 'inputs' of a workflow_dispatch trigger
+func dispatchInputs(raw interface{}) []DispatchInput {
+	inputs := make([]DispatchInput, 0)
+	defs, err := MapOfStringInterface(raw)
+	if err != nil {
+		return inputs
+	}
+	for name, value := range defs {
+		def, err := MapOfStringInterface(value)
+		if err != nil {
+			continue
+		}
+		input := DispatchInput{Name: name, Type: "string"}
+		input.Description, _ = def["description"].(string)
+		input.Required, _ = def["required"].(bool)
+		if typ, ok := def["type"].(string); ok && typ != "" {
+			input.Type = typ
+		}
+		if value, ok := def["default"]; ok && value != nil {
+			input.Default = fmt.Sprint(value)
+		}
+		input.Options, _ = stringList(def["options"])
+		inputs = append(inputs, input)
+	}
+	sort.Slice(inputs, func(i, j int) bool { return inputs[i].Name < inputs[j].Name })
+	return inputs
+}
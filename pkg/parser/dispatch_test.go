@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestManualTriggers(t *testing.T) {
+	set := WorkflowSet{
+		"deploy.yml": mustParse(t, `
+name: Deploy
+on:
+  workflow_dispatch:
+    inputs:
+      environment:
+        description: Target environment
+        type: choice
+        required: true
+        options: [staging, production]
+      dry-run:
+        type: boolean
+        default: false
+      ref:
+        default: main
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make deploy
+`),
+		"manual.yml": mustParse(t, `
+on: [push, workflow_dispatch]
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+`),
+		"ci.yml": mustParse(t, `
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+`),
+		"action.yml": mustParse(t, `
+name: Setup
+runs:
+  using: node20
+  main: index.js
+`),
+	}
+
+	got := ManualTriggers(set)
+	want := &DispatchInventory{
+		Workflows: []DispatchWorkflow{
+			{File: "deploy.yml", Name: "Deploy", Inputs: []DispatchInput{
+				{Name: "dry-run", Type: "boolean", Default: "false"},
+				{Name: "environment", Description: "Target environment", Type: "choice", Required: true, Options: []string{"staging", "production"}},
+				{Name: "ref", Type: "string", Default: "main"},
+			}},
+			{File: "manual.yml", Inputs: []DispatchInput{}},
+		},
+		NotDispatchable: []string{"ci.yml"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ManualTriggers() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultRegistry is the registry of Docker images without a registry host
+const DefaultRegistry = "docker.io"
+
+// digestPattern matches a content digest such as sha256:<64 hex digits>
+var digestPattern = regexp.MustCompile(`^[a-z0-9]+(?:[+._-][a-z0-9]+)*:[a-fA-F0-9]{32,}$`)
+
+// repositoryPattern matches the path components of an image repository
+var repositoryPattern = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*$`)
+
+// tagPattern matches an image tag
+var tagPattern = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+
+// ImageRef is a Docker image reference, as used by 'uses: docker://image'
+// steps and Docker actions
+type ImageRef struct {
+	// Registry is the registry host, DefaultRegistry if the reference names
+	// none
+	Registry   string `json:"registry"`
+	Repository string `json:"repository"`
+	// Tag is empty when the reference has no tag, which means 'latest'
+	Tag    string `json:"tag,omitempty"`
+	Digest string `json:"digest,omitempty"`
+}
+
+// ParseImageRef parses a Docker image reference, with or without the
+// docker:// prefix
+func ParseImageRef(s string) (ImageRef, error) {
+	name := strings.TrimPrefix(s, "docker://")
+	var ref ImageRef
+
+	if i := strings.Index(name, "@"); i >= 0 {
+		ref.Digest = name[i+1:]
+		name = name[:i]
+		if !digestPattern.MatchString(ref.Digest) {
+			return ImageRef{}, fmt.Errorf("invalid image digest in %q", s)
+		}
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		ref.Tag = name[i+1:]
+		name = name[:i]
+		if !tagPattern.MatchString(ref.Tag) {
+			return ImageRef{}, fmt.Errorf("invalid image tag in %q", s)
+		}
+	}
+
+	ref.Registry = DefaultRegistry
+	if i := strings.Index(name, "/"); i >= 0 {
+		host := name[:i]
+		if strings.ContainsAny(host, ".:") || host == "localhost" {
+			ref.Registry = host
+			name = name[i+1:]
+		}
+	}
+	if !repositoryPattern.MatchString(name) {
+		return ImageRef{}, fmt.Errorf("invalid image repository in %q", s)
+	}
+	ref.Repository = name
+	return ref, nil
+}
+
+// IsPinned reports whether the reference names an immutable digest
+func (r ImageRef) IsPinned() bool {
+	return r.Digest != ""
+}
+
+// String returns the reference without the docker:// prefix
+func (r ImageRef) String() string {
+	s := r.Repository
+	if r.Registry != DefaultRegistry {
+		s = r.Registry + "/" + s
+	}
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// lintDockerImage reports docker:// references that are not pinned to a
+// digest. Missing and 'latest' tags are warnings, other tags are info.
+func (l *Linter) lintDockerImage(field, uses string) {
+	ref, err := ParseImageRef(uses)
+	if err != nil || ref.IsPinned() {
+		return
+	}
+	if ref.Tag == "" || ref.Tag == "latest" {
+		l.addIssue("docker-image-digest", SeverityWarning, field,
+			fmt.Sprintf("Image '%s' uses the 'latest' tag, which changes with every release", ref))
+		return
+	}
+	l.addIssue("docker-image-digest", SeverityInfo, field,
+		fmt.Sprintf("Image '%s' is not pinned to a digest; tags can be moved", ref))
+}
@@ -0,0 +1,89 @@
+package parser
+
+import "testing"
+
+const alpineDigest = "sha256:c5b1261d6d3e43071626931fc004f70149baeba2c8ec672bd4f27761f8e1ad6b"
+
+func TestParseImageRef(t *testing.T) {
+	tests := []struct {
+		input string
+		want  ImageRef
+	}{
+		{"docker://alpine", ImageRef{Registry: "docker.io", Repository: "alpine"}},
+		{"docker://alpine:3.19", ImageRef{Registry: "docker.io", Repository: "alpine", Tag: "3.19"}},
+		{"docker://ghcr.io/octo/tool:1.2.3", ImageRef{Registry: "ghcr.io", Repository: "octo/tool", Tag: "1.2.3"}},
+		{"docker://localhost:5000/tool", ImageRef{Registry: "localhost:5000", Repository: "tool"}},
+		{"alpine:3.19@" + alpineDigest, ImageRef{Registry: "docker.io", Repository: "alpine", Tag: "3.19", Digest: alpineDigest}},
+	}
+	for _, tt := range tests {
+		got, err := ParseImageRef(tt.input)
+		if err != nil {
+			t.Errorf("ParseImageRef(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseImageRef(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{"docker://", "docker://Alpine", "docker://alpine:", "docker://alpine@sha256:abc"} {
+		if _, err := ParseImageRef(input); err == nil {
+			t.Errorf("ParseImageRef(%q) succeeded, want error", input)
+		}
+	}
+}
+
+func TestImageRefString(t *testing.T) {
+	ref, err := ParseImageRef("docker://ghcr.io/octo/tool:1.2.3@" + alpineDigest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ref.String(); got != "ghcr.io/octo/tool:1.2.3@"+alpineDigest {
+		t.Errorf("String() = %q", got)
+	}
+	if !ref.IsPinned() {
+		t.Error("Expected reference with digest to be pinned")
+	}
+}
+
+func TestDockerStepChecks(t *testing.T) {
+	action := mustParse(t, `
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: docker://alpine
+        with:
+          args: echo hello
+      - uses: docker://alpine:3.19
+      - uses: docker://alpine:3.19@`+alpineDigest+`
+      - uses: docker://Alpine
+`)
+
+	errs := NewValidator().Validate(action)
+	if len(errs) != 1 || errs[0].Rule != "docker-reference" || errs[0].Field != "jobs.build.steps[3].uses" {
+		t.Errorf("Unexpected validation errors: %v", errs)
+	}
+
+	issues := NewLinter().Lint(action)
+	severities := make(map[string]Severity)
+	for _, issue := range issues {
+		if issue.Rule != "docker-image-digest" {
+			t.Errorf("Unexpected lint issue: %v", issue)
+		}
+		severities[issue.Field] = issue.Severity
+	}
+	want := map[string]Severity{
+		"jobs.build.steps[0].uses": SeverityWarning,
+		"jobs.build.steps[1].uses": SeverityInfo,
+	}
+	if len(severities) != len(want) {
+		t.Fatalf("Expected %d issues, got %v", len(want), issues)
+	}
+	for field, severity := range want {
+		if severities[field] != severity {
+			t.Errorf("Expected %s for %s, got %q", severity, field, severities[field])
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DuplicateMember identifies a job, or a range of its steps, taking part in
+// a DuplicateCluster
+type DuplicateMember struct {
+	File  string `json:"file"`
+	JobID string `json:"job"`
+	// Start and End delimit the duplicated steps as steps[Start:End]
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// DuplicateCluster groups jobs or step sequences that are near-identical and
+// are therefore candidates for a composite action or reusable workflow
+type DuplicateCluster struct {
+	// Similarity is the lowest similarity between two linked members, 1.0
+	// for identical step sequences
+	Similarity float64           `json:"similarity"`
+	Members    []DuplicateMember `json:"members"`
+}
+
+// jobRef is a job in a set of parsed workflows together with the signatures
+// of its steps
+type jobRef struct {
+	file  string
+	jobID string
+	steps []string
+}
+
+// FindDuplicateJobs clusters jobs across workflows whose step sequences are
+// at least minSimilarity alike (between 0 and 1). Similarity is computed from
+// the longest common subsequence of steps, where steps compare equal when
+// they use the same action with the same inputs or run the same script.
+func FindDuplicateJobs(workflows map[string]*ActionFile, minSimilarity float64) []DuplicateCluster {
+	jobs := collectJobRefs(workflows)
+
+	parent := make([]int, len(jobs))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	linkScore := make(map[int]float64)
+	for i := 0; i < len(jobs); i++ {
+		for j := i + 1; j < len(jobs); j++ {
+			score := stepSimilarity(jobs[i].steps, jobs[j].steps)
+			if score < minSimilarity {
+				continue
+			}
+			ri, rj := find(i), find(j)
+			lowest := score
+			for _, r := range []int{ri, rj} {
+				if s, ok := linkScore[r]; ok && s < lowest {
+					lowest = s
+				}
+			}
+			parent[rj] = ri
+			linkScore[ri] = lowest
+		}
+	}
+
+	groups := make(map[int][]DuplicateMember)
+	for i, job := range jobs {
+		root := find(i)
+		if _, linked := linkScore[root]; !linked {
+			continue
+		}
+		groups[root] = append(groups[root], DuplicateMember{
+			File:  job.file,
+			JobID: job.jobID,
+			End:   len(job.steps),
+		})
+	}
+
+	clusters := make([]DuplicateCluster, 0, len(groups))
+	for root, members := range groups {
+		clusters = append(clusters, DuplicateCluster{Similarity: linkScore[root], Members: members})
+	}
+	sortClusters(clusters)
+	return clusters
+}
+
+// FindDuplicateSteps finds sequences of at least minLength consecutive steps
+// that appear identically in more than one job. Only maximal sequences are
+// reported: a sequence is omitted when a longer one covers all of its
+// occurrences.
+func FindDuplicateSteps(workflows map[string]*ActionFile, minLength int) []DuplicateCluster {
+	if minLength < 1 {
+		minLength = 1
+	}
+	jobs := collectJobRefs(workflows)
+
+	occurrences := make(map[string][]DuplicateMember)
+	for _, job := range jobs {
+		for start := 0; start < len(job.steps); start++ {
+			for end := start + minLength; end <= len(job.steps); end++ {
+				key := strings.Join(job.steps[start:end], "\x00")
+				occurrences[key] = append(occurrences[key], DuplicateMember{
+					File:  job.file,
+					JobID: job.jobID,
+					Start: start,
+					End:   end,
+				})
+			}
+		}
+	}
+
+	var candidates []DuplicateCluster
+	for _, members := range occurrences {
+		if len(members) < 2 {
+			continue
+		}
+		candidates = append(candidates, DuplicateCluster{Similarity: 1, Members: members})
+	}
+
+	// Longest sequences first, so that covered sequences can be dropped
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Members[0].End-candidates[i].Members[0].Start >
+			candidates[j].Members[0].End-candidates[j].Members[0].Start
+	})
+
+	var clusters []DuplicateCluster
+	for _, candidate := range candidates {
+		if !coveredBy(candidate, clusters) {
+			clusters = append(clusters, candidate)
+		}
+	}
+	sortClusters(clusters)
+	return clusters
+}
+
+// coveredBy reports whether every occurrence of c lies within an occurrence
+// of one of the clusters
+func coveredBy(c DuplicateCluster, clusters []DuplicateCluster) bool {
+	for _, other := range clusters {
+		covered := true
+		for _, m := range c.Members {
+			found := false
+			for _, o := range other.Members {
+				if o.File == m.File && o.JobID == m.JobID && o.Start <= m.Start && m.End <= o.End {
+					found = true
+					break
+				}
+			}
+			if !found {
+				covered = false
+				break
+			}
+		}
+		if covered && len(other.Members) >= len(c.Members) {
+			return true
+		}
+	}
+	return false
+}
+
+// collectJobRefs returns the jobs with steps of all workflows in a stable
+// order
+func collectJobRefs(workflows map[string]*ActionFile) []jobRef {
+	files := make([]string, 0, len(workflows))
+	for file := range workflows {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	var jobs []jobRef
+	for _, file := range files {
+		workflow := workflows[file]
+		for _, jobID := range sortedJobIDs(workflow) {
+			job := workflow.Jobs[jobID]
+			if len(job.Steps) == 0 {
+				continue
+			}
+			steps := make([]string, len(job.Steps))
+			for i, step := range job.Steps {
+				steps[i] = stepSignature(step)
+			}
+			jobs = append(jobs, jobRef{file: file, jobID: jobID, steps: steps})
+		}
+	}
+	return jobs
+}
+
+// stepSignature returns a string that is equal for steps doing the same
+// thing. Step names, IDs and action versions are ignored.
+func stepSignature(step Step) string {
+	var b strings.Builder
+	if step.Uses != "" {
+		uses := step.Uses
+		if at := strings.LastIndex(uses, "@"); at > 0 {
+			uses = uses[:at]
+		}
+		b.WriteString("uses:" + uses)
+		keys := make([]string, 0, len(step.With))
+		for k := range step.With {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(&b, "|%s=%v", k, step.With[k])
+		}
+	}
+	if step.Run != "" {
+		b.WriteString("run:" + strings.Join(strings.Fields(step.Run), " "))
+	}
+	return b.String()
+}
+
+// stepSimilarity returns 2*LCS/(len(a)+len(b)) for two step sequences
+func stepSimilarity(a, b []string) float64 {
+	if len(a)+len(b) == 0 {
+		return 0
+	}
+
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			switch {
+			case a[i-1] == b[j-1]:
+				curr[j] = prev[j-1] + 1
+			case prev[j] > curr[j-1]:
+				curr[j] = prev[j]
+			default:
+				curr[j] = curr[j-1]
+			}
+		}
+		prev, curr = curr, prev
+	}
+
+	return 2 * float64(prev[len(b)]) / float64(len(a)+len(b))
+}
+
+// sortClusters orders clusters by size, then similarity, then first member
+func sortClusters(clusters []DuplicateCluster) {
+	for _, c := range clusters {
+		sort.Slice(c.Members, func(i, j int) bool {
+			a, b := c.Members[i], c.Members[j]
+			if a.File != b.File {
+				return a.File < b.File
+			}
+			if a.JobID != b.JobID {
+				return a.JobID < b.JobID
+			}
+			return a.Start < b.Start
+		})
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		a, b := clusters[i], clusters[j]
+		if len(a.Members) != len(b.Members) {
+			return len(a.Members) > len(b.Members)
+		}
+		if a.Similarity != b.Similarity {
+			return a.Similarity > b.Similarity
+		}
+		return a.Members[0].File+a.Members[0].JobID < b.Members[0].File+b.Members[0].JobID
+	})
+}
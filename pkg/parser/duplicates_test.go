@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestFindDuplicateSteps(t *testing.T) {
+	workflows, err := ParseDir("testdata")
+	if err != nil {
+		t.Fatalf("Failed to parse directory: %v", err)
+	}
+
+	clusters := FindDuplicateSteps(workflows, 3)
+	if len(clusters) != 1 {
+		t.Fatalf("Expected 1 duplicated step sequence, got %d: %v", len(clusters), clusters)
+	}
+
+	cluster := clusters[0]
+	if len(cluster.Members) != 3 {
+		t.Fatalf("Expected the setup sequence to appear in 3 jobs, got %d", len(cluster.Members))
+	}
+	for _, m := range cluster.Members {
+		if m.File != "workflow.yml" || m.Start != 0 || m.End != 3 {
+			t.Errorf("Unexpected member %+v", m)
+		}
+	}
+
+	// Shorter sequences covered by the reported one must not be repeated
+	if clusters := FindDuplicateSteps(workflows, 2); len(clusters) != 1 {
+		t.Errorf("Expected covered sub-sequences to be omitted, got %d clusters", len(clusters))
+	}
+}
+
+func TestFindDuplicateJobs(t *testing.T) {
+	workflows, err := ParseDir("testdata")
+	if err != nil {
+		t.Fatalf("Failed to parse directory: %v", err)
+	}
+
+	clusters := FindDuplicateJobs(workflows, 0.6)
+	if len(clusters) != 1 {
+		t.Fatalf("Expected 1 cluster, got %d: %v", len(clusters), clusters)
+	}
+	if len(clusters[0].Members) != 3 {
+		t.Errorf("Expected lint, test and build to be clustered, got %v", clusters[0].Members)
+	}
+	if clusters[0].Similarity != 0.6 {
+		t.Errorf("Expected lowest similarity 0.6, got %v", clusters[0].Similarity)
+	}
+
+	if clusters := FindDuplicateJobs(workflows, 0.9); len(clusters) != 0 {
+		t.Errorf("Expected no clusters at 0.9 similarity, got %v", clusters)
+	}
+}
+
+func TestStepSignatureIgnoresVersionsAndNames(t *testing.T) {
+	a := Step{Name: "Checkout", Uses: "actions/checkout@v3", With: map[string]interface{}{"fetch-depth": 0}}
+	b := Step{Uses: "actions/checkout@v4", With: map[string]interface{}{"fetch-depth": 0}}
+	c := Step{Uses: "actions/checkout@v4"}
+
+	if stepSignature(a) != stepSignature(b) {
+		t.Errorf("Expected steps differing only in name and version to match")
+	}
+	if stepSignature(b) == stepSignature(c) {
+		t.Errorf("Expected steps with different inputs not to match")
+	}
+	if stepSignature(Step{Run: "npm  ci\n"}) != stepSignature(Step{Run: "npm ci"}) {
+		t.Errorf("Expected whitespace differences in scripts to be ignored")
+	}
+}
@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"sync"
+)
+
+// utf8BOM is the byte order mark some Windows editors put at the start of
+// UTF-8 files
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// normalizeEncoding strips a UTF-8 byte order mark and converts CRLF line
+// endings to LF, returning an informational finding for each correction
+func normalizeEncoding(data []byte) ([]byte, []ValidationError) {
+	bom := bytes.HasPrefix(data, utf8BOM)
+	if bom {
+		data = data[len(utf8BOM):]
+	}
+	crlf := bytes.Contains(data, []byte("\r\n"))
+	if crlf {
+		data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	}
+	return data, encodingDiagnostics(bom, crlf)
+}
+
+// encodingDiagnostics returns the findings for a stripped byte order mark
+// and converted CRLF line endings
+func encodingDiagnostics(bom, crlf bool) []ValidationError {
+	var diagnostics []ValidationError
+	if bom {
+		diagnostics = append(diagnostics, newFinding("byte-order-mark", SeverityInfo, "",
+			"The file starts with a UTF-8 byte order mark, which was ignored"))
+	}
+	if crlf {
+		diagnostics = append(diagnostics, newFinding("crlf-line-endings", SeverityInfo, "",
+			"The file uses CRLF line endings, which were converted to LF"))
+	}
+	return diagnostics
+}
+
+// encodingReader strips a UTF-8 byte order mark and converts CRLF line
+// endings to LF while reading, remembering whether it did either
+type encodingReader struct {
+	r       *bufio.Reader
+	started bool
+	bom     bool
+	crlf    bool
+}
+
+// readerPool holds finished encodingReaders with their buffers, so that
+// parsing many files does not allocate a reader and buffer for each
+var readerPool = sync.Pool{
+	New: func() interface{} { return &encodingReader{r: bufio.NewReader(nil)} },
+}
+
+func newEncodingReader(r io.Reader) *encodingReader {
+	e := readerPool.Get().(*encodingReader)
+	e.r.Reset(r)
+	return e
+}
+
+// release returns the reader to the pool. The encodingReader must not be
+// used afterwards.
+func (e *encodingReader) release() {
+	e.r.Reset(nil)
+	*e = encodingReader{r: e.r}
+	readerPool.Put(e)
+}
+
+// Read implements io.Reader
+func (e *encodingReader) Read(p []byte) (int, error) {
+	if !e.started {
+		e.started = true
+		if prefix, err := e.r.Peek(len(utf8BOM)); err == nil && bytes.Equal(prefix, utf8BOM) {
+			e.r.Discard(len(utf8BOM))
+			e.bom = true
+		}
+	}
+
+	n := 0
+	for n < len(p) {
+		c, err := e.r.ReadByte()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+		if c == '\r' {
+			if next, err := e.r.Peek(1); err == nil && next[0] == '\n' {
+				e.crlf = true
+				continue
+			}
+		}
+		p[n] = c
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,46 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseBOMAndCRLF(t *testing.T) {
+	doc := "\xEF\xBB\xBFname: CI\r\non: push\r\njobs:\r\n  build:\r\n    runs-on: ubuntu-latest\r\n    steps:\r\n      - run: |\r\n          make\r\n          make test\r\n"
+	action, err := Parse(strings.NewReader(doc), WithStrict())
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if action.Name != "CI" {
+		t.Errorf("Expected name 'CI', got %q", action.Name)
+	}
+	if run := action.Jobs["build"].Steps[0].Run; run != "make\nmake test\n" {
+		t.Errorf("Expected LF line endings in run, got %q", run)
+	}
+
+	var rules []string
+	for _, d := range action.Diagnostics {
+		if d.Severity != SeverityInfo {
+			t.Errorf("Expected info severity, got %v", d)
+		}
+		rules = append(rules, d.Rule)
+	}
+	if strings.Join(rules, ",") != "byte-order-mark,crlf-line-endings" {
+		t.Errorf("Unexpected diagnostics %v", action.Diagnostics)
+	}
+
+	issues := NewLinter().Lint(action)
+	if len(issues) != 2 || issues[0].Rule != "byte-order-mark" {
+		t.Errorf("Expected the linter to report the diagnostics, got %v", issues)
+	}
+}
+
+func TestParseWithoutEncodingIssues(t *testing.T) {
+	action, err := Parse(strings.NewReader("on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(action.Diagnostics) != 0 {
+		t.Errorf("Expected no diagnostics, got %v", action.Diagnostics)
+	}
+}
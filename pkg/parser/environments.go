@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+)
+
+// JobEnvironment is the deployment environment of a job
+type JobEnvironment struct {
+	Name string `json:"name"`
+	// URL is the deployment URL, usually an expression such as
+	// ${{ steps.deploy.outputs.url }}
+	URL string `json:"url,omitempty"`
+}
+
+// ParseJobEnvironment interprets the 'environment' value of a job, which is
+// either a name or a mapping with 'name' and 'url'. It returns nil if the job
+// does not deploy to an environment.
+func ParseJobEnvironment(job Job) (*JobEnvironment, error) {
+	switch value := job.Environment.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return &JobEnvironment{Name: value}, nil
+	default:
+		m, err := MapOfStringString(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid environment: %w", err)
+		}
+		if m["name"] == "" {
+			return nil, fmt.Errorf("environment must specify a name")
+		}
+		return &JobEnvironment{Name: m["name"], URL: m["url"]}, nil
+	}
+}
+
+// Deployment is a job deploying to an environment
+type Deployment struct {
+	File  string `json:"file"`
+	JobID string `json:"job"`
+	URL   string `json:"url,omitempty"`
+}
+
+// DeploymentMap returns the jobs deploying to each environment across a
+// directory of workflows, as returned by ParseDir. Deployments are sorted by
+// file and job. Jobs with an invalid environment are skipped; the Validator
+// reports them.
+func DeploymentMap(workflows map[string]*ActionFile) map[string][]Deployment {
+	result := make(map[string][]Deployment)
+	for file, workflow := range workflows {
+		for jobID, job := range workflow.Jobs {
+			env, err := ParseJobEnvironment(job)
+			if err != nil || env == nil {
+				continue
+			}
+			result[env.Name] = append(result[env.Name], Deployment{File: file, JobID: jobID, URL: env.URL})
+		}
+	}
+	for _, deployments := range result {
+		sort.Slice(deployments, func(i, j int) bool {
+			if deployments[i].File != deployments[j].File {
+				return deployments[i].File < deployments[j].File
+			}
+			return deployments[i].JobID < deployments[j].JobID
+		})
+	}
+	return result
+}
@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestParseJobEnvironment(t *testing.T) {
+	env, err := ParseJobEnvironment(Job{Environment: "staging"})
+	if err != nil || env == nil || env.Name != "staging" || env.URL != "" {
+		t.Errorf("Expected staging, got %+v, %v", env, err)
+	}
+
+	env, err = ParseJobEnvironment(Job{Environment: map[string]interface{}{
+		"name": "production",
+		"url":  "${{ steps.deploy.outputs.url }}",
+	}})
+	if err != nil || env.Name != "production" || env.URL != "${{ steps.deploy.outputs.url }}" {
+		t.Errorf("Expected production with URL, got %+v, %v", env, err)
+	}
+
+	if env, err := ParseJobEnvironment(Job{}); env != nil || err != nil {
+		t.Errorf("Expected no environment, got %+v, %v", env, err)
+	}
+	if _, err := ParseJobEnvironment(Job{Environment: map[string]interface{}{"url": "https://example.com"}}); err == nil {
+		t.Error("Expected an error for an environment without a name")
+	}
+}
+
+func TestDeploymentMap(t *testing.T) {
+	workflows := map[string]*ActionFile{
+		"release.yml": mustParse(t, `
+on: push
+jobs:
+  staging:
+    runs-on: ubuntu-latest
+    environment: staging
+    steps:
+      - run: ./deploy.sh staging
+  production:
+    needs: staging
+    runs-on: ubuntu-latest
+    environment:
+      name: production
+      url: ${{ steps.deploy.outputs.url }}
+    steps:
+      - id: deploy
+        run: ./deploy.sh production
+`),
+		"hotfix.yml": mustParse(t, `
+on: workflow_dispatch
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    environment: production
+    steps:
+      - run: ./deploy.sh production
+`),
+		"ci.yml": mustParse(t, "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make\n"),
+	}
+
+	deployments := DeploymentMap(workflows)
+	if len(deployments) != 2 {
+		t.Fatalf("Expected 2 environments, got %v", deployments)
+	}
+
+	production := deployments["production"]
+	if len(production) != 2 {
+		t.Fatalf("Expected 2 production deployments, got %v", production)
+	}
+	if production[0].File != "hotfix.yml" || production[1].File != "release.yml" {
+		t.Errorf("Expected deployments sorted by file, got %v", production)
+	}
+	if production[1].URL != "${{ steps.deploy.outputs.url }}" {
+		t.Errorf("Expected URL expression, got %q", production[1].URL)
+	}
+	if staging := deployments["staging"]; len(staging) != 1 || staging[0].JobID != "staging" {
+		t.Errorf("Unexpected staging deployments %v", staging)
+	}
+}
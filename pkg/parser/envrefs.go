@@ -0,0 +1,209 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// EnvSource tells where a referenced environment variable is defined
+type EnvSource string
+
+const (
+	// EnvSourceWorkflow is the top-level env of a workflow
+	EnvSourceWorkflow EnvSource = "workflow"
+	// EnvSourceJob is the env of the job
+	EnvSourceJob EnvSource = "job"
+	// EnvSourceStep is the env of the step itself
+	EnvSourceStep EnvSource = "step"
+	// EnvSourceGitHubEnv is a variable exported to $GITHUB_ENV by an earlier
+	// step
+	EnvSourceGitHubEnv EnvSource = "github-env"
+	// EnvSourceDefault is a variable set by the runner, such as GITHUB_SHA
+	EnvSourceDefault EnvSource = "default"
+	// EnvSourceUndefined is a variable not defined anywhere
+	EnvSourceUndefined EnvSource = "undefined"
+)
+
+// EnvReference is a reference to an environment variable, either as env.FOO
+// in an expression or as $FOO in a run script
+type EnvReference struct {
+	// Field is the path of the value containing the reference, e.g.
+	// "jobs.build.steps[1].run"
+	Field string `json:"field"`
+	Name  string `json:"name"`
+	// Expression is set for env.FOO references, which evaluate to an empty
+	// string when the variable is undefined; shell references may also be
+	// satisfied by the runner's own environment
+	Expression bool      `json:"expression"`
+	Source     EnvSource `json:"source"`
+	// DefinedAt is the path of the definition, e.g. "env.FOO" or
+	// "jobs.build.steps[0]" for $GITHUB_ENV exports
+	DefinedAt string `json:"definedAt,omitempty"`
+}
+
+// defaultEnvVars are variables present on GitHub-hosted runners besides the
+// GITHUB_*, RUNNER_* and ACTIONS_* families
+var defaultEnvVars = map[string]bool{
+	"CI": true, "HOME": true, "PATH": true, "PWD": true, "USER": true, "SHELL": true,
+	"TMPDIR": true, "TEMP": true, "TMP": true, "LANG": true, "HOSTNAME": true,
+	"ImageOS": true, "ImageVersion": true, "RANDOM": true, "OLDPWD": true,
+}
+
+var (
+	// shellVarPattern matches $FOO and ${FOO...}, but not ${{ expressions
+	shellVarPattern = regexp.MustCompile(`\$(?:\{([A-Za-z_][A-Za-z0-9_]*)|([A-Za-z_][A-Za-z0-9_]*))`)
+	// psVarPattern matches PowerShell's $env:FOO
+	psVarPattern = regexp.MustCompile(`(?i)\$env:([A-Za-z_][A-Za-z0-9_]*)`)
+	// shellAssignPattern matches variables assigned within a script
+	shellAssignPattern = regexp.MustCompile(`(?m)(?:^\s*|[;&|(]\s*|\b(?:export|local|readonly|declare(?:\s+-\w+)*)\s+)([A-Za-z_][A-Za-z0-9_]*)=|\bfor\s+([A-Za-z_][A-Za-z0-9_]*)\s+in\b|\bread\s+(?:-\w+\s+)*([A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+// EnvReferences lists every environment variable referenced by the steps of
+// a workflow or composite action, with where each is defined. Variables
+// assigned within the same script are not reported.
+func EnvReferences(action *ActionFile) []EnvReference {
+	var refs []EnvReference
+
+	scopes := []envScope{{source: EnvSourceWorkflow, prefix: "env", vars: action.Env}}
+	if len(action.Runs.Steps) > 0 {
+		refs = append(refs, stepsEnvReferences("runs.steps", action.Runs.Steps, nil)...)
+	}
+	for _, jobID := range sortedJobIDs(action) {
+		job := action.Jobs[jobID]
+		jobScopes := append([]envScope{{source: EnvSourceJob, prefix: fmt.Sprintf("jobs.%s.env", jobID), vars: job.Env}}, scopes...)
+		refs = append(refs, stepsEnvReferences(fmt.Sprintf("jobs.%s.steps", jobID), job.Steps, jobScopes)...)
+	}
+	return refs
+}
+
+// envScope is a set of variables visible to a step, innermost first
+type envScope struct {
+	source EnvSource
+	prefix string
+	vars   map[string]string
+}
+
+// stepsEnvReferences resolves the references of a sequence of steps
+func stepsEnvReferences(prefix string, steps []Step, scopes []envScope) []EnvReference {
+	var refs []EnvReference
+	exported := make(map[string]string)
+
+	for i, step := range steps {
+		field := fmt.Sprintf("%s[%d]", prefix, i)
+		stepScopes := append([]envScope{{source: EnvSourceStep, prefix: field + ".env", vars: step.Env}}, scopes...)
+
+		resolve := func(valueField, name string, expression bool) {
+			ref := EnvReference{Field: valueField, Name: name, Expression: expression, Source: EnvSourceUndefined}
+			for _, scope := range stepScopes {
+				if _, ok := scope.vars[name]; ok {
+					ref.Source, ref.DefinedAt = scope.source, scope.prefix+"."+name
+					break
+				}
+			}
+			if ref.Source == EnvSourceUndefined {
+				if at, ok := exported[name]; ok {
+					ref.Source, ref.DefinedAt = EnvSourceGitHubEnv, at
+				} else if isDefaultEnvVar(name) {
+					ref.Source = EnvSourceDefault
+				}
+			}
+			refs = append(refs, ref)
+		}
+
+		scanExpressions := func(valueField, s string) {
+			for _, r := range ExtractContextReferences(s) {
+				if r.Context == "env" {
+					resolve(valueField, r.Path[0], true)
+				}
+			}
+		}
+
+		scanExpressions(field+".if", conditionExpression(step.If))
+		scanExpressions(field+".name", step.Name)
+		for _, k := range sortedKeys(step.Env) {
+			scanExpressions(field+".env."+k, step.Env[k])
+		}
+		for _, k := range sortedWithKeys(step.With) {
+			if s, ok := step.With[k].(string); ok {
+				scanExpressions(field+".with."+k, s)
+			}
+		}
+		scanExpressions(field+".working-directory", step.WorkingDir)
+		scanExpressions(field+".run", step.Run)
+		for _, name := range shellEnvReferences(step.Run) {
+			resolve(field+".run", name, false)
+		}
+
+		for _, name := range StepEnvExports(step) {
+			if _, ok := exported[name]; !ok {
+				exported[name] = field
+			}
+		}
+	}
+	return refs
+}
+
+// shellEnvReferences returns the variables a script reads that it does not
+// assign itself, in order of first use
+func shellEnvReferences(script string) []string {
+	if script == "" {
+		return nil
+	}
+	script = expressionPattern.ReplaceAllString(script, "")
+
+	local := make(map[string]bool)
+	for _, m := range shellAssignPattern.FindAllStringSubmatch(script, -1) {
+		for _, name := range m[1:] {
+			if name != "" {
+				local[name] = true
+			}
+		}
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !local[name] && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, m := range psVarPattern.FindAllStringSubmatch(script, -1) {
+		add(m[1])
+	}
+	script = psVarPattern.ReplaceAllString(script, "")
+	for _, m := range shellVarPattern.FindAllStringSubmatch(script, -1) {
+		if m[1] != "" {
+			add(m[1])
+		} else {
+			add(m[2])
+		}
+	}
+	return names
+}
+
+func isDefaultEnvVar(name string) bool {
+	return defaultEnvVars[name] ||
+		strings.HasPrefix(name, "GITHUB_") ||
+		strings.HasPrefix(name, "RUNNER_") ||
+		strings.HasPrefix(name, "ACTIONS_")
+}
+
+// lintEnvReferences reports references to variables defined nowhere. Steps of
+// composite actions are skipped, since they see the environment of the
+// calling workflow.
+func (l *Linter) lintEnvReferences(action *ActionFile) {
+	for _, ref := range EnvReferences(action) {
+		if ref.Source != EnvSourceUndefined || strings.HasPrefix(ref.Field, "runs.") {
+			continue
+		}
+		if ref.Expression {
+			l.addIssue("undefined-env", SeverityWarning, ref.Field,
+				fmt.Sprintf("env.%s is not defined and evaluates to an empty string", ref.Name))
+		} else {
+			l.addIssue("undefined-env", SeverityInfo, ref.Field,
+				fmt.Sprintf("$%s is not defined by the workflow", ref.Name))
+		}
+	}
+}
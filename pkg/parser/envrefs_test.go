@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestEnvReferences(t *testing.T) {
+	workflow := mustParse(t, `
+on: push
+env:
+  REGISTRY: ghcr.io
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    env:
+      IMAGE: app
+    steps:
+      - run: echo "VERSION=1.0" >> "$GITHUB_ENV"
+      - env:
+          TAG: latest
+        run: |
+          NAME="$REGISTRY/$IMAGE"
+          docker build -t "$NAME:${TAG}" --build-arg sha=$GITHUB_SHA .
+          echo ${{ env.VERSION }} $MISSING
+      - if: env.DEPLOY == 'true'
+        run: echo deploy
+`)
+
+	refs := EnvReferences(workflow)
+	got := make(map[string]EnvReference)
+	for _, ref := range refs {
+		got[ref.Name] = ref
+	}
+
+	tests := []struct {
+		name      string
+		source    EnvSource
+		definedAt string
+	}{
+		{"REGISTRY", EnvSourceWorkflow, "env.REGISTRY"},
+		{"IMAGE", EnvSourceJob, "jobs.build.env.IMAGE"},
+		{"TAG", EnvSourceStep, "jobs.build.steps[1].env.TAG"},
+		{"VERSION", EnvSourceGitHubEnv, "jobs.build.steps[0]"},
+		{"GITHUB_SHA", EnvSourceDefault, ""},
+		{"MISSING", EnvSourceUndefined, ""},
+		{"DEPLOY", EnvSourceUndefined, ""},
+	}
+	for _, tt := range tests {
+		ref, ok := got[tt.name]
+		if !ok {
+			t.Errorf("Expected a reference to %s, got %v", tt.name, refs)
+			continue
+		}
+		if ref.Source != tt.source || ref.DefinedAt != tt.definedAt {
+			t.Errorf("%s: expected %s at %q, got %s at %q", tt.name, tt.source, tt.definedAt, ref.Source, ref.DefinedAt)
+		}
+	}
+	if _, ok := got["NAME"]; ok {
+		t.Errorf("Expected variables assigned in the script to be skipped")
+	}
+	if got["VERSION"].Field != "jobs.build.steps[1].run" || !got["VERSION"].Expression {
+		t.Errorf("Unexpected VERSION reference %+v", got["VERSION"])
+	}
+	if got["DEPLOY"].Field != "jobs.build.steps[2].if" {
+		t.Errorf("Unexpected DEPLOY reference %+v", got["DEPLOY"])
+	}
+}
+
+func TestLintUndefinedEnv(t *testing.T) {
+	workflow := mustParse(t, `
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ env.TARGET }} $env:HOME $CACHE_DIR
+`)
+
+	var severities []Severity
+	for _, issue := range NewLinter().Lint(workflow) {
+		if issue.Rule == "undefined-env" {
+			severities = append(severities, issue.Severity)
+		}
+	}
+	if len(severities) != 2 || severities[0] != SeverityWarning || severities[1] != SeverityInfo {
+		t.Errorf("Expected a warning for env.TARGET and info for $CACHE_DIR, got %v", severities)
+	}
+}
@@ -0,0 +1,90 @@
+package parser
+
+import "fmt"
+
+// EnvShadow is an environment variable defined again in a narrower scope,
+// hiding the outer definition from the steps that see both
+type EnvShadow struct {
+	Name string `json:"name"`
+	// Field is the inner definition, e.g. "jobs.build.env.FOO", or the step
+	// exporting the variable to $GITHUB_ENV, e.g. "jobs.build.steps[1]"
+	Field  string    `json:"field"`
+	Source EnvSource `json:"source"`
+	// Shadowed is the outer definition, in the same form as Field
+	Shadowed       string    `json:"shadowed"`
+	ShadowedSource EnvSource `json:"shadowedSource"`
+}
+
+// EnvShadowing lists the variables of a workflow or composite action that a
+// job or step env, or a step exporting to $GITHUB_ENV, defines again after
+// the workflow, job or an earlier step already did. Each definition is
+// reported against the innermost one it hides.
+func EnvShadowing(action *ActionFile) []EnvShadow {
+	var shadows []EnvShadow
+	workflow := envScope{source: EnvSourceWorkflow, prefix: "env", vars: action.Env}
+	if len(action.Runs.Steps) > 0 {
+		shadows = append(shadows, stepsEnvShadowing("runs.steps", action.Runs.Steps, nil)...)
+	}
+	for _, jobID := range sortedJobIDs(action) {
+		job := action.Jobs[jobID]
+		scope := envScope{source: EnvSourceJob, prefix: fmt.Sprintf("jobs.%s.env", jobID), vars: job.Env}
+		for _, name := range sortedKeys(job.Env) {
+			if _, ok := action.Env[name]; ok {
+				shadows = append(shadows, EnvShadow{Name: name, Field: scope.prefix + "." + name, Source: EnvSourceJob,
+					Shadowed: workflow.prefix + "." + name, ShadowedSource: EnvSourceWorkflow})
+			}
+		}
+		shadows = append(shadows, stepsEnvShadowing(fmt.Sprintf("jobs.%s.steps", jobID), job.Steps, []envScope{scope, workflow})...)
+	}
+	return shadows
+}
+
+// stepsEnvShadowing reports the step envs and $GITHUB_ENV exports of a
+// sequence of steps that define variables of the enclosing scopes, innermost
+// first, or variables exported by earlier steps
+func stepsEnvShadowing(prefix string, steps []Step, scopes []envScope) []EnvShadow {
+	var shadows []EnvShadow
+	exported := make(map[string]string)
+	outer := func(name string) (string, EnvSource, bool) {
+		for _, scope := range scopes {
+			if _, ok := scope.vars[name]; ok {
+				return scope.prefix + "." + name, scope.source, true
+			}
+		}
+		if at, ok := exported[name]; ok {
+			return at, EnvSourceGitHubEnv, true
+		}
+		return "", "", false
+	}
+
+	for i, step := range steps {
+		field := fmt.Sprintf("%s[%d]", prefix, i)
+		for _, name := range sortedKeys(step.Env) {
+			if at, source, ok := outer(name); ok {
+				shadows = append(shadows, EnvShadow{Name: name, Field: field + ".env." + name, Source: EnvSourceStep, Shadowed: at, ShadowedSource: source})
+			}
+		}
+		for _, name := range StepEnvExports(step) {
+			if at, source, ok := outer(name); ok && source != EnvSourceGitHubEnv {
+				shadows = append(shadows, EnvShadow{Name: name, Field: field, Source: EnvSourceGitHubEnv, Shadowed: at, ShadowedSource: source})
+			}
+			if _, ok := exported[name]; !ok {
+				exported[name] = field
+			}
+		}
+	}
+	return shadows
+}
+
+// lintEnvShadowing reports variables defined again in a narrower scope
+func (l *Linter) lintEnvShadowing(action *ActionFile) {
+	for _, s := range EnvShadowing(action) {
+		if s.Source == EnvSourceGitHubEnv {
+			l.addIssue("env-shadowing", SeverityInfo, s.Field+".run",
+				fmt.Sprintf("%s is exported to $GITHUB_ENV but also defined at %s, so later steps may see either value", s.Name, s.Shadowed))
+			continue
+		}
+		l.addIssue("env-shadowing", SeverityInfo, s.Field,
+			fmt.Sprintf("%s overrides the %s definition at %s", s.Name, s.ShadowedSource, s.Shadowed))
+	}
+}
@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestEnvShadowing(t *testing.T) {
+	workflow := mustParse(t, `on: push
+env:
+  TARGET: staging
+  NODE_VERSION: "20"
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    env:
+      TARGET: production
+    steps:
+      - run: echo "VERSION=$(cat VERSION)" >> "$GITHUB_ENV"
+      - run: echo "NODE_VERSION=18" >> $GITHUB_ENV
+      - run: ./deploy.sh
+        env:
+          TARGET: canary
+          VERSION: override
+          EXTRA: value
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make test
+`)
+	want := []EnvShadow{
+		{Name: "TARGET", Field: "jobs.deploy.env.TARGET", Source: EnvSourceJob, Shadowed: "env.TARGET", ShadowedSource: EnvSourceWorkflow},
+		{Name: "NODE_VERSION", Field: "jobs.deploy.steps[1]", Source: EnvSourceGitHubEnv, Shadowed: "env.NODE_VERSION", ShadowedSource: EnvSourceWorkflow},
+		{Name: "TARGET", Field: "jobs.deploy.steps[2].env.TARGET", Source: EnvSourceStep, Shadowed: "jobs.deploy.env.TARGET", ShadowedSource: EnvSourceJob},
+		{Name: "VERSION", Field: "jobs.deploy.steps[2].env.VERSION", Source: EnvSourceStep, Shadowed: "jobs.deploy.steps[0]", ShadowedSource: EnvSourceGitHubEnv},
+	}
+	if got := EnvShadowing(workflow); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected shadowing:\n got: %+v\nwant: %+v", got, want)
+	}
+
+	data, err := json.Marshal(want[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != `{"name":"TARGET","field":"jobs.deploy.env.TARGET","source":"job","shadowed":"env.TARGET","shadowedSource":"workflow"}` {
+		t.Errorf("Unexpected JSON %s", got)
+	}
+}
+
+func TestLintEnvShadowing(t *testing.T) {
+	workflow := mustParse(t, `on: push
+env:
+  TARGET: staging
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo "TARGET=production" >> "$GITHUB_ENV"
+      - run: ./deploy.sh "$TARGET"
+`)
+	var issues []ValidationError
+	for _, issue := range NewLinter().Lint(workflow) {
+		if issue.Rule == "env-shadowing" {
+			issues = append(issues, issue)
+		}
+	}
+	if len(issues) != 1 || issues[0].Field != "jobs.deploy.steps[0].run" || issues[0].Severity != SeverityInfo {
+		t.Fatalf("Expected one env-shadowing issue on the exporting step, got %v", issues)
+	}
+}
@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrNotFound is returned when a file or directory does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidYAML is returned when a document is not valid YAML or does not
+	// match the structure of an ActionFile
+	ErrInvalidYAML = errors.New("invalid YAML")
+	// ErrNotAWorkflow is returned by operations that require a workflow when
+	// given an action or an empty document
+	ErrNotAWorkflow = errors.New("not a workflow")
+	// ErrNotAnAction is returned by operations that require an action when
+	// given a workflow or an empty document
+	ErrNotAnAction = errors.New("not an action")
+	// ErrUnsupportedField is returned in strict mode when a document contains
+	// a field that is not part of the ActionFile model
+	ErrUnsupportedField = errors.New("unsupported field")
+	// ErrTooLarge is returned when a document exceeds the size set with
+	// WithMaxFileSize
+	ErrTooLarge = errors.New("file too large")
+)
+
+// FileError describes the failure to parse a single file
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// DirError collects the files that failed to parse during a best-effort
+// ParseDir
+type DirError struct {
+	Errors []*FileError
+}
+
+func (e *DirError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fileErr := range e.Errors {
+		msgs[i] = fileErr.Error()
+	}
+	return fmt.Sprintf("failed to parse %d file(s): %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+func (e *DirError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, fileErr := range e.Errors {
+		errs[i] = fileErr
+	}
+	return errs
+}
+
+// classifiedError attaches a sentinel error to an error without changing its
+// message, so both can be matched with errors.Is and errors.As
+type classifiedError struct {
+	kind error
+	err  error
+}
+
+func (e *classifiedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classifiedError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// classify wraps err so that errors.Is(err, kind) reports true
+func classify(kind, err error) error {
+	return &classifiedError{kind: kind, err: err}
+}
+
+// decodeErrorKind returns the sentinel error describing a YAML decoding error
+func decodeErrorKind(err error) error {
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		for _, msg := range typeErr.Errors {
+			if !strings.Contains(msg, "not found in type") {
+				return ErrInvalidYAML
+			}
+		}
+		return ErrUnsupportedField
+	}
+	return ErrInvalidYAML
+}
@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestErrorClassification(t *testing.T) {
+	_, err := ParseFile("testdata/does-not-exist.yml")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing file, got %v", err)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected the underlying fs error to be preserved, got %v", err)
+	}
+
+	_, err = ParseDir("testdata/does-not-exist")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing directory, got %v", err)
+	}
+
+	_, err = Parse(strings.NewReader("name: [unclosed"))
+	if !errors.Is(err, ErrInvalidYAML) {
+		t.Errorf("Expected ErrInvalidYAML for malformed YAML, got %v", err)
+	}
+
+	_, err = Parse(strings.NewReader("jobs: not-a-map"))
+	if !errors.Is(err, ErrInvalidYAML) {
+		t.Errorf("Expected ErrInvalidYAML for mistyped fields, got %v", err)
+	}
+
+	_, err = Parse(strings.NewReader("name: test\nextra: true\n"), WithStrict())
+	if !errors.Is(err, ErrUnsupportedField) {
+		t.Errorf("Expected ErrUnsupportedField in strict mode, got %v", err)
+	}
+	if errors.Is(err, ErrInvalidYAML) {
+		t.Errorf("Expected unknown fields not to be classified as ErrInvalidYAML")
+	}
+
+	_, err = BuildJobGraph(&ActionFile{Name: "action"})
+	if !errors.Is(err, ErrNotAWorkflow) {
+		t.Errorf("Expected ErrNotAWorkflow, got %v", err)
+	}
+}
+
+func TestClassifiedErrorMessage(t *testing.T) {
+	_, err := Parse(strings.NewReader("name: [unclosed"))
+	if !strings.HasPrefix(err.Error(), "failed to unmarshal YAML: ") {
+		t.Errorf("Expected error message to be unchanged, got %q", err.Error())
+	}
+}
+
+func TestParseDirContinueOnError(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"valid.yml":       "name: Valid\non: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n",
+		"broken.yml":      "name: [unclosed\n",
+		"also-valid.yaml": "name: Also Valid\n",
+		"mistyped.yml":    "jobs: not-a-map\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	if _, err := ParseDir(tempDir); err == nil {
+		t.Errorf("Expected ParseDir to fail without WithContinueOnError")
+	}
+
+	result, err := ParseDir(tempDir, WithContinueOnError())
+	if len(result) != 2 {
+		t.Errorf("Expected 2 parsed files, got %d", len(result))
+	}
+
+	var dirErr *DirError
+	if !errors.As(err, &dirErr) {
+		t.Fatalf("Expected a *DirError, got %v", err)
+	}
+	if len(dirErr.Errors) != 2 {
+		t.Fatalf("Expected 2 file errors, got %d", len(dirErr.Errors))
+	}
+	if dirErr.Errors[0].Path != "broken.yml" || dirErr.Errors[1].Path != "mistyped.yml" {
+		t.Errorf("Unexpected failed paths: %s, %s", dirErr.Errors[0].Path, dirErr.Errors[1].Path)
+	}
+	if !errors.Is(err, ErrInvalidYAML) {
+		t.Errorf("Expected the aggregated error to match ErrInvalidYAML")
+	}
+}
@@ -0,0 +1,203 @@
+package parser
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// graphView is the form of a graph rendered by the DOT and Mermaid exporters
+type graphView struct {
+	nodes []viewNode
+	edges []viewEdge
+}
+
+type viewNode struct {
+	id    string
+	label string
+}
+
+type viewEdge struct {
+	from  string
+	to    string
+	label string
+}
+
+// dot renders the view as a Graphviz digraph called name
+func (v graphView) dot(name string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n\trankdir=LR;\n", name)
+	for _, node := range v.nodes {
+		fmt.Fprintf(&b, "\t%s [label=%s];\n", dotQuote(node.id), dotQuote(node.label))
+	}
+	for _, edge := range v.edges {
+		fmt.Fprintf(&b, "\t%s -> %s", dotQuote(edge.from), dotQuote(edge.to))
+		if edge.label != "" {
+			fmt.Fprintf(&b, " [label=%s]", dotQuote(edge.label))
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotQuote quotes s as a DOT string, keeping newlines as line breaks
+func dotQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+	return `"` + s + `"`
+}
+
+// mermaid renders the view as a left-to-right Mermaid flowchart. Node IDs
+// are replaced by generated ones, since Mermaid IDs cannot hold paths.
+func (v graphView) mermaid() string {
+	ids := make(map[string]string, len(v.nodes))
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for i, node := range v.nodes {
+		ids[node.id] = fmt.Sprintf("n%d", i)
+		fmt.Fprintf(&b, "\t%s[%s]\n", ids[node.id], mermaidQuote(node.label))
+	}
+	for _, edge := range v.edges {
+		from, to := ids[edge.from], ids[edge.to]
+		if from == "" || to == "" {
+			// Edges to nodes outside the graph, such as unknown needs
+			continue
+		}
+		if edge.label != "" {
+			fmt.Fprintf(&b, "\t%s -->|%s| %s\n", from, mermaidQuote(edge.label), to)
+		} else {
+			fmt.Fprintf(&b, "\t%s --> %s\n", from, to)
+		}
+	}
+	return b.String()
+}
+
+// mermaidQuote quotes s as a Mermaid label, keeping newlines as line breaks
+func mermaidQuote(s string) string {
+	s = strings.NewReplacer(`"`, "#quot;", "\n", "<br/>").Replace(s)
+	return `"` + s + `"`
+}
+
+// SVG layout of graphView.svg, in pixels
+const (
+	svgCharWidth  = 7
+	svgLineHeight = 16
+	svgPadding    = 8
+	svgColumnGap  = 48
+	svgRowGap     = 16
+)
+
+// svg renders the view as a left-to-right SVG drawing, placing every node in
+// the column after the deepest node pointing to it, so that pages can show
+// the graph without a script. Edges within a cycle are drawn backwards.
+func (v graphView) svg() string {
+	index := make(map[string]int, len(v.nodes))
+	for i, node := range v.nodes {
+		index[node.id] = i
+	}
+	// Longest path layering, bounded so that cycles terminate
+	column := make([]int, len(v.nodes))
+	for pass := 0; pass < len(v.nodes); pass++ {
+		changed := false
+		for _, edge := range v.edges {
+			from, okFrom := index[edge.from]
+			to, okTo := index[edge.to]
+			if okFrom && okTo && column[to] < column[from]+1 && column[from]+1 < len(v.nodes) {
+				column[to], changed = column[from]+1, true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	chars, lines := 1, 1
+	for _, node := range v.nodes {
+		parts := strings.Split(node.label, "\n")
+		if len(parts) > lines {
+			lines = len(parts)
+		}
+		for _, part := range parts {
+			if len(part) > chars {
+				chars = len(part)
+			}
+		}
+	}
+	boxWidth := chars*svgCharWidth + 2*svgPadding
+	boxHeight := lines*svgLineHeight + 2*svgPadding
+
+	row := make([]int, len(v.nodes))
+	rows := make(map[int]int)
+	columns, maxRows := 0, 0
+	for i := range v.nodes {
+		row[i] = rows[column[i]]
+		rows[column[i]]++
+		if column[i]+1 > columns {
+			columns = column[i] + 1
+		}
+		if rows[column[i]] > maxRows {
+			maxRows = rows[column[i]]
+		}
+	}
+	x := func(i int) int { return column[i] * (boxWidth + svgColumnGap) }
+	y := func(i int) int { return row[i] * (boxHeight + svgRowGap) }
+	width := columns*(boxWidth+svgColumnGap) - svgColumnGap
+	height := maxRows*(boxHeight+svgRowGap) - svgRowGap
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="-1 -1 %d %d" font-family="monospace" font-size="12">`+"\n",
+		width+2, height+2, width+2, height+2)
+	for _, edge := range v.edges {
+		from, okFrom := index[edge.from]
+		to, okTo := index[edge.to]
+		if !okFrom || !okTo {
+			// Edges to nodes outside the graph, such as unknown needs
+			continue
+		}
+		x1, y1 := x(from)+boxWidth, y(from)+boxHeight/2
+		x2, y2 := x(to), y(to)+boxHeight/2
+		fmt.Fprintf(&b, `<path d="M%d %d C%d %d %d %d %d %d" fill="none" stroke="#59636e"/>`+"\n",
+			x1, y1, x1+svgColumnGap/2, y1, x2-svgColumnGap/2, y2, x2, y2)
+		fmt.Fprintf(&b, `<polygon points="%d,%d %d,%d %d,%d" fill="#59636e"/>`+"\n", x2, y2, x2-6, y2-4, x2-6, y2+4)
+		if edge.label != "" {
+			fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle" fill="#59636e">%s</text>`+"\n",
+				(x1+x2)/2, (y1+y2)/2-4, html.EscapeString(edge.label))
+		}
+	}
+	for i, node := range v.nodes {
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" rx="4" fill="#f6f8fa" stroke="#d1d9e0"/>`+"\n",
+			x(i), y(i), boxWidth, boxHeight)
+		for j, part := range strings.Split(node.label, "\n") {
+			fmt.Fprintf(&b, `<text x="%d" y="%d">%s</text>`+"\n",
+				x(i)+svgPadding, y(i)+svgPadding+(j+1)*svgLineHeight-4, html.EscapeString(part))
+		}
+	}
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// DOT renders the job graph in the Graphviz DOT language
+func (g *JobGraph) DOT() string {
+	return g.view().dot("jobs")
+}
+
+// Mermaid renders the job graph as a Mermaid flowchart
+func (g *JobGraph) Mermaid() string {
+	return g.view().mermaid()
+}
+
+// view converts the job graph for the exporters
+func (g *JobGraph) view() graphView {
+	var v graphView
+	for _, node := range g.Nodes {
+		label := node.ID
+		if node.Name != "" && node.Name != node.ID {
+			label += "\n" + node.Name
+		}
+		v.nodes = append(v.nodes, viewNode{id: node.ID, label: label})
+	}
+	for _, edge := range g.Edges {
+		v.edges = append(v.edges, viewEdge{from: edge.From, to: edge.To})
+	}
+	return v
+}
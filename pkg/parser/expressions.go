@@ -0,0 +1,153 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// expressionPattern matches a ${{ }} expression
+var expressionPattern = regexp.MustCompile(`\$\{\{(.*?)\}\}`)
+
+// contextReferencePattern matches a property access on a named context, such
+// as env.FOO or steps.build.outputs.result
+var contextReferencePattern = regexp.MustCompile(`\b([a-z]+)((?:\.[A-Za-z_][A-Za-z0-9_-]*)+)`)
+
+// ContextReference is a property access on an expression context
+type ContextReference struct {
+	// Context is the name of the context, e.g. "env" or "steps"
+	Context string
+	// Path is the property path below the context, e.g. ["build", "outputs", "result"]
+	Path []string
+}
+
+// String returns the reference in expression syntax
+func (r ContextReference) String() string {
+	return r.Context + "." + strings.Join(r.Path, ".")
+}
+
+// ExtractExpressions returns the trimmed contents of every ${{ }} expression
+// in s
+func ExtractExpressions(s string) []string {
+	matches := expressionPattern.FindAllStringSubmatch(s, -1)
+	result := make([]string, 0, len(matches))
+	for _, m := range matches {
+		result = append(result, strings.TrimSpace(m[1]))
+	}
+	return result
+}
+
+// ExtractContextReferences returns the context property accesses made by the
+// ${{ }} expressions in s. String literals inside expressions are ignored.
+func ExtractContextReferences(s string) []ContextReference {
+	var refs []ContextReference
+	for _, expr := range ExtractExpressions(s) {
+		for _, m := range contextReferencePattern.FindAllStringSubmatch(stripStringLiterals(expr), -1) {
+			refs = append(refs, ContextReference{
+				Context: m[1],
+				Path:    strings.Split(m[2][1:], "."),
+			})
+		}
+	}
+	return refs
+}
+
+// RewriteContextReferences replaces context property accesses inside the
+// ${{ }} expressions of s. fn receives each reference and returns its
+// replacement in expression syntax, or "" to leave it unchanged.
+func RewriteContextReferences(s string, fn func(ContextReference) string) string {
+	return expressionPattern.ReplaceAllStringFunc(s, func(expr string) string {
+		var b strings.Builder
+		last := 0
+		for _, loc := range contextReferencePattern.FindAllStringIndex(stripStringLiterals(expr), -1) {
+			ref := expr[loc[0]:loc[1]]
+			parts := strings.Split(ref, ".")
+			replacement := fn(ContextReference{Context: parts[0], Path: parts[1:]})
+			if replacement == "" {
+				continue
+			}
+			b.WriteString(expr[last:loc[0]])
+			b.WriteString(replacement)
+			last = loc[1]
+		}
+		b.WriteString(expr[last:])
+		return b.String()
+	})
+}
+
+// stripStringLiterals blanks out single-quoted string literals in an
+// expression so their contents are not mistaken for references. The result
+// has the same length as expr.
+func stripStringLiterals(expr string) string {
+	var b strings.Builder
+	inString := false
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		if c == '\'' {
+			// '' is an escaped quote inside a literal
+			if inString && i+1 < len(expr) && expr[i+1] == '\'' {
+				b.WriteString("  ")
+				i++
+				continue
+			}
+			inString = !inString
+			b.WriteByte(c)
+			continue
+		}
+		if inString {
+			b.WriteByte(' ')
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// conditionExpression returns an 'if' value in ${{ }} syntax. Conditions are
+// always evaluated as expressions, so the delimiters are optional there.
+func conditionExpression(s string) string {
+	if s == "" || strings.Contains(s, "${{") {
+		return s
+	}
+	return "${{ " + s + " }}"
+}
+
+// ExpressionField is a field whose value contains ${{ }} expressions
+type ExpressionField struct {
+	// Field is the field path, e.g. "jobs.build.steps[0].run"
+	Field string `json:"field"`
+	Value string `json:"value"`
+	// Expressions are the trimmed contents of the expressions in Value
+	Expressions []string `json:"expressions"`
+}
+
+// ExpressionFields lists the fields of a workflow or action whose value
+// contains ${{ }} expressions, in document order with jobs sorted by ID.
+// Job and step 'if' conditions are always expressions, so they are listed
+// even without the delimiters.
+func ExpressionFields(action *ActionFile) []ExpressionField {
+	fields := make([]ExpressionField, 0)
+	var doc yaml.Node
+	if err := doc.Encode(action); err != nil {
+		// Decoded documents always encode
+		return fields
+	}
+	walkScalars(&doc, "", func(field, value string) {
+		expressions := ExtractExpressions(value)
+		if len(expressions) == 0 && isConditionField(field) {
+			expressions = ExtractExpressions(conditionExpression(value))
+		}
+		if len(expressions) > 0 {
+			fields = append(fields, ExpressionField{Field: field, Value: value, Expressions: expressions})
+		}
+	})
+	return fields
+}
+
+// isConditionField reports whether a field path is the 'if' of a job or
+// step
+func isConditionField(field string) bool {
+	m := jobFieldPattern.FindStringSubmatch(field)
+	return m != nil && m[3] == "if"
+}
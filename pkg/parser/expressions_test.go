@@ -0,0 +1,36 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpressionFields(t *testing.T) {
+	workflow := mustParse(t, `
+name: Deploy ${{ github.ref_name }}
+on: push
+jobs:
+  deploy:
+    if: github.ref == 'refs/heads/main'
+    runs-on: ubuntu-latest
+    env:
+      TARGET: production
+    steps:
+      - uses: actions/checkout@v4
+      - run: ./deploy.sh ${{ env.TARGET }} ${{ secrets.TOKEN }}
+        if: ${{ success() }}
+`)
+	want := []ExpressionField{
+		{Field: "name", Value: "Deploy ${{ github.ref_name }}", Expressions: []string{"github.ref_name"}},
+		{Field: "jobs.deploy.if", Value: "github.ref == 'refs/heads/main'", Expressions: []string{"github.ref == 'refs/heads/main'"}},
+		{Field: "jobs.deploy.steps[1].if", Value: "${{ success() }}", Expressions: []string{"success()"}},
+		{Field: "jobs.deploy.steps[1].run", Value: "./deploy.sh ${{ env.TARGET }} ${{ secrets.TOKEN }}", Expressions: []string{"env.TARGET", "secrets.TOKEN"}},
+	}
+	if got := ExpressionFields(workflow); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExpressionFields() = %+v, want %+v", got, want)
+	}
+
+	if got := ExpressionFields(&ActionFile{Name: "static"}); got == nil || len(got) != 0 {
+		t.Errorf("Expected an empty list, got %v", got)
+	}
+}
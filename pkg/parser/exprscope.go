@@ -0,0 +1,152 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// expressionContexts are the contexts expressions can read
+var expressionContexts = map[string]bool{
+	"github": true, "env": true, "vars": true, "job": true, "jobs": true, "steps": true,
+	"runner": true, "secrets": true, "strategy": true, "matrix": true, "needs": true, "inputs": true,
+}
+
+// expressionFunctions maps the lower-cased names of the expression functions
+// to their documented spelling
+var expressionFunctions = map[string]string{
+	"contains": "contains", "startswith": "startsWith", "endswith": "endsWith", "format": "format",
+	"join": "join", "tojson": "toJSON", "fromjson": "fromJSON", "hashfiles": "hashFiles",
+	"success": "success", "always": "always", "cancelled": "cancelled", "failure": "failure",
+}
+
+// statusFunctions are only available in 'if' conditions
+var statusFunctions = map[string]bool{"success": true, "always": true, "cancelled": true, "failure": true}
+
+// expressionLiterals are the keywords of the expression syntax
+var expressionLiterals = map[string]bool{"true": true, "false": true, "null": true, "nan": true, "infinity": true}
+
+// expressionScope lists the contexts available in the fields matching a
+// pattern, after GitHub's context availability table
+type expressionScope struct {
+	pattern  *regexp.Regexp
+	contexts []string
+}
+
+// stepContexts are the contexts available in the fields of job steps
+var stepContexts = []string{"github", "needs", "strategy", "matrix", "job", "runner", "env", "vars", "secrets", "steps", "inputs"}
+
+// expressionScopes are checked in order; fields matching none of them are
+// only checked for unknown names
+var expressionScopes = []expressionScope{
+	{regexp.MustCompile(`^jobs\.[^.]+\.steps\[\d+\]\.`), stepContexts},
+	{regexp.MustCompile(`^jobs\.[^.]+\.if$`), []string{"github", "needs", "vars", "inputs"}},
+	{regexp.MustCompile(`^jobs\.[^.]+\.outputs\.`), stepContexts},
+	{regexp.MustCompile(`^jobs\.[^.]+\.env\.`), []string{"github", "needs", "strategy", "matrix", "vars", "secrets", "inputs"}},
+	{regexp.MustCompile(`^jobs\.[^.]+\.strategy\.`), []string{"github", "needs", "vars", "inputs"}},
+	{regexp.MustCompile(`^jobs\.[^.]+\.(?:container|services\.[^.]+)\.credentials\.`), []string{"github", "needs", "strategy", "matrix", "env", "vars", "secrets", "inputs"}},
+	{regexp.MustCompile(`^jobs\.[^.]+\.(?:container|services\.[^.]+)\.env\.`), []string{"github", "needs", "strategy", "matrix", "job", "runner", "env", "vars", "secrets", "inputs"}},
+	{regexp.MustCompile(`^jobs\.[^.]+\.secrets\.`), []string{"github", "needs", "strategy", "matrix", "secrets", "inputs", "vars"}},
+	{regexp.MustCompile(`^jobs\.[^.]+\.environment\.url$`), []string{"github", "needs", "strategy", "matrix", "job", "runner", "env", "vars", "steps", "inputs"}},
+	{regexp.MustCompile(`^jobs\.[^.]+\.defaults\.run\.`), []string{"github", "needs", "strategy", "matrix", "env", "vars", "inputs"}},
+	{regexp.MustCompile(`^jobs\.[^.]+\.(?:name|runs-on|environment|concurrency|container|services|with|continue-on-error)(?:[.\[]|$)`),
+		[]string{"github", "needs", "strategy", "matrix", "vars", "inputs"}},
+	{regexp.MustCompile(`^env\.`), []string{"github", "secrets", "inputs", "vars"}},
+	{regexp.MustCompile(`^concurrency(?:\.|$)`), []string{"github", "inputs", "vars"}},
+	{regexp.MustCompile(`^on\.workflow_call\.outputs\.`), []string{"github", "jobs", "vars", "inputs"}},
+	{regexp.MustCompile(`^on\.(?:workflow_call|workflow_dispatch)\.inputs\.`), []string{"github", "inputs", "vars"}},
+}
+
+// identifierPattern matches names and property accesses in an expression;
+// names directly preceded by a dot or digit are skipped by the caller
+var identifierPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_-]*(\s*\()?`)
+
+// expressionName is a function call or context read in an expression
+type expressionName struct {
+	name     string
+	function bool
+}
+
+// expressionNames returns the functions called and the contexts read by an
+// expression, skipping property names and literals
+func expressionNames(expr string) []expressionName {
+	stripped := stripStringLiterals(expr)
+	var names []expressionName
+	for _, loc := range identifierPattern.FindAllStringSubmatchIndex(stripped, -1) {
+		if loc[0] > 0 {
+			if prev := stripped[loc[0]-1]; prev == '.' || prev >= '0' && prev <= '9' {
+				continue
+			}
+		}
+		function := loc[2] >= 0
+		name := stripped[loc[0]:loc[1]]
+		if function {
+			name = strings.TrimSpace(stripped[loc[0]:loc[2]])
+		} else if i := strings.IndexByte(name, '-'); i >= 0 {
+			// Dashes only appear in property names, never in context names
+			name = name[:i]
+		}
+		if !function && expressionLiterals[strings.ToLower(name)] {
+			continue
+		}
+		names = append(names, expressionName{name: name, function: function})
+	}
+	return names
+}
+
+// validateExpressions reports unknown functions and contexts in the
+// expressions of a workflow or action, and in workflows also functions and
+// contexts used where GitHub does not provide them
+func (v *Validator) validateExpressions(action *ActionFile) {
+	workflow := action.Jobs != nil
+	for _, f := range ExpressionFields(action) {
+		var available map[string]bool
+		if workflow {
+			for _, scope := range expressionScopes {
+				if scope.pattern.MatchString(f.Field) {
+					available = make(map[string]bool, len(scope.contexts))
+					for _, c := range scope.contexts {
+						available[c] = true
+					}
+					break
+				}
+			}
+		}
+		inStep := strings.HasPrefix(f.Field, "runs.steps[") || strings.Contains(f.Field, ".steps[")
+		inCondition := isConditionField(f.Field)
+
+		reported := make(map[expressionName]bool)
+		for _, expr := range f.Expressions {
+			for _, n := range expressionNames(expr) {
+				lower := expressionName{name: strings.ToLower(n.name), function: n.function}
+				if reported[lower] {
+					continue
+				}
+				reported[lower] = true
+				if n.function {
+					v.checkExpressionFunction(f.Field, n.name, lower.name, workflow, inStep, inCondition)
+					continue
+				}
+				switch {
+				case !expressionContexts[lower.name]:
+					v.addError("expression-contexts", f.Field, fmt.Sprintf("Unknown context '%s'", n.name))
+				case available != nil && !available[lower.name]:
+					v.addError("expression-contexts", f.Field, fmt.Sprintf("Context '%s' is not available here, only %s", n.name, strings.Join(sortedSet(available), ", ")))
+				}
+			}
+		}
+	}
+}
+
+// checkExpressionFunction reports a call to an unknown function, or to a
+// function unavailable in the field
+func (v *Validator) checkExpressionFunction(field, name, lower string, workflow, inStep, inCondition bool) {
+	switch {
+	case expressionFunctions[lower] == "":
+		v.addError("expression-functions", field, fmt.Sprintf("Unknown function '%s'", name))
+	case statusFunctions[lower] && !inCondition:
+		v.addError("expression-functions", field, fmt.Sprintf("Function '%s' is only available in 'if' conditions", expressionFunctions[lower]))
+	case lower == "hashfiles" && workflow && !inStep:
+		v.addError("expression-functions", field, "Function 'hashFiles' is only available in steps")
+	}
+}
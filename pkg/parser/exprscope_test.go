@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestExpressionNames(t *testing.T) {
+	got := expressionNames("contains(github.event.head_commit.message, 'skip ci') || fromJson(steps.meta.outputs.json).tags[0] == 1e3 && !null")
+	want := []expressionName{
+		{name: "contains", function: true},
+		{name: "github"},
+		{name: "fromJson", function: true},
+		{name: "steps"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expressionNames() = %+v, want %+v", got, want)
+	}
+}
+
+func TestValidateExpressions(t *testing.T) {
+	workflow := mustParse(t, `
+on:
+  workflow_call:
+    outputs:
+      version:
+        value: ${{ jobs.build.outputs.version }}
+env:
+  TOKEN: ${{ secrets.TOKEN }}
+jobs:
+  build:
+    if: ${{ always() && secrets.DEPLOY != '' }}
+    runs-on: ${{ matrix.os }}
+    outputs:
+      version: ${{ steps.meta.outputs.version }}
+    environment:
+      name: production
+      url: ${{ steps.deploy.outputs.url }}
+    strategy:
+      matrix:
+        os: [ubuntu-latest]
+    env:
+      KEY: ${{ hashFiles('go.sum') }}
+    steps:
+      - run: echo ${{ toJson(github.event) }} ${{ tojason(github) }} ${{ inputz.name }}
+      - run: echo done
+        if: ${{ failure() }}
+      - run: echo ${{ success() }}
+      - uses: actions/cache@v4
+        with:
+          path: ~/go/pkg/mod
+          key: ${{ runner.os }}-${{ hashFiles('**/go.sum') }}
+`)
+	var found []string
+	for _, e := range NewValidator().Validate(workflow) {
+		if e.Rule == "expression-functions" || e.Rule == "expression-contexts" {
+			found = append(found, e.Field+": "+e.Message)
+		}
+	}
+	sort.Strings(found)
+	want := []string{
+		"jobs.build.env.KEY: Function 'hashFiles' is only available in steps",
+		"jobs.build.if: Context 'secrets' is not available here, only github, inputs, needs, vars",
+		"jobs.build.steps[0].run: Unknown context 'inputz'",
+		"jobs.build.steps[0].run: Unknown function 'tojason'",
+		"jobs.build.steps[2].run: Function 'success' is only available in 'if' conditions",
+	}
+	if fmt.Sprint(found) != fmt.Sprint(want) {
+		t.Errorf("Expected\n%v\ngot\n%v", want, found)
+	}
+}
+
+func TestValidateExpressionsAction(t *testing.T) {
+	action := mustParse(t, `
+name: Setup
+description: Sets up the tool
+runs:
+  using: composite
+  steps:
+    - run: echo ${{ hashFiles('go.sum') }} ${{ inputs.version }} ${{ lookup(inputs) }}
+      shell: bash
+`)
+	var found []string
+	for _, e := range NewValidator().Validate(action) {
+		found = append(found, e.Rule+"@"+e.Field+": "+e.Message)
+	}
+	want := "[expression-functions@runs.steps[0].run: Unknown function 'lookup']"
+	if fmt.Sprint(found) != want {
+		t.Errorf("Expected %s, got %v", want, found)
+	}
+}
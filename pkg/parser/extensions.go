@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExtensionLevel is a level of a document at which x- prefixed extension
+// keys, used by some organizations to annotate workflows with metadata, can
+// be tolerated or rejected
+type ExtensionLevel string
+
+const (
+	// ExtensionLevelRoot is the top level of workflows and actions
+	ExtensionLevelRoot ExtensionLevel = "root"
+	// ExtensionLevelJob is a job of a workflow
+	ExtensionLevelJob ExtensionLevel = "job"
+	// ExtensionLevelStep is a step of a workflow job or composite action
+	ExtensionLevelStep ExtensionLevel = "step"
+)
+
+// ExtensionPolicy is how extension keys are handled at a level
+type ExtensionPolicy string
+
+const (
+	// ExtensionDefault handles extension keys like any other unknown field:
+	// ignored, or rejected with WithStrict
+	ExtensionDefault ExtensionPolicy = ""
+	// ExtensionAllow tolerates extension keys even with WithStrict and keeps
+	// their values in ActionFile.Extensions
+	ExtensionAllow ExtensionPolicy = "allow"
+	// ExtensionReject fails parsing with ErrUnsupportedField even without
+	// WithStrict
+	ExtensionReject ExtensionPolicy = "reject"
+)
+
+// WithExtensions sets the extension policy of each level, leaving other
+// levels to ExtensionDefault. Later calls replace the policies.
+func WithExtensions(policies map[ExtensionLevel]ExtensionPolicy) Option {
+	return func(o *options) {
+		o.extensions = policies
+	}
+}
+
+// Extension returns the value of the extension key at a field path, such as
+// "jobs.build.x-owner". Values are only kept for levels where extensions are
+// allowed with WithExtensions.
+func (a *ActionFile) Extension(field string) (interface{}, bool) {
+	value, ok := a.Extensions[field]
+	return value, ok
+}
+
+// parseExtensionPolicy checks a policy name as used in Config.Extensions
+func parseExtensionPolicy(level ExtensionLevel, policy ExtensionPolicy) error {
+	switch level {
+	case ExtensionLevelRoot, ExtensionLevelJob, ExtensionLevelStep:
+	default:
+		return fmt.Errorf("invalid extension level %q, expected %s, %s or %s", level, ExtensionLevelRoot, ExtensionLevelJob, ExtensionLevelStep)
+	}
+	switch policy {
+	case ExtensionAllow, ExtensionReject:
+		return nil
+	}
+	return fmt.Errorf("invalid extension policy %q for %s, expected %s or %s", policy, level, ExtensionAllow, ExtensionReject)
+}
+
+// applyExtensionPolicies removes the extension keys of the levels where
+// they are allowed from the document, returning their values by field
+// path, and fails on the extension keys of the levels where they are
+// rejected. Documents that are not valid YAML are returned unchanged for
+// the decoder to report.
+func applyExtensionPolicies(data []byte, policies map[ExtensionLevel]ExtensionPolicy) ([]byte, map[string]interface{}, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return data, nil, nil
+	}
+	e := extensionWalker{policies: policies, values: make(map[string]interface{})}
+	root := doc.Content[0]
+	if err := e.apply(root, "", ExtensionLevelRoot); err != nil {
+		return nil, nil, err
+	}
+	if jobs := mappingValue(root, "jobs"); jobs != nil && jobs.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(jobs.Content); i += 2 {
+			path := "jobs." + jobs.Content[i].Value
+			job := jobs.Content[i+1]
+			if err := e.apply(job, path, ExtensionLevelJob); err != nil {
+				return nil, nil, err
+			}
+			if err := e.applySteps(mappingValue(job, "steps"), path+".steps"); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	if runs := mappingValue(root, "runs"); runs != nil {
+		if err := e.applySteps(mappingValue(runs, "steps"), "runs.steps"); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if !e.removed {
+		return data, e.values, nil
+	}
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	return out, e.values, nil
+}
+
+// extensionWalker applies extension policies to the mappings of a document
+type extensionWalker struct {
+	policies map[ExtensionLevel]ExtensionPolicy
+	values   map[string]interface{}
+	removed  bool
+}
+
+// applySteps applies the step policy to a sequence of steps
+func (e *extensionWalker) applySteps(steps *yaml.Node, path string) error {
+	if steps == nil || steps.Kind != yaml.SequenceNode {
+		return nil
+	}
+	for i, step := range steps.Content {
+		if err := e.apply(step, fmt.Sprintf("%s[%d]", path, i), ExtensionLevelStep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply applies the policy of level to the extension keys of a mapping
+func (e *extensionWalker) apply(node *yaml.Node, path string, level ExtensionLevel) error {
+	policy := e.policies[level]
+	if node.Kind != yaml.MappingNode || policy == ExtensionDefault {
+		return nil
+	}
+	content := node.Content[:0]
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if !strings.HasPrefix(key.Value, "x-") {
+			content = append(content, key, value)
+			continue
+		}
+		field := key.Value
+		if path != "" {
+			field = path + "." + key.Value
+		}
+		if policy == ExtensionReject {
+			return classify(ErrUnsupportedField, fmt.Errorf("extension field %s is not allowed at line %d", field, key.Line))
+		}
+		var v interface{}
+		if err := value.Decode(&v); err != nil {
+			return classify(ErrInvalidYAML, fmt.Errorf("failed to decode extension field %s: %w", field, err))
+		}
+		e.values[field] = v
+		e.removed = true
+	}
+	node.Content = content
+	return nil
+}
+
+// mappingValue returns the value of key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const extensionsWorkflow = `name: CI
+x-owner: platform-team
+on: push
+jobs:
+  build:
+    x-cost-center: 1234
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+        x-reviewed: true
+      - run: make
+`
+
+func TestWithExtensionsAllow(t *testing.T) {
+	action, err := Parse(strings.NewReader(extensionsWorkflow), WithStrict(), WithPositions(),
+		WithExtensions(map[ExtensionLevel]ExtensionPolicy{
+			ExtensionLevelRoot: ExtensionAllow,
+			ExtensionLevelJob:  ExtensionAllow,
+			ExtensionLevelStep: ExtensionAllow,
+		}))
+	if err != nil {
+		t.Fatalf("Expected extension keys to be tolerated in strict mode, got %v", err)
+	}
+	if v, ok := action.Extension("x-owner"); !ok || v != "platform-team" {
+		t.Errorf("Expected x-owner, got %v", v)
+	}
+	if v, ok := action.Extension("jobs.build.x-cost-center"); !ok || v != 1234 {
+		t.Errorf("Expected x-cost-center, got %v", v)
+	}
+	if v, ok := action.Extension("jobs.build.steps[0].x-reviewed"); !ok || v != true {
+		t.Errorf("Expected x-reviewed, got %v", v)
+	}
+	if action.Jobs["build"].Steps[1].Run != "make" {
+		t.Errorf("Unexpected steps %+v", action.Jobs["build"].Steps)
+	}
+	if pos, ok := action.Position("jobs.build.steps[1].run"); !ok || pos.Line != 11 {
+		t.Errorf("Expected positions of the source document, got %v", pos)
+	}
+}
+
+func TestWithExtensionsLevels(t *testing.T) {
+	_, err := Parse(strings.NewReader(extensionsWorkflow), WithStrict(),
+		WithExtensions(map[ExtensionLevel]ExtensionPolicy{ExtensionLevelRoot: ExtensionAllow}))
+	if !errors.Is(err, ErrUnsupportedField) {
+		t.Errorf("Expected job extensions to be rejected in strict mode, got %v", err)
+	}
+
+	_, err = Parse(strings.NewReader(extensionsWorkflow),
+		WithExtensions(map[ExtensionLevel]ExtensionPolicy{ExtensionLevelStep: ExtensionReject}))
+	if !errors.Is(err, ErrUnsupportedField) || !strings.Contains(err.Error(), "jobs.build.steps[0].x-reviewed") {
+		t.Errorf("Expected step extensions to be rejected, got %v", err)
+	}
+
+	action, err := Parse(strings.NewReader(extensionsWorkflow))
+	if err != nil || len(action.Extensions) != 0 {
+		t.Errorf("Expected extensions to be ignored by default, got %v (%v)", action.Extensions, err)
+	}
+}
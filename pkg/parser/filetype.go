@@ -0,0 +1,71 @@
+package parser
+
+import "strings"
+
+// FileType is the kind of document an ActionFile was parsed from
+type FileType string
+
+const (
+	// FileTypeUnknown is a document that is neither an action nor a
+	// workflow, or that mixes both
+	FileTypeUnknown FileType = "unknown"
+	// FileTypeCompositeAction is an action with 'runs.using: composite'
+	FileTypeCompositeAction FileType = "composite-action"
+	// FileTypeNodeAction is a JavaScript action, e.g. 'runs.using: node20'
+	FileTypeNodeAction FileType = "node-action"
+	// FileTypeDockerAction is a Docker container action
+	FileTypeDockerAction FileType = "docker-action"
+	// FileTypeWorkflow is a workflow that cannot be called by other workflows
+	FileTypeWorkflow FileType = "workflow"
+	// FileTypeReusableWorkflow is a workflow triggered by workflow_call
+	FileTypeReusableWorkflow FileType = "reusable-workflow"
+)
+
+// IsAction reports whether t is one of the action types
+func (t FileType) IsAction() bool {
+	return t == FileTypeCompositeAction || t == FileTypeNodeAction || t == FileTypeDockerAction
+}
+
+// IsWorkflow reports whether t is one of the workflow types
+func (t FileType) IsWorkflow() bool {
+	return t == FileTypeWorkflow || t == FileTypeReusableWorkflow
+}
+
+// DetectType determines whether a parsed document is an action, and of
+// which kind, or a workflow. Documents with a 'runs' section are actions and
+// documents with 'on' or 'jobs' are workflows; a document with both, or an
+// action with an unrecognized 'runs.using', is FileTypeUnknown. Future
+// Node.js versions such as 'node24' are recognized as Node actions.
+func DetectType(action *ActionFile) FileType {
+	if action == nil {
+		return FileTypeUnknown
+	}
+	isAction := action.Runs.Using != "" || action.Runs.Main != "" || action.Runs.Image != "" || len(action.Runs.Steps) > 0
+	isWorkflow := action.On != nil || action.Jobs != nil
+	switch {
+	case isAction && isWorkflow:
+		return FileTypeUnknown
+	case isAction:
+		return actionType(action.Runs.Using)
+	case isWorkflow:
+		if _, ok, _ := triggerConfig(action, "workflow_call"); ok {
+			return FileTypeReusableWorkflow
+		}
+		return FileTypeWorkflow
+	}
+	return FileTypeUnknown
+}
+
+// actionType returns the action type of a 'runs.using' value
+func actionType(using string) FileType {
+	using = strings.ToLower(strings.TrimSpace(using))
+	switch {
+	case using == "composite":
+		return FileTypeCompositeAction
+	case using == "docker":
+		return FileTypeDockerAction
+	case strings.HasPrefix(using, "node") && len(using) > len("node") && strings.Trim(using[len("node"):], "0123456789") == "":
+		return FileTypeNodeAction
+	}
+	return FileTypeUnknown
+}
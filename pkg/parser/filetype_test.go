@@ -0,0 +1,35 @@
+package parser
+
+import "testing"
+
+func TestDetectType(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want FileType
+	}{
+		{"composite", "name: a\nruns:\n  using: composite\n  steps:\n    - run: echo\n      shell: bash\n", FileTypeCompositeAction},
+		{"node20", "name: a\nruns:\n  using: node20\n  main: index.js\n", FileTypeNodeAction},
+		{"future node", "name: a\nruns:\n  using: Node24\n  main: index.js\n", FileTypeNodeAction},
+		{"docker", "name: a\nruns:\n  using: docker\n  image: Dockerfile\n", FileTypeDockerAction},
+		{"unknown using", "name: a\nruns:\n  using: python\n", FileTypeUnknown},
+		{"missing using", "name: a\nruns:\n  main: index.js\n", FileTypeUnknown},
+		{"workflow", "on: push\njobs:\n  a:\n    runs-on: ubuntu-latest\n", FileTypeWorkflow},
+		{"workflow without jobs", "on: push\n", FileTypeWorkflow},
+		{"reusable map", "on:\n  workflow_call:\n    inputs: {}\njobs: {}\n", FileTypeReusableWorkflow},
+		{"reusable string", "on: workflow_call\njobs: {}\n", FileTypeReusableWorkflow},
+		{"reusable list", "on: [push, workflow_call]\njobs: {}\n", FileTypeReusableWorkflow},
+		{"mixed", "on: push\nruns:\n  using: composite\n", FileTypeUnknown},
+		{"empty", "name: nothing\n", FileTypeUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectType(mustParse(t, tt.doc)); got != tt.want {
+				t.Errorf("DetectType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if got := DetectType(nil); got != FileTypeUnknown {
+		t.Errorf("DetectType(nil) = %q, want %q", got, FileTypeUnknown)
+	}
+}
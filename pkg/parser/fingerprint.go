@@ -0,0 +1,159 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Fingerprint returns a stable hash of the semantic content of an ActionFile.
+// The hash does not depend on formatting, comments or the order of keys in
+// the source document, so two revisions of a file have the same fingerprint
+// unless something meaningful changed.
+func Fingerprint(action *ActionFile) string {
+	tree, err := normalizedTree(action)
+	if err != nil {
+		return ""
+	}
+
+	// encoding/json sorts map keys, which makes the encoding canonical
+	data, err := json.Marshal(tree)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizedTree converts an ActionFile into a generic tree of maps, slices
+// and scalars keyed by the YAML field names, with equivalent spellings
+// reduced to a single form (see Equal)
+func normalizedTree(action *ActionFile) (interface{}, error) {
+	data, err := yaml.Marshal(action)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+
+	var tree interface{}
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal YAML: %w", err)
+	}
+
+	return normalizeValue(tree, ""), nil
+}
+
+// normalizeValue reduces a decoded YAML value at the given field path to its
+// canonical form: map keys become strings, scalars become strings, one-element
+// lists become their element, and fields set to their default are dropped
+func normalizeValue(v interface{}, path string) interface{} {
+	if path == "on" {
+		v = eventMap(v)
+	}
+
+	switch value := v.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			childPath := joinPath(path, k)
+			normalized := normalizeValue(item, childPath)
+			if isDefaultValue(childPath, normalized) {
+				continue
+			}
+			// Drop mappings that only contained default values
+			if m, ok := normalized.(map[string]interface{}); ok && len(m) == 0 && !isEmptyMap(item) {
+				continue
+			}
+			result[k] = normalized
+		}
+		if len(result) == 0 && isEventPath(path) {
+			return nil
+		}
+		return result
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			converted[fmt.Sprint(k)] = item
+		}
+		return normalizeValue(converted, path)
+	case []interface{}:
+		if len(value) == 1 && isScalar(value[0]) {
+			return normalizeValue(value[0], path+"[0]")
+		}
+		result := make([]interface{}, len(value))
+		for i, item := range value {
+			result[i] = normalizeValue(item, fmt.Sprintf("%s[%d]", path, i))
+		}
+		return result
+	case string:
+		return value
+	case bool:
+		return strconv.FormatBool(value)
+	case int:
+		return strconv.Itoa(value)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case nil:
+		return nil
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// eventMap converts the string and list forms of 'on' into the equivalent
+// mapping form
+func eventMap(v interface{}) interface{} {
+	switch value := v.(type) {
+	case string:
+		return map[string]interface{}{value: nil}
+	case []interface{}:
+		result := make(map[string]interface{}, len(value))
+		for _, event := range value {
+			if !isScalar(event) {
+				return v
+			}
+			result[fmt.Sprint(event)] = nil
+		}
+		return result
+	}
+	return v
+}
+
+// isEmptyMap reports whether a decoded YAML value is a mapping without entries
+func isEmptyMap(v interface{}) bool {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		return len(value) == 0
+	case map[interface{}]interface{}:
+		return len(value) == 0
+	}
+	return false
+}
+
+// isScalar reports whether a decoded YAML value is neither a map nor a list
+func isScalar(v interface{}) bool {
+	switch v.(type) {
+	case map[string]interface{}, map[interface{}]interface{}, []interface{}:
+		return false
+	}
+	return true
+}
+
+// joinPath appends a map key to a field path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// isEventPath reports whether path is the configuration of a trigger event,
+// where an empty mapping means the same as no configuration at all
+func isEventPath(path string) bool {
+	return strings.HasPrefix(path, "on.") && strings.Count(path, ".") == 1
+}
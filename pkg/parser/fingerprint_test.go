@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	original := `# CI workflow
+name: CI
+on:
+  push:
+    branches: [main]
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - run: make test
+`
+	reformatted := `jobs:
+    build:
+        steps:
+            - uses: "actions/checkout@v4"
+            -   run: make test
+        runs-on: ubuntu-latest
+on: {push: {branches: ["main"]}}
+name: 'CI'
+`
+	changed := strings.Replace(original, "make test", "make lint", 1)
+
+	fingerprint := func(doc string) string {
+		action, err := Parse(strings.NewReader(doc))
+		if err != nil {
+			t.Fatalf("Failed to parse: %v", err)
+		}
+		return Fingerprint(action)
+	}
+
+	a, b, c := fingerprint(original), fingerprint(reformatted), fingerprint(changed)
+	if a == "" {
+		t.Fatalf("Expected a non-empty fingerprint")
+	}
+	if a != b {
+		t.Errorf("Expected reformatted document to have the same fingerprint")
+	}
+	if a == c {
+		t.Errorf("Expected changed document to have a different fingerprint")
+	}
+}
+
+func TestFingerprintIgnoresPositions(t *testing.T) {
+	plain, err := ParseFile("testdata/workflow.yml")
+	if err != nil {
+		t.Fatalf("Failed to parse workflow file: %v", err)
+	}
+	withPositions, err := ParseFile("testdata/workflow.yml", WithPositions())
+	if err != nil {
+		t.Fatalf("Failed to parse workflow file: %v", err)
+	}
+
+	if Fingerprint(plain) != Fingerprint(withPositions) {
+		t.Errorf("Expected positions not to affect the fingerprint")
+	}
+}
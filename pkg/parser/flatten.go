@@ -0,0 +1,469 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxCompositeDepth is the nesting limit GitHub imposes on composite actions
+const maxCompositeDepth = 10
+
+// WithComposites makes Flatten also replace the steps using composite
+// actions by the steps of the action
+func WithComposites() Option {
+	return func(o *options) {
+		o.composites = true
+	}
+}
+
+// Flatten returns the effective workflow GitHub runs for a workflow: every
+// job calling a reusable workflow is replaced by the jobs of the called
+// workflow, loaded through resolver. The inlined jobs are named
+// '<caller>-<job>', need the jobs the caller needs, and have the inputs and
+// secrets passed by the caller substituted into their expressions. Jobs
+// needing the caller need all inlined jobs instead, and references to the
+// caller's outputs are replaced by the job outputs they are taken from.
+// Nested calls are inlined as well.
+//
+// With WithComposites, steps using composite actions that resolver can load
+// are replaced by the steps of the action in the same way, with their step
+// IDs prefixed by the ID of the replaced step. Steps using other actions, or
+// actions resolver cannot load, are kept.
+//
+// The workflow itself is not modified. Flatten fails if a called workflow
+// cannot be loaded, or calls are nested deeper than GitHub allows, which
+// also stops workflows calling themselves.
+func Flatten(workflow *ActionFile, resolver Resolver, opts ...Option) (*ActionFile, error) {
+	if workflow.Jobs == nil {
+		return nil, ErrNotAWorkflow
+	}
+	f := &flattener{resolver: resolver, opts: newOptions(opts)}
+	flat, _, err := f.workflow(workflow, 0)
+	return flat, err
+}
+
+// flattener implements Flatten
+type flattener struct {
+	resolver Resolver
+	opts     *options
+}
+
+// workflow flattens a workflow, returning the expressions of the outputs
+// of the inlined callers keyed by '<caller>.<output>'. depth is the number
+// of calls leading to the workflow.
+func (f *flattener) workflow(workflow *ActionFile, depth int) (*ActionFile, map[string]string, error) {
+	flat := *workflow
+	flat.Jobs = make(map[string]Job, len(workflow.Jobs))
+
+	taken := make(map[string]bool, len(workflow.Jobs))
+	for id := range workflow.Jobs {
+		taken[id] = true
+	}
+	// inlined maps the callers to the IDs of the jobs replacing them, and
+	// outputs maps '<caller>.<output>' to the expression of the output
+	inlined := make(map[string][]string)
+	outputs := make(map[string]string)
+	for _, jobID := range sortedJobIDs(workflow) {
+		job := workflow.Jobs[jobID]
+		if job.Uses == "" {
+			if f.opts.composites {
+				var err error
+				if job, err = f.compositeJob(job); err != nil {
+					return nil, nil, err
+				}
+			}
+			flat.Jobs[jobID] = job
+			continue
+		}
+		jobs, ids, callOutputs, err := f.call(jobID, job, taken, depth)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, id := range ids {
+			flat.Jobs[id] = jobs[id]
+		}
+		inlined[jobID] = ids
+		for name, expr := range callOutputs {
+			outputs[jobID+"."+name] = expr
+		}
+	}
+	if len(inlined) == 0 {
+		return &flat, outputs, nil
+	}
+
+	rewrite := func(ref ContextReference) string {
+		if ref.Context == "needs" && len(ref.Path) == 3 && ref.Path[1] == "outputs" {
+			return outputs[ref.Path[0]+"."+ref.Path[2]]
+		}
+		return ""
+	}
+	for id, job := range flat.Jobs {
+		job = mapJobStrings(withConditionExpressions(job), func(s string) string {
+			return RewriteContextReferences(s, rewrite)
+		})
+		if needs := JobNeeds(job); len(needs) > 0 {
+			var expanded []string
+			for _, need := range needs {
+				if ids, ok := inlined[need]; ok {
+					expanded = append(expanded, ids...)
+				} else {
+					expanded = append(expanded, need)
+				}
+			}
+			job.Needs = expanded
+		}
+		flat.Jobs[id] = job
+	}
+	return &flat, outputs, nil
+}
+
+// call inlines the reusable workflow called by a job, returning the inlined
+// jobs with their IDs in order, and the expressions of the outputs of the
+// called workflow
+func (f *flattener) call(callerID string, caller Job, taken map[string]bool, depth int) (map[string]Job, []string, map[string]string, error) {
+	if depth+1 >= maxReusableDepth {
+		return nil, nil, nil, fmt.Errorf("job %s: reusable workflows are nested more than %d levels deep", callerID, maxReusableDepth)
+	}
+	if f.resolver == nil {
+		return nil, nil, nil, fmt.Errorf("job %s: cannot resolve %s without a resolver", callerID, caller.Uses)
+	}
+	called, err := f.opts.resolve(f.resolver, caller.Uses)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("job %s: failed to resolve %s: %w", callerID, caller.Uses, err)
+	}
+	if called.Jobs == nil {
+		return nil, nil, nil, fmt.Errorf("job %s: failed to inline %s: %w", callerID, caller.Uses, ErrNotAWorkflow)
+	}
+	called, nested, err := f.workflow(called, depth+1)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("job %s: %w", callerID, err)
+	}
+
+	values := make(map[string]string)
+	inputs, err := ExtractInputsFromWorkflowCall(called)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("job %s: failed to inline %s: %w", callerID, caller.Uses, err)
+	}
+	for name, input := range inputs {
+		if value, ok := caller.With[name]; ok {
+			values["inputs."+name] = valueExpression(value)
+		} else if input.Default != "" {
+			values["inputs."+name] = defaultExpression(input)
+		}
+	}
+	secrets, err := ParseJobSecrets(caller)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("job %s: %w", callerID, err)
+	}
+	for name, value := range secrets.Values {
+		values["secrets."+name] = valueExpression(value)
+	}
+
+	calledIDs := sortedJobIDs(called)
+	ids := make(map[string]string, len(calledIDs))
+	order := make([]string, 0, len(calledIDs))
+	for _, id := range calledIDs {
+		ids[id] = uniqueJobID(callerID+"-"+id, taken)
+		order = append(order, ids[id])
+	}
+	rewrite := func(ref ContextReference) string {
+		switch {
+		case (ref.Context == "inputs" || ref.Context == "secrets") && len(ref.Path) == 1:
+			return values[ref.Context+"."+ref.Path[0]]
+		case (ref.Context == "needs" || ref.Context == "jobs") && ids[ref.Path[0]] != "":
+			return "needs." + ids[ref.Path[0]] + strings.TrimPrefix(ref.String(), ref.Context+"."+ref.Path[0])
+		}
+		return ""
+	}
+	rewriteString := func(s string) string {
+		return RewriteContextReferences(s, rewrite)
+	}
+
+	jobs := make(map[string]Job, len(calledIDs))
+	callerNeeds := JobNeeds(caller)
+	for _, id := range calledIDs {
+		job := called.Jobs[id]
+		// The env and defaults of the called workflow do not apply to the
+		// caller's other jobs
+		job.Env = mergeEnv(called.Env, job.Env)
+		if job.Defaults == nil {
+			job.Defaults = called.Defaults
+		}
+		job = mapJobStrings(withConditionExpressions(job), rewriteString)
+
+		// The caller's settings are in the caller's context and are not
+		// rewritten
+		if job.Strategy == nil {
+			job.Strategy = caller.Strategy
+		}
+		if job.Permissions == nil {
+			job.Permissions = caller.Permissions
+		}
+		job.If = combineConditions(caller.If, job.If)
+
+		name := job.Name
+		if name == "" {
+			name = id
+		}
+		if caller.Name != "" {
+			name = caller.Name + " / " + name
+		}
+		job.Name = name
+
+		needs := append([]string(nil), callerNeeds...)
+		for _, need := range JobNeeds(job) {
+			if ids[need] != "" {
+				need = ids[need]
+			}
+			needs = append(needs, need)
+		}
+		job.Needs = nil
+		if len(needs) > 0 {
+			job.Needs = needs
+		}
+		jobs[ids[id]] = job
+	}
+
+	declared, err := ExtractOutputsFromWorkflowCall(called)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("job %s: failed to inline %s: %w", callerID, caller.Uses, err)
+	}
+	outputs := make(map[string]string, len(declared))
+	for name, output := range declared {
+		// Outputs taken from jobs that were callers themselves are taken
+		// from the jobs inlined for them
+		value := RewriteContextReferences(output.Value, func(ref ContextReference) string {
+			if ref.Context == "jobs" && len(ref.Path) == 3 && ref.Path[1] == "outputs" {
+				return nested[ref.Path[0]+"."+ref.Path[2]]
+			}
+			return ""
+		})
+		outputs[name] = valueExpression(rewriteString(value))
+	}
+	return jobs, order, outputs, nil
+}
+
+// compositeJob replaces the composite action steps of a job by their steps
+func (f *flattener) compositeJob(job Job) (Job, error) {
+	if len(job.Steps) == 0 || f.resolver == nil {
+		return job, nil
+	}
+	steps, outputs, err := f.compositeSteps(job.Steps, 0)
+	if err != nil {
+		return job, err
+	}
+	job.Steps = steps
+	if len(outputs) == 0 {
+		return job, nil
+	}
+	return mapJobStrings(withConditionExpressions(job), func(s string) string {
+		return RewriteContextReferences(s, func(ref ContextReference) string {
+			if ref.Context == "steps" && len(ref.Path) == 3 && ref.Path[1] == "outputs" {
+				return outputs[ref.Path[0]+"."+ref.Path[2]]
+			}
+			return ""
+		})
+	}), nil
+}
+
+// compositeSteps inlines the composite actions used by steps, returning the
+// expressions of their outputs keyed by '<step>.<output>'. depth is the
+// number of composite actions the steps are nested in.
+func (f *flattener) compositeSteps(steps []Step, depth int) ([]Step, map[string]string, error) {
+	result := make([]Step, 0, len(steps))
+	outputs := make(map[string]string)
+	for i, step := range steps {
+		action := f.composite(step.Uses)
+		if action == nil {
+			result = append(result, step)
+			continue
+		}
+		if depth+1 >= maxCompositeDepth {
+			return nil, nil, fmt.Errorf("composite actions are nested more than %d levels deep at %s", maxCompositeDepth, step.Uses)
+		}
+		inner, innerOutputs, err := f.compositeSteps(action.Runs.Steps, depth+1)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		prefix := step.ID
+		if prefix == "" {
+			prefix = fmt.Sprintf("step%d", i)
+		}
+		values := make(map[string]string)
+		for name, input := range action.Inputs {
+			if value, ok := step.With[name]; ok {
+				values[name] = valueExpression(value)
+			} else if input.Default != "" {
+				values[name] = defaultExpression(input)
+			}
+		}
+		ids := make(map[string]string)
+		for _, s := range inner {
+			if s.ID != "" {
+				ids[s.ID] = prefix + "-" + s.ID
+			}
+		}
+		rewriteString := func(s string) string {
+			return RewriteContextReferences(s, func(ref ContextReference) string {
+				switch {
+				case ref.Context == "inputs" && len(ref.Path) == 1:
+					return values[ref.Path[0]]
+				case ref.Context == "steps" && len(ref.Path) == 3 && ref.Path[1] == "outputs" && innerOutputs[ref.Path[0]+"."+ref.Path[2]] != "":
+					return innerOutputs[ref.Path[0]+"."+ref.Path[2]]
+				case ref.Context == "steps" && ids[ref.Path[0]] != "":
+					return "steps." + ids[ref.Path[0]] + strings.TrimPrefix(ref.String(), "steps."+ref.Path[0])
+				}
+				return ""
+			})
+		}
+		for _, s := range inner {
+			s = mapStepStrings(withStepConditionExpression(s), rewriteString)
+			s.If = combineConditions(step.If, s.If)
+			s.Env = mergeEnv(step.Env, s.Env)
+			if s.ID != "" {
+				s.ID = ids[s.ID]
+			}
+			result = append(result, s)
+		}
+		for name, output := range action.Outputs {
+			outputs[prefix+"."+name] = valueExpression(rewriteString(output.Value))
+		}
+	}
+	return result, outputs, nil
+}
+
+// composite loads the composite action used by a step, or returns nil
+func (f *flattener) composite(uses string) *ActionFile {
+	if uses == "" || strings.HasPrefix(uses, "docker://") {
+		return nil
+	}
+	action, err := f.opts.resolve(f.resolver, uses)
+	if err != nil || action.Runs.Using != "composite" {
+		return nil
+	}
+	return action
+}
+
+// uniqueJobID returns id, or id with a numeric suffix if it is taken, and
+// marks the result as taken
+func uniqueJobID(id string, taken map[string]bool) string {
+	unique := id
+	for n := 2; taken[unique]; n++ {
+		unique = fmt.Sprintf("%s-%d", id, n)
+	}
+	taken[unique] = true
+	return unique
+}
+
+// withConditionExpressions wraps the conditions of a job and its steps in
+// ${{ }}, so that RewriteContextReferences sees them
+func withConditionExpressions(job Job) Job {
+	job.If = conditionExpression(job.If)
+	if len(job.Steps) > 0 {
+		steps := make([]Step, len(job.Steps))
+		for i, step := range job.Steps {
+			steps[i] = withStepConditionExpression(step)
+		}
+		job.Steps = steps
+	}
+	return job
+}
+
+// withStepConditionExpression wraps the condition of a step in ${{ }}
+func withStepConditionExpression(step Step) Step {
+	step.If = conditionExpression(step.If)
+	return step
+}
+
+// combineConditions returns a condition holding when both outer and inner
+// hold; either may be empty
+func combineConditions(outer, inner string) string {
+	switch {
+	case outer == "":
+		return inner
+	case inner == "":
+		return conditionExpression(outer)
+	}
+	return fmt.Sprintf("${{ (%s) && (%s) }}", trimExpression(outer), trimExpression(inner))
+}
+
+// trimExpression returns the contents of a condition without ${{ }}
+func trimExpression(condition string) string {
+	condition = strings.TrimSpace(condition)
+	if exprs := ExtractExpressions(condition); len(exprs) == 1 && strings.HasPrefix(condition, "${{") && strings.HasSuffix(condition, "}}") {
+		return exprs[0]
+	}
+	return condition
+}
+
+// mergeEnv returns the variables of outer overridden by those of inner
+func mergeEnv(outer, inner EnvMap) EnvMap {
+	if len(outer) == 0 {
+		return inner
+	}
+	merged := make(EnvMap, len(outer)+len(inner))
+	for k, v := range outer {
+		merged[k] = v
+	}
+	for k, v := range inner {
+		merged[k] = v
+	}
+	return merged
+}
+
+// valueExpression converts a value passed to an input, secret or output
+// into an expression: literals become expression literals, a value holding
+// a single expression becomes that expression, and values mixing text and
+// expressions become a format() call
+func valueExpression(v interface{}) string {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Sprint(v)
+	}
+	locs := expressionPattern.FindAllStringSubmatchIndex(s, -1)
+	if len(locs) == 0 {
+		return quoteLiteral(s)
+	}
+	if len(locs) == 1 && locs[0][0] == 0 && locs[0][1] == len(s) {
+		return "(" + strings.TrimSpace(s[locs[0][2]:locs[0][3]]) + ")"
+	}
+	var format strings.Builder
+	args := make([]string, 0, len(locs))
+	last := 0
+	for i, loc := range locs {
+		format.WriteString(escapeFormat(s[last:loc[0]]))
+		format.WriteString("{" + strconv.Itoa(i) + "}")
+		args = append(args, strings.TrimSpace(s[loc[2]:loc[3]]))
+		last = loc[1]
+	}
+	format.WriteString(escapeFormat(s[last:]))
+	return fmt.Sprintf("format(%s, %s)", quoteLiteral(format.String()), strings.Join(args, ", "))
+}
+
+// defaultExpression returns the default of an input as an expression
+// literal of the input's type
+func defaultExpression(input Input) string {
+	switch input.Type {
+	case "boolean":
+		if b, err := strconv.ParseBool(input.Default); err == nil {
+			return strconv.FormatBool(b)
+		}
+	case "number":
+		if _, err := strconv.ParseFloat(input.Default, 64); err == nil {
+			return input.Default
+		}
+	}
+	return valueExpression(input.Default)
+}
+
+// quoteLiteral returns s as an expression string literal
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// escapeFormat escapes the braces of text used in a format() string
+func escapeFormat(s string) string {
+	return strings.NewReplacer("{", "{{", "}", "}}").Replace(s)
+}
@@ -0,0 +1,209 @@
+package parser
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	workflow := mustParse(t, `
+on: push
+jobs:
+  setup:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make setup
+  test:
+    name: Test
+    needs: setup
+    if: github.ref == 'refs/heads/main'
+    uses: octo/ci/.github/workflows/test.yml@v1
+    with:
+      node: ${{ matrix.node }}
+      label: v${{ github.run_number }}
+    secrets:
+      token: ${{ secrets.NPM_TOKEN }}
+    strategy:
+      matrix:
+        node: [18, 20]
+  report:
+    needs: test
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ needs.test.outputs.coverage }}
+`)
+	called := mustParse(t, `
+on:
+  workflow_call:
+    inputs:
+      node:
+        type: string
+        required: true
+      label:
+        type: string
+      verbose:
+        type: boolean
+        default: false
+    secrets:
+      token:
+        required: true
+    outputs:
+      coverage:
+        value: ${{ jobs.unit.outputs.coverage }}
+env:
+  CI: "true"
+jobs:
+  unit:
+    runs-on: ubuntu-latest
+    outputs:
+      coverage: ${{ steps.test.outputs.coverage }}
+    steps:
+      - id: test
+        run: npm test -- --node=${{ inputs.node }} --verbose=${{ inputs.verbose }}
+        env:
+          TOKEN: ${{ secrets.token }}
+  lint:
+    needs: unit
+    if: inputs.label != ''
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ inputs.label }}
+`)
+
+	flat, err := Flatten(workflow, mapResolver{"octo/ci/.github/workflows/test.yml@v1": called})
+	if err != nil {
+		t.Fatalf("Flatten() error = %v", err)
+	}
+	if _, ok := workflow.Jobs["test"]; !ok {
+		t.Fatal("Expected the original workflow to be unchanged")
+	}
+	if ids := sortedJobIDs(flat); !reflect.DeepEqual(ids, []string{"report", "setup", "test-lint", "test-unit"}) {
+		t.Fatalf("Unexpected jobs %v", ids)
+	}
+
+	unit := flat.Jobs["test-unit"]
+	if unit.Name != "Test / unit" {
+		t.Errorf("Expected the caller's name as prefix, got %q", unit.Name)
+	}
+	if !reflect.DeepEqual(JobNeeds(unit), []string{"setup"}) {
+		t.Errorf("Expected the caller's needs, got %v", unit.Needs)
+	}
+	if want := "${{ github.ref == 'refs/heads/main' }}"; unit.If != want {
+		t.Errorf("Expected the caller's condition %q, got %q", want, unit.If)
+	}
+	if unit.Strategy == nil || unit.Env["CI"] != "true" {
+		t.Errorf("Expected the caller's strategy and the called workflow's env, got %+v", unit)
+	}
+	if want := "npm test -- --node=${{ (matrix.node) }} --verbose=${{ false }}"; unit.Steps[0].Run != want {
+		t.Errorf("Expected inputs to be substituted:\n got %q\nwant %q", unit.Steps[0].Run, want)
+	}
+	if want := "${{ (secrets.NPM_TOKEN) }}"; unit.Steps[0].Env["TOKEN"] != want {
+		t.Errorf("Expected secrets to be substituted, got %q", unit.Steps[0].Env["TOKEN"])
+	}
+
+	lint := flat.Jobs["test-lint"]
+	if !reflect.DeepEqual(JobNeeds(lint), []string{"setup", "test-unit"}) {
+		t.Errorf("Expected needs within the called workflow to be renamed, got %v", lint.Needs)
+	}
+	if want := "${{ (github.ref == 'refs/heads/main') && (format('v{0}', github.run_number) != '') }}"; lint.If != want {
+		t.Errorf("Unexpected condition:\n got %q\nwant %q", lint.If, want)
+	}
+
+	report := flat.Jobs["report"]
+	if !reflect.DeepEqual(JobNeeds(report), []string{"test-lint", "test-unit"}) {
+		t.Errorf("Expected needs on the caller to need the inlined jobs, got %v", report.Needs)
+	}
+	if want := "echo ${{ (needs.test-unit.outputs.coverage) }}"; report.Steps[0].Run != want {
+		t.Errorf("Expected caller outputs to be replaced:\n got %q\nwant %q", report.Steps[0].Run, want)
+	}
+}
+
+func TestFlattenComposites(t *testing.T) {
+	workflow := mustParse(t, `
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    outputs:
+      version: ${{ steps.setup.outputs.version }}
+    steps:
+      - uses: actions/checkout@v4
+      - id: setup
+        if: github.event_name == 'push'
+        uses: ./.github/actions/setup
+        with:
+          go: "1.22"
+      - run: echo ${{ steps.setup.outputs.version }}
+`)
+	composite := mustParse(t, `
+name: Setup
+inputs:
+  go:
+    required: true
+  cache:
+    default: "true"
+outputs:
+  version:
+    value: ${{ steps.version.outputs.value }}
+runs:
+  using: composite
+  steps:
+    - run: install-go ${{ inputs.go }} --cache=${{ inputs.cache }}
+      shell: bash
+    - id: version
+      run: echo "value=$(go version)" >> "$GITHUB_OUTPUT"
+      shell: bash
+`)
+	resolver := mapResolver{"./.github/actions/setup": composite}
+
+	flat, err := Flatten(workflow, resolver)
+	if err != nil {
+		t.Fatalf("Flatten() error = %v", err)
+	}
+	if len(flat.Jobs["build"].Steps) != 3 {
+		t.Errorf("Expected composite actions to be kept without WithComposites, got %+v", flat.Jobs["build"].Steps)
+	}
+
+	flat, err = Flatten(workflow, resolver, WithComposites())
+	if err != nil {
+		t.Fatalf("Flatten() error = %v", err)
+	}
+	steps := flat.Jobs["build"].Steps
+	if len(steps) != 4 {
+		t.Fatalf("Expected the composite steps to be inlined, got %+v", steps)
+	}
+	if steps[0].Uses != "actions/checkout@v4" {
+		t.Errorf("Expected other actions to be kept, got %+v", steps[0])
+	}
+	if want := "install-go ${{ '1.22' }} --cache=${{ 'true' }}"; steps[1].Run != want {
+		t.Errorf("Expected inputs to be substituted:\n got %q\nwant %q", steps[1].Run, want)
+	}
+	if steps[1].If != "${{ github.event_name == 'push' }}" || steps[2].ID != "setup-version" {
+		t.Errorf("Expected the step's condition and prefixed IDs, got %+v", steps[1:3])
+	}
+	if want := "echo ${{ (steps.setup-version.outputs.value) }}"; steps[3].Run != want {
+		t.Errorf("Expected step outputs to be replaced:\n got %q\nwant %q", steps[3].Run, want)
+	}
+	if want := "${{ (steps.setup-version.outputs.value) }}"; flat.Jobs["build"].Outputs["version"] != want {
+		t.Errorf("Expected job outputs to be replaced, got %q", flat.Jobs["build"].Outputs["version"])
+	}
+}
+
+func TestFlattenErrors(t *testing.T) {
+	workflow := mustParse(t, `
+on: push
+jobs:
+  call:
+    uses: ./.github/workflows/self.yml
+`)
+	if _, err := Flatten(workflow, mapResolver{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing workflow, got %v", err)
+	}
+	if _, err := Flatten(workflow, mapResolver{"./.github/workflows/self.yml": workflow}); err == nil {
+		t.Error("Expected an error for a workflow calling itself")
+	}
+	if _, err := Flatten(mustParse(t, "name: action\nruns:\n  using: node20\n  main: index.js\n"), nil); !errors.Is(err, ErrNotAWorkflow) {
+		t.Errorf("Expected ErrNotAWorkflow, got %v", err)
+	}
+}
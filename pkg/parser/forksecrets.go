@@ -0,0 +1,138 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ForkExposure is what happens to the secrets of a job when it runs for a
+// pull request from a fork
+type ForkExposure string
+
+const (
+	// ForkSecretsWithheld marks pull_request jobs: GitHub passes empty
+	// secrets to runs for forks, so steps relying on them fail
+	ForkSecretsWithheld ForkExposure = "withheld"
+	// ForkSecretsAvailable marks pull_request_target jobs running the code
+	// of the base branch with the secrets; they are exposed if untrusted
+	// input from the pull request reaches a step
+	ForkSecretsAvailable ForkExposure = "available"
+	// ForkSecretsExposed marks pull_request_target jobs that check out the
+	// code of the pull request, which can then read the secrets
+	ForkSecretsExposed ForkExposure = "exposed"
+)
+
+// ForkSecretExposure describes the secrets a job referencing secrets would
+// receive when it runs for a pull request from a fork
+type ForkSecretExposure struct {
+	File  string       `json:"file"`
+	Job   string       `json:"job"`
+	Event string       `json:"event"`
+	Level ForkExposure `json:"level"`
+	// Secrets are the sorted, upper-cased names of the secrets the job
+	// references, including through the env of the workflow. GITHUB_TOKEN
+	// is left out, as its permissions are restricted for forks.
+	Secrets []string `json:"secrets,omitempty"`
+	// Inherit is set if the job passes all secrets to a reusable workflow
+	Inherit bool `json:"inherit,omitempty"`
+	// Checkout is the field of the step checking out the pull request,
+	// set when Level is ForkSecretsExposed
+	Checkout string `json:"checkout,omitempty"`
+}
+
+// forkGuardPattern matches job conditions that skip pull requests from
+// forks
+var forkGuardPattern = regexp.MustCompile(`head\.repo\.full_name\s*==\s*github\.repository\b|github\.repository\s*==\s*github\.event\.pull_request\.head\.repo\.full_name|!\s*github\.event\.pull_request\.head\.repo\.fork\b|head\.repo\.fork\s*==\s*false`)
+
+// pullRequestHeadPattern matches references to the code of a pull request
+var pullRequestHeadPattern = regexp.MustCompile(`github\.event\.pull_request\.head\.(?:sha|ref|repo\.full_name)|github\.head_ref|refs/pull/`)
+
+// pullRequestCheckoutCommand matches commands checking out a pull request
+var pullRequestCheckoutCommand = regexp.MustCompile(`gh\s+pr\s+checkout|git\s+(?:fetch|checkout|switch)\b[^\n]*(?:\bpull/|github\.event\.pull_request\.head\.|github\.head_ref)`)
+
+// ForkSecretExposures analyzes the workflows of a directory, as returned by
+// ParseDir, triggered by pull_request or pull_request_target, reporting the
+// jobs that reference secrets and would run for pull requests from forks.
+// Jobs whose condition compares the head repository with the base
+// repository are skipped. Results are sorted by file, job and event.
+func ForkSecretExposures(workflows map[string]*ActionFile) []ForkSecretExposure {
+	exposures := make([]ForkSecretExposure, 0)
+	for _, file := range sortedFiles(workflows) {
+		workflow := workflows[file]
+		var events []string
+		for _, event := range []string{"pull_request", "pull_request_target"} {
+			if _, ok, _ := triggerConfig(workflow, event); ok {
+				events = append(events, event)
+			}
+		}
+		if len(events) == 0 {
+			continue
+		}
+
+		inventory := Secrets(map[string]*ActionFile{file: workflow})
+		for _, jobID := range sortedJobIDs(workflow) {
+			job := workflow.Jobs[jobID]
+			if forkGuardPattern.MatchString(conditionExpression(job.If)) {
+				continue
+			}
+			exposure := ForkSecretExposure{File: file, Job: jobID, Secrets: jobSecretNames(inventory, jobID)}
+			for _, use := range inventory.Inherit {
+				exposure.Inherit = exposure.Inherit || use.Job == jobID
+			}
+			if len(exposure.Secrets) == 0 && !exposure.Inherit {
+				continue
+			}
+			for _, event := range events {
+				exposure.Event, exposure.Level, exposure.Checkout = event, ForkSecretsWithheld, ""
+				if event == "pull_request_target" {
+					exposure.Level = ForkSecretsAvailable
+					if exposure.Checkout = pullRequestCheckout(jobID, job); exposure.Checkout != "" {
+						exposure.Level = ForkSecretsExposed
+					}
+				}
+				exposures = append(exposures, exposure)
+			}
+		}
+	}
+	return exposures
+}
+
+// jobSecretNames returns the secrets a job references, directly or through
+// the env of the workflow
+func jobSecretNames(inventory *SecretInventory, jobID string) []string {
+	var names []string
+	for _, usage := range inventory.Secrets {
+		if usage.Name == "GITHUB_TOKEN" {
+			continue
+		}
+		for _, use := range usage.Uses {
+			if use.Job == jobID || use.Job == "" && strings.HasPrefix(use.Field, "env.") {
+				names = append(names, usage.Name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pullRequestCheckout returns the field of the first step of a job checking
+// out the code of the pull request, with actions/checkout or a command
+func pullRequestCheckout(jobID string, job Job) string {
+	for i, step := range job.Steps {
+		field := fmt.Sprintf("jobs.%s.steps[%d]", jobID, i)
+		if strings.HasPrefix(strings.ToLower(step.Uses), "actions/checkout@") {
+			for _, key := range []string{"ref", "repository"} {
+				if value, ok := step.With[key].(string); ok && pullRequestHeadPattern.MatchString(value) {
+					return field + ".with." + key
+				}
+			}
+		}
+		if pullRequestCheckoutCommand.MatchString(step.Run) {
+			return field + ".run"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestForkSecretExposures(t *testing.T) {
+	workflows := map[string]*ActionFile{
+		"ci.yml": mustParse(t, `on: [push, pull_request]
+env:
+  REGISTRY_TOKEN: ${{ secrets.registry_token }}
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make test
+        env:
+          CODECOV_TOKEN: ${{ secrets.CODECOV_TOKEN }}
+          GH_TOKEN: ${{ secrets.GITHUB_TOKEN }}
+  publish:
+    if: github.event.pull_request.head.repo.full_name == github.repository
+    runs-on: ubuntu-latest
+    steps:
+      - run: make publish
+        env:
+          NPM_TOKEN: ${{ secrets.NPM_TOKEN }}
+`),
+		"preview.yml": mustParse(t, `on: pull_request_target
+jobs:
+  label:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/labeler@v5
+        with:
+          token: ${{ secrets.LABEL_TOKEN }}
+  preview:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+        with:
+          ref: ${{ github.event.pull_request.head.sha }}
+      - run: npm ci && npm run deploy-preview
+        env:
+          DEPLOY_KEY: ${{ secrets.DEPLOY_KEY }}
+  notify:
+    uses: org/shared/.github/workflows/notify.yml@v1
+    secrets: inherit
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - run: gh pr checkout ${{ github.event.number }} && make lint
+`),
+		"release.yml": mustParse(t, `on: push
+jobs:
+  release:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make release
+        env:
+          NPM_TOKEN: ${{ secrets.NPM_TOKEN }}
+`),
+	}
+
+	want := []ForkSecretExposure{
+		{File: "ci.yml", Job: "test", Event: "pull_request", Level: ForkSecretsWithheld, Secrets: []string{"CODECOV_TOKEN", "REGISTRY_TOKEN"}},
+		{File: "preview.yml", Job: "label", Event: "pull_request_target", Level: ForkSecretsAvailable, Secrets: []string{"LABEL_TOKEN"}},
+		{File: "preview.yml", Job: "notify", Event: "pull_request_target", Level: ForkSecretsAvailable, Inherit: true},
+		{File: "preview.yml", Job: "preview", Event: "pull_request_target", Level: ForkSecretsExposed, Secrets: []string{"DEPLOY_KEY"}, Checkout: "jobs.preview.steps[0].with.ref"},
+	}
+	if got := ForkSecretExposures(workflows); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected exposures:\n got: %+v\nwant: %+v", got, want)
+	}
+}
+
+func TestPullRequestCheckout(t *testing.T) {
+	tests := []struct {
+		yaml string
+		want string
+	}{
+		{"- uses: actions/checkout@v4\n  with:\n    repository: ${{ github.event.pull_request.head.repo.full_name }}\n", "jobs.j.steps[0].with.repository"},
+		{"- uses: actions/checkout@v4\n  with:\n    ref: refs/pull/${{ github.event.number }}/merge\n", "jobs.j.steps[0].with.ref"},
+		{"- run: echo start\n- run: git fetch origin pull/${{ github.event.number }}/head:pr && git checkout pr\n", "jobs.j.steps[1].run"},
+		{"- uses: actions/checkout@v4\n- run: echo ${{ github.head_ref }}\n", ""},
+	}
+	for _, tt := range tests {
+		workflow := mustParse(t, "on: pull_request_target\njobs:\n  j:\n    runs-on: ubuntu-latest\n    steps:\n      "+strings.ReplaceAll(tt.yaml, "\n", "\n      "))
+		if got := pullRequestCheckout("j", workflow.Jobs["j"]); got != tt.want {
+			t.Errorf("pullRequestCheckout(%q) = %q, want %q", tt.yaml, got, tt.want)
+		}
+	}
+}
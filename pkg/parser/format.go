@@ -0,0 +1,178 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// flowSequenceWidth is the maximum width of a sequence of scalars written in
+// flow style, e.g. "branches: [main, develop]"
+const flowSequenceWidth = 60
+
+// canonicalKeyOrder lists the keys of each kind of mapping in the order the
+// GitHub documentation uses. Keys not listed keep their relative order after
+// the listed ones.
+var canonicalKeyOrder = map[string][]string{
+	"root": {
+		"name", "run-name", "author", "description", "branding",
+		"on", "inputs", "outputs", "permissions", "env", "defaults",
+		"concurrency", "runs", "jobs",
+	},
+	"event": {
+		"types", "workflows", "branches", "branches-ignore", "tags", "tags-ignore",
+		"paths", "paths-ignore", "inputs", "outputs", "secrets",
+	},
+	"input":  {"description", "required", "type", "default", "options", "deprecationMessage"},
+	"output": {"description", "value"},
+	"runs": {
+		"using", "pre", "pre-if", "main", "post", "post-if",
+		"image", "pre-entrypoint", "entrypoint", "post-entrypoint", "args", "env", "steps",
+	},
+	"job": {
+		"name", "needs", "if", "runs-on", "environment", "permissions", "concurrency",
+		"outputs", "env", "defaults", "timeout-minutes", "continue-on-error",
+		"strategy", "container", "services", "uses", "with", "secrets", "steps",
+	},
+	"strategy": {"fail-fast", "max-parallel", "matrix"},
+	"step": {
+		"name", "id", "if", "uses", "with", "run", "shell", "working-directory",
+		"env", "continue-on-error", "timeout-minutes",
+	},
+}
+
+// Format re-emits a workflow or action file in canonical style: two-space
+// indentation, keys ordered as in the GitHub documentation, short sequences
+// of scalars in flow style and all other collections in block style, and
+// multi-line strings as literal blocks. Comments are preserved. Job, step,
+// input and event names keep their original order.
+func Format(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, classify(ErrInvalidYAML, fmt.Errorf("failed to unmarshal YAML: %w", err))
+	}
+	if len(doc.Content) == 0 {
+		return []byte{}, nil
+	}
+	formatNode(doc.Content[0], "root", 0)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// formatNode normalizes a node of the given kind in place. indent is the
+// column of the node's key, used to decide whether a flow sequence fits.
+func formatNode(node *yaml.Node, kind string, indent int) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		node.Style &^= yaml.FlowStyle
+		sortMappingKeys(node, canonicalKeyOrder[kind])
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			formatNode(value, childKind(kind, key.Value), indent+2)
+		}
+	case yaml.SequenceNode:
+		item := "sequence"
+		if kind == "steps" {
+			item = "step"
+		}
+		for _, child := range node.Content {
+			formatNode(child, item, indent+2)
+		}
+		if fitsFlowStyle(node, indent) {
+			node.Style |= yaml.FlowStyle
+		} else {
+			node.Style &^= yaml.FlowStyle
+		}
+	case yaml.ScalarNode:
+		if node.Tag == "!!str" && strings.Contains(strings.TrimRight(node.Value, "\n"), "\n") {
+			node.Style = yaml.LiteralStyle
+		}
+	}
+}
+
+// childKind returns the kind of the value of key in a mapping of kind parent
+func childKind(parent, key string) string {
+	switch parent {
+	case "root":
+		switch key {
+		case "on", "jobs", "runs", "inputs", "outputs":
+			return key
+		}
+	case "on":
+		return "event"
+	case "event":
+		switch key {
+		case "inputs", "outputs":
+			return key
+		}
+	case "inputs":
+		return "input"
+	case "outputs":
+		return "output"
+	case "jobs":
+		return "job"
+	case "job":
+		switch key {
+		case "strategy", "steps":
+			return key
+		}
+	case "runs":
+		if key == "steps" {
+			return key
+		}
+	}
+	return ""
+}
+
+// sortMappingKeys stably reorders the key/value pairs of a mapping so that
+// the keys in order come first, in that order
+func sortMappingKeys(node *yaml.Node, order []string) {
+	if len(order) == 0 {
+		return
+	}
+	rank := func(key string) int {
+		for i, k := range order {
+			if k == key {
+				return i
+			}
+		}
+		return len(order)
+	}
+
+	pairs := make([][2]*yaml.Node, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs = append(pairs, [2]*yaml.Node{node.Content[i], node.Content[i+1]})
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return rank(pairs[i][0].Value) < rank(pairs[j][0].Value)
+	})
+	for i, pair := range pairs {
+		node.Content[2*i], node.Content[2*i+1] = pair[0], pair[1]
+	}
+}
+
+// fitsFlowStyle reports whether a sequence holds only single-line scalars
+// without comments that fit on one line in flow style
+func fitsFlowStyle(node *yaml.Node, indent int) bool {
+	width := indent
+	for _, item := range node.Content {
+		if item.Kind != yaml.ScalarNode || strings.Contains(item.Value, "\n") ||
+			item.HeadComment != "" || item.LineComment != "" || item.FootComment != "" {
+			return false
+		}
+		width += len(item.Value) + 2
+	}
+	return width <= flowSequenceWidth
+}
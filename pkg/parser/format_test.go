@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	input := `jobs:
+    test:
+        steps:
+        -   run: |
+                echo a
+                echo b
+            name: Test   # runs the tests
+        -   {uses: actions/checkout@v4, name: Checkout}
+        runs-on: ubuntu-latest
+on:
+    push:
+        branches:
+        - main
+name: CI
+`
+	expected := `name: CI
+on:
+  push:
+    branches: [main]
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - name: Test # runs the tests
+        run: |
+          echo a
+          echo b
+      - name: Checkout
+        uses: actions/checkout@v4
+`
+	got, err := Format([]byte(input))
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if string(got) != expected {
+		t.Errorf("Unexpected output:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestFormatLongSequence(t *testing.T) {
+	input := "on:\n  push:\n    paths: [src/very/long/path/one/**, src/very/long/path/two/**, docs/**]\n"
+	expected := "on:\n  push:\n    paths:\n      - src/very/long/path/one/**\n      - src/very/long/path/two/**\n      - docs/**\n"
+	got, err := Format([]byte(input))
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if string(got) != expected {
+		t.Errorf("Unexpected output:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestFormatIdempotent(t *testing.T) {
+	for _, file := range []string{"testdata/action.yml", "testdata/workflow.yml", "testdata/reusable-workflow.yml"} {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		once, err := Format(data)
+		if err != nil {
+			t.Fatalf("%s: Format failed: %v", file, err)
+		}
+		twice, err := Format(once)
+		if err != nil {
+			t.Fatalf("%s: Format failed: %v", file, err)
+		}
+		if string(once) != string(twice) {
+			t.Errorf("%s: Format is not idempotent:\n%s\n---\n%s", file, once, twice)
+		}
+
+		original, err := Parse(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		formatted, err := Parse(bytes.NewReader(once))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if Fingerprint(original) != Fingerprint(formatted) {
+			t.Errorf("%s: Format changed the document", file)
+		}
+	}
+}
+
+func TestFormatInvalidYAML(t *testing.T) {
+	if _, err := Format([]byte("jobs: [")); !errors.Is(err, ErrInvalidYAML) {
+		t.Errorf("Expected ErrInvalidYAML, got %v", err)
+	}
+}
@@ -0,0 +1,278 @@
+//go:build !js
+
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// This file holds the functions that need the operating system's file
+// system. They are left out of js/wasm builds, see fs_js.go.
+
+// ParseFile parses a GitHub Action YAML file at the specified path
+func ParseFile(path string, opts ...Option) (*ActionFile, error) {
+	return parseFile(path, newOptions(opts))
+}
+
+// parseFile implements ParseFile with options that have already been applied
+func parseFile(path string, o *options) (*ActionFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, classify(ErrNotFound, fmt.Errorf("failed to open file: %w", err))
+		}
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	// Fragments are included relative to the file unless a directory is given
+	if o.includes && o.includeDir == "" {
+		fileOpts := *o
+		fileOpts.includeDir = filepath.Dir(path)
+		o = &fileOpts
+	}
+	return parse(file, o)
+}
+
+// ParseActionFile parses the action at path, see ParseAction
+func ParseActionFile(path string, opts ...Option) (*Action, error) {
+	file, err := ParseFile(path, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return file.AsAction()
+}
+
+// ParseWorkflowFile parses the workflow at path, see ParseWorkflow
+func ParseWorkflowFile(path string, opts ...Option) (*Workflow, error) {
+	file, err := ParseFile(path, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return file.AsWorkflow()
+}
+
+// ParseFileMetadata reads the metadata of the file at path, see ParseMetadata
+func ParseFileMetadata(path string, opts ...Option) (*Metadata, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, classify(ErrNotFound, fmt.Errorf("failed to open file: %w", err))
+		}
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	// Fragments are included relative to the file unless a directory is given
+	opts = append(opts[:len(opts):len(opts)], func(o *options) {
+		if o.includeDir == "" {
+			o.includeDir = filepath.Dir(path)
+		}
+	})
+	return ParseMetadata(file, opts...)
+}
+
+// ParseDir parses all GitHub Action YAML files in a directory recursively,
+// keyed by their path relative to dir.
+//
+// By default the first file that fails to parse aborts the walk. With
+// WithContinueOnError the remaining files are still parsed, and the results
+// are returned together with a *DirError describing every failed file.
+// WithSkipDirs and WithGitignore leave out irrelevant parts of the tree.
+// Disabled workflows, such as ci.yml.off, are left out unless WithDisabled
+// is given.
+func ParseDir(dir string, opts ...Option) (WorkflowSet, error) {
+	o := newOptions(opts)
+	ctx, span := o.tracer.Start(o.ctx, "parser.ParseDir")
+	span.SetAttribute("dir", dir)
+	defer span.End()
+
+	filter := newWalkFilter(dir, o)
+	progress := newProgressTracker(o)
+	var failures []*FileError
+	var files []dirFile
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err := o.ctx.Err(); err != nil {
+			return err
+		}
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				err = classify(ErrNotFound, err)
+			}
+			if o.continueOnError && path != dir {
+				rel := relPath(dir, path)
+				failures = append(failures, &FileError{Path: rel, Err: err})
+				progress.failed(rel, err)
+				return nil
+			}
+			return err
+		}
+		if skip, err := filter.skip(path, entry); err != nil || skip {
+			if err == nil && entry.IsDir() {
+				err = filepath.SkipDir
+			}
+			return err
+		}
+
+		// Only process YAML files
+		if entry.IsDir() {
+			return nil
+		}
+		rel := relPath(dir, path)
+		name, disabled := disabledPath(rel)
+		if ext := filepath.Ext(name); (ext == ".yml" || ext == ".yaml") && (!disabled || o.disabled) {
+			files = append(files, dirFile{path: path, rel: rel, disabled: disabled})
+			progress.discovered(rel, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	}
+
+	result := make(WorkflowSet, len(files))
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to walk directory: %w", err)
+		}
+		_, fileSpan := o.tracer.Start(ctx, "parser.ParseFile")
+		fileSpan.SetAttribute("path", file.path)
+		action, err := parseFile(file.path, o)
+		if err != nil {
+			fileSpan.RecordError(err)
+			fileSpan.End()
+			progress.failed(file.rel, err)
+			if o.continueOnError {
+				failures = append(failures, &FileError{Path: file.rel, Err: err})
+				continue
+			}
+			err = fmt.Errorf("failed to parse %s: %w", file.path, err)
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to walk directory: %w", err)
+		}
+		fileSpan.End()
+		action.Disabled = file.disabled
+		result[file.rel] = action
+		progress.parsed(file.rel, action)
+	}
+
+	span.SetAttribute("files", len(result))
+	if len(failures) > 0 {
+		span.SetAttribute("failures", len(failures))
+		return result, &DirError{Errors: failures}
+	}
+	return result, nil
+}
+
+// dirFile is a file found by the walk of ParseDir
+type dirFile struct {
+	path string
+	// rel is path relative to the walked directory
+	rel      string
+	disabled bool
+}
+
+// disabledSuffixes are appended to the name of a workflow file to park it
+var disabledSuffixes = []string{".disabled", ".off"}
+
+// disabledPath reports whether the file at the relative path rel is
+// disabled, by its name or by being inside a 'disabled' directory, and
+// returns rel without the disabled suffix
+func disabledPath(rel string) (string, bool) {
+	disabled := false
+	for _, suffix := range disabledSuffixes {
+		if strings.HasSuffix(rel, suffix) {
+			rel, disabled = strings.TrimSuffix(rel, suffix), true
+			break
+		}
+	}
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(rel)), "/") {
+		if dir == "disabled" {
+			disabled = true
+		}
+	}
+	return rel, disabled
+}
+
+// relPath returns path relative to dir, falling back to path itself. Paths
+// below dir, as found by walking it, are sliced rather than rebuilt.
+func relPath(dir, path string) string {
+	if dir = filepath.Clean(dir); dir != "." && len(path) > len(dir)+1 && os.IsPathSeparator(path[len(dir)]) &&
+		strings.HasPrefix(path, dir) && filepath.Clean(path) == path {
+		return path[len(dir)+1:]
+	}
+	if rel, err := filepath.Rel(dir, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+// LoadConfig reads the ConfigFileName file at the root of a repository. A
+// missing file yields an empty configuration.
+func LoadConfig(root string) (*Config, error) {
+	file, err := os.Open(filepath.Join(root, ConfigFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config: %w", err)
+	}
+	defer file.Close()
+	return ParseConfig(file)
+}
+
+// LocalResolver resolves local references ("./path") against a repository
+// checkout. References to other repositories fail with ErrNotFound.
+type LocalResolver struct {
+	// Root is the root directory of the repository
+	Root string
+	// Options are passed to ParseFile
+	Options []Option
+}
+
+// Resolve implements Resolver
+func (r LocalResolver) Resolve(uses string) (*ActionFile, error) {
+	if !strings.HasPrefix(uses, "./") {
+		return nil, fmt.Errorf("cannot resolve %s locally: %w", uses, ErrNotFound)
+	}
+
+	path := filepath.Join(r.Root, filepath.FromSlash(uses))
+	if ext := filepath.Ext(path); ext != ".yml" && ext != ".yaml" {
+		// Local actions reference their directory
+		action, err := ParseFile(filepath.Join(path, "action.yml"), r.Options...)
+		if err == nil {
+			return action, nil
+		}
+		path = filepath.Join(path, "action.yaml")
+	}
+	return ParseFile(path, r.Options...)
+}
+
+// ExpandIncludes composes a document from fragment files and returns
+// standard GitHub YAML without any x-include keys, see IncludeKey. Paths are
+// relative to dir for the document, and to the fragment's own directory for
+// nested includes.
+func ExpandIncludes(data []byte, dir string) ([]byte, error) {
+	return expandIncludes(data, dir, func(dir, name string) ([]byte, string, error) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		data, err := os.ReadFile(path)
+		return data, path, err
+	}, filepath.Dir)
+}
+
+// readFile reads the file name, relative to dir
+func readFile(dir, name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+}
+
+// toSlash converts a path returned by ParseDir to slash-separated form
+func toSlash(path string) string {
+	return filepath.ToSlash(path)
+}
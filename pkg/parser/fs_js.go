@@ -0,0 +1,23 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errNoFileSystem is returned on js/wasm, where the parser does not access
+// the file system. Use Parse and ExpandIncludesFS instead.
+var errNoFileSystem = errors.New("file system access is not available on js/wasm")
+
+// ExpandIncludes is not available on js/wasm, see ExpandIncludesFS
+func ExpandIncludes(data []byte, dir string) ([]byte, error) {
+	return nil, fmt.Errorf("failed to expand includes: %w", errNoFileSystem)
+}
+
+func readFile(dir, name string) ([]byte, error) {
+	return nil, errNoFileSystem
+}
+
+func toSlash(path string) string {
+	return path
+}
@@ -0,0 +1,45 @@
+package parser
+
+import (
+	"go/build"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// TestWasmCoreImports checks that the js/wasm build of the package does not
+// depend on the file system or the network
+func TestWasmCoreImports(t *testing.T) {
+	ctx := build.Default
+	ctx.GOOS, ctx.GOARCH = "js", "wasm"
+	pkg, err := ctx.ImportDir(".", 0)
+	if err != nil {
+		t.Fatalf("ImportDir failed: %v", err)
+	}
+	for _, imp := range pkg.Imports {
+		switch imp {
+		case "os", "os/exec", "path/filepath", "net", "net/http":
+			t.Errorf("js/wasm build imports %s", imp)
+		}
+	}
+}
+
+func TestExpandIncludesFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"workflows/ci.yml":    {Data: []byte("jobs:\n  test:\n    x-include: ../fragments/job.yml\n")},
+		"fragments/job.yml":   {Data: []byte("runs-on: ubuntu-latest\nsteps:\n  - x-include: steps.yml\n")},
+		"fragments/steps.yml": {Data: []byte("- run: make test\n")},
+	}
+	data, err := ExpandIncludesFS(fsys, fsys["workflows/ci.yml"].Data, "workflows")
+	if err != nil {
+		t.Fatalf("ExpandIncludesFS failed: %v", err)
+	}
+	action, err := Parse(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	job := action.Jobs["test"]
+	if job.RunsOn != "ubuntu-latest" || len(job.Steps) != 1 || job.Steps[0].Run != "make test" {
+		t.Errorf("Unexpected job: %+v", job)
+	}
+}
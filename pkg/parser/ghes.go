@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ghesEvents maps the events missing from older GitHub Enterprise Server
+// releases to the first release supporting them
+var ghesEvents = map[string]string{
+	"merge_group": "3.12",
+}
+
+// parseGHESVersion parses a GitHub Enterprise Server release such as "3.12"
+func parseGHESVersion(version string) ([2]int, error) {
+	parts := strings.Split(strings.TrimSpace(version), ".")
+	if len(parts) != 2 {
+		return [2]int{}, fmt.Errorf("invalid GitHub Enterprise Server version %q, expected major.minor", version)
+	}
+	var v [2]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return [2]int{}, fmt.Errorf("invalid GitHub Enterprise Server version %q, expected major.minor", version)
+		}
+		v[i] = n
+	}
+	return v, nil
+}
+
+// ghesBefore reports whether release a is older than release b. Invalid
+// versions are never older.
+func ghesBefore(a, b string) bool {
+	va, errA := parseGHESVersion(a)
+	vb, errB := parseGHESVersion(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return va[0] < vb[0] || va[0] == vb[0] && va[1] < vb[1]
+}
+
+// lintGHES reports the triggers of a workflow that the GitHub Enterprise
+// Server release of the project configuration does not support
+func (l *Linter) lintGHES(action *ActionFile) {
+	if l.opts.config == nil || l.opts.config.GHES == "" {
+		return
+	}
+	target := l.opts.config.GHES
+	events := workflowEvents(action)
+	sort.Strings(events)
+	for _, event := range events {
+		if since, ok := ghesEvents[event]; ok && ghesBefore(target, since) {
+			l.addIssue("ghes-compatibility", SeverityError, "on."+event,
+				fmt.Sprintf("Event '%s' requires GitHub Enterprise Server %s or later, but the project targets %s", event, since, target))
+		}
+	}
+}
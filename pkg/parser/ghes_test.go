@@ -0,0 +1,58 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestLintGHESCompatibility(t *testing.T) {
+	workflow := mustParse(t, `
+on:
+  push:
+  merge_group:
+    types: [checks_requested]
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+`)
+
+	tests := []struct {
+		ghes   string
+		issues int
+	}{
+		{"", 0},
+		{"3.10", 1},
+		{"3.12", 0},
+		{"4.0", 0},
+	}
+	for _, tt := range tests {
+		issues := NewLinter(WithConfig(&Config{GHES: tt.ghes})).Lint(workflow)
+		var found []ValidationError
+		for _, issue := range issues {
+			if issue.Rule == "ghes-compatibility" {
+				found = append(found, issue)
+			}
+		}
+		if len(found) != tt.issues {
+			t.Errorf("GHES %q: expected %d issues, got %v", tt.ghes, tt.issues, found)
+		}
+		if len(found) == 1 && found[0].Field != "on.merge_group" {
+			t.Errorf("Expected the merge_group trigger to be reported, got %s", found[0].Field)
+		}
+	}
+}
+
+func TestParseGHESVersion(t *testing.T) {
+	if v, err := parseGHESVersion("3.12"); err != nil || v != [2]int{3, 12} {
+		t.Errorf("parseGHESVersion(3.12) = %v, %v", v, err)
+	}
+	for _, invalid := range []string{"3", "3.x", "3.12.1", "-1.0"} {
+		if _, err := parseGHESVersion(invalid); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+	if !ghesBefore("3.9", "3.12") || ghesBefore("3.12", "3.9") || ghesBefore("3.12", "3.12") {
+		t.Error("Expected releases to be compared numerically")
+	}
+}
@@ -0,0 +1,80 @@
+//go:build !js
+
+package parser
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// walkFilter decides which entries of a directory walk are skipped,
+// according to WithSkipDirs and WithGitignore
+type walkFilter struct {
+	root     string
+	skipDirs map[string]bool
+	ignore   *gitignore
+}
+
+// newWalkFilter creates the filter for a walk of root
+func newWalkFilter(root string, o *options) *walkFilter {
+	f := &walkFilter{root: root, skipDirs: make(map[string]bool, len(o.skipDirs))}
+	for _, name := range o.skipDirs {
+		f.skipDirs[name] = true
+	}
+	if o.gitignore {
+		f.ignore = &gitignore{}
+	}
+	return f
+}
+
+// skip reports whether the entry at path is skipped. The .gitignore file of
+// every directory that is not skipped is loaded for the entries below it.
+func (f *walkFilter) skip(p string, entry fs.DirEntry) (bool, error) {
+	if p == f.root {
+		return false, f.load(p, "")
+	}
+	if entry.IsDir() && f.skipDirs[entry.Name()] {
+		return true, nil
+	}
+	if f.ignore == nil {
+		return false, nil
+	}
+	rel := filepath.ToSlash(relPath(f.root, p))
+	if f.ignore.ignored(rel, entry.IsDir()) {
+		return true, nil
+	}
+	if entry.IsDir() {
+		return false, f.load(p, rel)
+	}
+	return false, nil
+}
+
+// load reads the .gitignore file of the directory dir, whose slash-separated
+// path relative to the root is rel
+func (f *walkFilter) load(dir, rel string) error {
+	if f.ignore == nil {
+		return nil
+	}
+	file, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read .gitignore: %w", err)
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreRule(rel, scanner.Text()); ok {
+			f.ignore.rules = append(f.ignore.rules, rule)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read .gitignore: %w", err)
+	}
+	return nil
+}
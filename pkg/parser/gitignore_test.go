@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGitignoreRules(t *testing.T) {
+	var g gitignore
+	for _, rule := range []struct{ base, line string }{
+		{"", "# comment"},
+		{"", "*.generated.yml"},
+		{"", "build/"},
+		{"", "/fixtures"},
+		{"", "docs/**/examples"},
+		{"", "!keep.generated.yml"},
+		{"pkg", "local.yml"},
+	} {
+		if r, ok := parseIgnoreRule(rule.base, rule.line); ok {
+			g.rules = append(g.rules, r)
+		}
+	}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"ci.generated.yml", false, true},
+		{"a/b/ci.generated.yml", false, true},
+		{"keep.generated.yml", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"src/build", true, true},
+		{"fixtures", true, true},
+		{"src/fixtures", true, false},
+		{"docs/examples", true, true},
+		{"docs/a/b/examples", true, true},
+		{"pkg/local.yml", false, true},
+		{"local.yml", false, false},
+		{"ci.yml", false, false},
+	}
+	for _, tt := range tests {
+		if got := g.ignored(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("ignored(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestParseDirSkipsIgnoredFiles(t *testing.T) {
+	dir := t.TempDir()
+	workflow := "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n"
+	files := map[string]string{
+		".gitignore":                 "generated/\n",
+		"ci.yml":                     workflow,
+		"generated/out.yml":          "{{ not yaml",
+		"node_modules/pkg/conf.yml":  "{{ not yaml",
+		"app/.gitignore":             "*.local.yml\n",
+		"app/release.yml":            workflow,
+		"app/dev.local.yml":          "{{ not yaml",
+		"vendor/github.com/x/ci.yml": workflow,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := ParseDir(dir); err == nil {
+		t.Fatal("ParseDir() without filtering succeeded, want an error for the invalid files")
+	}
+	set, err := ParseDir(dir, WithGitignore(), WithSkipDirs(DefaultSkipDirs...))
+	if err != nil {
+		t.Fatalf("ParseDir() error = %v", err)
+	}
+	var got []string
+	for _, path := range set.Paths() {
+		got = append(got, filepath.ToSlash(path))
+	}
+	if want := []string{"app/release.yml", "ci.yml"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseDir() files = %v, want %v", got, want)
+	}
+}
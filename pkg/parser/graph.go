@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+)
+
+// JobGraph describes the dependencies between the jobs of a workflow
+type JobGraph struct {
+	Nodes []JobNode `json:"nodes"`
+	Edges []JobEdge `json:"edges"`
+}
+
+// JobNode is a single job in a JobGraph
+type JobNode struct {
+	ID    string   `json:"id"`
+	Name  string   `json:"name,omitempty"`
+	Needs []string `json:"needs,omitempty"`
+}
+
+// JobEdge points from a job to a job that needs it
+type JobEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// JobNeeds returns the IDs of the jobs a job depends on. The 'needs' field may
+// be either a single job ID or a list of job IDs.
+func JobNeeds(job Job) []string {
+	switch needs := job.Needs.(type) {
+	case string:
+		return []string{needs}
+	case []string:
+		return append([]string(nil), needs...)
+	case []interface{}:
+		result := make([]string, 0, len(needs))
+		for _, n := range needs {
+			if s, ok := n.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// BuildJobGraph builds the job dependency graph of a workflow
+func BuildJobGraph(action *ActionFile) (*JobGraph, error) {
+	if action.Jobs == nil {
+		return nil, ErrNotAWorkflow
+	}
+
+	graph := &JobGraph{
+		Nodes: make([]JobNode, 0, len(action.Jobs)),
+		Edges: make([]JobEdge, 0),
+	}
+
+	for _, jobID := range sortedJobIDs(action) {
+		job := action.Jobs[jobID]
+		needs := JobNeeds(job)
+		sort.Strings(needs)
+
+		graph.Nodes = append(graph.Nodes, JobNode{
+			ID:    jobID,
+			Name:  job.Name,
+			Needs: needs,
+		})
+		for _, need := range needs {
+			graph.Edges = append(graph.Edges, JobEdge{From: need, To: jobID})
+		}
+	}
+
+	return graph, nil
+}
+
+// Waves groups the jobs of the graph into waves: the first wave holds the
+// jobs without dependencies, and every later wave the jobs whose dependencies
+// all belong to earlier waves. Jobs within a wave are sorted by ID. Needs on
+// unknown jobs are ignored. An error is returned if the graph has a cycle.
+func (g *JobGraph) Waves() ([][]string, error) {
+	known := make(map[string]bool, len(g.Nodes))
+	for _, node := range g.Nodes {
+		known[node.ID] = true
+	}
+
+	done := make(map[string]bool, len(g.Nodes))
+	var waves [][]string
+	for len(done) < len(g.Nodes) {
+		var wave []string
+		for _, node := range g.Nodes {
+			if done[node.ID] {
+				continue
+			}
+			ready := true
+			for _, need := range node.Needs {
+				if known[need] && !done[need] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				wave = append(wave, node.ID)
+			}
+		}
+		if len(wave) == 0 {
+			return waves, fmt.Errorf("job dependency cycle detected")
+		}
+		for _, id := range wave {
+			done[id] = true
+		}
+		waves = append(waves, wave)
+	}
+
+	return waves, nil
+}
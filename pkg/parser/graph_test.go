@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBuildJobGraph(t *testing.T) {
+	workflow, err := ParseFile("testdata/workflow.yml")
+	if err != nil {
+		t.Fatalf("Failed to parse workflow file: %v", err)
+	}
+
+	graph, err := BuildJobGraph(workflow)
+	if err != nil {
+		t.Fatalf("Failed to build job graph: %v", err)
+	}
+	if len(graph.Nodes) != 4 {
+		t.Errorf("Expected 4 nodes, got %d", len(graph.Nodes))
+	}
+
+	expected := map[JobEdge]bool{
+		{From: "lint", To: "test"}:    true,
+		{From: "test", To: "build"}:   true,
+		{From: "build", To: "deploy"}: true,
+	}
+	if len(graph.Edges) != len(expected) {
+		t.Errorf("Expected %d edges, got %d", len(expected), len(graph.Edges))
+	}
+	for _, edge := range graph.Edges {
+		if !expected[edge] {
+			t.Errorf("Unexpected edge %v", edge)
+		}
+	}
+
+	if _, err := BuildJobGraph(&ActionFile{}); err == nil {
+		t.Errorf("Expected error when building graph of a non-workflow")
+	}
+}
+
+func TestJobNeeds(t *testing.T) {
+	tests := []struct {
+		needs    interface{}
+		expected int
+	}{
+		{nil, 0},
+		{"build", 1},
+		{[]interface{}{"build", "test"}, 2},
+		{[]string{"a", "b", "c"}, 3},
+	}
+
+	for _, tt := range tests {
+		if got := JobNeeds(Job{Needs: tt.needs}); len(got) != tt.expected {
+			t.Errorf("JobNeeds(%v) returned %d jobs, expected %d", tt.needs, len(got), tt.expected)
+		}
+	}
+}
+
+func TestJobGraphWaves(t *testing.T) {
+	workflow := &ActionFile{
+		Jobs: map[string]Job{
+			"lint":    {},
+			"unit":    {},
+			"build":   {Needs: []interface{}{"lint", "unit"}},
+			"e2e":     {Needs: "build"},
+			"package": {Needs: "build"},
+			"release": {Needs: []interface{}{"e2e", "package", "missing"}},
+		},
+	}
+
+	graph, err := BuildJobGraph(workflow)
+	if err != nil {
+		t.Fatalf("Failed to build job graph: %v", err)
+	}
+	waves, err := graph.Waves()
+	if err != nil {
+		t.Fatalf("Failed to compute waves: %v", err)
+	}
+
+	expected := [][]string{{"lint", "unit"}, {"build"}, {"e2e", "package"}, {"release"}}
+	if fmt.Sprint(waves) != fmt.Sprint(expected) {
+		t.Errorf("Expected waves %v, got %v", expected, waves)
+	}
+
+	workflow.Jobs["lint"] = Job{Needs: "release"}
+	graph, _ = BuildJobGraph(workflow)
+	if _, err := graph.Waves(); err == nil {
+		t.Errorf("Expected error for a dependency cycle")
+	}
+}
@@ -0,0 +1,72 @@
+package parser
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+)
+
+//go:embed templates/report.html
+var htmlReportTemplate string
+
+// htmlReport is the compiled template of HTMLReport
+var htmlReport = template.Must(template.New("report").Parse(htmlReportTemplate))
+
+// HTMLReport renders findings, job graphs and the dependency inventory as
+// a single self-contained HTML page for sharing audit results: styles are
+// inline and job graphs are drawn as inline SVG, so the page loads nothing
+// else and works offline.
+type HTMLReport struct {
+	// Title defaults to "GitHub Actions report"
+	Title    string
+	Findings []LocatedFinding
+	// JobGraphs are the job graphs of the workflows keyed by path
+	JobGraphs    map[string]*JobGraph
+	Dependencies []Dependency
+}
+
+// htmlJobGraph is a job graph as shown in the page
+type htmlJobGraph struct {
+	File string
+	// SVG is generated with escaped labels
+	SVG template.HTML
+}
+
+// Write writes the page to w
+func (r *HTMLReport) Write(w io.Writer) error {
+	data := struct {
+		Title        string
+		Report       *Report
+		Findings     []LocatedFinding
+		JobGraphs    []htmlJobGraph
+		Dependencies []Dependency
+	}{Title: r.Title, Report: &Report{}, Findings: r.Findings, Dependencies: r.Dependencies}
+	if data.Title == "" {
+		data.Title = "GitHub Actions report"
+	}
+	for _, f := range r.Findings {
+		data.Report.Add(f.ValidationError)
+	}
+	for _, file := range sortedGraphFiles(r.JobGraphs) {
+		if graph := r.JobGraphs[file]; graph != nil && len(graph.Nodes) > 0 {
+			data.JobGraphs = append(data.JobGraphs, htmlJobGraph{File: file, SVG: template.HTML(graph.view().svg())})
+		}
+	}
+
+	if err := htmlReport.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// sortedGraphFiles returns the keys of graphs in sorted order
+func sortedGraphFiles(graphs map[string]*JobGraph) []string {
+	files := make([]string, 0, len(graphs))
+	for file := range graphs {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files
+}
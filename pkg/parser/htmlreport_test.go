@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestHTMLReport(t *testing.T) {
+	workflow := mustParse(t, `
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+  test:
+    needs: build
+    runs-on: ubuntu-latest
+    steps:
+      - run: make test
+`)
+	graph, err := BuildJobGraph(workflow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := &HTMLReport{
+		Findings: []LocatedFinding{
+			{File: "ci.yml", Line: 4, ValidationError: ValidationError{Field: "jobs.build", Message: "<script>alert(1)</script>", Severity: SeverityError, Rule: "rule"}},
+		},
+		JobGraphs:    map[string]*JobGraph{"ci.yml": graph},
+		Dependencies: Dependencies(WorkflowSet{"ci.yml": workflow}),
+	}
+	var buf bytes.Buffer
+	if err := report.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"<title>GitHub Actions report</title>",
+		"1 error, 0 warnings, 0 infos",
+		"&lt;script&gt;alert(1)&lt;/script&gt;",
+		`<div class="graph"><svg xmlns="http://www.w3.org/2000/svg"`,
+		`<text x="8" y="20">build</text>`,
+		`<text x="107" y="20">test</text>`,
+		"<code>actions/checkout@v4</code>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected report to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "<script") || strings.Contains(out, "https://") {
+		t.Error("The report must not run scripts or load resources")
+	}
+}
+
+func TestGraphViewSVG(t *testing.T) {
+	v := graphView{
+		nodes: []viewNode{{id: "a", label: "a\n<Build>"}, {id: "b", label: "b"}, {id: "c", label: "c"}},
+		edges: []viewEdge{{from: "a", to: "b"}, {from: "b", to: "c"}, {from: "c", to: "b"}, {from: "a", to: "missing"}},
+	}
+	out := v.svg()
+	if strings.Count(out, "<rect") != 3 || strings.Count(out, "<path") != 3 {
+		t.Errorf("Expected 3 nodes and 3 edges, got:\n%s", out)
+	}
+	if !strings.Contains(out, "&lt;Build&gt;") || strings.Contains(out, "<Build>") {
+		t.Errorf("Expected escaped labels, got:\n%s", out)
+	}
+}
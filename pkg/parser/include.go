@@ -0,0 +1,225 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// IncludeKey is the key that pulls fragment files into a document.
+//
+// In a mapping, 'x-include: path' (or a list of paths) merges the keys of
+// the fragment mappings into the mapping; keys of the mapping itself take
+// precedence, as do later fragments over earlier ones. A sequence item
+// consisting only of 'x-include' is replaced by the items of the fragment
+// sequences, e.g. to share steps between jobs. Paths are relative to the
+// including file.
+const IncludeKey = "x-include"
+
+// expandIncludes implements ExpandIncludes and ExpandIncludesFS, reading
+// fragments with read and resolving nested includes relative to dirOf the
+// fragment's path
+func expandIncludes(data []byte, dir string, read fragmentLoader, dirOf func(string) string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, classify(ErrInvalidYAML, fmt.Errorf("failed to unmarshal YAML: %w", err))
+	}
+	if len(doc.Content) == 0 {
+		return data, nil
+	}
+
+	in := &includer{read: read, dirOf: dirOf}
+	if err := in.expand(doc.Content[0], dir); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ExpandIncludesFS is like ExpandIncludes but reads fragments from fsys, in
+// which dir and the fragment paths are slash-separated
+func ExpandIncludesFS(fsys fs.FS, data []byte, dir string) ([]byte, error) {
+	return expandIncludes(data, dir, func(dir, name string) ([]byte, string, error) {
+		p := path.Join(dir, name)
+		data, err := fs.ReadFile(fsys, p)
+		return data, p, err
+	}, path.Dir)
+}
+
+// fragmentLoader reads the fragment name relative to dir and returns its
+// content and path
+type fragmentLoader func(dir, name string) ([]byte, string, error)
+
+// includer expands includes, tracking the chain of fragments being loaded to
+// detect cycles
+type includer struct {
+	read  fragmentLoader
+	dirOf func(string) string
+	chain []string
+}
+
+// expand resolves the includes in node and its children
+func (in *includer) expand(node *yaml.Node, dir string) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return in.expandMapping(node, dir)
+	case yaml.SequenceNode:
+		var items []*yaml.Node
+		for _, item := range node.Content {
+			if item.Kind == yaml.MappingNode && len(item.Content) == 2 && item.Content[0].Value == IncludeKey {
+				fragments, err := in.loadAll(item.Content[1], dir)
+				if err != nil {
+					return err
+				}
+				if fragments[0].Kind == yaml.SequenceNode {
+					for _, fragment := range fragments {
+						if fragment.Kind != yaml.SequenceNode {
+							return fmt.Errorf("failed to include fragment at line %d: cannot mix sequence and mapping fragments", item.Line)
+						}
+						items = append(items, fragment.Content...)
+					}
+					continue
+				}
+			}
+			if err := in.expand(item, dir); err != nil {
+				return err
+			}
+			items = append(items, item)
+		}
+		node.Content = items
+	}
+	return nil
+}
+
+// expandMapping merges the fragments included by a mapping into it
+func (in *includer) expandMapping(node *yaml.Node, dir string) error {
+	at := -1
+	var fragments []*yaml.Node
+	own := make(map[string]bool)
+	var content []*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Value == IncludeKey {
+			loaded, err := in.loadAll(value, dir)
+			if err != nil {
+				return err
+			}
+			for _, fragment := range loaded {
+				if fragment.Kind != yaml.MappingNode {
+					return fmt.Errorf("failed to include fragment at line %d: expected a mapping", key.Line)
+				}
+			}
+			at = len(content)
+			fragments = loaded
+			continue
+		}
+		if err := in.expand(value, dir); err != nil {
+			return err
+		}
+		own[key.Value] = true
+		content = append(content, key, value)
+	}
+	if at < 0 {
+		return nil
+	}
+
+	// Later fragments override earlier ones, the mapping overrides both
+	var included []*yaml.Node
+	index := make(map[string]int)
+	for _, fragment := range fragments {
+		for i := 0; i+1 < len(fragment.Content); i += 2 {
+			key, value := fragment.Content[i], fragment.Content[i+1]
+			if own[key.Value] {
+				continue
+			}
+			if j, ok := index[key.Value]; ok {
+				included[j+1] = value
+				continue
+			}
+			index[key.Value] = len(included)
+			included = append(included, key, value)
+		}
+	}
+
+	merged := make([]*yaml.Node, 0, len(content)+len(included))
+	merged = append(merged, content[:at]...)
+	merged = append(merged, included...)
+	merged = append(merged, content[at:]...)
+	node.Content = merged
+	return nil
+}
+
+// loadAll loads the fragments named by the value of an x-include key
+func (in *includer) loadAll(value *yaml.Node, dir string) ([]*yaml.Node, error) {
+	var paths []string
+	switch value.Kind {
+	case yaml.ScalarNode:
+		paths = []string{value.Value}
+	case yaml.SequenceNode:
+		for _, item := range value.Content {
+			if item.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("failed to include fragment at line %d: expected a path", item.Line)
+			}
+			paths = append(paths, item.Value)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("failed to include fragment at line %d: expected a path or a list of paths", value.Line)
+	}
+
+	fragments := make([]*yaml.Node, 0, len(paths))
+	for _, p := range paths {
+		fragment, err := in.load(dir, p)
+		if err != nil {
+			return nil, err
+		}
+		fragments = append(fragments, fragment)
+	}
+	return fragments, nil
+}
+
+// load reads a fragment file and expands its own includes
+func (in *includer) load(dir, name string) (*yaml.Node, error) {
+	data, file, err := in.read(dir, name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, classify(ErrNotFound, fmt.Errorf("failed to include %s: %w", file, err))
+		}
+		return nil, fmt.Errorf("failed to include %s: %w", file, err)
+	}
+	for _, p := range in.chain {
+		if p == file {
+			return nil, fmt.Errorf("include cycle: %s -> %s", strings.Join(in.chain, " -> "), file)
+		}
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, classify(ErrInvalidYAML, fmt.Errorf("failed to include %s: %w", file, err))
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("failed to include %s: fragment is empty", file)
+	}
+
+	in.chain = append(in.chain, file)
+	defer func() { in.chain = in.chain[:len(in.chain)-1] }()
+	fragment := doc.Content[0]
+	if err := in.expand(fragment, in.dirOf(file)); err != nil {
+		return nil, err
+	}
+	return fragment, nil
+}
@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles creates files relative to dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestParseFileWithIncludes(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"workflows/ci.yml": `name: CI
+on: push
+jobs:
+  test:
+    x-include: ../fragments/job.yml
+    timeout-minutes: 30
+    steps:
+      - x-include: ../fragments/setup.yml
+      - run: go test ./...
+`,
+		"fragments/job.yml": `runs-on: ubuntu-latest
+timeout-minutes: 10
+`,
+		"fragments/setup.yml": `- uses: actions/checkout@v4
+- x-include: go.yml
+`,
+		"fragments/go.yml": `- uses: actions/setup-go@v5
+  with:
+    go-version: stable
+`,
+	})
+
+	action, err := ParseFile(filepath.Join(dir, "workflows/ci.yml"), WithIncludes(""), WithStrict())
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	job := action.Jobs["test"]
+	if job.RunsOn != "ubuntu-latest" {
+		t.Errorf("Expected runs-on from fragment, got %v", job.RunsOn)
+	}
+	if job.TimeoutMin != 30 {
+		t.Errorf("Expected the job's own timeout to win, got %v", job.TimeoutMin)
+	}
+	var uses []string
+	for _, step := range job.Steps {
+		uses = append(uses, step.Uses+step.Run)
+	}
+	if strings.Join(uses, ",") != "actions/checkout@v4,actions/setup-go@v5,go test ./..." {
+		t.Errorf("Unexpected steps: %v", uses)
+	}
+}
+
+func TestExpandIncludesErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.yml":     "x-include: b.yml\n",
+		"b.yml":     "x-include: a.yml\n",
+		"steps.yml": "- run: echo\n",
+	})
+
+	if _, err := ExpandIncludes([]byte("x-include: a.yml\n"), dir); err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Errorf("Expected include cycle error, got %v", err)
+	}
+	if _, err := ExpandIncludes([]byte("x-include: missing.yml\n"), dir); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if _, err := ExpandIncludes([]byte("jobs:\n  x-include: steps.yml\n"), dir); err == nil {
+		t.Errorf("Expected error for sequence fragment in a mapping")
+	}
+}
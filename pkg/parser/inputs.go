@@ -0,0 +1,138 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// inputTypes lists the input types each event supports
+var inputTypes = map[string][]string{
+	"workflow_call":     {"boolean", "number", "string"},
+	"workflow_dispatch": {"boolean", "number", "string", "choice", "environment"},
+}
+
+// validateInputTypes checks that typed workflow_call and workflow_dispatch
+// inputs declare a supported type and a default of that type
+func (v *Validator) validateInputTypes(action *ActionFile) {
+	for _, event := range []string{"workflow_call", "workflow_dispatch"} {
+		config, ok, err := triggerConfig(action, event)
+		if err != nil || !ok {
+			continue
+		}
+		inputs, err := MapOfStringInterface(config["inputs"])
+		if err != nil {
+			v.addError("input-definition", fmt.Sprintf("on.%s.inputs", event), err.Error())
+			continue
+		}
+
+		names := make([]string, 0, len(inputs))
+		for name := range inputs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			field := fmt.Sprintf("on.%s.inputs.%s", event, name)
+			def, err := MapOfStringInterface(inputs[name])
+			if err != nil {
+				v.addError("input-definition", field, err.Error())
+				continue
+			}
+
+			typ, _ := def["type"].(string)
+			if typ == "" {
+				if event == "workflow_call" {
+					v.addError("input-type", field+".type", "Reusable workflow inputs must specify a type")
+				}
+				continue
+			}
+			if !containsString(inputTypes[event], typ) {
+				v.addError("input-type", field+".type", fmt.Sprintf("Unsupported input type '%s', expected one of %s", typ, strings.Join(inputTypes[event], ", ")))
+				continue
+			}
+
+			if value, ok := def["default"]; ok {
+				if problem := checkInputValue(typ, value); problem != "" {
+					v.addError("input-default-type", field+".default", "Default "+problem)
+				}
+			}
+
+			if typ == "choice" {
+				options, err := stringList(def["options"])
+				if err != nil || len(options) == 0 {
+					v.addError("choice-options", field+".options", "Choice inputs must list their options")
+				} else if value, ok := def["default"].(string); ok && !containsString(options, value) {
+					v.addError("choice-options", field+".default", fmt.Sprintf("Default '%s' is not one of the options", value))
+				}
+			}
+		}
+	}
+}
+
+// validateCallInputs checks the literal values a job passes to a reusable
+// workflow against the types of the workflow's inputs
+func (v *Validator) validateCallInputs(jobID string, job Job, called *ActionFile) {
+	inputs, err := ExtractInputsFromWorkflowCall(called)
+	if err != nil {
+		return
+	}
+	for _, name := range sortedWithKeys(job.With) {
+		input, ok := inputs[name]
+		if !ok || input.Type == "" {
+			continue
+		}
+		if problem := checkInputValue(input.Type, job.With[name]); problem != "" {
+			v.addError("call-input-type", fmt.Sprintf("jobs.%s.with.%s", jobID, name), fmt.Sprintf("Value for input '%s' %s", name, problem))
+		}
+	}
+}
+
+// checkInputValue describes why a literal value does not fit an input type,
+// or returns "" if it does. Values containing expressions are not checked.
+func checkInputValue(typ string, value interface{}) string {
+	s, isString := value.(string)
+	if isString && len(ExtractExpressions(s)) > 0 {
+		return ""
+	}
+
+	switch typ {
+	case "boolean":
+		if _, ok := value.(bool); ok {
+			return ""
+		}
+		if isString {
+			if _, err := strconv.ParseBool(s); err == nil {
+				return fmt.Sprintf("must be a boolean, not the string %q; remove the quotes", s)
+			}
+		}
+		return fmt.Sprintf("must be a boolean, got %v", value)
+	case "number":
+		switch value.(type) {
+		case int, float64:
+			return ""
+		}
+		if isString {
+			if _, err := strconv.ParseFloat(s, 64); err == nil {
+				return fmt.Sprintf("must be a number, not the string %q; remove the quotes", s)
+			}
+		}
+		return fmt.Sprintf("must be a number, got %v", value)
+	case "string", "choice", "environment":
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			return fmt.Sprintf("must be a string, got %v", value)
+		}
+	}
+	return ""
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
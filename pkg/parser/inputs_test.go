@@ -0,0 +1,118 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateInputDefaultTypes(t *testing.T) {
+	workflow := mustParse(t, `
+on:
+  workflow_call:
+    inputs:
+      dry-run:
+        type: boolean
+        default: "true"
+      retries:
+        type: number
+        default: "3"
+      verbose:
+        type: boolean
+        default: false
+      untyped:
+        default: x
+  workflow_dispatch:
+    inputs:
+      level:
+        type: choice
+        options: [debug, info]
+        default: trace
+      region:
+        type: region
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+`)
+
+	messages := make(map[string]string)
+	for _, e := range NewValidator().Validate(workflow) {
+		messages[e.Field] = e.Message
+	}
+
+	if !strings.Contains(messages["on.workflow_call.inputs.dry-run.default"], "remove the quotes") {
+		t.Errorf("Expected the quoted boolean to be reported, got %v", messages)
+	}
+	if !strings.Contains(messages["on.workflow_call.inputs.retries.default"], "must be a number") {
+		t.Errorf("Expected the quoted number to be reported, got %v", messages)
+	}
+	if _, ok := messages["on.workflow_call.inputs.verbose.default"]; ok {
+		t.Errorf("Expected a boolean default to be accepted")
+	}
+	if _, ok := messages["on.workflow_call.inputs.untyped.type"]; !ok {
+		t.Errorf("Expected a missing type to be reported, got %v", messages)
+	}
+	if _, ok := messages["on.workflow_dispatch.inputs.level.default"]; !ok {
+		t.Errorf("Expected a default outside the options to be reported, got %v", messages)
+	}
+	if _, ok := messages["on.workflow_dispatch.inputs.region.type"]; !ok {
+		t.Errorf("Expected an unsupported type to be reported, got %v", messages)
+	}
+	if len(messages) != 5 {
+		t.Errorf("Expected 5 errors, got %v", messages)
+	}
+}
+
+func TestValidateCallInputTypes(t *testing.T) {
+	resolver := mapResolver{"./.github/workflows/build.yml": mustParse(t, `
+on:
+  workflow_call:
+    inputs:
+      dry-run:
+        type: boolean
+      retries:
+        type: number
+      name:
+        type: string
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+`)}
+
+	workflow := mustParse(t, `
+on: push
+jobs:
+  call:
+    uses: ./.github/workflows/build.yml
+    with:
+      dry-run: "false"
+      retries: ${{ github.run_attempt }}
+      name: 42
+`)
+
+	errs := NewValidator(WithResolver(resolver)).Validate(workflow)
+	if len(errs) != 1 || errs[0].Field != "jobs.call.with.dry-run" {
+		t.Errorf("Expected only the quoted boolean to be reported, got %v", errs)
+	}
+}
+
+func TestExtractInputsFromWorkflowCallTypes(t *testing.T) {
+	workflow := mustParse(t, `
+on:
+  workflow_call:
+    inputs:
+      dry-run:
+        type: boolean
+        default: true
+`)
+	inputs, err := ExtractInputsFromWorkflowCall(workflow)
+	if err != nil {
+		t.Fatalf("Failed to extract inputs: %v", err)
+	}
+	if inputs["dry-run"].Type != "boolean" || inputs["dry-run"].Default != "true" {
+		t.Errorf("Expected type and stringified default, got %+v", inputs["dry-run"])
+	}
+}
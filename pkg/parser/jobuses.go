@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ValidateWorkflowRef checks the 'uses' value of a job calling a reusable
+// workflow. GitHub accepts two forms: './.github/workflows/<file>' for a
+// workflow of the same repository, and
+// '<owner>/<repo>/.github/workflows/<file>@<ref>' for another repository,
+// where the file has a .yml or .yaml extension. Expressions are not
+// evaluated in job-level 'uses'.
+func ValidateWorkflowRef(uses string) error {
+	if strings.Contains(uses, "${{") {
+		return fmt.Errorf("reusable workflow reference '%s' cannot contain expressions", uses)
+	}
+	var file string
+	if strings.HasPrefix(uses, "./") {
+		if strings.Contains(uses, "@") {
+			return fmt.Errorf("local reusable workflow '%s' cannot have a ref; it runs from the same commit as the caller", uses)
+		}
+		file = strings.TrimPrefix(uses, "./")
+	} else {
+		ref, ok := ParseActionRef(uses)
+		if !ok {
+			return fmt.Errorf("invalid reusable workflow reference '%s', expected ./.github/workflows/<file> or <owner>/<repo>/.github/workflows/<file>@<ref>", uses)
+		}
+		file = ref.Path
+	}
+	if path.Dir(file) != ".github/workflows" {
+		return fmt.Errorf("reusable workflow '%s' must be a file directly in .github/workflows", uses)
+	}
+	if ext := path.Ext(file); ext != ".yml" && ext != ".yaml" {
+		return fmt.Errorf("reusable workflow '%s' must be a .yml or .yaml file", uses)
+	}
+	return nil
+}
+
+// callerOnlyKeys returns the keys of a job that cannot be combined with
+// 'uses', since the called workflow defines its own jobs
+func callerOnlyKeys(job Job) []string {
+	var keys []string
+	set := map[string]bool{
+		"runs-on":           job.RunsOn != nil,
+		"container":         job.Container != nil,
+		"services":          job.Services != nil,
+		"outputs":           job.Outputs != nil,
+		"env":               job.Env != nil,
+		"defaults":          job.Defaults != nil,
+		"steps":             job.Steps != nil,
+		"timeout-minutes":   job.TimeoutMin != 0,
+		"continue-on-error": job.ContinueOn != nil,
+		"environment":       job.Environment != nil,
+	}
+	for _, key := range sortedSet(set) {
+		if set[key] {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// validateJobUses validates the reusable workflow a job calls
+func (v *Validator) validateJobUses(jobID string, job Job) {
+	if job.Uses == "" {
+		return
+	}
+	if err := ValidateWorkflowRef(job.Uses); err != nil {
+		v.addError("job-uses", fmt.Sprintf("jobs.%s.uses", jobID), err.Error())
+	}
+	for _, key := range callerOnlyKeys(job) {
+		v.addError("job-uses", fmt.Sprintf("jobs.%s.%s", jobID, key),
+			fmt.Sprintf("'%s' cannot be used in a job calling a reusable workflow", key))
+	}
+}
@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateWorkflowRef(t *testing.T) {
+	valid := []string{
+		"./.github/workflows/test.yml",
+		"./.github/workflows/test.yaml",
+		"octo/ci/.github/workflows/test.yml@v1",
+		"octo/ci/.github/workflows/test.yml@0123456789abcdef0123456789abcdef01234567",
+	}
+	for _, uses := range valid {
+		if err := ValidateWorkflowRef(uses); err != nil {
+			t.Errorf("ValidateWorkflowRef(%q) error = %v", uses, err)
+		}
+	}
+	invalid := []string{
+		"./.github/workflows/test.yml@main",
+		"./workflows/test.yml",
+		"./.github/workflows/ci/test.yml",
+		"octo/ci/.github/workflows/test.yml",
+		"octo/ci/.github/workflows/test.json@v1",
+		"octo/ci@v1",
+		"octo/ci/.github/workflows/${{ inputs.file }}@v1",
+	}
+	for _, uses := range invalid {
+		if err := ValidateWorkflowRef(uses); err == nil {
+			t.Errorf("ValidateWorkflowRef(%q) expected an error", uses)
+		}
+	}
+}
+
+func TestValidateJobUses(t *testing.T) {
+	workflow := mustParse(t, `
+on: push
+jobs:
+  test:
+    uses: octo/ci/.github/workflows/test.yml@v1
+    runs-on: ubuntu-latest
+    env:
+      CI: true
+    steps:
+      - run: make
+    with:
+      node: 20
+  lint:
+    uses: octo/ci/lint.yml@v1
+`)
+	var fields []string
+	for _, e := range NewValidator().Validate(workflow) {
+		if e.Rule == "job-uses" {
+			fields = append(fields, e.Field)
+		}
+	}
+	want := []string{"jobs.lint.uses", "jobs.test.env", "jobs.test.runs-on", "jobs.test.steps"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("Expected job-uses findings for %v, got %v", want, fields)
+	}
+}
@@ -0,0 +1,87 @@
+package parser
+
+import (
+	_ "embed"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed knowledge/actions.yml
+var builtinActionsYAML []byte
+
+var (
+	builtinActionsOnce sync.Once
+	// builtinActions maps lower-cased owner/repo names to the embedded
+	// schemas by major version, e.g. "v4"
+	builtinActions map[string]map[string]*ActionFile
+)
+
+// majorVersionPattern matches version refs such as v4, v4.1.2 or 4
+var majorVersionPattern = regexp.MustCompile(`^v?(\d+)(?:\.\d+)*$`)
+
+// BuiltinResolver resolves popular first-party actions such as
+// actions/checkout and actions/cache from an embedded copy of their inputs,
+// so that 'with' inputs can be checked without network access. Version refs
+// must match a major version the embedded schemas describe; other refs, such
+// as commit SHAs, are assumed to be the latest one.
+type BuiltinResolver struct{}
+
+// Resolve implements Resolver. The returned action is a copy that callers
+// may modify.
+func (BuiltinResolver) Resolve(uses string) (*ActionFile, error) {
+	builtinActionsOnce.Do(loadBuiltinActions)
+
+	ref, ok := ParseActionRef(uses)
+	if !ok || ref.Path != "" {
+		return nil, fmt.Errorf("%s is not a built-in action: %w", uses, ErrNotFound)
+	}
+	name := strings.ToLower(ref.Owner + "/" + ref.Repo)
+	major := builtinLatest()[name]
+	if m := majorVersionPattern.FindStringSubmatch(ref.Ref); m != nil {
+		major = "v" + m[1]
+	}
+	action, ok := builtinActions[name][major]
+	if !ok {
+		return nil, fmt.Errorf("%s is not a built-in action: %w", uses, ErrNotFound)
+	}
+	return copyBuiltinAction(action), nil
+}
+
+// copyBuiltinAction copies an embedded schema so callers cannot modify the
+// shared one through its maps
+func copyBuiltinAction(action *ActionFile) *ActionFile {
+	copied := *action
+	if action.Inputs != nil {
+		copied.Inputs = make(map[string]Input, len(action.Inputs))
+		for name, input := range action.Inputs {
+			copied.Inputs[name] = input
+		}
+	}
+	if action.Outputs != nil {
+		copied.Outputs = make(map[string]Output, len(action.Outputs))
+		for name, output := range action.Outputs {
+			copied.Outputs[name] = output
+		}
+	}
+	return &copied
+}
+
+func loadBuiltinActions() {
+	var schemas map[string]*ActionFile
+	if err := yaml.Unmarshal(builtinActionsYAML, &schemas); err != nil {
+		panic(fmt.Sprintf("invalid built-in action schemas: %v", err))
+	}
+	builtinActions = make(map[string]map[string]*ActionFile)
+	for key, action := range schemas {
+		repo, major, _ := strings.Cut(key, "@")
+		repo = strings.ToLower(repo)
+		if builtinActions[repo] == nil {
+			builtinActions[repo] = make(map[string]*ActionFile)
+		}
+		builtinActions[repo][major] = action
+	}
+}
@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBuiltinResolver(t *testing.T) {
+	for _, uses := range []string{"actions/checkout@v4", "actions/checkout@v4.1.7", "actions/checkout@b4ffde65f46336ab88eb53be808477a3936bae11"} {
+		action, err := BuiltinResolver{}.Resolve(uses)
+		if err != nil {
+			t.Errorf("Resolve(%q) failed: %v", uses, err)
+			continue
+		}
+		if _, ok := action.Inputs["fetch-depth"]; !ok {
+			t.Errorf("Resolve(%q): expected fetch-depth input", uses)
+		}
+	}
+	for _, uses := range []string{"actions/checkout@v3", "actions/unknown@v1", "./local", "github/codeql-action/init@v3"} {
+		if _, err := (BuiltinResolver{}).Resolve(uses); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Resolve(%q): expected ErrNotFound, got %v", uses, err)
+		}
+	}
+}
+
+func TestBuiltinResolverVersions(t *testing.T) {
+	builtinActionsOnce.Do(loadBuiltinActions)
+	builtinActions["octo/multi"] = map[string]*ActionFile{"v1": {Name: "v1"}, "v2": {Name: "v2"}}
+	t.Cleanup(func() { delete(builtinActions, "octo/multi") })
+	for _, major := range []string{"v1", "v2"} {
+		action, err := BuiltinResolver{}.Resolve("octo/multi@" + major + ".0.1")
+		if err != nil || action.Name != major {
+			t.Errorf("Expected the %s schema, got %+v, %v", major, action, err)
+		}
+	}
+
+	action, err := BuiltinResolver{}.Resolve("actions/checkout@v4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	delete(action.Inputs, "fetch-depth")
+	if again, _ := (BuiltinResolver{}).Resolve("actions/checkout@v4"); again.Inputs["fetch-depth"].Description == "" {
+		t.Error("Modifying a resolved action must not change the embedded schema")
+	}
+}
+
+func TestLintStepInputs(t *testing.T) {
+	workflow := mustParse(t, `
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+        with:
+          fetch_depth: 0
+      - uses: actions/cache@v4
+        with:
+          path: ~/.cache
+          save-always: true
+      - uses: actions/upload-artifact@v4
+        with:
+          name: dist
+`)
+	issues := NewLinter().Lint(workflow)
+
+	expected := map[string]string{
+		"jobs.build.steps[0].with.fetch_depth": "unknown-input",
+		"jobs.build.steps[1].with":             "missing-required-input",
+		"jobs.build.steps[1].with.save-always": "deprecated-input",
+		"jobs.build.steps[2].with":             "missing-required-input",
+	}
+	found := make(map[string]string)
+	for _, issue := range issues {
+		found[issue.Field] = issue.Rule
+	}
+	for field, rule := range expected {
+		if found[field] != rule {
+			t.Errorf("Expected %s at %s, got issues %+v", rule, field, issues)
+		}
+	}
+	if len(issues) != len(expected) {
+		t.Errorf("Expected %d issues, got %+v", len(expected), issues)
+	}
+}
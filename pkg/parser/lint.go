@@ -0,0 +1,272 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// deprecatedCommands maps workflow commands GitHub has disabled or deprecated
+// to the environment file that replaces them
+var deprecatedCommands = map[string]string{
+	"::set-output": "$GITHUB_OUTPUT",
+	"::save-state": "$GITHUB_STATE",
+	"::set-env":    "$GITHUB_ENV",
+	"::add-path":   "$GITHUB_PATH",
+}
+
+// Linter checks an ActionFile for practices that GitHub accepts but which are
+// likely to cause problems. Unlike the Validator, its findings are warnings.
+type Linter struct {
+	issues []ValidationError
+	opts   *options
+
+	// resolved caches the actions loaded through the resolver during Lint
+	resolved map[string]*ActionFile
+}
+
+// NewLinter creates a new Linter. Rules inspecting the actions used by steps
+// run for the popular actions known to BuiltinResolver, and for any action
+// the resolver configured with WithResolver can load.
+func NewLinter(opts ...Option) *Linter {
+	return &Linter{
+		issues: make([]ValidationError, 0),
+		opts:   newOptions(opts),
+	}
+}
+
+// Lint checks an ActionFile and returns the issues found
+func (l *Linter) Lint(action *ActionFile) []ValidationError {
+	if l.opts == nil {
+		l.opts = newOptions(nil)
+	}
+	l.issues = append(make([]ValidationError, 0), action.Diagnostics...)
+	l.resolved = make(map[string]*ActionFile)
+
+	for i, step := range action.Runs.Steps {
+		l.lintStep(fmt.Sprintf("runs.steps[%d]", i), step)
+	}
+	if len(action.Runs.Steps) > 0 {
+		outputs := make(map[string]string)
+		for name, output := range action.Outputs {
+			outputs["outputs."+name] = output.Value
+		}
+		l.lintStepOutputReferences("runs.steps", action.Runs.Steps, outputs)
+		l.lintCacheSteps("runs.steps", action.Runs.Steps)
+	}
+
+	for _, jobID := range sortedJobIDs(action) {
+		job := action.Jobs[jobID]
+		if job.Uses != "" {
+			l.lintPinning(fmt.Sprintf("jobs.%s.uses", jobID), job.Uses)
+		}
+		l.lintRunners(jobID, job)
+		l.lintCondition(fmt.Sprintf("jobs.%s.if", jobID), job.If)
+		for i, step := range job.Steps {
+			l.lintStep(fmt.Sprintf("jobs.%s.steps[%d]", jobID, i), step)
+		}
+		outputs := make(map[string]string)
+		for name, value := range job.Outputs {
+			outputs[fmt.Sprintf("jobs.%s.outputs.%s", jobID, name)] = value
+		}
+		l.lintStepOutputReferences(fmt.Sprintf("jobs.%s.steps", jobID), job.Steps, outputs)
+		l.lintCacheSteps(fmt.Sprintf("jobs.%s.steps", jobID), job.Steps)
+		l.lintMatrixReferences(jobID, job)
+		l.lintMatrixStrategy(jobID, job)
+		l.lintMatrixExcludes(jobID, job)
+	}
+
+	l.lintEnvReferences(action)
+	l.lintEnvShadowing(action)
+	l.lintContinueOnError(action)
+	l.lintTimeouts(action)
+	l.lintGHES(action)
+
+	return l.opts.config.Apply(l.issues)
+}
+
+// lintStep runs the step-level rules
+func (l *Linter) lintStep(field string, step Step) {
+	l.lintCondition(field+".if", step.If)
+	if step.Run != "" {
+		for _, command := range sortedKeys(deprecatedCommands) {
+			if strings.Contains(step.Run, command) {
+				l.addIssue("deprecated-command", SeverityWarning, field+".run",
+					fmt.Sprintf("The '%s' workflow command is deprecated, write to %s instead", command, deprecatedCommands[command]))
+			}
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(step.Uses, "docker://"):
+		// Images take no inputs besides args and entrypoint
+		l.lintDockerImage(field+".uses", step.Uses)
+	case step.Uses != "":
+		l.lintPinning(field+".uses", step.Uses)
+		l.lintStepInputs(field, step)
+	}
+}
+
+// lintPinning applies the pinning policy to references to actions or
+// reusable workflows of other repositories: they must name a ref, and the
+// ref should not be a moving branch
+func (l *Linter) lintPinning(field, uses string) {
+	if strings.HasPrefix(uses, "./") || strings.HasPrefix(uses, "docker://") {
+		return
+	}
+	kind := "Action"
+	if strings.Contains(uses, "/.github/workflows/") {
+		kind = "Reusable workflow"
+	}
+	if !strings.Contains(uses, "@") {
+		l.addIssue("missing-action-ref", SeverityWarning, field,
+			fmt.Sprintf("%s '%s' is not pinned to a ref", kind, uses))
+		return
+	}
+
+	ref, ok := ParseActionRef(uses)
+	if !ok {
+		return
+	}
+	if !l.opts.config.allowsOwner(ref.Owner) {
+		l.addIssue("disallowed-action", SeverityError, field,
+			fmt.Sprintf("%s '%s' is not owned by one of the allowed owners %s", kind, uses, strings.Join(l.opts.config.AllowedOwners, ", ")))
+	}
+	switch ref.Pinning() {
+	case PinningBranch:
+		l.addIssue("branch-pinned-action", SeverityWarning, field,
+			fmt.Sprintf("'%s' is pinned to the branch '%s', which runs whatever is pushed to it next", uses, ref.Ref))
+	case PinningTag:
+		if l.opts.config != nil && l.opts.config.Pinning == PinningSHA {
+			l.addIssue("sha-pinning", SeverityWarning, field,
+				fmt.Sprintf("'%s' is pinned to the tag '%s', but the project requires a full commit SHA", uses, ref.Ref))
+		}
+	}
+}
+
+// lintRunners reports runner labels outside the allowed runners of the
+// project configuration. Labels computed by expressions are skipped.
+func (l *Linter) lintRunners(jobID string, job Job) {
+	for _, label := range jobRunnerLabels(job) {
+		if !strings.Contains(label, "${{") && !l.opts.config.allowsRunner(label) {
+			l.addIssue("disallowed-runner", SeverityError, fmt.Sprintf("jobs.%s.runs-on", jobID),
+				fmt.Sprintf("Runner '%s' is not one of the allowed runners %s", label, strings.Join(l.opts.config.AllowedRunners, ", ")))
+		}
+	}
+}
+
+// lintStepInputs checks the inputs passed to a step against the inputs the
+// action declares, reporting unknown, deprecated and missing required inputs
+func (l *Linter) lintStepInputs(field string, step Step) {
+	action := l.resolve(step.Uses)
+	if action == nil {
+		return
+	}
+	declared := make(map[string]Input, len(action.Inputs))
+	for name, input := range action.Inputs {
+		declared[strings.ToLower(name)] = input
+	}
+
+	passed := make(map[string]bool, len(step.With))
+	for _, name := range sortedWithKeys(step.With) {
+		passed[strings.ToLower(name)] = true
+		inputField := fmt.Sprintf("%s.with.%s", field, name)
+		input, ok := declared[strings.ToLower(name)]
+		if !ok {
+			// Docker actions accept args and entrypoint overrides
+			if action.Runs.Using == "docker" && (name == "args" || name == "entrypoint") {
+				continue
+			}
+			l.addIssue("unknown-input", SeverityWarning, inputField,
+				fmt.Sprintf("%s has no input '%s'", step.Uses, name))
+			continue
+		}
+		if input.IsDeprecated() {
+			message := fmt.Sprintf("Input '%s' of %s is deprecated", name, step.Uses)
+			if input.DeprecationMessage != "" {
+				message += ": " + input.DeprecationMessage
+			}
+			l.addIssue("deprecated-input", SeverityWarning, inputField, message)
+		}
+	}
+
+	names := make([]string, 0, len(action.Inputs))
+	for name := range action.Inputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		input := action.Inputs[name]
+		if input.Required && input.Default == "" && !passed[strings.ToLower(name)] {
+			l.addIssue("missing-required-input", SeverityWarning, field+".with",
+				fmt.Sprintf("Required input '%s' of %s is not set", name, step.Uses))
+		}
+	}
+}
+
+// resolve loads an action through the configured resolver, falling back to
+// the built-in schemas of popular actions. It returns nil if the action
+// cannot be loaded.
+func (l *Linter) resolve(uses string) *ActionFile {
+	if action, ok := l.resolved[uses]; ok {
+		return action
+	}
+	var action *ActionFile
+	if l.opts.resolver != nil {
+		if resolved, err := l.opts.resolve(l.opts.resolver, uses); err == nil {
+			action = resolved
+		}
+	}
+	if action == nil {
+		if builtin, err := (BuiltinResolver{}).Resolve(uses); err == nil {
+			action = builtin
+		}
+	}
+	l.resolved[uses] = action
+	return action
+}
+
+// addIssue adds a lint issue to the list
+func (l *Linter) addIssue(rule string, severity Severity, field, message string) {
+	l.issues = append(l.issues, newFinding(rule, severity, field, message))
+}
+
+// sortedJobIDs returns the job IDs of a workflow in a stable order
+func sortedJobIDs(action *ActionFile) []string {
+	ids := make([]string, 0, len(action.Jobs))
+	for id := range action.Jobs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// sortedWithKeys returns the keys of a 'with' map in a stable order
+func sortedWithKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedKeys returns the keys of a string map in a stable order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedSet returns the members of a set in sorted order
+func sortedSet(set map[string]bool) []string {
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Marshal writes an ActionFile as YAML with reproducible key order. Files
+// parsed WithPositions keep the order of their source document; other keys
+// are written in field order, with map keys such as job IDs, inputs and
+// environment variables sorted. Comments and extension keys are not
+// written.
+func Marshal(action *ActionFile) ([]byte, error) {
+	var node yaml.Node
+	if err := node.Encode(action); err != nil {
+		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	if len(action.Positions) > 0 {
+		orderBySource(&node, "", action.Positions)
+	}
+	// yaml.v3 quotes 'on' as a YAML 1.1 boolean, which GitHub does not need
+	if key := mappingKey(&node, "on"); key != nil {
+		key.Style = 0
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// orderBySource sorts the keys of the mappings under node by their position
+// in the source document. Keys without a position, such as fields added
+// after parsing, keep their order after the keys with one.
+func orderBySource(node *yaml.Node, path string, positions map[string]Position) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		type pair struct {
+			key, value *yaml.Node
+			pos        Position
+			found      bool
+		}
+		pairs := make([]pair, 0, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			childPath := key.Value
+			if path != "" {
+				childPath = path + "." + key.Value
+			}
+			pos, found := positions[childPath]
+			pairs = append(pairs, pair{key, value, pos, found})
+			orderBySource(value, childPath, positions)
+		}
+		sort.SliceStable(pairs, func(i, j int) bool {
+			a, b := pairs[i], pairs[j]
+			if a.found != b.found {
+				return a.found
+			}
+			if a.pos.Line != b.pos.Line {
+				return a.pos.Line < b.pos.Line
+			}
+			return a.pos.Column < b.pos.Column
+		})
+		node.Content = node.Content[:0]
+		for _, p := range pairs {
+			node.Content = append(node.Content, p.key, p.value)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			orderBySource(item, fmt.Sprintf("%s[%d]", path, i), positions)
+		}
+	}
+}
+
+// mappingKey returns the key node of key in a mapping node, or nil
+func mappingKey(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+const marshalSource = `name: CI
+on:
+  workflow_dispatch:
+    inputs:
+      zone:
+        type: string
+      app:
+        type: string
+  push:
+env:
+  ZED: "1"
+  ALPHA: "2"
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make test
+        name: Test
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+`
+
+func TestMarshalSourceOrder(t *testing.T) {
+	action, err := Parse(strings.NewReader(marshalSource), WithPositions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := Marshal(action)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := `name: CI
+on:
+  workflow_dispatch:
+    inputs:
+      zone:
+        type: string
+      app:
+        type: string
+  push: null
+env:
+  ZED: "1"
+  ALPHA: "2"
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make test
+        name: Test
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+`
+	if string(data) != want {
+		t.Errorf("Marshal() =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestMarshalSorted(t *testing.T) {
+	action, err := Parse(strings.NewReader(marshalSource))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := Marshal(action)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		data, err := Marshal(action)
+		if err != nil || string(data) != string(first) {
+			t.Fatalf("Marshal() is not reproducible:\n%s\n%s", first, data)
+		}
+	}
+	out := string(first)
+	if strings.Index(out, "ALPHA") > strings.Index(out, "ZED") || strings.Index(out, "  build:") > strings.Index(out, "  test:") {
+		t.Errorf("Expected map keys to be sorted without positions, got\n%s", out)
+	}
+	if !strings.HasPrefix(out, "name: CI\n") {
+		t.Errorf("Expected fields in struct order, got\n%s", out)
+	}
+}
@@ -0,0 +1,478 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MatrixCombinations expands the matrix of a job strategy into the
+// combinations GitHub runs, applying 'include' and 'exclude' the way GitHub
+// does. It returns nil if the strategy has no matrix. Matrix keys are
+// expanded in alphabetical order, since the order of the YAML mapping is not
+// preserved.
+func MatrixCombinations(strategy map[string]interface{}) ([]map[string]interface{}, error) {
+	raw, ok := strategy["matrix"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	matrix, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("matrix is computed at runtime: %v", raw)
+	}
+
+	keys := matrixKeys(matrix)
+	combinations, err := matrixProduct(matrix, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	excludes, err := matrixEntries(matrix, "exclude")
+	if err != nil {
+		return nil, err
+	}
+	filtered := combinations[:0]
+	for _, c := range combinations {
+		excluded := false
+		for _, exclude := range excludes {
+			if combinationMatches(c, exclude) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			filtered = append(filtered, c)
+		}
+	}
+	combinations = filtered
+
+	includes, err := matrixEntries(matrix, "include")
+	if err != nil {
+		return nil, err
+	}
+	original := len(combinations)
+	for _, include := range includes {
+		// An include extends every original combination whose original
+		// values it does not overwrite; otherwise it is a new combination
+		extended := false
+		for _, c := range combinations[:original] {
+			overwrites := false
+			for _, key := range keys {
+				if v, ok := include[key]; ok && !reflect.DeepEqual(v, c[key]) {
+					overwrites = true
+					break
+				}
+			}
+			if overwrites {
+				continue
+			}
+			for k, v := range include {
+				c[k] = v
+			}
+			extended = true
+		}
+		if !extended {
+			combinations = append(combinations, copyCombination(include))
+		}
+	}
+
+	return combinations, nil
+}
+
+// matrixKeys returns the dimensions of a matrix in alphabetical order
+func matrixKeys(matrix map[string]interface{}) []string {
+	keys := make([]string, 0, len(matrix))
+	for key := range matrix {
+		if key != "include" && key != "exclude" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// matrixProduct returns the combinations of the values of the dimensions
+// keys, before 'exclude' and 'include' are applied
+func matrixProduct(matrix map[string]interface{}, keys []string) ([]map[string]interface{}, error) {
+	var combinations []map[string]interface{}
+	if len(keys) > 0 {
+		combinations = []map[string]interface{}{{}}
+	}
+	for _, key := range keys {
+		values, ok := matrix[key].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("matrix.%s is computed at runtime: %v", key, matrix[key])
+		}
+		next := make([]map[string]interface{}, 0, len(combinations)*len(values))
+		for _, c := range combinations {
+			for _, value := range values {
+				combination := copyCombination(c)
+				combination[key] = value
+				next = append(next, combination)
+			}
+		}
+		combinations = next
+	}
+	return combinations, nil
+}
+
+// matrixEntries returns the 'include' or 'exclude' entries of a matrix
+func matrixEntries(matrix map[string]interface{}, key string) ([]map[string]interface{}, error) {
+	raw, ok := matrix[key]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("matrix.%s is computed at runtime: %v", key, raw)
+	}
+	entries := make([]map[string]interface{}, 0, len(list))
+	for i, item := range list {
+		entry, err := MapOfStringInterface(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid matrix.%s[%d]: %w", key, i, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// combinationMatches reports whether a combination has all values of partial
+func combinationMatches(combination, partial map[string]interface{}) bool {
+	for k, v := range partial {
+		if !reflect.DeepEqual(combination[k], v) {
+			return false
+		}
+	}
+	return true
+}
+
+func copyCombination(c map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(c))
+	for k, v := range c {
+		result[k] = v
+	}
+	return result
+}
+
+// formatMatrixValue formats a matrix value the way GitHub displays it
+func formatMatrixValue(v interface{}) string {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, len(keys))
+		for _, k := range keys {
+			parts = append(parts, formatMatrixValue(value[k]))
+		}
+		return strings.Join(parts, ", ")
+	case []interface{}:
+		parts := make([]string, 0, len(value))
+		for _, item := range value {
+			parts = append(parts, formatMatrixValue(item))
+		}
+		return strings.Join(parts, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// JobLocation identifies a job within a directory of workflows
+type JobLocation struct {
+	File  string `json:"file"`
+	JobID string `json:"job"`
+}
+
+// MatrixValueUsage lists the jobs whose matrix covers a value of a dimension
+type MatrixValueUsage struct {
+	Dimension string        `json:"dimension"`
+	Value     string        `json:"value"`
+	Jobs      []JobLocation `json:"jobs"`
+}
+
+// MatrixCoverage summarizes the matrix dimensions used across a directory of
+// workflows, as returned by ParseDir, e.g. which jobs run on which operating
+// systems or language versions. Values added through 'include' are covered;
+// computed matrices are skipped. Results are sorted by dimension and value.
+func MatrixCoverage(workflows map[string]*ActionFile) []MatrixValueUsage {
+	usage := make(map[[2]string][]JobLocation)
+	for file, workflow := range workflows {
+		for _, jobID := range sortedJobIDs(workflow) {
+			combinations, err := MatrixCombinations(workflow.Jobs[jobID].Strategy)
+			if err != nil {
+				continue
+			}
+			seen := make(map[[2]string]bool)
+			for _, c := range combinations {
+				for dim, value := range c {
+					key := [2]string{dim, formatMatrixValue(value)}
+					if !seen[key] {
+						seen[key] = true
+						usage[key] = append(usage[key], JobLocation{File: file, JobID: jobID})
+					}
+				}
+			}
+		}
+	}
+
+	result := make([]MatrixValueUsage, 0, len(usage))
+	for key, jobs := range usage {
+		sort.Slice(jobs, func(i, j int) bool {
+			if jobs[i].File != jobs[j].File {
+				return jobs[i].File < jobs[j].File
+			}
+			return jobs[i].JobID < jobs[j].JobID
+		})
+		result = append(result, MatrixValueUsage{Dimension: key[0], Value: key[1], Jobs: jobs})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Dimension != result[j].Dimension {
+			return result[i].Dimension < result[j].Dimension
+		}
+		return result[i].Value < result[j].Value
+	})
+	return result
+}
+
+// lintMatrixReferences reports matrix.<key> references to keys the job's
+// matrix does not define
+func (l *Linter) lintMatrixReferences(jobID string, job Job) {
+	combinations, err := MatrixCombinations(job.Strategy)
+	if err != nil {
+		return
+	}
+	keys := make(map[string]bool)
+	for _, c := range combinations {
+		for k := range c {
+			keys[k] = true
+		}
+	}
+
+	check := func(field string) func(string) string {
+		return func(s string) string {
+			for _, ref := range ExtractContextReferences(s) {
+				if ref.Context != "matrix" || keys[ref.Path[0]] {
+					continue
+				}
+				message := fmt.Sprintf("matrix.%s is not defined by the job's matrix", ref.Path[0])
+				if len(keys) == 0 {
+					message = fmt.Sprintf("matrix.%s is referenced but the job has no matrix", ref.Path[0])
+				}
+				l.addIssue("undefined-matrix-key", SeverityWarning, field, message)
+			}
+			return s
+		}
+	}
+
+	field := "jobs." + jobID
+	withoutSteps := job
+	withoutSteps.Steps = nil
+	withoutSteps.Strategy = nil
+	mapJobStrings(withoutSteps, check(field))
+	for i, step := range job.Steps {
+		mapStepStrings(step, check(fmt.Sprintf("%s.steps[%d]", field, i)))
+	}
+}
+
+// largeMatrixSize is the number of combinations from which a matrix should
+// make a deliberate fail-fast and max-parallel choice
+const largeMatrixSize = 10
+
+// lintMatrixStrategy reports large matrices that leave fail-fast and
+// max-parallel at their defaults, and matrices whose failures are hidden
+// entirely by fail-fast: false together with continue-on-error: true
+func (l *Linter) lintMatrixStrategy(jobID string, job Job) {
+	combinations, err := MatrixCombinations(job.Strategy)
+	if err != nil || len(combinations) == 0 {
+		return
+	}
+	field := fmt.Sprintf("jobs.%s.strategy", jobID)
+	failFast, hasFailFast := job.Strategy["fail-fast"]
+
+	if len(combinations) >= largeMatrixSize {
+		if !hasFailFast {
+			l.addIssue("matrix-fail-fast", SeverityInfo, field,
+				fmt.Sprintf("The matrix has %d combinations and fail-fast defaults to true, so one failure cancels all others", len(combinations)))
+		}
+		if _, ok := job.Strategy["max-parallel"]; !ok {
+			l.addIssue("matrix-max-parallel", SeverityInfo, field,
+				fmt.Sprintf("The matrix has %d combinations and no max-parallel, so it can occupy that many runners at once", len(combinations)))
+		}
+	}
+
+	if failFast == false && job.ContinueOn == true {
+		l.addIssue("matrix-hidden-failures", SeverityWarning, fmt.Sprintf("jobs.%s.continue-on-error", jobID),
+			"With fail-fast: false and continue-on-error: true, failing matrix combinations never fail the workflow")
+	}
+}
+
+// validateMatrix checks that the 'include' and 'exclude' entries of a job
+// matrix are mappings, and that exclude entries only use the matrix
+// dimensions. Entries computed by expressions are skipped.
+func (v *Validator) validateMatrix(jobID string, job Job) {
+	matrix, ok := job.Strategy["matrix"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	field := fmt.Sprintf("jobs.%s.strategy.matrix", jobID)
+	for _, key := range []string{"include", "exclude"} {
+		raw := matrix[key]
+		if raw == nil || isExpression(raw) {
+			continue
+		}
+		list, ok := raw.([]interface{})
+		if !ok {
+			v.addError("matrix-entries", field+"."+key, fmt.Sprintf("Matrix %s must be a list of mappings", key))
+			continue
+		}
+		for i, item := range list {
+			if isExpression(item) {
+				continue
+			}
+			entryField := fmt.Sprintf("%s.%s[%d]", field, key, i)
+			entry, err := MapOfStringInterface(item)
+			if err != nil {
+				v.addError("matrix-entries", entryField, fmt.Sprintf("Matrix %s entries must be mappings of matrix keys to values", key))
+				continue
+			}
+			if key != "exclude" {
+				continue
+			}
+			for _, name := range sortedWithKeys(entry) {
+				if _, ok := matrix[name]; !ok || name == "include" || name == "exclude" {
+					v.addError("matrix-entries", entryField+"."+name, fmt.Sprintf("Exclude key '%s' is not a dimension of the matrix", name))
+				}
+			}
+		}
+	}
+}
+
+// lintMatrixExcludes reports exclude entries that match no combination of
+// the matrix dimensions, which usually means a value was misspelled.
+// Matrices and entries computed by expressions are skipped.
+func (l *Linter) lintMatrixExcludes(jobID string, job Job) {
+	matrix, ok := job.Strategy["matrix"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	excludes, err := matrixEntries(matrix, "exclude")
+	if err != nil {
+		return
+	}
+	combinations, err := matrixProduct(matrix, matrixKeys(matrix))
+	if err != nil {
+		return
+	}
+	for i, exclude := range excludes {
+		if containsExpression(exclude) || !hasMatrixKeys(matrix, exclude) {
+			continue
+		}
+		matched := false
+		for _, c := range combinations {
+			if combinationMatches(c, exclude) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			l.addIssue("matrix-exclude-unmatched", SeverityWarning, fmt.Sprintf("jobs.%s.strategy.matrix.exclude[%d]", jobID, i),
+				fmt.Sprintf("Exclude entry {%s} matches no combination of the matrix", formatMatrixEntry(exclude)))
+		}
+	}
+}
+
+// hasMatrixKeys reports whether every key of entry is a matrix dimension
+func hasMatrixKeys(matrix, entry map[string]interface{}) bool {
+	for key := range entry {
+		if _, ok := matrix[key]; !ok || key == "include" || key == "exclude" {
+			return false
+		}
+	}
+	return true
+}
+
+// formatMatrixEntry formats an include or exclude entry as key: value pairs
+func formatMatrixEntry(entry map[string]interface{}) string {
+	parts := make([]string, 0, len(entry))
+	for _, key := range sortedWithKeys(entry) {
+		parts = append(parts, key+": "+formatMatrixValue(entry[key]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// isExpression reports whether a value is a string with a ${{ }} expression
+func isExpression(v interface{}) bool {
+	s, ok := v.(string)
+	return ok && len(ExtractExpressions(s)) > 0
+}
+
+// containsExpression reports whether a value of entry, at any depth, has a
+// ${{ }} expression
+func containsExpression(entry map[string]interface{}) bool {
+	for _, value := range entry {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if containsExpression(v) {
+				return true
+			}
+		case []interface{}:
+			for _, item := range v {
+				if isExpression(item) {
+					return true
+				}
+				if m, ok := item.(map[string]interface{}); ok && containsExpression(m) {
+					return true
+				}
+			}
+		default:
+			if isExpression(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasOrderedMatrix reports whether a job has a matrix with several keys,
+// whose order names its check runs
+func hasOrderedMatrix(action *ActionFile) bool {
+	for _, job := range action.Jobs {
+		if matrix, ok := job.Strategy["matrix"].(map[string]interface{}); ok && len(matrixKeys(matrix)) > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// matrixKeyOrder returns the matrix keys of the jobs of a document node in
+// source order, leaving out include and exclude
+func matrixKeyOrder(node *yaml.Node) map[string][]string {
+	order := make(map[string][]string)
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	jobs := mappingValue(node, "jobs")
+	if jobs == nil || jobs.Kind != yaml.MappingNode {
+		return order
+	}
+	for i := 0; i+1 < len(jobs.Content); i += 2 {
+		matrix := mappingValue(mappingValue(jobs.Content[i+1], "strategy"), "matrix")
+		if matrix == nil || matrix.Kind != yaml.MappingNode {
+			continue
+		}
+		var keys []string
+		for j := 0; j+1 < len(matrix.Content); j += 2 {
+			if key := matrix.Content[j].Value; key != "include" && key != "exclude" {
+				keys = append(keys, key)
+			}
+		}
+		order[jobs.Content[i].Value] = keys
+	}
+	return order
+}
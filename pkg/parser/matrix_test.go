@@ -0,0 +1,278 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestMatrixCombinations(t *testing.T) {
+	// Example from the GitHub documentation on expanding matrix configurations;
+	// keys are expanded alphabetically
+	workflow := mustParse(t, `
+jobs:
+  test:
+    strategy:
+      matrix:
+        fruit: [apple, pear]
+        animal: [cat, dog]
+        include:
+          - color: green
+          - color: pink
+            animal: cat
+          - fruit: apple
+            shape: circle
+          - fruit: banana
+          - fruit: banana
+            animal: cat
+`)
+
+	combinations, err := MatrixCombinations(workflow.Jobs["test"].Strategy)
+	if err != nil {
+		t.Fatalf("Failed to expand matrix: %v", err)
+	}
+
+	want := []string{
+		"map[animal:cat color:pink fruit:apple shape:circle]",
+		"map[animal:cat color:pink fruit:pear]",
+		"map[animal:dog color:green fruit:apple shape:circle]",
+		"map[animal:dog color:green fruit:pear]",
+		"map[fruit:banana]",
+		"map[animal:cat fruit:banana]",
+	}
+	if len(combinations) != len(want) {
+		t.Fatalf("Expected %d combinations, got %v", len(want), combinations)
+	}
+	for i, c := range combinations {
+		if got := fmt.Sprint(c); got != want[i] {
+			t.Errorf("Combination %d: expected %s, got %s", i, want[i], got)
+		}
+	}
+}
+
+func TestMatrixCombinationsExclude(t *testing.T) {
+	strategy := map[string]interface{}{
+		"matrix": map[string]interface{}{
+			"os":      []interface{}{"ubuntu", "windows"},
+			"version": []interface{}{12, 14},
+			"exclude": []interface{}{
+				map[string]interface{}{"os": "windows", "version": 12},
+			},
+		},
+	}
+	combinations, err := MatrixCombinations(strategy)
+	if err != nil {
+		t.Fatalf("Failed to expand matrix: %v", err)
+	}
+	if len(combinations) != 3 {
+		t.Errorf("Expected 3 combinations, got %v", combinations)
+	}
+
+	if c, err := MatrixCombinations(nil); c != nil || err != nil {
+		t.Errorf("Expected no combinations without a matrix, got %v, %v", c, err)
+	}
+
+	dynamic := map[string]interface{}{"matrix": "${{ fromJSON(needs.setup.outputs.matrix) }}"}
+	if _, err := MatrixCombinations(dynamic); err == nil {
+		t.Error("Expected an error for a computed matrix")
+	}
+}
+
+func TestMatrixCoverage(t *testing.T) {
+	workflows := map[string]*ActionFile{
+		"ci.yml": mustParse(t, `
+jobs:
+  test:
+    strategy:
+      matrix:
+        os: [ubuntu-latest, windows-latest]
+        go: ['1.21', '1.22']
+        include:
+          - os: macos-latest
+            go: '1.22'
+  lint:
+    steps:
+      - run: make lint
+`),
+		"nightly.yml": mustParse(t, `
+jobs:
+  e2e:
+    strategy:
+      matrix:
+        os: [ubuntu-latest]
+  dynamic:
+    strategy:
+      matrix: ${{ fromJSON(needs.setup.outputs.matrix) }}
+`),
+	}
+
+	coverage := MatrixCoverage(workflows)
+	var got []string
+	for _, u := range coverage {
+		got = append(got, fmt.Sprintf("%s=%s:%d", u.Dimension, u.Value, len(u.Jobs)))
+	}
+	want := "go=1.21:1 go=1.22:1 os=macos-latest:1 os=ubuntu-latest:2 os=windows-latest:1"
+	if fmt.Sprint(got) != "["+want+"]" {
+		t.Errorf("Expected %s, got %v", want, got)
+	}
+
+	ubuntu := coverage[3].Jobs
+	if ubuntu[0] != (JobLocation{File: "ci.yml", JobID: "test"}) || ubuntu[1] != (JobLocation{File: "nightly.yml", JobID: "e2e"}) {
+		t.Errorf("Unexpected jobs for ubuntu-latest: %v", ubuntu)
+	}
+}
+
+func TestLintUndefinedMatrixKey(t *testing.T) {
+	workflow := mustParse(t, `
+on: push
+jobs:
+  test:
+    runs-on: ${{ matrix.os }}
+    strategy:
+      matrix:
+        os: [ubuntu-latest]
+        include:
+          - os: ubuntu-latest
+            experimental: true
+    steps:
+      - run: go test -tags ${{ matrix.tags }} ${{ matrix.experimental }}
+  build:
+    runs-on: ${{ matrix.platform }}
+    steps:
+      - run: make
+`)
+
+	var fields []string
+	for _, issue := range NewLinter().Lint(workflow) {
+		if issue.Rule == "undefined-matrix-key" {
+			fields = append(fields, issue.Field)
+		}
+	}
+	if fmt.Sprint(fields) != "[jobs.build jobs.test.steps[0]]" {
+		t.Errorf("Expected jobs.build and jobs.test.steps[0], got %v", fields)
+	}
+}
+
+func TestLintMatrixStrategy(t *testing.T) {
+	workflow := mustParse(t, `
+on: push
+jobs:
+  large:
+    runs-on: ${{ matrix.os }}
+    strategy:
+      matrix:
+        os: [ubuntu-latest, windows-latest, macos-latest]
+        go: ["1.20", "1.21", "1.22", "1.23"]
+    steps:
+      - run: go test ./...
+  tuned:
+    runs-on: ${{ matrix.os }}
+    strategy:
+      fail-fast: true
+      max-parallel: 4
+      matrix:
+        os: [ubuntu-latest, windows-latest, macos-latest]
+        go: ["1.20", "1.21", "1.22", "1.23"]
+    steps:
+      - run: go test ./...
+  hidden:
+    runs-on: ubuntu-latest
+    continue-on-error: true
+    strategy:
+      fail-fast: false
+      matrix:
+        go: ["1.22", "1.23"]
+    steps:
+      - run: go test ./...
+  experimental:
+    runs-on: ubuntu-latest
+    continue-on-error: ${{ matrix.experimental }}
+    strategy:
+      fail-fast: false
+      matrix:
+        go: ["1.22", "1.23"]
+    steps:
+      - run: go test ./...
+`)
+
+	var found []string
+	for _, issue := range NewLinter().Lint(workflow) {
+		if strings.HasPrefix(issue.Rule, "matrix-") {
+			found = append(found, issue.Rule+"@"+issue.Field)
+		}
+	}
+	want := "[matrix-hidden-failures@jobs.hidden.continue-on-error matrix-fail-fast@jobs.large.strategy matrix-max-parallel@jobs.large.strategy]"
+	if fmt.Sprint(found) != want {
+		t.Errorf("Expected %s, got %v", want, found)
+	}
+}
+
+func TestValidateMatrixEntries(t *testing.T) {
+	workflow := mustParse(t, `
+on: push
+jobs:
+  test:
+    runs-on: ${{ matrix.os }}
+    strategy:
+      matrix:
+        os: [ubuntu-latest, windows-latest]
+        node: [18, 20]
+        include:
+          - os: macos-latest
+            experimental: true
+          - macos-latest
+        exclude:
+          - os: windows-latest
+            nodes: 18
+  computed:
+    runs-on: ubuntu-latest
+    strategy:
+      matrix:
+        os: [ubuntu-latest]
+        include: ${{ fromJSON(needs.setup.outputs.extra) }}
+    steps:
+      - run: make
+`)
+	var found []string
+	for _, e := range NewValidator().Validate(workflow) {
+		if e.Rule == "matrix-entries" {
+			found = append(found, e.Field)
+		}
+	}
+	want := "[jobs.test.strategy.matrix.exclude[0].nodes jobs.test.strategy.matrix.include[1]]"
+	sort.Strings(found)
+	if fmt.Sprint(found) != want {
+		t.Errorf("Expected %s, got %v", want, found)
+	}
+}
+
+func TestLintMatrixExcludes(t *testing.T) {
+	workflow := mustParse(t, `
+on: push
+jobs:
+  test:
+    runs-on: ${{ matrix.os }}
+    strategy:
+      matrix:
+        os: [ubuntu-latest, windows-latest]
+        node: [18, 20]
+        exclude:
+          - os: windows-latest
+            node: 18
+          - os: windows-lastest
+          - os: ${{ vars.SKIPPED_OS }}
+    steps:
+      - run: npm test
+`)
+	var found []string
+	for _, issue := range NewLinter().Lint(workflow) {
+		if issue.Rule == "matrix-exclude-unmatched" {
+			found = append(found, issue.Field+": "+issue.Message)
+		}
+	}
+	want := "[jobs.test.strategy.matrix.exclude[1]: Exclude entry {os: windows-lastest} matches no combination of the matrix]"
+	if fmt.Sprint(found) != want {
+		t.Errorf("Expected %s, got %v", want, found)
+	}
+}
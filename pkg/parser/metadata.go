@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Metadata is the header of a workflow or action: what an index needs
+// without the jobs and steps
+type Metadata struct {
+	Name        string      `json:"name,omitempty"`
+	Description string      `json:"description,omitempty"`
+	On          interface{} `json:"on,omitempty"`
+	// JobIDs lists the jobs of a workflow, sorted
+	JobIDs []string `json:"jobs,omitempty"`
+}
+
+// metadataKeys are the top-level sections ParseMetadata decodes; 'true' is
+// 'on' as written by YAML 1.1 tools, see normalizeOnKey
+var metadataKeys = map[string]bool{
+	"name":        true,
+	"description": true,
+	"on":          true,
+	"true":        true,
+}
+
+// ParseMetadata reads only the name, description, triggers and job IDs of a
+// document. Block-style documents are split by indentation and only the
+// needed sections are decoded, which is several times faster than Parse for
+// large workflows; other documents, such as JSON, are parsed fully. The
+// document is not validated beyond these sections.
+func ParseMetadata(r io.Reader, opts ...Option) (*Metadata, error) {
+	o := newOptions(opts)
+	data, _, err := readAll(r, o)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.SplitAfter(string(data), "\n")
+	sections := splitBlocks(lines, 0, len(lines))
+	if len(sections) == 0 || strings.HasPrefix(sections[0].key, "{") {
+		return fullMetadata(data, o)
+	}
+
+	var header strings.Builder
+	var jobIDs []string
+	for _, section := range sections {
+		if metadataKeys[section.key] {
+			for _, line := range lines[section.start:section.end] {
+				header.WriteString(line)
+			}
+			continue
+		}
+		if section.key != "jobs" {
+			continue
+		}
+		if value := strings.TrimSpace(strings.SplitN(lines[section.start], ":", 2)[1]); value != "" && !strings.HasPrefix(value, "#") {
+			// Flow-style or aliased jobs cannot be split by lines
+			return fullMetadata(data, o)
+		}
+		for _, job := range splitBlocks(lines, section.start+1, section.end) {
+			jobIDs = append(jobIDs, job.key)
+		}
+	}
+
+	var meta struct {
+		Name        string      `yaml:"name"`
+		Description string      `yaml:"description"`
+		On          interface{} `yaml:"on"`
+	}
+	if err := yaml.Unmarshal(normalizeOnKey([]byte(header.String())), &meta); err != nil {
+		return nil, classify(ErrInvalidYAML, fmt.Errorf("failed to unmarshal YAML: %w", err))
+	}
+	sort.Strings(jobIDs)
+	return &Metadata{Name: meta.Name, Description: meta.Description, On: meta.On, JobIDs: jobIDs}, nil
+}
+
+// fullMetadata extracts the metadata of a document by parsing all of it
+func fullMetadata(data []byte, o *options) (*Metadata, error) {
+	action, err := decode(data, o)
+	if err != nil {
+		return nil, err
+	}
+	meta := &Metadata{Name: action.Name, Description: action.Description, On: action.On}
+	if len(action.Jobs) > 0 {
+		meta.JobIDs = sortedJobIDs(action)
+	}
+	return meta, nil
+}
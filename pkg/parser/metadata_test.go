@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMetadata(t *testing.T) {
+	doc := `# CI pipeline
+name: CI
+description: |
+  Builds and tests: everything
+on:
+  push:
+    branches: [main]
+env:
+  GO: "1.22"
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: go test ./...
+  # A comment between jobs
+  lint:
+    runs-on: ubuntu-latest
+    steps: [{run: "golangci-lint run"}]
+  "deploy-prod":
+    needs: [test, lint]
+    uses: ./.github/workflows/deploy.yml
+`
+	meta, err := ParseMetadata(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("ParseMetadata failed: %v", err)
+	}
+	if meta.Name != "CI" || meta.Description != "Builds and tests: everything\n" {
+		t.Errorf("Unexpected name or description: %+v", meta)
+	}
+	if !reflect.DeepEqual(meta.JobIDs, []string{"deploy-prod", "lint", "test"}) {
+		t.Errorf("Unexpected job IDs %v", meta.JobIDs)
+	}
+	on, ok := meta.On.(map[string]interface{})
+	if !ok || on["push"] == nil {
+		t.Errorf("Unexpected triggers %v", meta.On)
+	}
+}
+
+func TestParseMetadataMatchesParse(t *testing.T) {
+	docs := []string{
+		`{"name": "JSON", "on": "push", "jobs": {"b": {"runs-on": "x"}, "a": {"runs-on": "x"}}}`,
+		"name: Flow\non: [push, pull_request]\njobs: {a: {runs-on: x}}\n",
+		"true: push\njobs:\n  build:\n    runs-on: x\n",
+		"name: Action\ndescription: Does things\nruns:\n  using: node20\n  main: index.js\n",
+	}
+	for _, doc := range docs {
+		meta, err := ParseMetadata(strings.NewReader(doc))
+		if err != nil {
+			t.Fatalf("ParseMetadata(%q) failed: %v", doc, err)
+		}
+		action, err := Parse(strings.NewReader(doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var jobIDs []string
+		if len(action.Jobs) > 0 {
+			jobIDs = sortedJobIDs(action)
+		}
+		want := &Metadata{Name: action.Name, Description: action.Description, On: action.On, JobIDs: jobIDs}
+		if !reflect.DeepEqual(meta, want) {
+			t.Errorf("ParseMetadata(%q) = %+v, want %+v", doc, meta, want)
+		}
+	}
+}
+
+func TestParseFileMetadata(t *testing.T) {
+	meta, err := ParseFileMetadata("testdata/workflow.yml")
+	if err != nil {
+		t.Fatalf("ParseFileMetadata failed: %v", err)
+	}
+	action, err := ParseFile("testdata/workflow.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Name != action.Name || !reflect.DeepEqual(meta.JobIDs, sortedJobIDs(action)) {
+		t.Errorf("Metadata %+v does not match the parsed file", meta)
+	}
+}
+
+// BenchmarkParseMetadata compares reading the header of a large workflow
+// with parsing all of it
+func BenchmarkParseMetadata(b *testing.B) {
+	var doc bytes.Buffer
+	doc.WriteString("name: CI\non:\n  push:\n    branches: [main]\n  pull_request:\njobs:\n")
+	for i := 0; i < 50; i++ {
+		fmt.Fprintf(&doc, "  job-%d:\n    runs-on: ubuntu-latest\n    env:\n      INDEX: %d\n    steps:\n", i, i)
+		for j := 0; j < 10; j++ {
+			fmt.Fprintf(&doc, "      - name: Step %d\n        uses: actions/setup-go@v5\n        with:\n          go-version: \"1.22\"\n      - run: |\n          go build ./...\n          go test ./...\n", j)
+		}
+	}
+
+	b.Run("metadata", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := ParseMetadata(bytes.NewReader(doc.Bytes())); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("full", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := Parse(bytes.NewReader(doc.Bytes())); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// NameCollision is a workflow name used by several workflow files, which
+// makes the Actions UI and required status checks ambiguous
+type NameCollision struct {
+	Name  string   `json:"name"`
+	Files []string `json:"files"`
+}
+
+// NameCollisions reports workflows of a directory, as returned by ParseDir,
+// that share the same 'name'. Names are compared ignoring case and
+// surrounding space, as the Actions UI lists them; unnamed workflows are shown
+// by their path and never collide. Collisions and their files are sorted.
+func NameCollisions(workflows map[string]*ActionFile) []NameCollision {
+	byName := make(map[string]*NameCollision)
+	for _, file := range sortedFiles(workflows) {
+		workflow := workflows[file]
+		name := strings.TrimSpace(workflow.Name)
+		if name == "" || len(workflow.Jobs) == 0 {
+			continue
+		}
+		key := strings.ToLower(name)
+		if byName[key] == nil {
+			byName[key] = &NameCollision{Name: name}
+		}
+		byName[key].Files = append(byName[key].Files, file)
+	}
+
+	var result []NameCollision
+	for _, collision := range byName {
+		if len(collision.Files) > 1 {
+			result = append(result, *collision)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// NameCollisionFindings reports the workflow-name-collision rule for every
+// file of the set involved in a collision, keyed by path
+func NameCollisionFindings(set WorkflowSet) map[string][]ValidationError {
+	findings := make(map[string][]ValidationError)
+	for _, collision := range NameCollisions(set) {
+		for _, file := range collision.Files {
+			findings[file] = append(findings[file], newFinding("workflow-name-collision", SeverityWarning, "name",
+				fmt.Sprintf("Workflow name '%s' is also used by %d other file(s)", collision.Name, len(collision.Files)-1)))
+		}
+	}
+	return findings
+}
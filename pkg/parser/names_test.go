@@ -0,0 +1,43 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNameCollisions(t *testing.T) {
+	workflow := func(name string) *ActionFile {
+		return &ActionFile{Name: name, On: "push", Jobs: map[string]Job{"build": {RunsOn: "ubuntu-latest"}}}
+	}
+	workflows := map[string]*ActionFile{
+		"ci.yml":        workflow("CI"),
+		"ci-legacy.yml": workflow("ci "),
+		"release.yml":   workflow("Release"),
+		"unnamed.yml":   workflow(""),
+		"unnamed-2.yml": workflow(""),
+		"action/ci.yml": {Name: "CI", Runs: RunsConfig{Using: "node20", Main: "index.js"}},
+	}
+
+	want := []NameCollision{{Name: "ci", Files: []string{"ci-legacy.yml", "ci.yml"}}}
+	if got := NameCollisions(workflows); !reflect.DeepEqual(got, want) {
+		t.Errorf("NameCollisions() = %+v, want %+v", got, want)
+	}
+}
+
+func TestNameCollisionFindings(t *testing.T) {
+	workflow := func(name string) *ActionFile {
+		return &ActionFile{Name: name, On: "push", Jobs: map[string]Job{"build": {RunsOn: "ubuntu-latest"}}}
+	}
+	findings := NameCollisionFindings(WorkflowSet{
+		"ci.yml":      workflow("CI"),
+		"ci-2.yml":    workflow("CI"),
+		"release.yml": workflow("Release"),
+	})
+	if len(findings) != 2 || len(findings["ci.yml"]) != 1 || len(findings["ci-2.yml"]) != 1 {
+		t.Fatalf("Expected one finding for each colliding file, got %v", findings)
+	}
+	finding := findings["ci.yml"][0]
+	if finding.Rule != "workflow-name-collision" || finding.Severity != SeverityWarning || finding.Field != "name" || finding.Suggestion == "" {
+		t.Errorf("Unexpected finding %+v", finding)
+	}
+}
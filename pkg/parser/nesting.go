@@ -0,0 +1,45 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// validateNesting follows the reusable workflows called by a job through
+// the resolver, reporting the first call chain that nests workflows beyond
+// GitHub's limit or calls a workflow already in the chain
+func (v *Validator) validateNesting(jobID string, job Job, called *ActionFile) {
+	if called == nil {
+		return
+	}
+	if problem := v.callChainProblem([]string{job.Uses}, called); problem != "" {
+		v.addError("reusable-nesting", fmt.Sprintf("jobs.%s.uses", jobID), problem)
+	}
+}
+
+// callChainProblem checks the calls of a workflow reached through chain, the
+// 'uses' references leading to it. The calling workflow is the first level,
+// so chains may hold up to maxReusableDepth-1 references.
+func (v *Validator) callChainProblem(chain []string, workflow *ActionFile) string {
+	for _, jobID := range sortedJobIDs(workflow) {
+		uses := workflow.Jobs[jobID].Uses
+		if uses == "" {
+			continue
+		}
+		next := append(append(make([]string, 0, len(chain)+1), chain...), uses)
+		for _, previous := range chain {
+			if previous == uses {
+				return fmt.Sprintf("Reusable workflow '%s' calls itself: %s", uses, strings.Join(next, " -> "))
+			}
+		}
+		if len(next) >= maxReusableDepth {
+			return fmt.Sprintf("Reusable workflows are nested more than %d levels deep: %s", maxReusableDepth, strings.Join(next, " -> "))
+		}
+		if nested := v.resolve(uses); nested != nil {
+			if problem := v.callChainProblem(next, nested); problem != "" {
+				return problem
+			}
+		}
+	}
+	return ""
+}
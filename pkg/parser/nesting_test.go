@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func nestingIssues(t *testing.T, workflow *ActionFile, resolver Resolver) []ValidationError {
+	t.Helper()
+	var issues []ValidationError
+	for _, err := range NewValidator(WithResolver(resolver)).Validate(workflow) {
+		if err.Rule == "reusable-nesting" {
+			issues = append(issues, err)
+		}
+	}
+	return issues
+}
+
+func callingWorkflow(t *testing.T, uses string) *ActionFile {
+	return mustParse(t, "on: workflow_call\njobs:\n  call:\n    uses: "+uses+"\n")
+}
+
+func TestValidateNestingDepth(t *testing.T) {
+	caller := mustParse(t, "on: push\njobs:\n  call:\n    uses: ./.github/workflows/a.yml\n")
+	leaf := mustParse(t, "on: workflow_call\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make\n")
+	resolver := mapResolver{
+		"./.github/workflows/a.yml": callingWorkflow(t, "./.github/workflows/b.yml"),
+		"./.github/workflows/b.yml": callingWorkflow(t, "./.github/workflows/c.yml"),
+		"./.github/workflows/c.yml": leaf,
+	}
+	if issues := nestingIssues(t, caller, resolver); len(issues) != 0 {
+		t.Errorf("Expected four levels to be accepted, got %v", issues)
+	}
+
+	resolver["./.github/workflows/c.yml"] = callingWorkflow(t, "./.github/workflows/d.yml")
+	resolver["./.github/workflows/d.yml"] = leaf
+	issues := nestingIssues(t, caller, resolver)
+	if len(issues) != 1 || issues[0].Field != "jobs.call.uses" || issues[0].Severity != SeverityError {
+		t.Fatalf("Expected one error for five levels, got %v", issues)
+	}
+	if !strings.Contains(issues[0].Message, "a.yml -> ./.github/workflows/b.yml -> ./.github/workflows/c.yml -> ./.github/workflows/d.yml") {
+		t.Errorf("Expected the call chain in the message, got %q", issues[0].Message)
+	}
+}
+
+func TestValidateNestingCycle(t *testing.T) {
+	caller := mustParse(t, "on: push\njobs:\n  call:\n    uses: ./.github/workflows/a.yml\n")
+	resolver := mapResolver{
+		"./.github/workflows/a.yml": callingWorkflow(t, "./.github/workflows/b.yml"),
+		"./.github/workflows/b.yml": callingWorkflow(t, "./.github/workflows/a.yml"),
+	}
+	issues := nestingIssues(t, caller, resolver)
+	if len(issues) != 1 || !strings.Contains(issues[0].Message, "'./.github/workflows/a.yml' calls itself") {
+		t.Errorf("Expected a cycle to be reported, got %v", issues)
+	}
+}
+
+func TestValidateNestingUnresolved(t *testing.T) {
+	caller := mustParse(t, "on: push\njobs:\n  call:\n    uses: ./.github/workflows/a.yml\n")
+	if issues := nestingIssues(t, caller, mapResolver{}); len(issues) != 0 {
+		t.Errorf("Expected unresolved workflows to be skipped, got %v", issues)
+	}
+	if errs := NewValidator().Validate(caller); len(errs) != 0 {
+		t.Errorf("Expected no errors without a resolver, got %v", errs)
+	}
+}
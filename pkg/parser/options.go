@@ -0,0 +1,204 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Option configures the behaviour of Parse, ParseFile and ParseDir
+type Option func(*options)
+
+// options holds the settings collected from a list of Option values
+type options struct {
+	ctx          context.Context
+	strict       bool
+	positions    bool
+	maxFileSize  int64
+	decoderHooks []func(*yaml.Decoder)
+	tracer       Tracer
+	resolver     Resolver
+	templateVars map[string]string
+	includes     bool
+	includeDir   string
+
+	continueOnError bool
+	skipDirs        []string
+	gitignore       bool
+	disabled        bool
+	progress        func(Progress)
+	config          *Config
+	extensions      map[ExtensionLevel]ExtensionPolicy
+	composites      bool
+}
+
+// newOptions applies opts on top of the defaults
+func newOptions(opts []Option) *options {
+	o := &options{
+		ctx:    context.Background(),
+		tracer: noopTracer{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithStrict rejects documents containing fields that are not part of the
+// ActionFile model instead of silently ignoring them. The model covers every
+// key of GitHub's action metadata and workflow syntax at the top level and
+// in inputs, outputs, runs, branding, jobs and steps; values GitHub leaves
+// free-form or that are kept as maps, such as on, strategy, container or
+// with, are not checked.
+func WithStrict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// WithPositions records the line and column of every field in
+// ActionFile.Positions
+func WithPositions() Option {
+	return func(o *options) {
+		o.positions = true
+	}
+}
+
+// WithMaxFileSize rejects documents larger than n bytes with ErrTooLarge,
+// without reading past the limit. A value of zero or less disables the limit.
+func WithMaxFileSize(n int64) Option {
+	return func(o *options) {
+		o.maxFileSize = n
+	}
+}
+
+// WithContinueOnError makes ParseDir keep going when a file fails to parse,
+// see ParseDir for how failures are reported
+func WithContinueOnError() Option {
+	return func(o *options) {
+		o.continueOnError = true
+	}
+}
+
+// DefaultSkipDirs are directories that rarely hold workflows or actions
+// but often hold many unrelated YAML files
+var DefaultSkipDirs = []string{".git", "node_modules", "vendor", "dist"}
+
+// WithSkipDirs makes ParseDir and ScanProjects skip directories with one of
+// the given names, such as DefaultSkipDirs. The directory being walked is
+// never skipped. Later calls replace the list.
+func WithSkipDirs(names ...string) Option {
+	return func(o *options) {
+		o.skipDirs = names
+	}
+}
+
+// WithGitignore makes ParseDir and ScanProjects skip the files and
+// directories ignored by the .gitignore files of the walked tree
+func WithGitignore() Option {
+	return func(o *options) {
+		o.gitignore = true
+	}
+}
+
+// WithDisabled makes ParseDir and ScanProjects return disabled workflows as
+// well, marked with ActionFile.Disabled. Files named like ci.yml.disabled
+// or ci.yml.off and files in a 'disabled' directory are disabled; they are
+// left out by default.
+func WithDisabled() Option {
+	return func(o *options) {
+		o.disabled = true
+	}
+}
+
+// WithContext makes ParseDir, ScanProjects and DiscoverActions stop with
+// the context's error once it is cancelled, and passes it to resolvers
+// implementing ContextResolver and to the Tracer
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+// WithResolver lets the Validator load the reusable workflows called by a
+// workflow, enabling checks across the call
+func WithResolver(r Resolver) Option {
+	return func(o *options) {
+		o.resolver = r
+	}
+}
+
+// WithTemplateVars substitutes {{ name }} template placeholders with values
+// before parsing, see ExpandTemplate. Documents containing placeholders
+// without a value fail with a *TemplateError.
+func WithTemplateVars(values map[string]string) Option {
+	return func(o *options) {
+		o.templateVars = values
+	}
+}
+
+// WithIncludes composes documents from the fragment files named by x-include
+// keys, see ExpandIncludes. Fragments are resolved relative to dir, or for
+// ParseFile and ParseDir relative to the parsed file when dir is empty.
+func WithIncludes(dir string) Option {
+	return func(o *options) {
+		o.includes = true
+		o.includeDir = dir
+	}
+}
+
+// WithYAMLDecoder registers a hook that can adjust the yaml.Decoder before
+// the document is decoded
+func WithYAMLDecoder(fn func(*yaml.Decoder)) Option {
+	return func(o *options) {
+		o.decoderHooks = append(o.decoderHooks, fn)
+	}
+}
+
+// Position is a location in a YAML document
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// String returns the position formatted as line:column
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Position returns the location of a field in the source document. Field
+// paths use the same notation as ValidationError.Field, for example
+// "jobs.build.steps[0].uses". Positions are only available when the file was
+// parsed using WithPositions.
+func (a *ActionFile) Position(field string) (Position, bool) {
+	pos, ok := a.Positions[field]
+	return pos, ok
+}
+
+// collectPositions walks a YAML node tree and records the position of every
+// mapping key and sequence item under its field path
+func collectPositions(node *yaml.Node, path string, positions map[string]Position) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			collectPositions(child, path, positions)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			childPath := key.Value
+			if path != "" {
+				childPath = path + "." + key.Value
+			}
+			positions[childPath] = Position{Line: key.Line, Column: key.Column}
+			collectPositions(value, childPath, positions)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			childPath := fmt.Sprintf("%s[%d]", path, i)
+			positions[childPath] = Position{Line: item.Line, Column: item.Column}
+			collectPositions(item, childPath, positions)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseWithStrict(t *testing.T) {
+	doc := "name: test\nunknown-field: value\n"
+
+	if _, err := Parse(strings.NewReader(doc)); err != nil {
+		t.Errorf("Expected unknown fields to be ignored by default, got %v", err)
+	}
+
+	if _, err := Parse(strings.NewReader(doc), WithStrict()); err == nil {
+		t.Errorf("Expected error for unknown field in strict mode, got nil")
+	}
+
+	if _, err := ParseFile("testdata/action.yml", WithStrict()); err != nil {
+		t.Errorf("Expected action file to parse in strict mode, got %v", err)
+	}
+}
+
+func TestParseWithStrictKeys(t *testing.T) {
+	workflow := `
+name: CI
+run-name: CI for ${{ github.ref }}
+on: push
+permissions: read-all
+env:
+  CI: "true"
+defaults:
+  run:
+    shell: bash
+concurrency: ci
+jobs:
+  build:
+    name: Build
+    needs: []
+    if: always()
+    runs-on: ubuntu-latest
+    environment: staging
+    permissions: {}
+    concurrency: build
+    outputs:
+      version: ${{ steps.version.outputs.version }}
+    env:
+      GO: "1.20"
+    defaults:
+      run:
+        working-directory: src
+    timeout-minutes: 10
+    continue-on-error: false
+    strategy:
+      matrix:
+        os: [ubuntu-latest]
+    container: golang:1.20
+    services:
+      redis:
+        image: redis
+    steps:
+      - id: version
+        if: success()
+        name: Version
+        uses: actions/checkout@v4
+        with:
+          fetch-depth: 0
+        env:
+          A: b
+        continue-on-error: true
+        timeout-minutes: 5
+      - run: make
+        shell: bash
+        working-directory: src
+  call:
+    uses: ./.github/workflows/release.yml
+    with:
+      version: "1"
+    secrets: inherit
+`
+	if _, err := Parse(strings.NewReader(workflow), WithStrict()); err != nil {
+		t.Errorf("Expected every workflow key to be accepted in strict mode, got %v", err)
+	}
+
+	action := `
+name: Docker
+author: octocat
+description: Runs a container
+inputs:
+  level:
+    description: Level
+    required: false
+    default: info
+    deprecationMessage: Use verbosity
+outputs:
+  result:
+    description: Result
+    value: ${{ steps.run.outputs.result }}
+runs:
+  using: docker
+  image: Dockerfile
+  pre-entrypoint: setup.sh
+  entrypoint: main.sh
+  post-entrypoint: cleanup.sh
+  args: [a]
+  env:
+    A: b
+branding:
+  icon: box
+  color: blue
+`
+	if _, err := Parse(strings.NewReader(action), WithStrict()); err != nil {
+		t.Errorf("Expected every action metadata key to be accepted in strict mode, got %v", err)
+	}
+
+	// Keys strict mode rejects, one per level the model covers
+	rejected := map[string]string{
+		"top level": "name: x\nrunname: x\n",
+		"input":     "inputs:\n  a:\n    requried: true\n",
+		"output":    "outputs:\n  a:\n    val: x\n",
+		"runs":      "runs:\n  using: node20\n  mian: index.js\n",
+		"branding":  "branding:\n  colour: blue\n",
+		"job":       "jobs:\n  a:\n    runs_on: ubuntu-latest\n",
+		"step":      "jobs:\n  a:\n    steps:\n      - run: make\n        working-dir: src\n",
+	}
+	for level, doc := range rejected {
+		if _, err := Parse(strings.NewReader(doc), WithStrict()); !errors.Is(err, ErrUnsupportedField) {
+			t.Errorf("Expected an unknown %s key to be rejected, got %v", level, err)
+		}
+	}
+}
+
+func TestParseWithMaxFileSize(t *testing.T) {
+	if _, err := ParseFile("testdata/action.yml", WithMaxFileSize(64)); err == nil {
+		t.Errorf("Expected error for file exceeding maximum size, got nil")
+	}
+
+	if _, err := ParseFile("testdata/action.yml", WithMaxFileSize(1<<20)); err != nil {
+		t.Errorf("Expected file within maximum size to parse, got %v", err)
+	}
+}
+
+func TestParseWithPositions(t *testing.T) {
+	action, err := ParseFile("testdata/workflow.yml")
+	if err != nil {
+		t.Fatalf("Failed to parse workflow file: %v", err)
+	}
+	if _, ok := action.Position("jobs"); ok {
+		t.Errorf("Expected no positions without WithPositions")
+	}
+
+	action, err = ParseFile("testdata/workflow.yml", WithPositions())
+	if err != nil {
+		t.Fatalf("Failed to parse workflow file: %v", err)
+	}
+
+	tests := []struct {
+		field string
+		pos   Position
+	}{
+		{"name", Position{Line: 1, Column: 1}},
+		{"jobs.lint", Position{Line: 25, Column: 3}},
+		{"jobs.lint.steps[0]", Position{Line: 29, Column: 9}},
+		{"jobs.lint.steps[1].with.node-version", Position{Line: 34, Column: 11}},
+	}
+	for _, tt := range tests {
+		pos, ok := action.Position(tt.field)
+		if !ok {
+			t.Errorf("Expected position for %s", tt.field)
+			continue
+		}
+		if pos != tt.pos {
+			t.Errorf("Expected %s at %s, got %s", tt.field, tt.pos, pos)
+		}
+	}
+}
+
+func TestParseWithYAMLDecoder(t *testing.T) {
+	called := false
+	_, err := Parse(strings.NewReader("name: test\n"), WithYAMLDecoder(func(d *yaml.Decoder) {
+		called = true
+	}))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if !called {
+		t.Errorf("Expected decoder hook to be called")
+	}
+}
+
+func TestParseDirWithOptions(t *testing.T) {
+	if _, err := ParseDir("testdata", WithMaxFileSize(64)); err == nil {
+		t.Errorf("Expected options to be applied to every file in the directory")
+	}
+}
+
+func TestParseDirWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ParseDir("testdata", WithContext(ctx), WithContinueOnError()); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	parsed := 0
+	_, err := ParseDir("testdata", WithContext(ctx), WithProgress(func(p Progress) {
+		if p.File != nil {
+			parsed++
+			cancel()
+		}
+	}))
+	if !errors.Is(err, context.Canceled) || parsed != 1 {
+		t.Errorf("Expected the scan to stop after one file, got %d files (%v)", parsed, err)
+	}
+}
@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// OutdatedDependency is an action or reusable workflow referenced at an
+// older version than the latest known one
+type OutdatedDependency struct {
+	Dependency
+	// Latest is the latest known version of the action or reusable workflow
+	Latest string `json:"latest"`
+}
+
+// Outdated reports the actions and reusable workflows among deps whose
+// version ref is older than the latest known version. latest maps an action
+// or reusable workflow, written as 'owner/repo' or 'owner/repo/path' without
+// a ref, to its latest version and takes precedence over the major versions
+// of the popular actions known to BuiltinResolver. Refs that are not
+// versions, such as commit SHAs and branches, cannot be compared and are
+// skipped; a floating major tag such as v4 is current for any latest v4.x.
+func Outdated(deps []Dependency, latest map[string]string) []OutdatedDependency {
+	var result []OutdatedDependency
+	for _, dep := range deps {
+		if dep.Action == nil || (dep.Kind != DependencyAction && dep.Kind != DependencyReusableWorkflow) {
+			continue
+		}
+		name := dep.Action.Owner + "/" + dep.Action.Repo
+		if dep.Action.Path != "" {
+			name += "/" + dep.Action.Path
+		}
+		version, ok := latest[name]
+		if !ok {
+			version, ok = builtinLatest()[strings.ToLower(name)]
+		}
+		if ok && olderVersion(dep.Action.Ref, version) {
+			result = append(result, OutdatedDependency{Dependency: dep, Latest: version})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Uses < result[j].Uses
+	})
+	return result
+}
+
+var (
+	builtinLatestOnce     sync.Once
+	builtinLatestVersions map[string]string
+)
+
+// builtinLatest returns the newest major version of each built-in action,
+// keyed by its lower-case name
+func builtinLatest() map[string]string {
+	builtinLatestOnce.Do(func() {
+		builtinActionsOnce.Do(loadBuiltinActions)
+		builtinLatestVersions = make(map[string]string)
+		for name, versions := range builtinActions {
+			for major := range versions {
+				if current, ok := builtinLatestVersions[name]; !ok || olderVersion(current, major) {
+					builtinLatestVersions[name] = major
+				}
+			}
+		}
+	})
+	return builtinLatestVersions
+}
+
+// olderVersion reports whether the version ref is older than latest,
+// comparing as many components as ref has
+func olderVersion(ref, latest string) bool {
+	current, ok := versionParts(ref)
+	if !ok {
+		return false
+	}
+	newest, ok := versionParts(latest)
+	if !ok {
+		return false
+	}
+	for i := 0; i < len(current) && i < len(newest); i++ {
+		if current[i] != newest[i] {
+			return current[i] < newest[i]
+		}
+	}
+	return false
+}
+
+// versionParts splits a version such as v4.1.2 into its numbers
+func versionParts(version string) ([]int, bool) {
+	if !majorVersionPattern.MatchString(version) {
+		return nil, false
+	}
+	fields := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	parts := make([]int, len(fields))
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
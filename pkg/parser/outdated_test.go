@@ -0,0 +1,59 @@
+package parser
+
+import "testing"
+
+func TestOutdated(t *testing.T) {
+	ci := mustParse(t, `
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v3
+      - uses: actions/setup-go@v5
+      - uses: actions/cache@0c45773b623bea8c8e75f6c82b208c3cf94ea4f9
+      - uses: octo/tool@v1.2.0
+  deploy:
+    uses: octo/workflows/.github/workflows/deploy.yml@v1
+  release:
+    uses: octo/workflows/.github/workflows/release.yml@main
+`)
+
+	outdated := Outdated(Dependencies(map[string]*ActionFile{"ci.yml": ci}), map[string]string{
+		"octo/tool": "v1.3.0",
+		"octo/workflows/.github/workflows/deploy.yml":  "v2",
+		"octo/workflows/.github/workflows/release.yml": "v2",
+	})
+	want := map[string]string{
+		"actions/checkout@v3":                            "v4",
+		"octo/tool@v1.2.0":                               "v1.3.0",
+		"octo/workflows/.github/workflows/deploy.yml@v1": "v2",
+	}
+	if len(outdated) != len(want) {
+		t.Fatalf("Expected %d outdated dependencies, got %+v", len(want), outdated)
+	}
+	for _, dep := range outdated {
+		if want[dep.Uses] != dep.Latest {
+			t.Errorf("Unexpected outdated dependency %s (latest %s)", dep.Uses, dep.Latest)
+		}
+	}
+}
+
+func TestOlderVersion(t *testing.T) {
+	tests := []struct {
+		ref, latest string
+		want        bool
+	}{
+		{"v3", "v4", true},
+		{"v4", "v4.2.1", false},
+		{"v4.1", "v4.2.1", true},
+		{"v4.2.1", "v4", false},
+		{"v5", "v4", false},
+		{"main", "v4", false},
+	}
+	for _, tt := range tests {
+		if got := olderVersion(tt.ref, tt.latest); got != tt.want {
+			t.Errorf("olderVersion(%q, %q) = %v, want %v", tt.ref, tt.latest, got, tt.want)
+		}
+	}
+}
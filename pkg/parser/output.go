@@ -0,0 +1,141 @@
+package parser
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// OutputVersion is the version of the machine-readable output format. It
+// changes when a field is removed or changes meaning; fields may be added
+// without changing it.
+const OutputVersion = 1
+
+// AnalysisKind identifies the analysis an AnalysisOutput holds
+type AnalysisKind string
+
+// Kinds of AnalysisOutput
+const (
+	AnalysisValidation   AnalysisKind = "validation"
+	AnalysisLint         AnalysisKind = "lint"
+	AnalysisDependencies AnalysisKind = "dependencies"
+	AnalysisCallGraph    AnalysisKind = "call-graph"
+	AnalysisJobGraph     AnalysisKind = "job-graph"
+	AnalysisSecrets      AnalysisKind = "secrets"
+	AnalysisPermissions  AnalysisKind = "permissions"
+	AnalysisRunners      AnalysisKind = "runners"
+	AnalysisRules        AnalysisKind = "rules"
+	AnalysisDispatch     AnalysisKind = "dispatch"
+)
+
+// OutputSchema is the JSON Schema describing AnalysisOutput documents
+//
+//go:embed schema/output.schema.json
+var OutputSchema []byte
+
+// AnalysisOutput is the versioned envelope in which analyses are written as JSON
+// for other tools. Only the field matching Kind is set.
+type AnalysisOutput struct {
+	Version int          `json:"version"`
+	Kind    AnalysisKind `json:"kind"`
+	// Files holds the findings of validation and lint, sorted by path
+	Files        []FileFindings       `json:"files,omitempty"`
+	Dependencies []Dependency         `json:"dependencies,omitempty"`
+	CallGraph    *CallGraph           `json:"callGraph,omitempty"`
+	JobGraph     *JobGraph            `json:"jobGraph,omitempty"`
+	Secrets      *SecretInventory     `json:"secrets,omitempty"`
+	Permissions  *PermissionInventory `json:"permissions,omitempty"`
+	Runners      *RunnerInventory     `json:"runners,omitempty"`
+	Rules        []Rule               `json:"rules,omitempty"`
+	Dispatch     *DispatchInventory   `json:"dispatch,omitempty"`
+}
+
+// FileFindings are the findings of a single file
+type FileFindings struct {
+	Path     string            `json:"path"`
+	Findings []ValidationError `json:"findings"`
+}
+
+// NewFindingsOutput wraps the findings of validation or lint, keyed by file
+// path, in an AnalysisOutput of the given kind
+func NewFindingsOutput(kind AnalysisKind, findings map[string][]ValidationError) *AnalysisOutput {
+	paths := make([]string, 0, len(findings))
+	for path := range findings {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	out := &AnalysisOutput{Version: OutputVersion, Kind: kind, Files: make([]FileFindings, 0, len(paths))}
+	for _, path := range paths {
+		list := findings[path]
+		if list == nil {
+			list = make([]ValidationError, 0)
+		}
+		out.Files = append(out.Files, FileFindings{Path: path, Findings: list})
+	}
+	return out
+}
+
+// NewDependenciesOutput wraps a dependency inventory in an AnalysisOutput
+func NewDependenciesOutput(deps []Dependency) *AnalysisOutput {
+	return &AnalysisOutput{Version: OutputVersion, Kind: AnalysisDependencies, Dependencies: deps}
+}
+
+// NewCallGraphOutput wraps a call graph in an AnalysisOutput
+func NewCallGraphOutput(graph *CallGraph) *AnalysisOutput {
+	return &AnalysisOutput{Version: OutputVersion, Kind: AnalysisCallGraph, CallGraph: graph}
+}
+
+// NewJobGraphOutput wraps a job graph in an AnalysisOutput
+func NewJobGraphOutput(graph *JobGraph) *AnalysisOutput {
+	return &AnalysisOutput{Version: OutputVersion, Kind: AnalysisJobGraph, JobGraph: graph}
+}
+
+// NewSecretsOutput wraps a secret inventory in an AnalysisOutput
+func NewSecretsOutput(inventory *SecretInventory) *AnalysisOutput {
+	return &AnalysisOutput{Version: OutputVersion, Kind: AnalysisSecrets, Secrets: inventory}
+}
+
+// NewPermissionsOutput wraps a permission inventory in an AnalysisOutput
+func NewPermissionsOutput(inventory *PermissionInventory) *AnalysisOutput {
+	return &AnalysisOutput{Version: OutputVersion, Kind: AnalysisPermissions, Permissions: inventory}
+}
+
+// NewRunnersOutput wraps a runner inventory in an AnalysisOutput
+func NewRunnersOutput(inventory *RunnerInventory) *AnalysisOutput {
+	return &AnalysisOutput{Version: OutputVersion, Kind: AnalysisRunners, Runners: inventory}
+}
+
+// NewDispatchOutput wraps a dispatch inventory in an AnalysisOutput
+func NewDispatchOutput(inventory *DispatchInventory) *AnalysisOutput {
+	return &AnalysisOutput{Version: OutputVersion, Kind: AnalysisDispatch, Dispatch: inventory}
+}
+
+// NewRulesOutput wraps rule metadata, such as Rules(), in an AnalysisOutput
+func NewRulesOutput(rules []Rule) *AnalysisOutput {
+	return &AnalysisOutput{Version: OutputVersion, Kind: AnalysisRules, Rules: rules}
+}
+
+// Write writes the output as indented JSON
+func (o *AnalysisOutput) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(o); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	return nil
+}
+
+// ReadAnalysisOutput reads an AnalysisOutput written by Write, rejecting other versions
+func ReadAnalysisOutput(r io.Reader) (*AnalysisOutput, error) {
+	var o AnalysisOutput
+	if err := json.NewDecoder(r).Decode(&o); err != nil {
+		return nil, fmt.Errorf("failed to decode output: %w", err)
+	}
+	if o.Version != OutputVersion {
+		return nil, fmt.Errorf("unsupported output version %d", o.Version)
+	}
+	return &o, nil
+}
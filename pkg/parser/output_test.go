@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOutputRoundTrip(t *testing.T) {
+	out := NewFindingsOutput(AnalysisLint, map[string][]ValidationError{
+		"b.yml": nil,
+		"a.yml": {newFinding("job-runner", SeverityError, "jobs.build", "Job must specify either 'runs-on' or 'uses'")},
+	})
+	if out.Files[0].Path != "a.yml" || out.Files[1].Findings == nil {
+		t.Errorf("NewFindingsOutput() = %+v, want files sorted with non-nil findings", out)
+	}
+
+	var buf bytes.Buffer
+	if err := out.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadAnalysisOutput(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, out) {
+		t.Errorf("ReadAnalysisOutput() = %+v, want %+v", read, out)
+	}
+
+	if _, err := ReadAnalysisOutput(strings.NewReader(`{"version": 2, "kind": "lint"}`)); err == nil {
+		t.Error("ReadAnalysisOutput() accepted an unsupported version")
+	}
+}
+
+// TestOutputSchema checks that every field written in an AnalysisOutput is
+// described by OutputSchema, so the published schema cannot fall behind
+func TestOutputSchema(t *testing.T) {
+	var schema map[string]interface{}
+	if err := json.Unmarshal(OutputSchema, &schema); err != nil {
+		t.Fatalf("OutputSchema is not valid JSON: %v", err)
+	}
+
+	set := WorkflowSet{"ci.yml": mustParse(t, `
+on: push
+permissions: read-all
+jobs:
+  build:
+    name: Build
+    runs-on: ubuntu-latest
+    permissions:
+      contents: read
+    steps:
+      - uses: actions/checkout@v4
+        with:
+          token: ${{ secrets.TOKEN }}
+      - uses: docker://alpine@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef
+      - uses: ./.github/actions/setup
+  test:
+    needs: build
+    uses: octo/repo/.github/workflows/test.yml@main
+    secrets: inherit
+`)}
+	jobGraph, err := BuildJobGraph(set["ci.yml"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	finding := newFinding("job-runner", SeverityError, "jobs.build", "message")
+	finding.Example = "example"
+	outputs := []*AnalysisOutput{
+		NewFindingsOutput(AnalysisValidation, map[string][]ValidationError{"ci.yml": {finding}}),
+		NewDependenciesOutput(Dependencies(set)),
+		NewCallGraphOutput(BuildCallGraph(set)),
+		NewJobGraphOutput(jobGraph),
+		NewSecretsOutput(Secrets(set)),
+		NewPermissionsOutput(EffectivePermissions(set)),
+		NewRunnersOutput(Runners(set)),
+		NewRulesOutput(Rules()),
+		NewDispatchOutput(ManualTriggers(set)),
+	}
+	for _, out := range outputs {
+		data, err := json.Marshal(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var doc interface{}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			t.Fatal(err)
+		}
+		checkSchemaFields(t, string(out.Kind), doc, schema, schema)
+	}
+}
+
+// checkSchemaFields reports the object keys of doc not declared in the
+// properties or additionalProperties of schema
+func checkSchemaFields(t *testing.T, path string, doc interface{}, schema, root map[string]interface{}) {
+	t.Helper()
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/$defs/")
+		schema = root["$defs"].(map[string]interface{})[name].(map[string]interface{})
+	}
+	switch value := doc.(type) {
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		additional, _ := schema["additionalProperties"].(map[string]interface{})
+		for key, child := range value {
+			childSchema, ok := properties[key].(map[string]interface{})
+			if !ok && additional != nil {
+				childSchema, ok = additional, true
+			}
+			if !ok {
+				t.Errorf("%s.%s is not described by the schema", path, key)
+				continue
+			}
+			checkSchemaFields(t, path+"."+key, child, childSchema, root)
+		}
+	case []interface{}:
+		items, _ := schema["items"].(map[string]interface{})
+		for _, item := range value {
+			checkSchemaFields(t, path+"[]", item, items, root)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// outputWritePattern matches a command writing a name=value or name<<DELIM
+// line, as written to $GITHUB_OUTPUT and $GITHUB_ENV
+var outputWritePattern = regexp.MustCompile(`(?:echo|printf|Write-Output)\s+(?:-[A-Za-z]+\s+)*["']?([A-Za-z_][A-Za-z0-9_-]*)(?:=|<<)`)
+
+// psOutputWritePattern matches PowerShell writes of the form
+// "name=value" >> $env:GITHUB_OUTPUT
+var psOutputWritePattern = regexp.MustCompile(`^\s*["']([A-Za-z_][A-Za-z0-9_-]*)(?:=|<<)`)
+
+// setOutputPattern matches the deprecated ::set-output workflow command
+var setOutputPattern = regexp.MustCompile(`::set-output name=([A-Za-z_][A-Za-z0-9_-]*)::`)
+
+// setEnvPattern matches the disabled ::set-env workflow command
+var setEnvPattern = regexp.MustCompile(`::set-env name=([A-Za-z_][A-Za-z0-9_-]*)::`)
+
+// StepOutputs returns the names of the outputs a run step sets, found by
+// scanning its script for writes to $GITHUB_OUTPUT and for the deprecated
+// ::set-output command. Writes inside a { ...; } >> "$GITHUB_OUTPUT" group
+// are recognized; outputs written by other scripts or with computed names
+// are not.
+func StepOutputs(step Step) []string {
+	return scanFileCommands(step.Run, "GITHUB_OUTPUT", setOutputPattern)
+}
+
+// StepEnvExports returns the names of the environment variables a run step
+// exports to later steps by writing to $GITHUB_ENV, or with the disabled
+// ::set-env command. The same heuristics as StepOutputs apply.
+func StepEnvExports(step Step) []string {
+	return scanFileCommands(step.Run, "GITHUB_ENV", setEnvPattern)
+}
+
+// scanFileCommands returns the names written to the environment file
+// variable in a script, together with those set by the legacy workflow
+// command matched by legacy
+func scanFileCommands(script, variable string, legacy *regexp.Regexp) []string {
+	if script == "" {
+		return nil
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	lines := strings.Split(script, "\n")
+	groupStart := -1
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "{") {
+			groupStart = i
+		}
+
+		for _, m := range legacy.FindAllStringSubmatch(line, -1) {
+			add(m[1])
+		}
+		if !strings.Contains(line, variable) {
+			continue
+		}
+
+		scan := []string{line}
+		if strings.HasPrefix(strings.TrimSpace(line), "}") && groupStart >= 0 {
+			scan = lines[groupStart:i]
+		}
+		for _, l := range scan {
+			for _, m := range outputWritePattern.FindAllStringSubmatch(l, -1) {
+				add(m[1])
+			}
+			if m := psOutputWritePattern.FindStringSubmatch(l); m != nil {
+				add(m[1])
+			}
+		}
+		groupStart = -1
+	}
+	return names
+}
+
+// lintStepOutputReferences reports steps.<id>.outputs.<name> references to
+// run steps whose script sets outputs, but not the referenced one. outputs
+// maps the fields of job or action outputs to their values.
+func (l *Linter) lintStepOutputReferences(prefix string, steps []Step, outputs map[string]string) {
+	produced := make(map[string]map[string]bool)
+	for _, step := range steps {
+		if step.ID == "" {
+			continue
+		}
+		names := StepOutputs(step)
+		if len(names) == 0 {
+			continue
+		}
+		produced[step.ID] = make(map[string]bool)
+		for _, name := range names {
+			produced[step.ID][name] = true
+		}
+	}
+	if len(produced) == 0 {
+		return
+	}
+
+	check := func(field, s string) string {
+		for _, ref := range ExtractContextReferences(s) {
+			if ref.Context != "steps" || len(ref.Path) < 3 || ref.Path[1] != "outputs" {
+				continue
+			}
+			if outputs, ok := produced[ref.Path[0]]; ok && !outputs[ref.Path[2]] {
+				l.addIssue("undefined-step-output", SeverityWarning, field,
+					fmt.Sprintf("Step '%s' does not set output '%s'", ref.Path[0], ref.Path[2]))
+			}
+		}
+		return s
+	}
+
+	for i, step := range steps {
+		field := fmt.Sprintf("%s[%d]", prefix, i)
+		mapStepStrings(step, func(s string) string { return check(field, s) })
+	}
+	for _, field := range sortedKeys(outputs) {
+		check(field, outputs[field])
+	}
+}
@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStepOutputs(t *testing.T) {
+	step := Step{Run: `
+echo "version=1.2.3" >> "$GITHUB_OUTPUT"
+echo sha=$(git rev-parse HEAD) >> $GITHUB_OUTPUT
+printf 'digest=%s\n' "$DIGEST" >> ${GITHUB_OUTPUT}
+echo "notes<<EOF" >> "$GITHUB_OUTPUT"
+cat notes.md >> "$GITHUB_OUTPUT"
+echo "EOF" >> "$GITHUB_OUTPUT"
+{
+  echo "major=1"
+  echo "minor=2"
+} >> "$GITHUB_OUTPUT"
+echo "::set-output name=legacy::x"
+echo "ignored=1"
+`}
+
+	got := strings.Join(StepOutputs(step), ",")
+	want := "version,sha,digest,notes,major,minor,legacy"
+	if got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+
+	ps := Step{Shell: "pwsh", Run: `"url=https://example.com" >> $env:GITHUB_OUTPUT`}
+	if got := StepOutputs(ps); len(got) != 1 || got[0] != "url" {
+		t.Errorf("Expected PowerShell output 'url', got %v", got)
+	}
+
+	if got := StepOutputs(Step{Uses: "actions/checkout@v4"}); got != nil {
+		t.Errorf("Expected no outputs for an action step, got %v", got)
+	}
+}
+
+func TestLintUndefinedStepOutput(t *testing.T) {
+	workflow := mustParse(t, `
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    outputs:
+      version: ${{ steps.meta.outputs.version }}
+      tag: ${{ steps.meta.outputs.tag }}
+    steps:
+      - id: meta
+        run: echo "version=1.0" >> "$GITHUB_OUTPUT"
+      - id: external
+        run: ./scripts/set-outputs.sh
+      - run: echo ${{ steps.meta.outputs.sha }} ${{ steps.external.outputs.anything }}
+`)
+
+	issues := NewLinter().Lint(workflow)
+	var fields []string
+	for _, issue := range issues {
+		if issue.Rule == "undefined-step-output" {
+			fields = append(fields, issue.Field)
+		}
+	}
+	want := "jobs.build.steps[2],jobs.build.outputs.tag"
+	if strings.Join(fields, ",") != want {
+		t.Errorf("Expected %s, got %v", want, issues)
+	}
+}
+
+func TestStepEnvExports(t *testing.T) {
+	step := Step{Run: `
+echo "NODE_ENV=production" >> "$GITHUB_ENV"
+echo "version=1" >> "$GITHUB_OUTPUT"
+{
+  echo "CHANGELOG<<EOF"
+  cat CHANGELOG.md
+  echo "EOF"
+} >> $GITHUB_ENV
+echo "::set-env name=LEGACY::1"
+`}
+
+	got := strings.Join(StepEnvExports(step), ",")
+	if got != "NODE_ENV,CHANGELOG,LEGACY" {
+		t.Errorf("Expected NODE_ENV,CHANGELOG,LEGACY, got %s", got)
+	}
+	if got := strings.Join(StepOutputs(step), ","); got != "version" {
+		t.Errorf("Expected environment writes not to be reported as outputs, got %s", got)
+	}
+}
@@ -0,0 +1,70 @@
+package parser
+
+// WaveParallelism is the demand for runners of one wave of a workflow's job
+// graph
+type WaveParallelism struct {
+	Jobs []string `json:"jobs"`
+	// Runners counts the jobs of the wave, each matrix combination as a job
+	// of its own, up to the matrix's max-parallel
+	Runners int `json:"runners"`
+}
+
+// ParallelismReport estimates how many runners a workflow occupies at once
+type ParallelismReport struct {
+	Waves []WaveParallelism `json:"waves"`
+	// MaxRunners is the largest number of runners needed by a single wave
+	MaxRunners int `json:"maxRunners"`
+	// Computed lists the jobs whose matrix is computed at runtime; they are
+	// counted as a single runner
+	Computed []string `json:"computed,omitempty"`
+}
+
+// Parallelism computes the runners each wave of a workflow's job graph needs
+// when all of its jobs run concurrently, to help size runner pools. Waves are
+// as returned by JobGraph.Waves; in practice jobs of different waves may
+// overlap when an early job is slow. Jobs calling reusable workflows count as
+// one runner.
+func Parallelism(action *ActionFile) (*ParallelismReport, error) {
+	graph, err := BuildJobGraph(action)
+	if err != nil {
+		return nil, err
+	}
+	waves, err := graph.Waves()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ParallelismReport{Waves: make([]WaveParallelism, 0, len(waves))}
+	for _, wave := range waves {
+		w := WaveParallelism{Jobs: wave}
+		for _, jobID := range wave {
+			runners, ok := jobRunners(action.Jobs[jobID])
+			if !ok {
+				report.Computed = append(report.Computed, jobID)
+			}
+			w.Runners += runners
+		}
+		if w.Runners > report.MaxRunners {
+			report.MaxRunners = w.Runners
+		}
+		report.Waves = append(report.Waves, w)
+	}
+	return report, nil
+}
+
+// jobRunners returns the number of runners a job occupies at once. It
+// reports false if the job's matrix is computed at runtime.
+func jobRunners(job Job) (int, bool) {
+	combinations, err := MatrixCombinations(job.Strategy)
+	if err != nil {
+		return 1, false
+	}
+	runners := len(combinations)
+	if runners == 0 {
+		runners = 1
+	}
+	if max, ok := job.Strategy["max-parallel"].(int); ok && max > 0 && max < runners {
+		runners = max
+	}
+	return runners, true
+}
@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParallelism(t *testing.T) {
+	action := mustParse(t, `
+on: push
+jobs:
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make lint
+  test:
+    runs-on: ${{ matrix.os }}
+    strategy:
+      matrix:
+        os: [ubuntu-latest, windows-latest, macos-latest]
+        go: ["1.21", "1.22"]
+    steps:
+      - run: make test
+  e2e:
+    needs: [lint, test]
+    runs-on: ubuntu-latest
+    strategy:
+      max-parallel: 2
+      matrix:
+        shard: [1, 2, 3, 4]
+    steps:
+      - run: make e2e
+  dynamic:
+    needs: lint
+    runs-on: ubuntu-latest
+    strategy:
+      matrix: ${{ fromJSON(needs.lint.outputs.matrix) }}
+    steps:
+      - run: make
+  deploy:
+    needs: e2e
+    uses: ./.github/workflows/deploy.yml
+`)
+
+	report, err := Parallelism(action)
+	if err != nil {
+		t.Fatalf("Parallelism failed: %v", err)
+	}
+	want := []WaveParallelism{
+		{Jobs: []string{"lint", "test"}, Runners: 7},
+		{Jobs: []string{"dynamic", "e2e"}, Runners: 3},
+		{Jobs: []string{"deploy"}, Runners: 1},
+	}
+	if !reflect.DeepEqual(report.Waves, want) {
+		t.Errorf("Waves = %+v, want %+v", report.Waves, want)
+	}
+	if report.MaxRunners != 7 {
+		t.Errorf("Expected MaxRunners 7, got %d", report.MaxRunners)
+	}
+	if !reflect.DeepEqual(report.Computed, []string{"dynamic"}) {
+		t.Errorf("Expected computed matrix of dynamic, got %v", report.Computed)
+	}
+
+	if _, err := Parallelism(&ActionFile{Name: "action"}); err == nil {
+		t.Error("Expected error for an action")
+	}
+}
@@ -1,168 +1,241 @@
 package parser
 
 import (
+	"bytes"
 	"fmt"
 	"io"
-	"os"
-	"path/filepath"
 
 	"gopkg.in/yaml.v3"
 )
 
 // ActionFile represents the structure of a GitHub Action YAML file
 type ActionFile struct {
-	Name        string                 `yaml:"name,omitempty"`
-	Description string                 `yaml:"description,omitempty"`
-	Author      string                 `yaml:"author,omitempty"`
-	Inputs      map[string]Input       `yaml:"inputs,omitempty"`
-	Outputs     map[string]Output      `yaml:"outputs,omitempty"`
-	Runs        RunsConfig             `yaml:"runs,omitempty"`
-	Branding    Branding               `yaml:"branding,omitempty"`
-	On          interface{}            `yaml:"on,omitempty"`
-	Jobs        map[string]Job         `yaml:"jobs,omitempty"`
-	Env         map[string]string      `yaml:"env,omitempty"`
-	Defaults    map[string]interface{} `yaml:"defaults,omitempty"`
-	Permissions interface{}            `yaml:"permissions,omitempty"`
+	Name        string                 `yaml:"name,omitempty" json:"name,omitempty"`
+	RunName     string                 `yaml:"run-name,omitempty" json:"run-name,omitempty"`
+	Description string                 `yaml:"description,omitempty" json:"description,omitempty"`
+	Author      string                 `yaml:"author,omitempty" json:"author,omitempty"`
+	Inputs      map[string]Input       `yaml:"inputs,omitempty" json:"inputs,omitempty"`
+	Outputs     map[string]Output      `yaml:"outputs,omitempty" json:"outputs,omitempty"`
+	Runs        RunsConfig             `yaml:"runs,omitempty" json:"runs,omitempty"`
+	Branding    Branding               `yaml:"branding,omitempty" json:"branding,omitempty"`
+	On          interface{}            `yaml:"on,omitempty" json:"on,omitempty"`
+	Jobs        map[string]Job         `yaml:"jobs,omitempty" json:"jobs,omitempty"`
+	Env         EnvMap                 `yaml:"env,omitempty" json:"env,omitempty"`
+	Defaults    map[string]interface{} `yaml:"defaults,omitempty" json:"defaults,omitempty"`
+	Permissions interface{}            `yaml:"permissions,omitempty" json:"permissions,omitempty"`
+	Concurrency interface{}            `yaml:"concurrency,omitempty" json:"concurrency,omitempty"`
+
+	// Positions maps field paths to their location in the source document,
+	// it is only populated when parsing with WithPositions
+	Positions map[string]Position `yaml:"-" json:"-"`
+
+	// Extensions maps the field paths of x- prefixed keys to their values,
+	// it is only populated for the levels allowed with WithExtensions
+	Extensions map[string]interface{} `yaml:"-" json:"-"`
+
+	// Diagnostics lists problems with the source document that parsing
+	// worked around, such as a byte order mark. The Linter reports them.
+	Diagnostics []ValidationError `yaml:"-" json:"-"`
+
+	// Disabled marks a file parked by a naming convention, such as
+	// ci.yml.disabled, that ParseDir returned because of WithDisabled
+	Disabled bool `yaml:"-" json:"-"`
+
+	// matrixOrder maps job IDs to their matrix keys in source order, for
+	// matrices with several keys, since Job.Strategy does not keep it
+	matrixOrder map[string][]string
 }
 
 // Input represents an input parameter for the action
 type Input struct {
-	Description string `yaml:"description,omitempty"`
-	Required    bool   `yaml:"required,omitempty"`
-	Default     string `yaml:"default,omitempty"`
-	Deprecated  bool   `yaml:"deprecated,omitempty"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	Required    bool   `yaml:"required,omitempty" json:"required,omitempty"`
+	Default     string `yaml:"default,omitempty" json:"default,omitempty"`
+	// Type is the type of a reusable or manually triggered workflow input:
+	// boolean, number, string, choice or environment
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
+	// Deprecated is kept for compatibility; GitHub's metadata syntax uses
+	// DeprecationMessage
+	Deprecated         bool   `yaml:"deprecated,omitempty" json:"deprecated,omitempty"`
+	DeprecationMessage string `yaml:"deprecationMessage,omitempty" json:"deprecationMessage,omitempty"`
+}
+
+// IsDeprecated reports whether the input is marked as deprecated
+func (i Input) IsDeprecated() bool {
+	return i.Deprecated || i.DeprecationMessage != ""
 }
 
 // Output represents an output value from the action
 type Output struct {
-	Description string `yaml:"description,omitempty"`
-	Value       string `yaml:"value,omitempty"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	Value       string `yaml:"value,omitempty" json:"value,omitempty"`
 }
 
 // RunsConfig defines how the action is executed
 type RunsConfig struct {
-	Using      string                 `yaml:"using,omitempty"`
-	Main       string                 `yaml:"main,omitempty"`
-	Pre        string                 `yaml:"pre,omitempty"`
-	PreIf      string                 `yaml:"pre-if,omitempty"`
-	Post       string                 `yaml:"post,omitempty"`
-	PostIf     string                 `yaml:"post-if,omitempty"`
-	Steps      []Step                 `yaml:"steps,omitempty"`
-	Image      string                 `yaml:"image,omitempty"`
-	Entrypoint string                 `yaml:"entrypoint,omitempty"`
-	Args       []string               `yaml:"args,omitempty"`
-	Env        map[string]string      `yaml:"env,omitempty"`
-	Shell      string                 `yaml:"shell,omitempty"`
-	Command    string                 `yaml:"command,omitempty"`
-	With       map[string]interface{} `yaml:"with,omitempty"`
+	Using          string                 `yaml:"using,omitempty" json:"using,omitempty"`
+	Main           string                 `yaml:"main,omitempty" json:"main,omitempty"`
+	Pre            string                 `yaml:"pre,omitempty" json:"pre,omitempty"`
+	PreIf          string                 `yaml:"pre-if,omitempty" json:"pre-if,omitempty"`
+	Post           string                 `yaml:"post,omitempty" json:"post,omitempty"`
+	PostIf         string                 `yaml:"post-if,omitempty" json:"post-if,omitempty"`
+	Steps          []Step                 `yaml:"steps,omitempty" json:"steps,omitempty"`
+	Image          string                 `yaml:"image,omitempty" json:"image,omitempty"`
+	Entrypoint     string                 `yaml:"entrypoint,omitempty" json:"entrypoint,omitempty"`
+	PreEntrypoint  string                 `yaml:"pre-entrypoint,omitempty" json:"pre-entrypoint,omitempty"`
+	PostEntrypoint string                 `yaml:"post-entrypoint,omitempty" json:"post-entrypoint,omitempty"`
+	Args           []string               `yaml:"args,omitempty" json:"args,omitempty"`
+	Env            EnvMap                 `yaml:"env,omitempty" json:"env,omitempty"`
+	Shell          string                 `yaml:"shell,omitempty" json:"shell,omitempty"`
+	Command        string                 `yaml:"command,omitempty" json:"command,omitempty"`
+	With           map[string]interface{} `yaml:"with,omitempty" json:"with,omitempty"`
 }
 
 // Step represents a single step in a workflow job
 type Step struct {
-	ID         string                 `yaml:"id,omitempty"`
-	If         string                 `yaml:"if,omitempty"`
-	Name       string                 `yaml:"name,omitempty"`
-	Uses       string                 `yaml:"uses,omitempty"`
-	Run        string                 `yaml:"run,omitempty"`
-	Shell      string                 `yaml:"shell,omitempty"`
-	With       map[string]interface{} `yaml:"with,omitempty"`
-	Env        map[string]string      `yaml:"env,omitempty"`
-	ContinueOn interface{}            `yaml:"continue-on-error,omitempty"`
-	TimeoutMin int                    `yaml:"timeout-minutes,omitempty"`
-	WorkingDir string                 `yaml:"working-directory,omitempty"`
+	ID         string                 `yaml:"id,omitempty" json:"id,omitempty"`
+	If         string                 `yaml:"if,omitempty" json:"if,omitempty"`
+	Name       string                 `yaml:"name,omitempty" json:"name,omitempty"`
+	Uses       string                 `yaml:"uses,omitempty" json:"uses,omitempty"`
+	Run        string                 `yaml:"run,omitempty" json:"run,omitempty"`
+	Shell      string                 `yaml:"shell,omitempty" json:"shell,omitempty"`
+	With       map[string]interface{} `yaml:"with,omitempty" json:"with,omitempty"`
+	Env        EnvMap                 `yaml:"env,omitempty" json:"env,omitempty"`
+	ContinueOn interface{}            `yaml:"continue-on-error,omitempty" json:"continue-on-error,omitempty"`
+	TimeoutMin int                    `yaml:"timeout-minutes,omitempty" json:"timeout-minutes,omitempty"`
+	WorkingDir string                 `yaml:"working-directory,omitempty" json:"working-directory,omitempty"`
 }
 
 // Job represents a workflow job
 type Job struct {
-	Name           string                 `yaml:"name,omitempty"`
-	Needs          interface{}            `yaml:"needs,omitempty"`
-	RunsOn         interface{}            `yaml:"runs-on,omitempty"`
-	Container      interface{}            `yaml:"container,omitempty"`
-	Services       map[string]interface{} `yaml:"services,omitempty"`
-	Outputs        map[string]string      `yaml:"outputs,omitempty"`
-	Env            map[string]string      `yaml:"env,omitempty"`
-	Defaults       map[string]interface{} `yaml:"defaults,omitempty"`
-	If             string                 `yaml:"if,omitempty"`
-	Steps          []Step                 `yaml:"steps,omitempty"`
-	TimeoutMin     int                    `yaml:"timeout-minutes,omitempty"`
-	Strategy       map[string]interface{} `yaml:"strategy,omitempty"`
-	ContinueOn     interface{}            `yaml:"continue-on-error,omitempty"`
-	Permissions    interface{}            `yaml:"permissions,omitempty"`
-	ConcurrencyKey string                 `yaml:"concurrency,omitempty"`
-	Uses           string                 `yaml:"uses,omitempty"`
-	With           map[string]interface{} `yaml:"with,omitempty"`
-	Secrets        interface{}            `yaml:"secrets,omitempty"`
+	Name           string                 `yaml:"name,omitempty" json:"name,omitempty"`
+	Needs          interface{}            `yaml:"needs,omitempty" json:"needs,omitempty"`
+	RunsOn         interface{}            `yaml:"runs-on,omitempty" json:"runs-on,omitempty"`
+	Container      interface{}            `yaml:"container,omitempty" json:"container,omitempty"`
+	Services       map[string]interface{} `yaml:"services,omitempty" json:"services,omitempty"`
+	Outputs        map[string]string      `yaml:"outputs,omitempty" json:"outputs,omitempty"`
+	Env            EnvMap                 `yaml:"env,omitempty" json:"env,omitempty"`
+	Defaults       map[string]interface{} `yaml:"defaults,omitempty" json:"defaults,omitempty"`
+	If             string                 `yaml:"if,omitempty" json:"if,omitempty"`
+	Steps          []Step                 `yaml:"steps,omitempty" json:"steps,omitempty"`
+	TimeoutMin     int                    `yaml:"timeout-minutes,omitempty" json:"timeout-minutes,omitempty"`
+	Strategy       map[string]interface{} `yaml:"strategy,omitempty" json:"strategy,omitempty"`
+	ContinueOn     interface{}            `yaml:"continue-on-error,omitempty" json:"continue-on-error,omitempty"`
+	Permissions    interface{}            `yaml:"permissions,omitempty" json:"permissions,omitempty"`
+	ConcurrencyKey interface{}            `yaml:"concurrency,omitempty" json:"concurrency,omitempty"`
+	Environment    interface{}            `yaml:"environment,omitempty" json:"environment,omitempty"`
+	Uses           string                 `yaml:"uses,omitempty" json:"uses,omitempty"`
+	With           map[string]interface{} `yaml:"with,omitempty" json:"with,omitempty"`
+	Secrets        interface{}            `yaml:"secrets,omitempty" json:"secrets,omitempty"`
 }
 
 // Branding defines the visual branding of the action
 type Branding struct {
-	Icon  string `yaml:"icon,omitempty"`
-	Color string `yaml:"color,omitempty"`
+	Icon  string `yaml:"icon,omitempty" json:"icon,omitempty"`
+	Color string `yaml:"color,omitempty" json:"color,omitempty"`
+}
+
+// Parse parses a GitHub Action YAML from an io.Reader. The document is
+// decoded as it is read unless templates, includes or positions require it
+// in memory first.
+func Parse(r io.Reader, opts ...Option) (*ActionFile, error) {
+	return parse(r, newOptions(opts))
 }
 
-// ParseFile parses a GitHub Action YAML file at the specified path
-func ParseFile(path string) (*ActionFile, error) {
-	file, err := os.Open(path)
+// parse implements Parse with options that have already been applied
+func parse(r io.Reader, o *options) (*ActionFile, error) {
+	if !o.needsBuffer() {
+		return decodeStream(r, o)
+	}
+
+	data, diagnostics, err := readAll(r, o)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
+		return nil, err
 	}
-	defer file.Close()
 
-	return Parse(file)
+	action, err := decode(data, o)
+	if err != nil {
+		return nil, err
+	}
+	action.Diagnostics = diagnostics
+	return action, nil
 }
 
-// Parse parses a GitHub Action YAML from an io.Reader
-func Parse(r io.Reader) (*ActionFile, error) {
+// readAll reads a whole document, enforcing the maximum file size, fixing
+// its encoding and expanding template placeholders and includes. It returns
+// the diagnostics of the encoding fixes.
+func readAll(r io.Reader, o *options) ([]byte, []ValidationError, error) {
+	if o.maxFileSize > 0 {
+		r = io.LimitReader(r, o.maxFileSize+1)
+	}
+
 	data, err := io.ReadAll(r)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read data: %w", err)
+		return nil, nil, fmt.Errorf("failed to read data: %w", err)
 	}
 
-	var action ActionFile
-	if err := yaml.Unmarshal(data, &action); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal YAML: %w", err)
+	if o.maxFileSize > 0 && int64(len(data)) > o.maxFileSize {
+		return nil, nil, classify(ErrTooLarge, fmt.Errorf("file exceeds maximum size of %d bytes", o.maxFileSize))
 	}
 
-	return &action, nil
-}
-
-// ParseDir parses all GitHub Action YAML files in a directory recursively
-func ParseDir(dir string) (map[string]*ActionFile, error) {
-	result := make(map[string]*ActionFile)
+	data, diagnostics := normalizeEncoding(data)
 
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
+	if o.templateVars != nil {
+		var unresolved []Placeholder
+		data, unresolved = ExpandTemplate(data, o.templateVars)
+		if len(unresolved) > 0 {
+			return nil, nil, &TemplateError{Unresolved: unresolved}
 		}
+	}
 
-		// Skip directories
-		if info.IsDir() {
-			return nil
+	if o.includes {
+		data, err = ExpandIncludes(data, o.includeDir)
+		if err != nil {
+			return nil, nil, err
 		}
+	}
 
-		// Only process YAML files
-		ext := filepath.Ext(path)
-		if ext != ".yml" && ext != ".yaml" {
-			return nil
-		}
+	return data, diagnostics, nil
+}
 
-		action, err := ParseFile(path)
-		if err != nil {
-			return fmt.Errorf("failed to parse %s: %w", path, err)
-		}
+// decode decodes a single YAML document into an ActionFile
+func decode(data []byte, o *options) (*ActionFile, error) {
+	data = normalizeOnKey(data)
+	source := data
 
-		relativePath, err := filepath.Rel(dir, path)
-		if err != nil {
-			return fmt.Errorf("failed to get relative path: %w", err)
+	var extensions map[string]interface{}
+	if len(o.extensions) > 0 {
+		var err error
+		if data, extensions, err = applyExtensionPolicies(data, o.extensions); err != nil {
+			return nil, err
 		}
+	}
 
-		result[relativePath] = action
-		return nil
-	})
+	var action ActionFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(o.strict)
+	for _, hook := range o.decoderHooks {
+		hook(decoder)
+	}
+	if err := decoder.Decode(&action); err != nil && err != io.EOF {
+		return nil, classify(decodeErrorKind(err), fmt.Errorf("failed to unmarshal YAML: %w", err))
+	}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	ordered := hasOrderedMatrix(&action)
+	if o.positions || ordered {
+		var node yaml.Node
+		if err := yaml.Unmarshal(source, &node); err != nil {
+			return nil, classify(ErrInvalidYAML, fmt.Errorf("failed to unmarshal YAML: %w", err))
+		}
+		if o.positions {
+			action.Positions = make(map[string]Position)
+			collectPositions(&node, "", action.Positions)
+		}
+		if ordered {
+			action.matrixOrder = matrixKeyOrder(&node)
+		}
 	}
+	action.Extensions = extensions
 
-	return result, nil
+	return &action, nil
 }
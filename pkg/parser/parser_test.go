@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -521,7 +522,7 @@ func TestIsValidMethod(t *testing.T) {
 	}
 
 	// Add an error and check again
-	validator.addError("test", "test error")
+	validator.addError("test", "test", "test error")
 	if validator.IsValid() {
 		t.Errorf("Expected validator with errors to be invalid")
 	}
@@ -785,6 +786,53 @@ func TestStringOrStringSliceUnmarshalYAML(t *testing.T) {
 	}
 }
 
+// TestEnvMap tests decoding env mappings of scalars into strings
+func TestEnvMap(t *testing.T) {
+	action, err := Parse(strings.NewReader(`
+on: push
+env:
+  RETRIES: 3
+  DEBUG: true
+  RATIO: 1.50
+  EMPTY:
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    env:
+      PORT: 8080
+    steps:
+      - run: make
+        env:
+          VERBOSE: false
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	want := EnvMap{"RETRIES": "3", "DEBUG": "true", "RATIO": "1.50", "EMPTY": ""}
+	if fmt.Sprint(action.Env) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, action.Env)
+	}
+	if action.Jobs["build"].Env["PORT"] != "8080" || action.Jobs["build"].Steps[0].Env["VERBOSE"] != "false" {
+		t.Errorf("Unexpected job or step env: %v %v", action.Jobs["build"].Env, action.Jobs["build"].Steps[0].Env)
+	}
+
+	if _, err := Parse(strings.NewReader("on: push\nenv:\n  LIST: [a, b]\n")); err == nil {
+		t.Error("Expected error for a list env value")
+	}
+
+	var env EnvMap
+	if err := json.Unmarshal([]byte(`{"RETRIES": 3, "DEBUG": true, "NAME": "x", "EMPTY": null}`), &env); err != nil {
+		t.Fatalf("Failed to unmarshal JSON: %v", err)
+	}
+	want = EnvMap{"RETRIES": "3", "DEBUG": "true", "NAME": "x", "EMPTY": ""}
+	if fmt.Sprint(env) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, env)
+	}
+	if err := json.Unmarshal([]byte(`{"LIST": [1]}`), &env); err == nil {
+		t.Error("Expected error for a list env value")
+	}
+}
+
 // TestIsReusableWorkflow tests the IsReusableWorkflow function
 func TestIsReusableWorkflow(t *testing.T) {
 	// Test with workflow_call in map[string]interface{}
@@ -1212,6 +1260,49 @@ runs:
 	}
 }
 
+// TestParseDirDisabled tests that parked workflows are left out unless
+// WithDisabled is given
+func TestParseDirDisabled(t *testing.T) {
+	tempDir := t.TempDir()
+	workflow := "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n"
+	for _, name := range []string{"ci.yml", "nightly.yml.disabled", "release.yaml.off", filepath.Join("disabled", "old.yml")} {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(workflow), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	active, err := ParseDir(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to parse directory: %v", err)
+	}
+	if got := active.Paths(); len(got) != 1 || got[0] != "ci.yml" {
+		t.Errorf("Expected only ci.yml, got %v", got)
+	}
+
+	all, err := ParseDir(tempDir, WithDisabled())
+	if err != nil {
+		t.Fatalf("Failed to parse directory: %v", err)
+	}
+	want := map[string]bool{
+		"ci.yml":                             false,
+		"nightly.yml.disabled":               true,
+		"release.yaml.off":                   true,
+		filepath.Join("disabled", "old.yml"): true,
+	}
+	if len(all) != len(want) {
+		t.Errorf("Expected %d files, got %v", len(want), all.Paths())
+	}
+	for path, disabled := range want {
+		if action, ok := all[path]; !ok || action.Disabled != disabled {
+			t.Errorf("Expected %s with Disabled=%v, got %+v", path, disabled, action)
+		}
+	}
+}
+
 // TestParseDirWithInvalidYAML tests ParseDir with invalid YAML files
 func TestParseDirWithInvalidYAML(t *testing.T) {
 	tempDir := t.TempDir()
@@ -1239,6 +1330,104 @@ runs:
 	}
 }
 
+func TestExtractSecretsFromWorkflowCall(t *testing.T) {
+	action, err := ParseFile("testdata/reusable-workflow.yml")
+	if err != nil {
+		t.Fatalf("Failed to parse reusable workflow: %v", err)
+	}
+
+	secrets, err := ExtractSecretsFromWorkflowCall(action)
+	if err != nil {
+		t.Fatalf("Failed to extract secrets: %v", err)
+	}
+	secret, ok := secrets["npm-token"]
+	if !ok {
+		t.Fatalf("Expected 'npm-token' secret, got %v", secrets)
+	}
+	if secret.Required || secret.Description != "NPM token for private packages" {
+		t.Errorf("Unexpected secret %+v", secret)
+	}
+
+	secrets, err = ExtractSecretsFromWorkflowCall(&ActionFile{On: "push"})
+	if err != nil || len(secrets) != 0 {
+		t.Errorf("Expected no secrets for a regular workflow, got %v, %v", secrets, err)
+	}
+}
+
+func TestParseJobSecrets(t *testing.T) {
+	secrets, err := ParseJobSecrets(Job{Secrets: "inherit"})
+	if err != nil || !secrets.Inherit {
+		t.Errorf("Expected inherit, got %+v, %v", secrets, err)
+	}
+
+	secrets, err = ParseJobSecrets(Job{Secrets: map[string]interface{}{"token": "${{ secrets.TOKEN }}"}})
+	if err != nil || secrets.Inherit || secrets.Values["token"] != "${{ secrets.TOKEN }}" {
+		t.Errorf("Expected explicit secrets, got %+v, %v", secrets, err)
+	}
+
+	if _, err := ParseJobSecrets(Job{Secrets: "all"}); err == nil {
+		t.Error("Expected an error for an unknown keyword")
+	}
+}
+
+func TestValidateJobSecrets(t *testing.T) {
+	called := &ActionFile{
+		On: map[string]interface{}{
+			"workflow_call": map[string]interface{}{
+				"secrets": map[string]interface{}{
+					"deploy-key": map[string]interface{}{"required": true},
+					"optional":   map[string]interface{}{"required": false},
+				},
+			},
+		},
+		Jobs: map[string]Job{},
+	}
+	resolver := mapResolver{"./.github/workflows/deploy.yml": called}
+
+	workflow := &ActionFile{
+		On: "push",
+		Jobs: map[string]Job{
+			"explicit": {
+				Uses:    "./.github/workflows/deploy.yml",
+				Secrets: map[string]interface{}{"typo": "${{ secrets.KEY }}"},
+			},
+			"inherit": {
+				Uses:    "./.github/workflows/deploy.yml",
+				Secrets: "inherit",
+			},
+			"steps": {
+				RunsOn:  "ubuntu-latest",
+				Secrets: "inherit",
+				Steps:   []Step{{Run: "echo"}},
+			},
+		},
+	}
+
+	fields := make(map[string]string)
+	for _, e := range NewValidator(WithResolver(resolver)).Validate(workflow) {
+		fields[e.Field] += e.Message + ";"
+	}
+	if !strings.Contains(fields["jobs.explicit.secrets"], "deploy-key") {
+		t.Errorf("Expected missing required secret to be reported, got %v", fields)
+	}
+	if _, ok := fields["jobs.explicit.secrets.typo"]; !ok {
+		t.Errorf("Expected undeclared secret to be reported, got %v", fields)
+	}
+	if _, ok := fields["jobs.inherit.secrets"]; ok {
+		t.Errorf("Expected inherited secrets to be accepted, got %v", fields)
+	}
+	if _, ok := fields["jobs.steps.secrets"]; !ok {
+		t.Errorf("Expected secrets on a regular job to be reported, got %v", fields)
+	}
+
+	// Without a resolver only the shape of the secrets is checked
+	for _, e := range NewValidator().Validate(workflow) {
+		if strings.HasPrefix(e.Field, "jobs.explicit") {
+			t.Errorf("Unexpected error without a resolver: %v", e)
+		}
+	}
+}
+
 // Benchmark tests for performance measurement
 
 // BenchmarkParseFile benchmarks the ParseFile function
@@ -1338,3 +1527,44 @@ func BenchmarkExtractInputsFromWorkflowCall(b *testing.B) {
 		}
 	}
 }
+
+// writeCorpus writes n copies of the files in testdata into nested
+// directories below dir, like the workflows of a large monorepo
+func writeCorpus(tb testing.TB, dir string, n int) {
+	tb.Helper()
+	var sources [][]byte
+	for _, name := range []string{"workflow.yml", "action.yml", "reusable-workflow.yml"} {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			tb.Fatal(err)
+		}
+		sources = append(sources, data)
+	}
+	for i := 0; i < n; i++ {
+		sub := filepath.Join(dir, fmt.Sprintf("service-%02d", i%50), ".github", "workflows")
+		if err := os.MkdirAll(sub, 0o755); err != nil {
+			tb.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(sub, fmt.Sprintf("ci-%04d.yml", i)), sources[i%len(sources)], 0o644); err != nil {
+			tb.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkParseDir benchmarks parsing a corpus of 1000 files
+func BenchmarkParseDir(b *testing.B) {
+	dir := b.TempDir()
+	writeCorpus(b, dir, 1000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		files, err := ParseDir(dir)
+		if err != nil {
+			b.Fatalf("Failed to parse directory: %v", err)
+		}
+		if len(files) != 1000 {
+			b.Fatalf("Expected 1000 files, got %d", len(files))
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// SectionError describes a region of a document that could not be decoded
+type SectionError struct {
+	// Path is the field path of the broken region, e.g. "jobs.build"
+	Path string
+	// Line is the first line of the broken region
+	Line int
+	Err  error
+}
+
+func (e *SectionError) Error() string {
+	return fmt.Sprintf("%s (line %d): %v", e.Path, e.Line, e.Err)
+}
+
+func (e *SectionError) Unwrap() error {
+	return e.Err
+}
+
+// PartialError is returned by ParsePartial when some regions of a document
+// could not be decoded
+type PartialError struct {
+	Errors []*SectionError
+}
+
+func (e *PartialError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, sectionErr := range e.Errors {
+		msgs[i] = sectionErr.Error()
+	}
+	return fmt.Sprintf("failed to decode %d section(s): %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+func (e *PartialError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, sectionErr := range e.Errors {
+		errs[i] = sectionErr
+	}
+	return errs
+}
+
+// ParsePartial parses a GitHub Action YAML from an io.Reader, recovering from
+// errors confined to parts of the document.
+//
+// When the document does not decode as a whole, each top-level section is
+// decoded on its own, and sections that still fail are split further into
+// their entries (for example the individual jobs under 'jobs'). Everything
+// that decodes is returned in the ActionFile, alongside a *PartialError
+// listing the regions that were dropped. Line numbers in errors and positions
+// refer to the original document.
+func ParsePartial(r io.Reader, opts ...Option) (*ActionFile, error) {
+	o := newOptions(opts)
+
+	data, diagnostics, err := readAll(r, o)
+	if err != nil {
+		return nil, err
+	}
+
+	action, err := decode(data, o)
+	if err == nil {
+		action.Diagnostics = diagnostics
+		return action, nil
+	}
+
+	lines := strings.SplitAfter(string(data), "\n")
+	sections := splitBlocks(lines, 0, len(lines))
+	if len(sections) == 0 {
+		return &ActionFile{}, &PartialError{Errors: []*SectionError{{Line: 1, Err: err}}}
+	}
+
+	action = &ActionFile{Diagnostics: diagnostics}
+	var failures []*SectionError
+
+	for _, section := range sections {
+		doc := strings.Repeat("\n", section.start) + strings.Join(lines[section.start:section.end], "")
+		part, err := decode([]byte(doc), o)
+		if err == nil {
+			mergePart(action, part)
+			continue
+		}
+
+		entries := splitBlocks(lines, section.start+1, section.end)
+		if len(entries) == 0 {
+			failures = append(failures, &SectionError{Path: section.key, Line: section.start + 1, Err: err})
+			continue
+		}
+
+		for _, entry := range entries {
+			doc := strings.Repeat("\n", section.start) + lines[section.start] +
+				strings.Repeat("\n", entry.start-section.start-1) + strings.Join(lines[entry.start:entry.end], "")
+			part, err := decode([]byte(doc), o)
+			if err != nil {
+				failures = append(failures, &SectionError{
+					Path: section.key + "." + entry.key,
+					Line: entry.start + 1,
+					Err:  err,
+				})
+				continue
+			}
+			mergePart(action, part)
+		}
+	}
+
+	if len(failures) > 0 {
+		return action, &PartialError{Errors: failures}
+	}
+	return action, nil
+}
+
+// block is a mapping entry spanning lines[start:end]
+type block struct {
+	key        string
+	start, end int
+}
+
+// splitBlocks splits lines[from:to] into the mapping entries found at the
+// indentation of the first content line. It returns nil if the lines do not
+// hold a block mapping.
+func splitBlocks(lines []string, from, to int) []block {
+	indent := -1
+	var blocks []block
+
+	for i := from; i < to; i++ {
+		trimmed := strings.TrimLeft(lines[i], " ")
+		content := strings.TrimSpace(trimmed)
+		if content == "" || strings.HasPrefix(content, "#") || content == "---" {
+			continue
+		}
+
+		lineIndent := len(lines[i]) - len(trimmed)
+		if indent == -1 {
+			if strings.HasPrefix(content, "-") {
+				return nil
+			}
+			indent = lineIndent
+		}
+		if lineIndent != indent {
+			continue
+		}
+
+		colon := strings.Index(content, ":")
+		if colon <= 0 {
+			return nil
+		}
+		if len(blocks) > 0 {
+			blocks[len(blocks)-1].end = i
+		}
+		blocks = append(blocks, block{
+			key:   strings.Trim(content[:colon], `"' `),
+			start: i,
+			end:   to,
+		})
+	}
+
+	return blocks
+}
+
+// mergePart merges a partial decode into action, including the unexported
+// state mergeValue cannot reach
+func mergePart(action, part *ActionFile) {
+	mergeValue(reflect.ValueOf(action).Elem(), reflect.ValueOf(part).Elem())
+	for jobID, order := range part.matrixOrder {
+		if action.matrixOrder == nil {
+			action.matrixOrder = make(map[string][]string)
+		}
+		action.matrixOrder[jobID] = order
+	}
+}
+
+// mergeValue copies the non-zero parts of src into dst, merging maps and
+// structs entry by entry so that partial decodes can be combined. Unexported
+// struct fields are skipped.
+func mergeValue(dst, src reflect.Value) {
+	if src.IsZero() {
+		return
+	}
+
+	switch src.Kind() {
+	case reflect.Struct:
+		for i := 0; i < src.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				mergeValue(dst.Field(i), src.Field(i))
+			}
+		}
+	case reflect.Map:
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		}
+		iter := src.MapRange()
+		for iter.Next() {
+			dst.SetMapIndex(iter.Key(), iter.Value())
+		}
+	case reflect.Interface:
+		dstMap, dstOK := dst.Interface().(map[string]interface{})
+		srcMap, srcOK := src.Interface().(map[string]interface{})
+		if dstOK && srcOK {
+			for k, v := range srcMap {
+				dstMap[k] = v
+			}
+			return
+		}
+		dst.Set(src)
+	default:
+		dst.Set(src)
+	}
+}
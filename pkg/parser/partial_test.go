@@ -0,0 +1,133 @@
+package parser
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+const brokenWorkflow = `name: Partially Broken
+
+on:
+  push:
+    branches: [main]
+
+jobs:
+  good:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ok
+  broken:
+    runs-on: [ubuntu-latest
+    steps:
+      - run: echo broken
+  also-good:
+    needs: good
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ok
+
+env:
+  FOO: bar
+`
+
+func TestParsePartial(t *testing.T) {
+	action, err := ParsePartial(strings.NewReader(brokenWorkflow), WithPositions())
+	if action == nil {
+		t.Fatalf("Expected a partially decoded ActionFile")
+	}
+
+	var partialErr *PartialError
+	if !errors.As(err, &partialErr) {
+		t.Fatalf("Expected a *PartialError, got %v", err)
+	}
+	if len(partialErr.Errors) != 1 {
+		t.Fatalf("Expected 1 section error, got %d: %v", len(partialErr.Errors), err)
+	}
+	if partialErr.Errors[0].Path != "jobs.broken" || partialErr.Errors[0].Line != 12 {
+		t.Errorf("Expected error for jobs.broken at line 12, got %s at line %d",
+			partialErr.Errors[0].Path, partialErr.Errors[0].Line)
+	}
+	if !errors.Is(err, ErrInvalidYAML) {
+		t.Errorf("Expected section errors to match ErrInvalidYAML")
+	}
+
+	if action.Name != "Partially Broken" {
+		t.Errorf("Expected name to be decoded, got '%s'", action.Name)
+	}
+	if action.On == nil {
+		t.Errorf("Expected triggers to be decoded")
+	}
+	if action.Env["FOO"] != "bar" {
+		t.Errorf("Expected env to be decoded")
+	}
+	if len(action.Jobs) != 2 {
+		t.Errorf("Expected 2 decoded jobs, got %d", len(action.Jobs))
+	}
+	if _, ok := action.Jobs["broken"]; ok {
+		t.Errorf("Expected broken job to be dropped")
+	}
+
+	if pos, ok := action.Position("jobs.also-good.needs"); !ok || pos.Line != 17 {
+		t.Errorf("Expected jobs.also-good.needs at line 17, got %v", pos)
+	}
+}
+
+func TestParsePartialValidDocument(t *testing.T) {
+	file, err := os.Open("testdata/workflow.yml")
+	if err != nil {
+		t.Fatalf("Failed to open test file: %v", err)
+	}
+	defer file.Close()
+
+	action, err := ParsePartial(file)
+	if err != nil {
+		t.Fatalf("Expected no error for a valid document, got %v", err)
+	}
+	if len(action.Jobs) != 4 {
+		t.Errorf("Expected 4 jobs, got %d", len(action.Jobs))
+	}
+}
+
+func TestParsePartialMergesNestedStructs(t *testing.T) {
+	doc := `name: Composite
+runs:
+  using: composite
+  steps: [unclosed
+`
+	action, err := ParsePartial(strings.NewReader(doc))
+	if err == nil {
+		t.Fatalf("Expected a partial error")
+	}
+	if action.Runs.Using != "composite" {
+		t.Errorf("Expected runs.using to be recovered, got '%s'", action.Runs.Using)
+	}
+}
+
+func TestParsePartialOrderedMatrix(t *testing.T) {
+	doc := `on: push
+env: [unclosed
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    strategy:
+      matrix:
+        os: [ubuntu]
+        node: [20]
+    steps:
+      - run: make test
+`
+	action, err := ParsePartial(strings.NewReader(doc))
+	var partialErr *PartialError
+	if !errors.As(err, &partialErr) || len(partialErr.Errors) != 1 || partialErr.Errors[0].Path != "env" {
+		t.Fatalf("Expected a section error for env, got %v", err)
+	}
+	names, err := StatusCheckNames(action, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 || names[0] != "test (ubuntu, 20)" {
+		t.Errorf("Expected [test (ubuntu, 20)], got %v", names)
+	}
+}
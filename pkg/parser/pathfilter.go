@@ -0,0 +1,239 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// pathFilterEvents are the events that support 'paths' and 'paths-ignore'
+var pathFilterEvents = []string{"push", "pull_request", "pull_request_target"}
+
+// PathFilter is the 'paths' or 'paths-ignore' configuration of an event
+type PathFilter struct {
+	Paths       []string `yaml:"paths,omitempty" json:"paths,omitempty"`
+	PathsIgnore []string `yaml:"paths-ignore,omitempty" json:"paths-ignore,omitempty"`
+}
+
+// ParsePathFilters returns the path filters of the push and pull request
+// events of a workflow, keyed by event. Events without path filters are
+// omitted.
+func ParsePathFilters(action *ActionFile) (map[string]PathFilter, error) {
+	filters := make(map[string]PathFilter)
+	for _, event := range pathFilterEvents {
+		config, ok, err := triggerConfig(action, event)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		var filter PathFilter
+		if filter.Paths, err = stringList(config["paths"]); err != nil {
+			return nil, fmt.Errorf("invalid %s.paths: %w", event, err)
+		}
+		if filter.PathsIgnore, err = stringList(config["paths-ignore"]); err != nil {
+			return nil, fmt.Errorf("invalid %s.paths-ignore: %w", event, err)
+		}
+		if len(filter.Paths) > 0 || len(filter.PathsIgnore) > 0 {
+			filters[event] = filter
+		}
+	}
+	return filters, nil
+}
+
+// Matches reports whether a change to the given files triggers the event.
+// As on GitHub, 'paths' patterns are evaluated in order and a later
+// '!'-prefixed pattern can exclude files matched earlier; with
+// 'paths-ignore', the event triggers unless every file is ignored.
+func (f PathFilter) Matches(files ...string) bool {
+	for _, file := range files {
+		if len(f.Paths) > 0 {
+			if matchPathPatterns(f.Paths, file) {
+				return true
+			}
+		} else if !matchPathPatterns(f.PathsIgnore, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPathPatterns applies a list of patterns in order, where the last
+// matching pattern decides
+func matchPathPatterns(patterns []string, file string) bool {
+	matched := false
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "!") {
+			if MatchPathPattern(pattern[1:], file) {
+				matched = false
+			}
+		} else if MatchPathPattern(pattern, file) {
+			matched = true
+		}
+	}
+	return matched
+}
+
+// MatchPathPattern reports whether a file path matches a GitHub filter
+// pattern. '*' matches any characters except '/', '**' matches any
+// characters, and '?' and '+' make the preceding character optional or
+// repeatable.
+func MatchPathPattern(pattern, file string) bool {
+	re, err := pathPatternRegexp(pattern)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(file)
+}
+
+// pathPatternRegexp translates a filter pattern into a regular expression
+func pathPatternRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?' || c == '+':
+			b.WriteByte(c)
+		case c == '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated character class in %q", pattern)
+			}
+			b.WriteString(pattern[i : i+end+1])
+			i += end
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// PathTrigger is a workflow event triggered by a change
+type PathTrigger struct {
+	File  string `json:"file"`
+	Event string `json:"event"`
+}
+
+// WorkflowsTriggeredBy returns every workflow event, among those supporting
+// path filters, that a change to the given files would trigger. Workflows are
+// keyed by file as returned by ParseDir.
+func WorkflowsTriggeredBy(workflows map[string]*ActionFile, files ...string) []PathTrigger {
+	var triggers []PathTrigger
+	for file, workflow := range workflows {
+		filters, err := ParsePathFilters(workflow)
+		if err != nil {
+			continue
+		}
+		for _, event := range pathFilterEvents {
+			if _, ok, _ := triggerConfig(workflow, event); !ok {
+				continue
+			}
+			if filter, ok := filters[event]; ok && !filter.Matches(files...) {
+				continue
+			}
+			triggers = append(triggers, PathTrigger{File: file, Event: event})
+		}
+	}
+	sort.Slice(triggers, func(i, j int) bool {
+		if triggers[i].File != triggers[j].File {
+			return triggers[i].File < triggers[j].File
+		}
+		return triggers[i].Event < triggers[j].Event
+	})
+	return triggers
+}
+
+// DeadPathFilter is a path filter that can never trigger its workflow
+type DeadPathFilter struct {
+	File  string `json:"file"`
+	Event string `json:"event"`
+	// Patterns lists the offending patterns, or is empty when only the
+	// combination of patterns never matches
+	Patterns []string `json:"patterns,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// FindDeadPathFilters reports 'paths' filters that match none of the given
+// repository files, typically the output of 'git ls-files'. Patterns written
+// as absolute or './'-relative paths are always reported, since GitHub
+// matches against paths relative to the repository root.
+func FindDeadPathFilters(workflows map[string]*ActionFile, repoFiles []string) []DeadPathFilter {
+	var findings []DeadPathFilter
+	for file, workflow := range workflows {
+		filters, err := ParsePathFilters(workflow)
+		if err != nil {
+			continue
+		}
+		for _, event := range pathFilterEvents {
+			filter, ok := filters[event]
+			if !ok || len(filter.Paths) == 0 {
+				continue
+			}
+
+			var rooted, unmatched []string
+			for _, pattern := range filter.Paths {
+				p := strings.TrimPrefix(pattern, "!")
+				if strings.HasPrefix(p, "/") || strings.HasPrefix(p, "./") {
+					rooted = append(rooted, pattern)
+					continue
+				}
+				if !strings.HasPrefix(pattern, "!") && !matchesAnyFile(p, repoFiles) {
+					unmatched = append(unmatched, pattern)
+				}
+			}
+
+			switch {
+			case len(rooted) > 0:
+				findings = append(findings, DeadPathFilter{
+					File: file, Event: event, Patterns: rooted,
+					Message: "path patterns are relative to the repository root and must not start with '/' or './'",
+				})
+			case len(unmatched) > 0:
+				findings = append(findings, DeadPathFilter{
+					File: file, Event: event, Patterns: unmatched,
+					Message: "path patterns match no file in the repository",
+				})
+			case !filter.Matches(repoFiles...):
+				findings = append(findings, DeadPathFilter{
+					File: file, Event: event,
+					Message: "negated patterns exclude every file the path filter matches",
+				})
+			}
+		}
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		return findings[i].Event < findings[j].Event
+	})
+	return findings
+}
+
+func matchesAnyFile(pattern string, files []string) bool {
+	re, err := pathPatternRegexp(pattern)
+	if err != nil {
+		return false
+	}
+	for _, f := range files {
+		if re.MatchString(f) {
+			return true
+		}
+	}
+	return false
+}
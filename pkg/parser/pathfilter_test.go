@@ -0,0 +1,147 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestMatchPathPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		file    string
+		want    bool
+	}{
+		{"docs/*", "docs/index.md", true},
+		{"docs/*", "docs/api/index.md", false},
+		{"docs/**", "docs/api/index.md", true},
+		{"**.js", "src/app/main.js", true},
+		{"*.js", "src/main.js", false},
+		{"**/README.md", "README.md", true},
+		{"**/README.md", "pkg/README.md", true},
+		{"*.jsx?", "app.js", true},
+		{"*.jsx?", "app.jsx", true},
+		{"v[12].txt", "v2.txt", true},
+		{"v[12].txt", "v3.txt", false},
+		{"src/main.go", "src/main.go", true},
+		{"src/main.go", "src/mainXgo", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchPathPattern(tt.pattern, tt.file); got != tt.want {
+			t.Errorf("MatchPathPattern(%q, %q) = %v, want %v", tt.pattern, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestPathFilterMatches(t *testing.T) {
+	paths := PathFilter{Paths: []string{"src/**", "!src/**/*.md"}}
+	if !paths.Matches("src/main.go") {
+		t.Error("Expected src/main.go to match")
+	}
+	if paths.Matches("src/docs/notes.md") {
+		t.Error("Expected a negated pattern to exclude markdown files")
+	}
+	if !paths.Matches("README.md", "src/main.go") {
+		t.Error("Expected a change with one matching file to trigger")
+	}
+
+	ignore := PathFilter{PathsIgnore: []string{"docs/**"}}
+	if ignore.Matches("docs/a.md", "docs/b.md") {
+		t.Error("Expected a change to ignored files only not to trigger")
+	}
+	if !ignore.Matches("docs/a.md", "main.go") {
+		t.Error("Expected a change to a non-ignored file to trigger")
+	}
+}
+
+func TestWorkflowsTriggeredBy(t *testing.T) {
+	workflows := map[string]*ActionFile{
+		"backend.yml": mustParse(t, `
+on:
+  push:
+    paths: ['server/**']
+  pull_request:
+    paths: ['server/**']
+jobs: {}
+`),
+		"docs.yml": mustParse(t, `
+on:
+  push:
+    paths: ['docs/**']
+jobs: {}
+`),
+		"ci.yml": mustParse(t, `
+on:
+  push:
+    paths-ignore: ['docs/**']
+  workflow_dispatch:
+jobs: {}
+`),
+		"all.yml": mustParse(t, "on: [push, pull_request]\njobs: {}\n"),
+	}
+
+	triggers := WorkflowsTriggeredBy(workflows, "server/main.go")
+	want := []PathTrigger{
+		{"all.yml", "pull_request"}, {"all.yml", "push"},
+		{"backend.yml", "pull_request"}, {"backend.yml", "push"},
+		{"ci.yml", "push"},
+	}
+	if len(triggers) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, triggers)
+	}
+	for i := range want {
+		if triggers[i] != want[i] {
+			t.Errorf("Expected %v at %d, got %v", want[i], i, triggers[i])
+		}
+	}
+
+	triggers = WorkflowsTriggeredBy(workflows, "docs/index.md")
+	for _, tr := range triggers {
+		if tr.File == "ci.yml" || tr.File == "backend.yml" {
+			t.Errorf("Unexpected trigger %v for a docs change", tr)
+		}
+	}
+}
+
+func TestFindDeadPathFilters(t *testing.T) {
+	workflows := map[string]*ActionFile{
+		"rooted.yml":   mustParse(t, "on:\n  push:\n    paths: ['./src/**']\njobs: {}\n"),
+		"missing.yml":  mustParse(t, "on:\n  push:\n    paths: ['src/**', 'frontend/**']\njobs: {}\n"),
+		"excluded.yml": mustParse(t, "on:\n  pull_request:\n    paths: ['src/**', '!src/**']\njobs: {}\n"),
+		"ok.yml":       mustParse(t, "on:\n  push:\n    paths: ['src/**']\njobs: {}\n"),
+	}
+	files := []string{"README.md", "src/main.go", "src/util.go"}
+
+	findings := FindDeadPathFilters(workflows, files)
+	if len(findings) != 3 {
+		t.Fatalf("Expected 3 findings, got %v", findings)
+	}
+	if findings[0].File != "excluded.yml" || len(findings[0].Patterns) != 0 {
+		t.Errorf("Unexpected finding %+v", findings[0])
+	}
+	if findings[1].File != "missing.yml" || len(findings[1].Patterns) != 1 || findings[1].Patterns[0] != "frontend/**" {
+		t.Errorf("Unexpected finding %+v", findings[1])
+	}
+	if findings[2].File != "rooted.yml" {
+		t.Errorf("Unexpected finding %+v", findings[2])
+	}
+}
+
+func TestValidatePathFilters(t *testing.T) {
+	workflow := mustParse(t, `
+on:
+  push:
+    paths: ['src/**']
+    paths-ignore: ['docs/**']
+  pull_request:
+    paths: ['src/[a-']
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+`)
+	errs := NewValidator().Validate(workflow)
+	if len(errs) != 2 {
+		t.Errorf("Expected conflicting filters and an invalid pattern, got %v", errs)
+	}
+}
@@ -0,0 +1,183 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// PermissionsReadAll grants read access to every scope
+	PermissionsReadAll = "read-all"
+	// PermissionsWriteAll grants write access to every scope
+	PermissionsWriteAll = "write-all"
+)
+
+// Permissions is the 'permissions' setting of a workflow or job. The three
+// forms have different meanings and are kept apart: a shorthand such as
+// read-all, a mapping of scopes, and the empty mapping '{}', which grants
+// no permissions to the GITHUB_TOKEN at all.
+type Permissions struct {
+	// Shorthand is read-all or write-all for the string form, empty otherwise
+	Shorthand string
+	// Scopes maps scopes such as contents or id-token to read, write or none
+	Scopes map[string]string
+}
+
+// ParsePermissions interprets a 'permissions' value. It returns nil if the
+// value is unset, and Permissions without shorthand or scopes for '{}'.
+func ParsePermissions(v interface{}) (*Permissions, error) {
+	switch value := v.(type) {
+	case nil:
+		return nil, nil
+	case *Permissions:
+		return value, nil
+	case Permissions:
+		return &value, nil
+	case string:
+		if value != PermissionsReadAll && value != PermissionsWriteAll {
+			return nil, fmt.Errorf("invalid permissions %q, expected %s, %s or a mapping of scopes", value, PermissionsReadAll, PermissionsWriteAll)
+		}
+		return &Permissions{Shorthand: value}, nil
+	default:
+		scopes, err := MapOfStringString(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid permissions: %w", err)
+		}
+		for _, scope := range sortedKeys(scopes) {
+			switch scopes[scope] {
+			case "read", "write", "none":
+			default:
+				return nil, fmt.Errorf("invalid permission %q for %s, expected read, write or none", scopes[scope], scope)
+			}
+		}
+		if scopes == nil {
+			scopes = map[string]string{}
+		}
+		return &Permissions{Scopes: scopes}, nil
+	}
+}
+
+// IsEmpty reports whether the permissions are '{}'
+func (p *Permissions) IsEmpty() bool {
+	return p.Shorthand == "" && len(p.Scopes) == 0
+}
+
+// Level returns the access granted to a scope: read, write or none
+func (p *Permissions) Level(scope string) string {
+	switch p.Shorthand {
+	case PermissionsReadAll:
+		return "read"
+	case PermissionsWriteAll:
+		return "write"
+	}
+	if level, ok := p.Scopes[scope]; ok {
+		return level
+	}
+	return "none"
+}
+
+// value returns the permissions in their YAML form
+func (p Permissions) value() interface{} {
+	if p.Shorthand != "" {
+		return p.Shorthand
+	}
+	if p.Scopes == nil {
+		return map[string]string{}
+	}
+	return p.Scopes
+}
+
+// MarshalYAML implements yaml.Marshaler, writing the form the permissions
+// were parsed from
+func (p Permissions) MarshalYAML() (interface{}, error) {
+	return p.value(), nil
+}
+
+// MarshalJSON implements json.Marshaler, writing the form the permissions
+// were parsed from
+func (p Permissions) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.value())
+}
+
+// PermissionSource tells where the effective permissions of a job come from
+type PermissionSource string
+
+// Sources of job permissions
+const (
+	// PermissionSourceJob is a 'permissions' setting on the job
+	PermissionSourceJob PermissionSource = "job"
+	// PermissionSourceWorkflow is the workflow-level setting inherited by the job
+	PermissionSourceWorkflow PermissionSource = "workflow"
+	// PermissionSourceDefault is the repository or organization default for
+	// the GITHUB_TOKEN, which is write-all on older repositories
+	PermissionSourceDefault PermissionSource = "default"
+)
+
+// JobPermissions are the effective permissions of a job
+type JobPermissions struct {
+	Job    string           `json:"job"`
+	Source PermissionSource `json:"source"`
+	// Permissions is nil for the default source, or when the setting is
+	// invalid
+	Permissions *Permissions `json:"permissions,omitempty"`
+}
+
+// WorkflowPermissions are the permissions of a workflow and its jobs
+type WorkflowPermissions struct {
+	File     string           `json:"file"`
+	Declared *Permissions     `json:"declared,omitempty"`
+	Jobs     []JobPermissions `json:"jobs"`
+	// UsesDefault is set when at least one job relies on the default token
+	// permissions
+	UsesDefault bool `json:"usesDefault,omitempty"`
+}
+
+// PermissionInventory lists the effective permissions of a set of workflows
+type PermissionInventory struct {
+	// Workflows are sorted by file, their jobs by ID
+	Workflows []WorkflowPermissions `json:"workflows"`
+	// UsingDefault lists the files of the workflows with UsesDefault set
+	UsingDefault []string `json:"usingDefault,omitempty"`
+}
+
+// EffectivePermissions builds the permission inventory of a directory of
+// workflows, as returned by ParseDir. Actions are skipped as they do not
+// set permissions.
+func EffectivePermissions(workflows map[string]*ActionFile) *PermissionInventory {
+	inventory := &PermissionInventory{Workflows: make([]WorkflowPermissions, 0)}
+	for _, file := range sortedFiles(workflows) {
+		action := workflows[file]
+		if !DetectType(action).IsWorkflow() {
+			continue
+		}
+		declared, err := ParsePermissions(action.Permissions)
+		workflowSet := action.Permissions != nil
+		if err != nil {
+			declared = nil
+		}
+
+		perms := WorkflowPermissions{File: file, Declared: declared, Jobs: make([]JobPermissions, 0, len(action.Jobs))}
+		for _, jobID := range sortedJobIDs(action) {
+			job := action.Jobs[jobID]
+			entry := JobPermissions{Job: jobID}
+			switch {
+			case job.Permissions != nil:
+				entry.Source = PermissionSourceJob
+				if p, err := ParsePermissions(job.Permissions); err == nil {
+					entry.Permissions = p
+				}
+			case workflowSet:
+				entry.Source, entry.Permissions = PermissionSourceWorkflow, declared
+			default:
+				entry.Source = PermissionSourceDefault
+				perms.UsesDefault = true
+			}
+			perms.Jobs = append(perms.Jobs, entry)
+		}
+		inventory.Workflows = append(inventory.Workflows, perms)
+		if perms.UsesDefault {
+			inventory.UsingDefault = append(inventory.UsingDefault, file)
+		}
+	}
+	return inventory
+}
@@ -0,0 +1,185 @@
+package parser
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParsePermissions(t *testing.T) {
+	action := mustParse(t, `
+on: push
+permissions: read-all
+jobs:
+  none:
+    runs-on: ubuntu-latest
+    permissions: {}
+    steps:
+      - run: make
+  scoped:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: read
+      id-token: write
+    steps:
+      - run: make
+  unset:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+`)
+
+	p, err := ParsePermissions(action.Permissions)
+	if err != nil || p.Shorthand != PermissionsReadAll || p.Level("packages") != "read" {
+		t.Errorf("Unexpected workflow permissions %+v (%v)", p, err)
+	}
+	p, err = ParsePermissions(action.Jobs["none"].Permissions)
+	if err != nil || !p.IsEmpty() || p.Level("contents") != "none" {
+		t.Errorf("Expected empty permissions, got %+v (%v)", p, err)
+	}
+	p, err = ParsePermissions(action.Jobs["scoped"].Permissions)
+	if err != nil || p.IsEmpty() || p.Level("id-token") != "write" || p.Level("packages") != "none" {
+		t.Errorf("Unexpected scoped permissions %+v (%v)", p, err)
+	}
+	if p, err := ParsePermissions(action.Jobs["unset"].Permissions); p != nil || err != nil {
+		t.Errorf("Expected nil for unset permissions, got %+v (%v)", p, err)
+	}
+
+	for _, invalid := range []interface{}{"read", map[string]interface{}{"contents": "admin"}, []interface{}{"contents"}} {
+		if _, err := ParsePermissions(invalid); err == nil {
+			t.Errorf("Expected error for %v", invalid)
+		}
+	}
+}
+
+func TestPermissionsRoundTrip(t *testing.T) {
+	for _, form := range []string{"{}", "read-all", "write-all", "\n  contents: read"} {
+		action := mustParse(t, "on: push\npermissions: "+form+"\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make\n")
+		want, err := ParsePermissions(action.Permissions)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Both the decoded value and the typed model marshal to the same form
+		for _, value := range []interface{}{action.Permissions, want} {
+			action.Permissions = value
+			data, err := yaml.Marshal(action)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reparsed, err := Parse(strings.NewReader(string(data)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ParsePermissions(reparsed.Permissions)
+			if err != nil || got == nil || got.Shorthand != want.Shorthand || len(got.Scopes) != len(want.Scopes) {
+				t.Errorf("%s: YAML round trip gave %+v (%v)\n%s", form, got, err, data)
+			}
+
+			data, err = json.Marshal(action)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded map[string]interface{}
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			got, err = ParsePermissions(decoded["permissions"])
+			if err != nil || got == nil || got.Shorthand != want.Shorthand || len(got.Scopes) != len(want.Scopes) {
+				t.Errorf("%s: JSON round trip gave %+v (%v)\n%s", form, got, err, data)
+			}
+		}
+	}
+}
+
+func TestValidatePermissions(t *testing.T) {
+	action := mustParse(t, `
+on: push
+permissions: read
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: admin
+    steps:
+      - run: make
+`)
+	var fields []string
+	for _, err := range NewValidator().Validate(action) {
+		if err.Rule == "permissions" {
+			fields = append(fields, err.Field)
+		}
+	}
+	if strings.Join(fields, ",") != "permissions,jobs.build.permissions" {
+		t.Errorf("Expected permissions errors for the workflow and the job, got %v", fields)
+	}
+}
+
+func TestEffectivePermissions(t *testing.T) {
+	set := WorkflowSet{
+		"ci.yml": mustParse(t, `
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+  deploy:
+    runs-on: ubuntu-latest
+    permissions:
+      id-token: write
+    steps:
+      - run: make deploy
+`),
+		"lint.yml": mustParse(t, `
+on: push
+permissions: read-all
+jobs:
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make lint
+  none:
+    runs-on: ubuntu-latest
+    permissions: {}
+    steps:
+      - run: make
+`),
+		"action.yml": mustParse(t, `
+runs:
+  using: composite
+  steps:
+    - run: make
+      shell: bash
+`),
+	}
+
+	inventory := EffectivePermissions(set)
+	if len(inventory.Workflows) != 2 {
+		t.Fatalf("EffectivePermissions() returned %d workflows, want 2", len(inventory.Workflows))
+	}
+	ci, lint := inventory.Workflows[0], inventory.Workflows[1]
+	if ci.File != "ci.yml" || !ci.UsesDefault || ci.Declared != nil {
+		t.Errorf("Unexpected ci.yml permissions %+v", ci)
+	}
+	if ci.Jobs[0].Source != PermissionSourceDefault || ci.Jobs[0].Permissions != nil {
+		t.Errorf("Expected build to use the default permissions, got %+v", ci.Jobs[0])
+	}
+	if ci.Jobs[1].Source != PermissionSourceJob || ci.Jobs[1].Permissions.Level("id-token") != "write" {
+		t.Errorf("Expected deploy to declare its permissions, got %+v", ci.Jobs[1])
+	}
+	if lint.UsesDefault || lint.Declared.Shorthand != PermissionsReadAll {
+		t.Errorf("Unexpected lint.yml permissions %+v", lint)
+	}
+	if lint.Jobs[0].Source != PermissionSourceWorkflow || lint.Jobs[0].Permissions.Shorthand != PermissionsReadAll {
+		t.Errorf("Expected lint to inherit the workflow permissions, got %+v", lint.Jobs[0])
+	}
+	if lint.Jobs[1].Source != PermissionSourceJob || !lint.Jobs[1].Permissions.IsEmpty() {
+		t.Errorf("Expected none to declare empty permissions, got %+v", lint.Jobs[1])
+	}
+	if len(inventory.UsingDefault) != 1 || inventory.UsingDefault[0] != "ci.yml" {
+		t.Errorf("UsingDefault = %v, want [ci.yml]", inventory.UsingDefault)
+	}
+}
@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// githubOwners are the organizations whose actions count as GitHub-owned
+var githubOwners = map[string]bool{"actions": true, "github": true}
+
+// ActionsPolicy is the "allow select actions and reusable workflows" policy
+// of an organization or enterprise. Actions and reusable workflows of the
+// same repository are always allowed.
+type ActionsPolicy struct {
+	// GitHubOwned allows everything in the actions and github organizations
+	GitHubOwned bool `yaml:"github-owned,omitempty" json:"githubOwned,omitempty"`
+	// VerifiedCreators allows the actions of Marketplace verified creators.
+	// Verification cannot be looked up offline, so the verified owners must
+	// be listed in VerifiedOwners.
+	VerifiedCreators bool     `yaml:"verified-creators,omitempty" json:"verifiedCreators,omitempty"`
+	VerifiedOwners   []string `yaml:"verified-owners,omitempty" json:"verifiedOwners,omitempty"`
+	// Patterns allow references matching them, such as "octo-org/*",
+	// "octo-org/deploy@v*" or "octo-org/ci/.github/workflows/test.yml@main".
+	// '*' matches any sequence of characters; patterns without a ref allow
+	// every ref.
+	Patterns []string `yaml:"patterns,omitempty" json:"patterns,omitempty"`
+}
+
+// Allows reports whether the policy allows a 'uses' reference. Local
+// references are allowed, and so are Docker images, which the policy does
+// not restrict; malformed references are not.
+func (p *ActionsPolicy) Allows(uses string) bool {
+	if strings.HasPrefix(uses, "./") || strings.HasPrefix(uses, "docker://") {
+		return true
+	}
+	ref, ok := ParseActionRef(uses)
+	if !ok {
+		return false
+	}
+	owner := strings.ToLower(ref.Owner)
+	if p.GitHubOwned && githubOwners[owner] {
+		return true
+	}
+	if p.VerifiedCreators {
+		for _, verified := range p.VerifiedOwners {
+			if strings.EqualFold(verified, owner) {
+				return true
+			}
+		}
+	}
+	for _, pattern := range p.Patterns {
+		if matchesActionPattern(strings.ToLower(pattern), strings.ToLower(ref.String())) {
+			return true
+		}
+	}
+	return false
+}
+
+// Blocked returns the dependencies, as returned by Dependencies, that the
+// policy would block, in the order given
+func (p *ActionsPolicy) Blocked(deps []Dependency) []Dependency {
+	blocked := make([]Dependency, 0)
+	for _, dep := range deps {
+		if !p.Allows(dep.Uses) {
+			blocked = append(blocked, dep)
+		}
+	}
+	return blocked
+}
+
+// SimulateActionsPolicy evaluates every 'uses' reference of a directory of
+// workflows and actions, as returned by ParseDir, against a policy before it
+// is enabled, returning the blocked dependencies sorted by their 'uses'
+// value with the places they are referenced from
+func SimulateActionsPolicy(workflows map[string]*ActionFile, policy *ActionsPolicy) []Dependency {
+	return policy.Blocked(Dependencies(workflows))
+}
+
+// matchesActionPattern matches a lower-cased reference against a policy
+// pattern
+func matchesActionPattern(pattern, uses string) bool {
+	if !strings.Contains(pattern, "@") {
+		uses, _, _ = strings.Cut(uses, "@")
+	}
+	// '*' also matches slashes, so 'owner/*' covers actions in subdirectories
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+	return regexp.MustCompile(expr).MatchString(uses)
+}
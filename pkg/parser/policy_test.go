@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestActionsPolicyAllows(t *testing.T) {
+	policy := &ActionsPolicy{
+		GitHubOwned:      true,
+		VerifiedCreators: true,
+		VerifiedOwners:   []string{"docker"},
+		Patterns:         []string{"octo-org/*", "hashicorp/setup-terraform@v*", "monalisa/lint"},
+	}
+	tests := []struct {
+		uses string
+		want bool
+	}{
+		{"actions/checkout@v4", true},
+		{"github/codeql-action/init@v3", true},
+		{"docker/build-push-action@v5", true},
+		{"Octo-Org/deploy@main", true},
+		{"octo-org/ci/.github/workflows/test.yml@v1", true},
+		{"hashicorp/setup-terraform@v3", true},
+		{"hashicorp/setup-terraform@main", false},
+		{"monalisa/lint@0123456789abcdef0123456789abcdef01234567", true},
+		{"monalisa/lint/sub@v1", false},
+		{"octo-org-fork/deploy@v1", false},
+		{"aws-actions/configure-aws-credentials@v4", false},
+		{"./.github/actions/setup", true},
+		{"docker://alpine:3", true},
+		{"not-a-ref", false},
+	}
+	for _, tt := range tests {
+		if got := policy.Allows(tt.uses); got != tt.want {
+			t.Errorf("Allows(%q) = %v, want %v", tt.uses, got, tt.want)
+		}
+	}
+
+	if (&ActionsPolicy{VerifiedOwners: []string{"docker"}}).Allows("docker/login-action@v3") {
+		t.Error("Expected verified owners to be ignored unless verified creators are allowed")
+	}
+	if (&ActionsPolicy{}).Allows("actions/checkout@v4") {
+		t.Error("Expected an empty policy to block GitHub-owned actions")
+	}
+}
+
+func TestSimulateActionsPolicy(t *testing.T) {
+	workflows := map[string]*ActionFile{
+		"ci.yml": mustParse(t, `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: third-party/setup@v1
+      - uses: ./.github/actions/local
+  deploy:
+    uses: other/workflows/.github/workflows/deploy.yml@v2
+`),
+		"release.yml": mustParse(t, `on: push
+jobs:
+  release:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: third-party/setup@v1
+`),
+	}
+	blocked := SimulateActionsPolicy(workflows, &ActionsPolicy{GitHubOwned: true})
+	var uses []string
+	for _, dep := range blocked {
+		uses = append(uses, dep.Uses)
+	}
+	if want := []string{"other/workflows/.github/workflows/deploy.yml@v2", "third-party/setup@v1"}; !reflect.DeepEqual(uses, want) {
+		t.Fatalf("Expected %v to be blocked, got %v", want, uses)
+	}
+	want := []DependencyLocation{
+		{File: "ci.yml", Field: "jobs.build.steps[1].uses"},
+		{File: "release.yml", Field: "jobs.release.steps[0].uses"},
+	}
+	if !reflect.DeepEqual(blocked[1].Locations, want) {
+		t.Errorf("Expected locations %v, got %v", want, blocked[1].Locations)
+	}
+}
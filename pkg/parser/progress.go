@@ -0,0 +1,83 @@
+package parser
+
+import "io/fs"
+
+// Progress is a snapshot of the progress of ParseDir, ScanProjects or
+// DiscoverActions, passed to the callback of WithProgress
+type Progress struct {
+	// Discovered is the number of files found so far. It is final once
+	// Parsed and Failed start growing.
+	Discovered int
+	Parsed     int
+	Failed     int
+	// Bytes is the total size of the files parsed or failed so far
+	Bytes int64
+
+	// Path is the file the update is about, relative to the directory
+	// being scanned. It is empty when a walk error has no path.
+	Path string
+	// File is set when Path was just parsed, allowing partial results
+	File *ActionFile
+	// Err is set when Path failed
+	Err error
+}
+
+// WithProgress calls fn after every file discovered, parsed or failed
+// during ParseDir, ScanProjects and DiscoverActions. fn is called from the
+// scanning goroutine and should return quickly.
+func WithProgress(fn func(Progress)) Option {
+	return func(o *options) {
+		o.progress = fn
+	}
+}
+
+// progressTracker accumulates Progress and reports it to the callback of
+// WithProgress. Its methods do nothing without a callback.
+type progressTracker struct {
+	fn    func(Progress)
+	state Progress
+	sizes map[string]int64
+}
+
+// newProgressTracker returns the tracker for the options
+func newProgressTracker(o *options) *progressTracker {
+	return &progressTracker{fn: o.progress, sizes: make(map[string]int64)}
+}
+
+// discovered reports the file at rel found by a directory walk
+func (p *progressTracker) discovered(rel string, entry fs.DirEntry) {
+	if p.fn == nil {
+		return
+	}
+	if info, err := entry.Info(); err == nil {
+		p.sizes[rel] = info.Size()
+	}
+	p.state.Discovered++
+	p.report(rel, nil, nil)
+}
+
+// parsed reports that the file at rel was parsed
+func (p *progressTracker) parsed(rel string, file *ActionFile) {
+	if p.fn == nil {
+		return
+	}
+	p.state.Parsed++
+	p.state.Bytes += p.sizes[rel]
+	p.report(rel, file, nil)
+}
+
+// failed reports that the file at rel failed
+func (p *progressTracker) failed(rel string, err error) {
+	if p.fn == nil {
+		return
+	}
+	p.state.Failed++
+	p.state.Bytes += p.sizes[rel]
+	p.report(rel, nil, err)
+}
+
+func (p *progressTracker) report(rel string, file *ActionFile, err error) {
+	update := p.state
+	update.Path, update.File, update.Err = rel, file, err
+	p.fn(update)
+}
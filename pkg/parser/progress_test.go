@@ -0,0 +1,43 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithProgress(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"a.yml": "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n",
+		"b.yml": "jobs: [",
+	}
+	var size int64
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		size += int64(len(content))
+	}
+
+	var updates []Progress
+	set, err := ParseDir(tempDir, WithContinueOnError(), WithProgress(func(p Progress) {
+		updates = append(updates, p)
+	}))
+	if err == nil || len(set) != 1 {
+		t.Fatalf("Expected one file and a DirError, got %v (%v)", set.Paths(), err)
+	}
+	if len(updates) != 4 {
+		t.Fatalf("Expected 4 updates, got %+v", updates)
+	}
+	if updates[1].Discovered != 2 || updates[1].Parsed != 0 {
+		t.Errorf("Expected discovery to finish before parsing, got %+v", updates[1])
+	}
+	if parsed := updates[2]; parsed.Path != "a.yml" || parsed.File == nil || parsed.Parsed != 1 {
+		t.Errorf("Unexpected parse update %+v", parsed)
+	}
+	last := updates[3]
+	if last.Path != "b.yml" || last.Err == nil || last.Failed != 1 || last.Bytes != size {
+		t.Errorf("Unexpected final update %+v, want %d bytes", last, size)
+	}
+}
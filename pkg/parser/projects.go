@@ -0,0 +1,161 @@
+//go:build !js
+
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Project is a directory of a repository with its own .github directory.
+// The scanned root is always a project.
+type Project struct {
+	// Dir is the project directory relative to the scanned root, "." for
+	// the root itself
+	Dir string `json:"dir"`
+	// Files are the workflows of the project's .github/workflows directory
+	// and the action metadata files of its tree outside nested projects,
+	// keyed by their path relative to Dir
+	Files WorkflowSet `json:"files"`
+}
+
+// ScanProjects scans a monorepo for projects: the root plus every nested
+// directory containing a .github directory. Workflows are read from the
+// .github/workflows directory of each project, as GitHub does, and
+// action.yml files anywhere in the tree are assigned to the innermost
+// project containing them. Projects are sorted by directory. Disabled
+// workflows are included only with WithDisabled, see ParseDir.
+//
+// DefaultSkipDirs are skipped unless WithSkipDirs sets another list. Errors
+// are handled like in ParseDir, including WithContinueOnError.
+func ScanProjects(root string, opts ...Option) ([]Project, error) {
+	o := newOptions(append([]Option{WithSkipDirs(DefaultSkipDirs...)}, opts...))
+	ctx, span := o.tracer.Start(o.ctx, "parser.ScanProjects")
+	span.SetAttribute("dir", root)
+	defer span.End()
+
+	filter := newWalkFilter(root, o)
+	progress := newProgressTracker(o)
+	var failures []*FileError
+	dirs := []string{"."}
+	var paths []string
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err := o.ctx.Err(); err != nil {
+			return err
+		}
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				err = classify(ErrNotFound, err)
+			}
+			if o.continueOnError && path != root {
+				failures = append(failures, &FileError{Path: relPath(root, path), Err: err})
+				progress.failed(relPath(root, path), err)
+				return nil
+			}
+			return err
+		}
+
+		if skip, err := filter.skip(path, entry); err != nil || skip {
+			if err == nil && entry.IsDir() {
+				err = filepath.SkipDir
+			}
+			return err
+		}
+
+		name := entry.Name()
+		if entry.IsDir() {
+			if name == ".github" && path != root {
+				if dir := filepath.Dir(relPath(root, path)); dir != "." {
+					dirs = append(dirs, dir)
+				}
+			}
+			return nil
+		}
+		rel, disabled := disabledPath(relPath(root, path))
+		if disabled && !o.disabled {
+			return nil
+		}
+		if ext := filepath.Ext(rel); isWorkflowPath(rel) && (ext == ".yml" || ext == ".yaml") ||
+			filepath.Base(rel) == "action.yml" || filepath.Base(rel) == "action.yaml" {
+			paths = append(paths, path)
+			progress.discovered(relPath(root, path), entry)
+		}
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	}
+
+	projects := make(map[string]*Project, len(dirs))
+	for _, dir := range dirs {
+		projects[dir] = &Project{Dir: dir, Files: make(WorkflowSet)}
+	}
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to walk directory: %w", err)
+		}
+		_, fileSpan := o.tracer.Start(ctx, "parser.ParseFile")
+		fileSpan.SetAttribute("path", path)
+		action, err := parseFile(path, o)
+		if err != nil {
+			fileSpan.RecordError(err)
+			fileSpan.End()
+			progress.failed(relPath(root, path), err)
+			if o.continueOnError {
+				failures = append(failures, &FileError{Path: relPath(root, path), Err: err})
+				continue
+			}
+			err = fmt.Errorf("failed to parse %s: %w", path, err)
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to walk directory: %w", err)
+		}
+		fileSpan.End()
+		rel := relPath(root, path)
+		_, action.Disabled = disabledPath(rel)
+		project := projects[projectOf(rel, dirs)]
+		project.Files[relPath(project.Dir, rel)] = action
+		progress.parsed(rel, action)
+	}
+
+	result := make([]Project, 0, len(projects))
+	for _, dir := range dirs {
+		result = append(result, *projects[dir])
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Dir < result[j].Dir
+	})
+
+	span.SetAttribute("projects", len(result))
+	if len(failures) > 0 {
+		span.SetAttribute("failures", len(failures))
+		return result, &DirError{Errors: failures}
+	}
+	return result, nil
+}
+
+// isWorkflowPath reports whether path is directly inside a
+// .github/workflows directory, or its 'disabled' subdirectory
+func isWorkflowPath(path string) bool {
+	dir := filepath.Dir(path)
+	if filepath.Base(dir) == "disabled" {
+		dir = filepath.Dir(dir)
+	}
+	return filepath.Base(dir) == "workflows" && filepath.Base(filepath.Dir(dir)) == ".github"
+}
+
+// projectOf returns the innermost of dirs containing the relative path rel
+func projectOf(rel string, dirs []string) string {
+	best := "."
+	for _, dir := range dirs {
+		if dir != "." && len(dir) > len(best) && strings.HasPrefix(rel, dir+string(filepath.Separator)) {
+			best = dir
+		}
+	}
+	return best
+}
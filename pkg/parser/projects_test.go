@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestScanProjects(t *testing.T) {
+	root := t.TempDir()
+	workflow := "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n"
+	action := "name: Setup\nruns:\n  using: composite\n  steps:\n    - run: echo\n      shell: bash\n"
+	files := map[string]string{
+		".github/workflows/ci.yml":                      workflow,
+		".github/workflows/nested/ignored.yml":          workflow,
+		".github/workflows/old.yml.off":                 workflow,
+		".github/workflows/disabled/older.yml":          workflow,
+		".github/actions/setup/action.yml":              action,
+		"services/api/.github/workflows/deploy.yaml":    workflow,
+		"services/api/tools/lint/action.yaml":           action,
+		"services/api/config.yml":                       "not: a workflow",
+		"libs/shared/action.yml":                        action,
+		"node_modules/some-action/action.yml":           action,
+		"services/api-gateway/.github/workflows/ci.yml": workflow,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	projects, err := ScanProjects(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string][]string)
+	var dirs []string
+	for _, p := range projects {
+		dirs = append(dirs, filepath.ToSlash(p.Dir))
+		for _, path := range p.Files.Paths() {
+			got[filepath.ToSlash(p.Dir)] = append(got[filepath.ToSlash(p.Dir)], filepath.ToSlash(path))
+		}
+	}
+
+	if want := []string{".", "services/api", "services/api-gateway"}; !reflect.DeepEqual(dirs, want) {
+		t.Errorf("project dirs = %v, want %v", dirs, want)
+	}
+	want := map[string][]string{
+		".":                    {".github/actions/setup/action.yml", ".github/workflows/ci.yml", "libs/shared/action.yml"},
+		"services/api":         {".github/workflows/deploy.yaml", "tools/lint/action.yaml"},
+		"services/api-gateway": {".github/workflows/ci.yml"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("project files = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"bytes"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// trueKeyPattern finds keys spelled like the boolean true at the start of a
+// line or of a flow mapping, the cheap check before normalizeOnKey parses
+// the document
+var trueKeyPattern = regexp.MustCompile(`(?m)(?:^|[{,][ \t]*)["']?(?:true|True|TRUE)["']?[ \t]*:`)
+
+// normalizeOnKey renames a top-level 'true' key to 'on'. YAML 1.1 loaders
+// such as PyYAML read the bare key 'on' as the boolean true, so workflows
+// that went through one, or were converted to JSON by one, carry their
+// triggers under 'true', where they would silently be dropped. Documents
+// that already have an 'on' key are left alone. The key is replaced in
+// place so that line and column positions are unchanged.
+func normalizeOnKey(data []byte) []byte {
+	if !trueKeyPattern.Match(data) {
+		return data
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return data
+	}
+
+	root := doc.Content[0]
+	var key *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		k := root.Content[i]
+		switch {
+		case k.Kind != yaml.ScalarNode:
+		case k.Value == "on" && k.Tag == "!!str":
+			return data
+		case key == nil && (k.Tag == "!!bool" || k.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0) && trueKeyPattern.MatchString(k.Value+":"):
+			key = k
+		}
+	}
+	if key == nil {
+		return data
+	}
+
+	start := 0
+	for line := 1; line < key.Line; line++ {
+		i := bytes.IndexByte(data[start:], '\n')
+		if i < 0 {
+			return data
+		}
+		start += i + 1
+	}
+	start += key.Column - 1
+	end := start + len(key.Value)
+	if key.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+		end += 2
+	}
+	if end > len(data) {
+		return data
+	}
+
+	result := make([]byte, 0, len(data))
+	result = append(result, data[:start]...)
+	result = append(result, "on"...)
+	return append(result, data[end:]...)
+}
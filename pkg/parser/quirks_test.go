@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTrueKeyAsOn(t *testing.T) {
+	tests := map[string]string{
+		"bare":   "true:\n  push:\n    branches: [main]\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make\n",
+		"quoted": "name: CI\n\"true\": {\"push\": {\"branches\": [\"main\"]}}\njobs: {\"build\": {\"runs-on\": \"ubuntu-latest\", \"steps\": [{\"run\": \"make\"}]}}\n",
+		"json":   `{"true": {"push": {"branches": ["main"]}}, "jobs": {"build": {"runs-on": "ubuntu-latest", "steps": [{"run": "make"}]}}}`,
+	}
+	for name, doc := range tests {
+		action, err := Parse(strings.NewReader(doc), WithStrict(), WithPositions())
+		if err != nil {
+			t.Errorf("%s: parse failed: %v", name, err)
+			continue
+		}
+		push, ok, err := triggerConfig(action, "push")
+		if err != nil || !ok {
+			t.Errorf("%s: expected push trigger, got %v (%v)", name, action.On, err)
+			continue
+		}
+		if branches, err := stringList(push["branches"]); err != nil || strings.Join(branches, ",") != "main" {
+			t.Errorf("%s: expected branches [main], got %v (%v)", name, branches, err)
+		}
+		if errs := NewValidator().Validate(action); len(errs) > 0 {
+			t.Errorf("%s: unexpected validation errors: %v", name, errs)
+		}
+	}
+}
+
+func TestNormalizeOnKeyKeepsOn(t *testing.T) {
+	doc := "on: push\ntrue: value\n"
+	if got := string(normalizeOnKey([]byte(doc))); got != doc {
+		t.Errorf("Expected document with 'on' to be unchanged, got %q", got)
+	}
+
+	nested := "on: push\njobs:\n  build:\n    with:\n      true: 1\n"
+	if got := string(normalizeOnKey([]byte(nested))); got != nested {
+		t.Errorf("Expected nested keys to be unchanged, got %q", got)
+	}
+}
+
+func TestNormalizeOnKeyPositions(t *testing.T) {
+	action, err := Parse(strings.NewReader("name: CI\ntrue: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n"), WithPositions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if action.On != "push" {
+		t.Errorf("Expected on: push, got %v", action.On)
+	}
+	if pos, ok := action.Positions["jobs.build"]; !ok || pos.Line != 4 {
+		t.Errorf("Expected jobs.build at line 4, got %+v", pos)
+	}
+}
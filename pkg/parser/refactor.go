@@ -0,0 +1,510 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// StepRange selects the steps steps[Start:End] of a job
+type StepRange struct {
+	Start int
+	End   int
+}
+
+// liftedContexts are the contexts that are not available inside a composite
+// action and are therefore turned into action inputs when steps are extracted
+var liftedContexts = map[string]bool{
+	"inputs":  true,
+	"env":     true,
+	"matrix":  true,
+	"secrets": true,
+}
+
+// ExtractComposite moves a range of steps of a workflow job into a new
+// composite action named name. It returns the composite action and a copy of
+// the workflow in which the steps are replaced by a single step calling the
+// action via 'uses: ./.github/actions/<name>'.
+//
+// Values the steps read from the inputs, env, matrix and secrets contexts, or
+// from outputs of steps outside the range, become inputs of the action and
+// are passed in by the calling step. Outputs of the extracted steps that are
+// used later in the job become outputs of the action. Run steps without a
+// shell get the job's default shell, or bash.
+func ExtractComposite(workflow *ActionFile, jobID string, steps StepRange, name string) (*ActionFile, *ActionFile, error) {
+	job, ok := workflow.Jobs[jobID]
+	if !ok {
+		return nil, nil, fmt.Errorf("job %q not found", jobID)
+	}
+	if steps.Start < 0 || steps.End > len(job.Steps) || steps.Start >= steps.End {
+		return nil, nil, fmt.Errorf("invalid step range [%d:%d] for job %q with %d steps", steps.Start, steps.End, jobID, len(job.Steps))
+	}
+	if name == "" {
+		return nil, nil, fmt.Errorf("composite action name is required")
+	}
+
+	selected := job.Steps[steps.Start:steps.End]
+	inside := make(map[string]bool)
+	for _, step := range selected {
+		if step.ID != "" {
+			inside[step.ID] = true
+		}
+	}
+
+	composite := &ActionFile{
+		Name:        name,
+		Description: fmt.Sprintf("Steps extracted from job '%s'", jobID),
+		Inputs:      make(map[string]Input),
+		Runs:        RunsConfig{Using: "composite"},
+	}
+	with := make(map[string]interface{})
+
+	// Rewrite references that are not available in the composite action.
+	// Distinct references whose names collide, such as env.NODE_VERSION and
+	// matrix.node-version, are told apart by prefixing the context.
+	sources := make(map[string]string)
+	names := make(map[string]string)
+	lift := func(ref ContextReference) string {
+		var inputName string
+		switch {
+		case liftedContexts[ref.Context] && len(ref.Path) == 1:
+			inputName = inputNameFor(ref.Path[0])
+		case ref.Context == "steps" && len(ref.Path) == 3 && ref.Path[1] == "outputs" && !inside[ref.Path[0]]:
+			inputName = inputNameFor(ref.Path[0] + "-" + ref.Path[2])
+		default:
+			return ""
+		}
+		source := ref.String()
+		if name, ok := names[source]; ok {
+			return "inputs." + name
+		}
+		if existing, ok := sources[inputName]; ok && existing != source {
+			prefixed := inputNameFor(ref.Context + "-" + inputName)
+			inputName = prefixed
+			for i := 2; sources[inputName] != ""; i++ {
+				inputName = fmt.Sprintf("%s-%d", prefixed, i)
+			}
+		}
+		sources[inputName], names[source] = source, inputName
+		composite.Inputs[inputName] = Input{
+			Description: fmt.Sprintf("Value of ${{ %s }}", ref),
+			Required:    true,
+		}
+		with[inputName] = fmt.Sprintf("${{ %s }}", ref)
+		return "inputs." + inputName
+	}
+
+	defaultShell := "bash"
+	if run, err := MapOfStringInterface(job.Defaults["run"]); err == nil {
+		if shell, ok := run["shell"].(string); ok && shell != "" {
+			defaultShell = shell
+		}
+	}
+
+	for _, step := range selected {
+		step = mapStepStrings(step, func(s string) string {
+			return RewriteContextReferences(s, lift)
+		})
+		if step.Run != "" && step.Shell == "" {
+			step.Shell = defaultShell
+		}
+		composite.Runs.Steps = append(composite.Runs.Steps, step)
+	}
+	if len(composite.Inputs) == 0 {
+		composite.Inputs = nil
+	}
+
+	// Outputs of extracted steps used after the range become action outputs
+	callID := inputNameFor(name)
+	outputs := make(map[string]Output)
+	expose := func(ref ContextReference) string {
+		if ref.Context != "steps" || len(ref.Path) != 3 || ref.Path[1] != "outputs" || !inside[ref.Path[0]] {
+			return ""
+		}
+		outputName := ref.Path[2]
+		value := fmt.Sprintf("${{ %s }}", ref)
+		if existing, ok := outputs[outputName]; ok && existing.Value != value {
+			outputName = inputNameFor(ref.Path[0] + "-" + ref.Path[2])
+		}
+		outputs[outputName] = Output{
+			Description: fmt.Sprintf("Output '%s' of step '%s'", ref.Path[2], ref.Path[0]),
+			Value:       value,
+		}
+		return fmt.Sprintf("steps.%s.outputs.%s", callID, outputName)
+	}
+
+	rewritten := *workflow
+	rewritten.Jobs = make(map[string]Job, len(workflow.Jobs))
+	for id, j := range workflow.Jobs {
+		rewritten.Jobs[id] = j
+	}
+
+	newJob := job
+	newJob.Steps = make([]Step, 0, len(job.Steps)-len(selected)+1)
+	newJob.Steps = append(newJob.Steps, job.Steps[:steps.Start]...)
+	call := Step{
+		Name: name,
+		Uses: "./.github/actions/" + name,
+	}
+	if len(with) > 0 {
+		call.With = with
+	}
+	callIndex := len(newJob.Steps)
+	newJob.Steps = append(newJob.Steps, call)
+	for _, step := range job.Steps[steps.End:] {
+		newJob.Steps = append(newJob.Steps, mapStepStrings(step, func(s string) string {
+			return RewriteContextReferences(s, expose)
+		}))
+	}
+	if len(job.Outputs) > 0 {
+		newJob.Outputs = make(map[string]string, len(job.Outputs))
+		for k, v := range job.Outputs {
+			newJob.Outputs[k] = RewriteContextReferences(v, expose)
+		}
+	}
+	if len(outputs) > 0 {
+		composite.Outputs = outputs
+		newJob.Steps[callIndex].ID = callID
+	}
+	rewritten.Jobs[jobID] = newJob
+
+	return composite, &rewritten, nil
+}
+
+// inputNameFor converts an identifier such as NODE_VERSION into the
+// kebab-case form conventionally used for action inputs
+func inputNameFor(s string) string {
+	return strings.ToLower(strings.ReplaceAll(s, "_", "-"))
+}
+
+// mapStepStrings returns a copy of step with fn applied to every string value
+// that may contain expressions
+func mapStepStrings(step Step, fn func(string) string) Step {
+	step.If = fn(step.If)
+	step.Name = fn(step.Name)
+	step.Run = fn(step.Run)
+	step.WorkingDir = fn(step.WorkingDir)
+	step.With = mapInterfaceStrings(step.With, fn)
+	step.Env = mapStrings(step.Env, fn)
+	if s, ok := step.ContinueOn.(string); ok {
+		step.ContinueOn = fn(s)
+	}
+	return step
+}
+
+// mapStrings returns a copy of m with fn applied to every value
+func mapStrings(m map[string]string, fn func(string) string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		result[k] = fn(v)
+	}
+	return result
+}
+
+// mapInterfaceStrings returns a copy of m with fn applied to every string
+// value, descending into nested maps and lists
+func mapInterfaceStrings(m map[string]interface{}, fn func(string) string) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		result[k] = mapValueStrings(v, fn)
+	}
+	return result
+}
+
+// mapValueStrings applies fn to every string in a decoded YAML value
+func mapValueStrings(v interface{}, fn func(string) string) interface{} {
+	switch value := v.(type) {
+	case string:
+		return fn(value)
+	case map[string]interface{}:
+		return mapInterfaceStrings(value, fn)
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		for i, item := range value {
+			result[i] = mapValueStrings(item, fn)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// ExtractReusableWorkflow lifts jobs of a workflow into a reusable workflow
+// triggered by workflow_call, to be stored at path relative to the repository
+// root (e.g. ".github/workflows/build.yml"). When no job IDs are given the
+// whole workflow is converted.
+//
+// The workflow_call contract is generated from the expressions the jobs use:
+// references to inputs and to outputs or results of jobs that stay behind
+// become inputs, referenced secrets other than GITHUB_TOKEN become secrets,
+// and job outputs become workflow outputs. Inputs keep the type of the
+// workflow input they come from. Other references to jobs that stay behind
+// cannot be passed in and are an error. Workflow-level env is copied, since
+// it is not passed on to called workflows.
+//
+// The second return value is a copy of the original workflow in which the
+// lifted jobs are replaced by one job calling the reusable workflow. When a
+// single job is extracted the calling job keeps its ID and its condition, so
+// references from other jobs keep working.
+func ExtractReusableWorkflow(workflow *ActionFile, path string, jobIDs ...string) (*ActionFile, *ActionFile, error) {
+	if workflow.Jobs == nil {
+		return nil, nil, ErrNotAWorkflow
+	}
+	if path == "" {
+		return nil, nil, fmt.Errorf("reusable workflow path is required")
+	}
+	if len(jobIDs) == 0 {
+		jobIDs = sortedJobIDs(workflow)
+	}
+
+	extracted := make(map[string]bool, len(jobIDs))
+	for _, id := range jobIDs {
+		if _, ok := workflow.Jobs[id]; !ok {
+			return nil, nil, fmt.Errorf("job %q not found", id)
+		}
+		extracted[id] = true
+	}
+
+	callID := jobIDs[0]
+	if len(jobIDs) > 1 {
+		base := path[strings.LastIndex(path, "/")+1:]
+		callID = inputNameFor(strings.TrimSuffix(strings.TrimSuffix(base, ".yml"), ".yaml"))
+	}
+	outputName := func(jobID, output string) string {
+		if len(jobIDs) == 1 {
+			return output
+		}
+		return jobID + "-" + output
+	}
+
+	inputs := make(map[string]interface{})
+	secrets := make(map[string]interface{})
+	with := make(map[string]interface{})
+	passSecrets := make(map[string]interface{})
+	callNeeds := make(map[string]bool)
+	types := workflowInputTypes(workflow)
+	var liftErr error
+
+	lift := func(ref ContextReference) string {
+		switch {
+		case ref.Context == "inputs" && len(ref.Path) == 1:
+			name := ref.Path[0]
+			if _, ok := inputs[name]; !ok {
+				typ := types[name]
+				if typ == "" {
+					typ = "string"
+				}
+				inputs[name] = map[string]interface{}{"type": typ, "required": true}
+				with[name] = fmt.Sprintf("${{ %s }}", ref)
+			}
+			return ""
+		case ref.Context == "secrets" && len(ref.Path) == 1 && ref.Path[0] != "GITHUB_TOKEN":
+			name := ref.Path[0]
+			if _, ok := secrets[name]; !ok {
+				secrets[name] = map[string]interface{}{"required": true}
+				passSecrets[name] = fmt.Sprintf("${{ %s }}", ref)
+			}
+			return ""
+		case ref.Context == "needs" && len(ref.Path) > 0 && !extracted[ref.Path[0]]:
+			// Outputs and results of jobs that stay behind are strings the
+			// caller can pass in; whole objects cannot be
+			var name string
+			switch {
+			case len(ref.Path) == 3 && ref.Path[1] == "outputs":
+				name = inputNameFor(ref.Path[0] + "-" + ref.Path[2])
+			case len(ref.Path) == 2 && ref.Path[1] == "result":
+				name = inputNameFor(ref.Path[0] + "-result")
+			default:
+				if liftErr == nil {
+					liftErr = fmt.Errorf("cannot pass %s to the reusable workflow, reference a single output or the result instead", ref)
+				}
+				return ""
+			}
+			if _, ok := inputs[name]; !ok {
+				inputs[name] = map[string]interface{}{"type": "string", "required": true}
+				with[name] = fmt.Sprintf("${{ %s }}", ref)
+			}
+			callNeeds[ref.Path[0]] = true
+			return "inputs." + name
+		}
+		return ""
+	}
+
+	reusable := &ActionFile{
+		Name: workflow.Name,
+		Jobs: make(map[string]Job, len(jobIDs)),
+	}
+	if len(jobIDs) == 1 && workflow.Jobs[callID].Name != "" {
+		reusable.Name = workflow.Jobs[callID].Name
+	}
+	if len(workflow.Env) > 0 {
+		reusable.Env = mapStrings(workflow.Env, func(s string) string { return s })
+	}
+
+	outputs := make(map[string]interface{})
+	for _, id := range jobIDs {
+		job := workflow.Jobs[id]
+		if len(jobIDs) == 1 {
+			// The calling job keeps the condition
+			job.If = ""
+		}
+		job = mapJobStrings(job, func(s string) string {
+			return RewriteContextReferences(s, lift)
+		})
+
+		var needs []string
+		for _, need := range JobNeeds(job) {
+			if extracted[need] {
+				needs = append(needs, need)
+			} else {
+				callNeeds[need] = true
+			}
+		}
+		job.Needs = nil
+		if len(needs) > 0 {
+			job.Needs = needs
+		}
+
+		for name := range job.Outputs {
+			outputs[outputName(id, name)] = map[string]interface{}{
+				"value": fmt.Sprintf("${{ jobs.%s.outputs.%s }}", id, name),
+			}
+		}
+		reusable.Jobs[id] = job
+	}
+
+	if liftErr != nil {
+		return nil, nil, liftErr
+	}
+
+	workflowCall := make(map[string]interface{})
+	if len(inputs) > 0 {
+		workflowCall["inputs"] = inputs
+	}
+	if len(secrets) > 0 {
+		workflowCall["secrets"] = secrets
+	}
+	if len(outputs) > 0 {
+		workflowCall["outputs"] = outputs
+	}
+	reusable.On = map[string]interface{}{"workflow_call": workflowCall}
+
+	// Build the caller
+	call := Job{Uses: "./" + strings.TrimPrefix(path, "./")}
+	if len(jobIDs) == 1 {
+		original := workflow.Jobs[callID]
+		call.Name = original.Name
+		call.If = original.If
+	}
+	if len(with) > 0 {
+		call.With = with
+	}
+	if len(passSecrets) > 0 {
+		call.Secrets = passSecrets
+	}
+	if len(callNeeds) > 0 {
+		needs := make([]string, 0, len(callNeeds))
+		for need := range callNeeds {
+			needs = append(needs, need)
+		}
+		sort.Strings(needs)
+		call.Needs = needs
+	}
+
+	caller := *workflow
+	caller.Jobs = make(map[string]Job, len(workflow.Jobs)-len(jobIDs)+1)
+	redirect := func(ref ContextReference) string {
+		if ref.Context != "needs" || len(ref.Path) < 2 || !extracted[ref.Path[0]] {
+			return ""
+		}
+		if len(ref.Path) == 3 && ref.Path[1] == "outputs" {
+			return fmt.Sprintf("needs.%s.outputs.%s", callID, outputName(ref.Path[0], ref.Path[2]))
+		}
+		if len(ref.Path) == 2 && ref.Path[1] == "result" {
+			return fmt.Sprintf("needs.%s.result", callID)
+		}
+		return ""
+	}
+	for id, job := range workflow.Jobs {
+		if extracted[id] {
+			continue
+		}
+		job = mapJobStrings(job, func(s string) string {
+			return RewriteContextReferences(s, redirect)
+		})
+		needs := JobNeeds(job)
+		if len(needs) > 0 {
+			rewrittenNeeds := make([]string, 0, len(needs))
+			seen := make(map[string]bool)
+			for _, need := range needs {
+				if extracted[need] {
+					need = callID
+				}
+				if !seen[need] {
+					seen[need] = true
+					rewrittenNeeds = append(rewrittenNeeds, need)
+				}
+			}
+			job.Needs = rewrittenNeeds
+		}
+		caller.Jobs[id] = job
+	}
+	caller.Jobs[callID] = call
+
+	return reusable, &caller, nil
+}
+
+// workflowInputTypes returns the types of the workflow_call and
+// workflow_dispatch inputs of a workflow, as far as workflow_call supports
+// them; other types, such as choice, are strings to a called workflow
+func workflowInputTypes(workflow *ActionFile) map[string]string {
+	types := make(map[string]string)
+	for _, event := range []string{"workflow_dispatch", "workflow_call"} {
+		config, ok, err := triggerConfig(workflow, event)
+		if err != nil || !ok {
+			continue
+		}
+		defs, err := MapOfStringInterface(config["inputs"])
+		if err != nil {
+			continue
+		}
+		for name, value := range defs {
+			def, err := MapOfStringInterface(value)
+			if err != nil {
+				continue
+			}
+			if typ, _ := def["type"].(string); containsString(inputTypes["workflow_call"], typ) {
+				types[name] = typ
+			}
+		}
+	}
+	return types
+}
+
+// mapJobStrings returns a copy of job with fn applied to every string value
+// that may contain expressions, including those of its steps
+func mapJobStrings(job Job, fn func(string) string) Job {
+	job.Name = fn(job.Name)
+	job.If = fn(job.If)
+	job.RunsOn = mapValueStrings(job.RunsOn, fn)
+	job.Container = mapValueStrings(job.Container, fn)
+	job.Outputs = mapStrings(job.Outputs, fn)
+	job.Env = mapStrings(job.Env, fn)
+	job.Strategy = mapInterfaceStrings(job.Strategy, fn)
+	job.With = mapInterfaceStrings(job.With, fn)
+	job.Secrets = mapValueStrings(job.Secrets, fn)
+	if len(job.Steps) > 0 {
+		steps := make([]Step, len(job.Steps))
+		for i, step := range job.Steps {
+			steps[i] = mapStepStrings(step, fn)
+		}
+		job.Steps = steps
+	}
+	return job
+}
@@ -0,0 +1,348 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestExtractComposite(t *testing.T) {
+	workflow, err := ParseFile("testdata/reusable-workflow.yml")
+	if err != nil {
+		t.Fatalf("Failed to parse workflow file: %v", err)
+	}
+
+	composite, rewritten, err := ExtractComposite(workflow, "build", StepRange{Start: 1, End: 5}, "build-node")
+	if err != nil {
+		t.Fatalf("Failed to extract composite action: %v", err)
+	}
+
+	// The composite action
+	if composite.Runs.Using != "composite" || len(composite.Runs.Steps) != 4 {
+		t.Fatalf("Expected a composite action with 4 steps, got %q with %d", composite.Runs.Using, len(composite.Runs.Steps))
+	}
+	for _, name := range []string{"node-version", "build-command", "npm-token"} {
+		if _, ok := composite.Inputs[name]; !ok {
+			t.Errorf("Expected lifted input %q", name)
+		}
+	}
+	if len(composite.Inputs) != 3 {
+		t.Errorf("Expected 3 inputs, got %d", len(composite.Inputs))
+	}
+	if got := composite.Runs.Steps[0].With["node-version"]; got != "${{ inputs.node-version }}" {
+		t.Errorf("Expected node-version to read the action input, got %v", got)
+	}
+	if got := composite.Runs.Steps[1].If; got != "${{ inputs.npm-token != '' }}" {
+		t.Errorf("Expected secret reference to be rewritten, got %q", got)
+	}
+	for i, step := range composite.Runs.Steps {
+		if step.Run != "" && step.Shell != "bash" {
+			t.Errorf("Expected run step %d to get a shell, got %q", i, step.Shell)
+		}
+	}
+	if out, ok := composite.Outputs["result"]; !ok || out.Value != "${{ steps.build-step.outputs.result }}" {
+		t.Errorf("Expected 'result' output to expose the build step, got %+v", out)
+	}
+
+	// The rewritten workflow
+	job := rewritten.Jobs["build"]
+	if len(job.Steps) != 3 {
+		t.Fatalf("Expected 3 steps after extraction, got %d", len(job.Steps))
+	}
+	call := job.Steps[1]
+	if call.Uses != "./.github/actions/build-node" || call.ID != "build-node" {
+		t.Errorf("Unexpected calling step %+v", call)
+	}
+	if call.With["npm-token"] != "${{ secrets.npm-token }}" {
+		t.Errorf("Expected the secret to be passed in, got %v", call.With["npm-token"])
+	}
+	if job.Outputs["result"] != "${{ steps.build-node.outputs.result }}" {
+		t.Errorf("Expected job output to read the composite output, got %q", job.Outputs["result"])
+	}
+
+	// The original workflow is left untouched
+	if len(workflow.Jobs["build"].Steps) != 6 {
+		t.Errorf("Expected original workflow to be unchanged")
+	}
+}
+
+func TestExtractCompositeInputCollision(t *testing.T) {
+	workflow := mustParse(t, `on: push
+env:
+  NODE_VERSION: "20"
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    strategy:
+      matrix:
+        node-version: [18, 20]
+    steps:
+      - uses: actions/setup-node@v4
+        with:
+          node-version: ${{ env.NODE_VERSION }}
+      - run: echo ${{ matrix.node-version }} ${{ env.NODE_VERSION }}
+`)
+	composite, rewritten, err := ExtractComposite(workflow, "build", StepRange{Start: 0, End: 2}, "setup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(composite.Inputs) != 2 {
+		t.Fatalf("Expected 2 inputs, got %v", composite.Inputs)
+	}
+	if got := composite.Runs.Steps[1].Run; got != "echo ${{ inputs.matrix-node-version }} ${{ inputs.node-version }}" {
+		t.Errorf("Expected the matrix value to get its own input, got %q", got)
+	}
+	with := rewritten.Jobs["build"].Steps[0].With
+	if with["node-version"] != "${{ env.NODE_VERSION }}" || with["matrix-node-version"] != "${{ matrix.node-version }}" {
+		t.Errorf("Unexpected inputs passed by the calling step: %v", with)
+	}
+}
+
+func TestExtractCompositeErrors(t *testing.T) {
+	workflow, err := ParseFile("testdata/workflow.yml")
+	if err != nil {
+		t.Fatalf("Failed to parse workflow file: %v", err)
+	}
+
+	if _, _, err := ExtractComposite(workflow, "missing", StepRange{0, 1}, "x"); err == nil {
+		t.Errorf("Expected error for unknown job")
+	}
+	if _, _, err := ExtractComposite(workflow, "lint", StepRange{2, 10}, "x"); err == nil {
+		t.Errorf("Expected error for out of range steps")
+	}
+	if _, _, err := ExtractComposite(workflow, "lint", StepRange{0, 1}, ""); err == nil {
+		t.Errorf("Expected error for empty name")
+	}
+}
+
+func TestRewriteContextReferences(t *testing.T) {
+	in := "echo ${{ env.FOO }} ${{ contains(github.ref, 'env.FOO') && env.FOO }} env.FOO"
+	out := RewriteContextReferences(in, func(ref ContextReference) string {
+		if ref.Context == "env" {
+			return "inputs.foo"
+		}
+		return ""
+	})
+	expected := "echo ${{ inputs.foo }} ${{ contains(github.ref, 'env.FOO') && inputs.foo }} env.FOO"
+	if out != expected {
+		t.Errorf("Expected %q, got %q", expected, out)
+	}
+
+	refs := ExtractContextReferences("${{ steps.build.outputs.result == 'a.b' }}")
+	if len(refs) != 1 || refs[0].String() != "steps.build.outputs.result" {
+		t.Errorf("Unexpected references %v", refs)
+	}
+}
+
+const releaseWorkflow = `name: Release
+on: push
+env:
+  REGISTRY: ghcr.io
+jobs:
+  version:
+    runs-on: ubuntu-latest
+    outputs:
+      tag: ${{ steps.v.outputs.tag }}
+    steps:
+      - id: v
+        run: echo "tag=v1" >> "$GITHUB_OUTPUT"
+  publish:
+    needs: version
+    runs-on: ubuntu-latest
+    outputs:
+      digest: ${{ steps.push.outputs.digest }}
+    steps:
+      - id: push
+        run: docker push ${{ env.REGISTRY }}/app:${{ needs.version.outputs.tag }}
+        env:
+          TOKEN: ${{ secrets.REGISTRY_TOKEN }}
+          GH: ${{ secrets.GITHUB_TOKEN }}
+  announce:
+    needs: [version, publish]
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ needs.publish.outputs.digest }}
+`
+
+func TestExtractReusableWorkflowJob(t *testing.T) {
+	workflow := mustParse(t, releaseWorkflow)
+
+	reusable, caller, err := ExtractReusableWorkflow(workflow, ".github/workflows/publish.yml", "publish")
+	if err != nil {
+		t.Fatalf("Failed to extract reusable workflow: %v", err)
+	}
+
+	if !IsReusableWorkflow(reusable) {
+		t.Fatalf("Expected a reusable workflow")
+	}
+	inputs, err := ExtractInputsFromWorkflowCall(reusable)
+	if err != nil {
+		t.Fatalf("Failed to extract inputs: %v", err)
+	}
+	if _, ok := inputs["version-tag"]; !ok || len(inputs) != 1 {
+		t.Errorf("Expected a single 'version-tag' input, got %v", inputs)
+	}
+	outputs, _ := ExtractOutputsFromWorkflowCall(reusable)
+	if outputs["digest"].Value != "${{ jobs.publish.outputs.digest }}" {
+		t.Errorf("Expected 'digest' output, got %v", outputs)
+	}
+	secrets := reusable.On.(map[string]interface{})["workflow_call"].(map[string]interface{})["secrets"].(map[string]interface{})
+	if _, ok := secrets["REGISTRY_TOKEN"]; !ok || len(secrets) != 1 {
+		t.Errorf("Expected only REGISTRY_TOKEN as secret, got %v", secrets)
+	}
+	if reusable.Env["REGISTRY"] != "ghcr.io" {
+		t.Errorf("Expected workflow env to be copied")
+	}
+	if got := reusable.Jobs["publish"].Steps[0].Run; got != "docker push ${{ env.REGISTRY }}/app:${{ inputs.version-tag }}" {
+		t.Errorf("Unexpected rewritten script %q", got)
+	}
+	if reusable.Jobs["publish"].Needs != nil {
+		t.Errorf("Expected needs on lifted job to be dropped, got %v", reusable.Jobs["publish"].Needs)
+	}
+
+	call := caller.Jobs["publish"]
+	if call.Uses != "./.github/workflows/publish.yml" {
+		t.Errorf("Unexpected caller uses %q", call.Uses)
+	}
+	if call.With["version-tag"] != "${{ needs.version.outputs.tag }}" {
+		t.Errorf("Expected output of 'version' to be passed in, got %v", call.With)
+	}
+	if needs := JobNeeds(call); len(needs) != 1 || needs[0] != "version" {
+		t.Errorf("Expected caller to need 'version', got %v", needs)
+	}
+	if caller.Jobs["announce"].Steps[0].Run != "echo ${{ needs.publish.outputs.digest }}" {
+		t.Errorf("Expected downstream references to stay intact")
+	}
+}
+
+func TestExtractReusableWorkflowWhole(t *testing.T) {
+	workflow := mustParse(t, releaseWorkflow)
+
+	reusable, caller, err := ExtractReusableWorkflow(workflow, ".github/workflows/release_all.yml")
+	if err != nil {
+		t.Fatalf("Failed to convert workflow: %v", err)
+	}
+
+	if len(reusable.Jobs) != 3 {
+		t.Errorf("Expected all 3 jobs in the reusable workflow, got %d", len(reusable.Jobs))
+	}
+	if len(caller.Jobs) != 1 {
+		t.Fatalf("Expected a single calling job, got %d", len(caller.Jobs))
+	}
+	if _, ok := caller.Jobs["release-all"]; !ok {
+		t.Errorf("Expected calling job 'release-all', got %v", caller.Jobs)
+	}
+	outputs, _ := ExtractOutputsFromWorkflowCall(reusable)
+	if _, ok := outputs["publish-digest"]; !ok {
+		t.Errorf("Expected prefixed job outputs, got %v", outputs)
+	}
+	if needs := JobNeeds(reusable.Jobs["announce"]); len(needs) != 2 {
+		t.Errorf("Expected needs between lifted jobs to be kept, got %v", needs)
+	}
+
+	if _, _, err := ExtractReusableWorkflow(&ActionFile{}, "x.yml"); err == nil {
+		t.Errorf("Expected error for a non-workflow")
+	}
+}
+
+func TestExtractReusableWorkflowCondition(t *testing.T) {
+	workflow := mustParse(t, `
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+  deploy:
+    needs: build
+    if: ${{ needs.build.result == 'success' && github.ref == 'refs/heads/main' }}
+    runs-on: ubuntu-latest
+    steps:
+      - run: make deploy
+`)
+
+	reusable, caller, err := ExtractReusableWorkflow(workflow, ".github/workflows/deploy.yml", "deploy")
+	if err != nil {
+		t.Fatalf("Failed to extract reusable workflow: %v", err)
+	}
+	if got := reusable.Jobs["deploy"].If; got != "" {
+		t.Errorf("Expected the condition to stay with the calling job only, got %q", got)
+	}
+	if got := caller.Jobs["deploy"].If; got != workflow.Jobs["deploy"].If {
+		t.Errorf("Expected the calling job to keep the condition, got %q", got)
+	}
+	if inputs, _ := ExtractInputsFromWorkflowCall(reusable); len(inputs) != 0 {
+		t.Errorf("Expected no inputs lifted from the condition, got %v", inputs)
+	}
+}
+
+func TestExtractReusableWorkflowNeedsResult(t *testing.T) {
+	workflow := mustParse(t, `
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+  report:
+    needs: build
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ needs.build.result }}
+`)
+
+	reusable, caller, err := ExtractReusableWorkflow(workflow, ".github/workflows/report.yml", "report")
+	if err != nil {
+		t.Fatalf("Failed to extract reusable workflow: %v", err)
+	}
+	inputs, _ := ExtractInputsFromWorkflowCall(reusable)
+	if _, ok := inputs["build-result"]; !ok || len(inputs) != 1 {
+		t.Errorf("Expected a single 'build-result' input, got %v", inputs)
+	}
+	if got := reusable.Jobs["report"].Steps[0].Run; got != "echo ${{ inputs.build-result }}" {
+		t.Errorf("Unexpected rewritten script %q", got)
+	}
+	if got := caller.Jobs["report"].With["build-result"]; got != "${{ needs.build.result }}" {
+		t.Errorf("Expected the result of 'build' to be passed in, got %v", got)
+	}
+	if needs := JobNeeds(caller.Jobs["report"]); len(needs) != 1 || needs[0] != "build" {
+		t.Errorf("Expected caller to need 'build', got %v", needs)
+	}
+
+	workflow.Jobs["report"].Steps[0].Run = "echo '${{ toJSON(needs.build.outputs) }}'"
+	if _, _, err := ExtractReusableWorkflow(workflow, ".github/workflows/report.yml", "report"); err == nil {
+		t.Error("Expected an error for a reference to all outputs of a job left behind")
+	}
+}
+
+func TestExtractReusableWorkflowInputTypes(t *testing.T) {
+	workflow := mustParse(t, `
+on:
+  workflow_dispatch:
+    inputs:
+      dry-run:
+        type: boolean
+      retries:
+        type: number
+      target:
+        type: choice
+        options: [staging, production]
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    steps:
+      - run: deploy --dry-run=${{ inputs.dry-run }} --retries=${{ inputs.retries }} ${{ inputs.target }}
+`)
+
+	reusable, _, err := ExtractReusableWorkflow(workflow, ".github/workflows/deploy.yml", "deploy")
+	if err != nil {
+		t.Fatalf("Failed to extract reusable workflow: %v", err)
+	}
+	inputs, err := ExtractInputsFromWorkflowCall(reusable)
+	if err != nil {
+		t.Fatalf("Failed to extract inputs: %v", err)
+	}
+	for name, typ := range map[string]string{"dry-run": "boolean", "retries": "number", "target": "string"} {
+		if inputs[name].Type != typ {
+			t.Errorf("Expected input '%s' to be a %s, got %q", name, typ, inputs[name].Type)
+		}
+	}
+}
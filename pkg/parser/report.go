@@ -0,0 +1,96 @@
+package parser
+
+import "fmt"
+
+// Report summarizes findings by severity to decide whether a CI run passes
+type Report struct {
+	Errors   int `json:"errors"`
+	Warnings int `json:"warnings"`
+	Infos    int `json:"infos"`
+}
+
+// NewReport counts findings keyed by file path. Findings without a
+// severity count as errors.
+func NewReport(findings map[string][]ValidationError) *Report {
+	r := &Report{}
+	for _, list := range findings {
+		for _, finding := range list {
+			r.Add(finding)
+		}
+	}
+	return r
+}
+
+// Add counts a single finding
+func (r *Report) Add(finding ValidationError) {
+	switch finding.Severity {
+	case SeverityWarning:
+		r.Warnings++
+	case SeverityInfo:
+		r.Infos++
+	default:
+		r.Errors++
+	}
+}
+
+// Total returns the number of findings
+func (r *Report) Total() int {
+	return r.Errors + r.Warnings + r.Infos
+}
+
+// Pass reports whether no finding is at least as severe as threshold: with
+// SeverityWarning, errors and warnings fail but infos pass
+func (r *Report) Pass(threshold Severity) bool {
+	switch threshold {
+	case SeverityInfo:
+		return r.Total() == 0
+	case SeverityWarning:
+		return r.Errors+r.Warnings == 0
+	default:
+		return r.Errors == 0
+	}
+}
+
+// String returns a summary such as "2 errors, 1 warning, 0 infos"
+func (r *Report) String() string {
+	return fmt.Sprintf("%s, %s, %s", plural(r.Errors, "error"), plural(r.Warnings, "warning"), plural(r.Infos, "info"))
+}
+
+// ParseSeverity parses a severity name as used by ValidationError.Severity
+func ParseSeverity(s string) (Severity, error) {
+	switch severity := Severity(s); severity {
+	case SeverityError, SeverityWarning, SeverityInfo:
+		return severity, nil
+	}
+	return "", fmt.Errorf("invalid severity %q, expected %s, %s or %s", s, SeverityError, SeverityWarning, SeverityInfo)
+}
+
+// Badge is a shields.io endpoint badge, see https://shields.io/badges/endpoint-badge
+type Badge struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+// Badge returns the badge of the report: "workflows: valid" in green when
+// there are no errors or warnings, or the number of issues in red when
+// there are errors and in yellow otherwise. Infos are not counted.
+func (r *Report) Badge() *Badge {
+	badge := &Badge{SchemaVersion: 1, Label: "workflows", Message: "valid", Color: "brightgreen"}
+	if issues := r.Errors + r.Warnings; issues > 0 {
+		badge.Message, badge.Color = plural(issues, "issue"), "yellow"
+		if r.Errors > 0 {
+			badge.Color = "red"
+		}
+	}
+	return badge
+}
+
+// plural formats a count of a noun
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,50 @@
+package parser
+
+import "testing"
+
+func TestReport(t *testing.T) {
+	r := NewReport(map[string][]ValidationError{
+		"ci.yml":   {{Severity: SeverityWarning}, {Severity: SeverityInfo}, {Severity: SeverityInfo}},
+		"lint.yml": nil,
+	})
+	if r.Errors != 0 || r.Warnings != 1 || r.Infos != 2 || r.Total() != 3 {
+		t.Errorf("Unexpected counts %+v", r)
+	}
+	if got := r.String(); got != "0 errors, 1 warning, 2 infos" {
+		t.Errorf("String() = %q", got)
+	}
+	for threshold, want := range map[Severity]bool{SeverityError: true, SeverityWarning: false, SeverityInfo: false} {
+		if got := r.Pass(threshold); got != want {
+			t.Errorf("Pass(%s) = %v, want %v", threshold, got, want)
+		}
+	}
+
+	r.Add(ValidationError{Message: "validation errors have no severity"})
+	if r.Errors != 1 || r.Pass(SeverityError) {
+		t.Errorf("Expected a finding without severity to fail, got %+v", r)
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	if s, err := ParseSeverity("warning"); err != nil || s != SeverityWarning {
+		t.Errorf("ParseSeverity(warning) = %q, %v", s, err)
+	}
+	if _, err := ParseSeverity("fatal"); err == nil {
+		t.Error("Expected an error for an unknown severity")
+	}
+}
+
+func TestReportBadge(t *testing.T) {
+	for _, tt := range []struct {
+		report Report
+		want   Badge
+	}{
+		{Report{Infos: 3}, Badge{1, "workflows", "valid", "brightgreen"}},
+		{Report{Warnings: 1}, Badge{1, "workflows", "1 issue", "yellow"}},
+		{Report{Errors: 1, Warnings: 2}, Badge{1, "workflows", "3 issues", "red"}},
+	} {
+		if got := tt.report.Badge(); *got != tt.want {
+			t.Errorf("Badge() for %+v = %+v, want %+v", tt.report, *got, tt.want)
+		}
+	}
+}
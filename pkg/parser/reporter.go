@@ -0,0 +1,133 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// LocatedFinding is a finding together with the file and position it
+// refers to, as written by the reporters
+type LocatedFinding struct {
+	File string `json:"file"`
+	// Line and Column are zero when the file was parsed without
+	// WithPositions
+	Line   int `json:"line,omitempty"`
+	Column int `json:"column,omitempty"`
+	ValidationError
+}
+
+// LocateFindings attaches files and positions to findings keyed by file
+// path. Findings whose field has no recorded position take the position of
+// the closest enclosing field. The result is sorted by file, then line, and
+// keeps the order of findings on the same line.
+func LocateFindings(files map[string]*ActionFile, findings map[string][]ValidationError) []LocatedFinding {
+	result := make([]LocatedFinding, 0)
+	for path, list := range findings {
+		for _, finding := range list {
+			located := LocatedFinding{File: path, ValidationError: finding}
+			if pos, ok := fieldPosition(files[path], finding.Field); ok {
+				located.Line, located.Column = pos.Line, pos.Column
+			}
+			result = append(result, located)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].File != result[j].File {
+			return result[i].File < result[j].File
+		}
+		return result[i].Line < result[j].Line
+	})
+	return result
+}
+
+// fieldPosition returns the position of field in action, or of the closest
+// enclosing field with a position
+func fieldPosition(action *ActionFile, field string) (Position, bool) {
+	if action == nil {
+		return Position{}, false
+	}
+	for field != "" {
+		if pos, ok := action.Position(field); ok {
+			return pos, true
+		}
+		i := strings.LastIndexAny(field, ".[")
+		if i < 0 {
+			break
+		}
+		field = field[:i]
+	}
+	return Position{}, false
+}
+
+// JSONLinesReporter writes findings as JSON lines, one object per finding
+// as soon as it is reported, for jq and log pipelines
+type JSONLinesReporter struct {
+	encoder *json.Encoder
+}
+
+// NewJSONLinesReporter creates a JSONLinesReporter writing to w
+func NewJSONLinesReporter(w io.Writer) *JSONLinesReporter {
+	return &JSONLinesReporter{encoder: json.NewEncoder(w)}
+}
+
+// Report writes a single finding
+func (r *JSONLinesReporter) Report(finding LocatedFinding) error {
+	if err := r.encoder.Encode(finding); err != nil {
+		return fmt.Errorf("failed to write finding: %w", err)
+	}
+	return nil
+}
+
+// AnnotationReporter writes findings as GitHub Actions workflow commands
+// such as "::error file=ci.yml,line=3,col=5::message", so running inside a
+// workflow annotates the files of the pull request
+type AnnotationReporter struct {
+	w io.Writer
+}
+
+// NewAnnotationReporter creates an AnnotationReporter writing to w
+func NewAnnotationReporter(w io.Writer) *AnnotationReporter {
+	return &AnnotationReporter{w: w}
+}
+
+// Report writes a single finding. Errors become error annotations,
+// warnings warning annotations and infos notices.
+func (r *AnnotationReporter) Report(finding LocatedFinding) error {
+	command := "error"
+	switch finding.Severity {
+	case SeverityWarning:
+		command = "warning"
+	case SeverityInfo:
+		command = "notice"
+	}
+
+	properties := []string{"file=" + escapeProperty(finding.File)}
+	if finding.Line > 0 {
+		properties = append(properties, fmt.Sprintf("line=%d", finding.Line), fmt.Sprintf("col=%d", finding.Column))
+	}
+	if finding.Rule != "" {
+		properties = append(properties, "title="+escapeProperty(finding.Rule))
+	}
+	message := finding.Field + ": " + finding.Message
+	if finding.Suggestion != "" {
+		message += "\n" + finding.Suggestion
+	}
+
+	if _, err := fmt.Fprintf(r.w, "::%s %s::%s\n", command, strings.Join(properties, ","), escapeData(message)); err != nil {
+		return fmt.Errorf("failed to write annotation: %w", err)
+	}
+	return nil
+}
+
+// escapeData escapes the message of a workflow command
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property value of a workflow command
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestLocateFindings(t *testing.T) {
+	action, err := Parse(strings.NewReader(`on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+        with:
+          fetch-depth: 0
+`), WithPositions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]*ActionFile{"ci.yml": action, "other.yml": {}}
+	findings := map[string][]ValidationError{
+		"ci.yml": {
+			{Field: "jobs.build.steps[0].with.missing", Message: "second"},
+			{Field: "jobs.build", Message: "first"},
+		},
+		"other.yml": {{Field: "on", Message: "unlocated"}},
+		"a.yml":     {{Field: "name", Message: "unknown file"}},
+	}
+
+	located := LocateFindings(files, findings)
+	var got []string
+	for _, f := range located {
+		got = append(got, f.File+":"+Position{f.Line, f.Column}.String()+":"+f.Message)
+	}
+	want := []string{"a.yml:0:0:unknown file", "ci.yml:3:3:first", "ci.yml:7:9:second", "other.yml:0:0:unlocated"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("LocateFindings() = %v, want %v", got, want)
+	}
+}
+
+func TestJSONLinesReporter(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewJSONLinesReporter(&buf)
+	findings := []LocatedFinding{
+		{File: "ci.yml", Line: 3, Column: 5, ValidationError: newFinding("job-runner", SeverityError, "jobs.build", "Job must specify either 'runs-on' or 'uses'")},
+		{File: "lint.yml", ValidationError: ValidationError{Field: "on", Message: "message"}},
+	}
+	for _, f := range findings {
+		if err := r.Report(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", buf.String())
+	}
+	var first map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"file", "line", "column", "field", "message", "severity", "rule", "suggestion"} {
+		if _, ok := first[key]; !ok {
+			t.Errorf("Expected key %q in %s", key, lines[0])
+		}
+	}
+	if lines[1] != `{"file":"lint.yml","field":"on","message":"message"}` {
+		t.Errorf("Unexpected line %s", lines[1])
+	}
+}
+
+func TestAnnotationReporter(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewAnnotationReporter(&buf)
+	findings := []LocatedFinding{
+		{File: "ci.yml", Line: 3, Column: 5, ValidationError: ValidationError{Field: "jobs.build", Message: "100% broken", Severity: SeverityError, Rule: "job-runner", Suggestion: "Add runs-on"}},
+		{File: "a,b.yml", ValidationError: ValidationError{Field: "on", Message: "message", Severity: SeverityInfo}},
+	}
+	for _, f := range findings {
+		if err := r.Report(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := "::error file=ci.yml,line=3,col=5,title=job-runner::jobs.build: 100%25 broken%0AAdd runs-on\n" +
+		"::notice file=a%2Cb.yml::on: message\n"
+	if buf.String() != want {
+		t.Errorf("Unexpected annotations:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
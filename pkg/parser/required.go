@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// requiredWorkflowEvents are the events that run a workflow required by an
+// organization ruleset
+var requiredWorkflowEvents = []string{"pull_request", "pull_request_target", "merge_group"}
+
+// repositoryComparisonPattern matches comparisons of the repository of the
+// run with a literal, which tie a workflow to a single repository
+var repositoryComparisonPattern = regexp.MustCompile(`github\.repository(?:_owner|_id)?\s*[!=]=\s*'|'[^']*'\s*[!=]=\s*github\.repository(?:_owner|_id)?\b`)
+
+// CheckRequiredWorkflow checks a workflow meant to be enforced across the
+// repositories of an organization as a required workflow of a ruleset,
+// reporting constructs that break in other repositories: triggers rulesets
+// do not run, branch and path filters that leave the required check
+// pending, references to local actions and environments, comparisons with
+// a hardcoded repository, and secrets other than GITHUB_TOKEN, which must
+// be organization secrets shared with every repository.
+func CheckRequiredWorkflow(workflow *ActionFile) []ValidationError {
+	findings := make([]ValidationError, 0)
+	add := func(severity Severity, field, message string) {
+		findings = append(findings, newFinding("required-workflow", severity, field, message))
+	}
+	if workflow.Jobs == nil {
+		return findings
+	}
+
+	triggered := false
+	for _, event := range requiredWorkflowEvents {
+		config, ok, err := triggerConfig(workflow, event)
+		if !ok || err != nil {
+			continue
+		}
+		triggered = true
+		for _, filter := range []string{"branches", "branches-ignore", "paths", "paths-ignore"} {
+			if _, ok := config[filter]; ok {
+				add(SeverityError, fmt.Sprintf("on.%s.%s", event, filter),
+					fmt.Sprintf("'%s' filters leave the required check pending on pull requests they skip, blocking the merge", filter))
+			}
+		}
+	}
+	if !triggered {
+		add(SeverityError, "on", "Required workflows only run for pull_request, pull_request_target and merge_group events")
+	}
+
+	for _, jobID := range sortedJobIDs(workflow) {
+		job := workflow.Jobs[jobID]
+		if strings.HasPrefix(job.Uses, "./") {
+			add(SeverityError, fmt.Sprintf("jobs.%s.uses", jobID),
+				fmt.Sprintf("Local workflow '%s' is looked up in each repository; reference it as owner/repo/path@ref", job.Uses))
+		}
+		if s, ok := job.Secrets.(string); ok && s == "inherit" {
+			add(SeverityWarning, fmt.Sprintf("jobs.%s.secrets", jobID), "Inherited secrets differ between repositories")
+		}
+		if job.Environment != nil {
+			add(SeverityError, fmt.Sprintf("jobs.%s.environment", jobID), "Environments are defined per repository and are missing from most of them")
+		}
+		for i, step := range job.Steps {
+			if strings.HasPrefix(step.Uses, "./") {
+				add(SeverityError, fmt.Sprintf("jobs.%s.steps[%d].uses", jobID, i),
+					fmt.Sprintf("Local action '%s' is looked up in each repository; reference it as owner/repo/path@ref", step.Uses))
+			}
+		}
+	}
+
+	for _, f := range ExpressionFields(workflow) {
+		reported := make(map[string]bool)
+		for _, expr := range f.Expressions {
+			if repositoryComparisonPattern.MatchString(expr) && !reported["github.repository"] {
+				reported["github.repository"] = true
+				add(SeverityError, f.Field, "Comparing the repository with a literal ties the workflow to a single repository")
+			}
+		}
+		for _, ref := range ExtractContextReferences(conditionExpression(f.Value)) {
+			if ref.Context != "secrets" || ref.Path[0] == "GITHUB_TOKEN" || reported["secrets."+ref.Path[0]] {
+				continue
+			}
+			reported["secrets."+ref.Path[0]] = true
+			add(SeverityWarning, f.Field,
+				fmt.Sprintf("Secret '%s' must be an organization secret available to every repository the workflow is required in", ref.Path[0]))
+		}
+	}
+	return findings
+}
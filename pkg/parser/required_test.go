@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckRequiredWorkflow(t *testing.T) {
+	workflow := mustParse(t, `
+on:
+  push:
+  pull_request:
+    paths: ['src/**']
+jobs:
+  scan:
+    if: github.repository == 'octo/app'
+    runs-on: ubuntu-latest
+    environment: production
+    steps:
+      - uses: ./.github/actions/setup
+      - run: scan --token ${{ secrets.SCAN_TOKEN }} --github ${{ secrets.GITHUB_TOKEN }}
+  shared:
+    uses: ./.github/workflows/shared.yml
+    secrets: inherit
+`)
+	var fields []string
+	for _, finding := range CheckRequiredWorkflow(workflow) {
+		if finding.Rule != "required-workflow" {
+			t.Errorf("Unexpected rule %s", finding.Rule)
+		}
+		fields = append(fields, finding.Field)
+	}
+	want := []string{
+		"on.pull_request.paths",
+		"jobs.scan.environment",
+		"jobs.scan.steps[0].uses",
+		"jobs.shared.uses",
+		"jobs.shared.secrets",
+		"jobs.scan.if",
+		"jobs.scan.steps[1].run",
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("CheckRequiredWorkflow() fields = %v, want %v", fields, want)
+	}
+
+	portable := mustParse(t, `
+on:
+  pull_request:
+  merge_group:
+jobs:
+  scan:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: my-org/scan@v1
+        with:
+          token: ${{ secrets.GITHUB_TOKEN }}
+`)
+	if findings := CheckRequiredWorkflow(portable); len(findings) != 0 {
+		t.Errorf("Expected no findings, got %v", findings)
+	}
+
+	pushOnly := mustParse(t, "on: push\njobs:\n  a:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make\n")
+	if findings := CheckRequiredWorkflow(pushOnly); len(findings) != 1 || findings[0].Field != "on" {
+		t.Errorf("Expected the trigger to be reported, got %v", findings)
+	}
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Resolver loads the action or reusable workflow referenced by a 'uses'
+// value
+type Resolver interface {
+	Resolve(uses string) (*ActionFile, error)
+}
+
+// ContextResolver is a Resolver whose work, such as network requests, can
+// be cancelled. It is used instead of Resolve when an operation is given a
+// context with WithContext.
+type ContextResolver interface {
+	Resolver
+	ResolveContext(ctx context.Context, uses string) (*ActionFile, error)
+}
+
+// resolveContext resolves uses through r, passing ctx on if r supports it
+func resolveContext(ctx context.Context, r Resolver, uses string) (*ActionFile, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if cr, ok := r.(ContextResolver); ok {
+		return cr.ResolveContext(ctx, uses)
+	}
+	return r.Resolve(uses)
+}
+
+// resolve resolves uses through r like resolveContext, within a span of the
+// configured Tracer
+func (o *options) resolve(r Resolver, uses string) (*ActionFile, error) {
+	ctx, span := o.tracer.Start(o.ctx, "parser.Resolve")
+	defer span.End()
+	span.SetAttribute("uses", uses)
+	action, err := resolveContext(ctx, r, uses)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return action, err
+}
+
+// ChainResolver tries each resolver in turn, moving on to the next when one
+// fails with ErrNotFound
+type ChainResolver []Resolver
+
+// Resolve implements Resolver
+func (c ChainResolver) Resolve(uses string) (*ActionFile, error) {
+	return c.ResolveContext(context.Background(), uses)
+}
+
+// ResolveContext implements ContextResolver
+func (c ChainResolver) ResolveContext(ctx context.Context, uses string) (*ActionFile, error) {
+	for _, r := range c {
+		action, err := resolveContext(ctx, r, uses)
+		if err == nil || !errors.Is(err, ErrNotFound) {
+			return action, err
+		}
+	}
+	return nil, fmt.Errorf("cannot resolve %s: %w", uses, ErrNotFound)
+}
@@ -0,0 +1,35 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestChainResolver(t *testing.T) {
+	local := mapResolver{"./.github/workflows/build.yml": &ActionFile{Name: "local"}}
+	remote := mapResolver{"octo/repo/.github/workflows/build.yml@v1": &ActionFile{Name: "remote"}}
+	chain := ChainResolver{local, remote}
+
+	for uses, name := range map[string]string{
+		"./.github/workflows/build.yml":            "local",
+		"octo/repo/.github/workflows/build.yml@v1": "remote",
+	} {
+		action, err := chain.Resolve(uses)
+		if err != nil || action.Name != name {
+			t.Errorf("Resolve(%q) = %v, %v; expected %s", uses, action, err, name)
+		}
+	}
+	if _, err := chain.Resolve("octo/missing@v1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestChainResolverContext(t *testing.T) {
+	chain := ChainResolver{mapResolver{"./a": &ActionFile{Name: "a"}}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := chain.ResolveContext(ctx, "./a"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
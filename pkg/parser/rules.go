@@ -0,0 +1,145 @@
+package parser
+
+import "sort"
+
+// RuleDocsURL is the page documenting every rule, with an anchor per rule ID
+const RuleDocsURL = "https://scagogogo.github.io/github-action-parser/rules"
+
+// RuleCategory groups rules by the kind of problem they report
+type RuleCategory string
+
+const (
+	// RuleCategorySyntax marks files GitHub rejects or misreads
+	RuleCategorySyntax RuleCategory = "syntax"
+	// RuleCategoryCorrectness marks references that do not resolve and
+	// values that do not do what was likely meant
+	RuleCategoryCorrectness RuleCategory = "correctness"
+	// RuleCategorySecurity marks supply chain and secret handling risks
+	RuleCategorySecurity RuleCategory = "security"
+	// RuleCategoryMaintenance marks deprecated features and dead code
+	RuleCategoryMaintenance RuleCategory = "maintenance"
+	// RuleCategoryPolicy marks violations of the project configuration
+	RuleCategoryPolicy RuleCategory = "policy"
+	// RuleCategoryStyle marks file encoding and conventions
+	RuleCategoryStyle RuleCategory = "style"
+)
+
+// Rule describes a built-in validation or lint rule
+type Rule struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	// Severity is the severity of the rule's findings unless overridden in
+	// Config.Rules. Some rules lower it for less certain cases, such as
+	// undefined-env outside expressions.
+	Severity   Severity     `json:"severity"`
+	Category   RuleCategory `json:"category"`
+	DocURL     string       `json:"docUrl"`
+	Suggestion string       `json:"suggestion,omitempty"`
+	Example    string       `json:"example,omitempty"`
+}
+
+// ruleInfo is the catalog entry of a rule
+type ruleInfo struct {
+	description string
+	severity    Severity
+	category    RuleCategory
+}
+
+// ruleCatalog describes every built-in rule; ruleFixes holds their guidance
+var ruleCatalog = map[string]ruleInfo{
+	"action-name":              {"Actions must have a name", SeverityError, RuleCategorySyntax},
+	"action-description":       {"Actions must have a description", SeverityError, RuleCategorySyntax},
+	"action-runs-using":        {"Actions must declare a supported runs.using runtime", SeverityError, RuleCategorySyntax},
+	"javascript-main":          {"JavaScript actions must set runs.main", SeverityError, RuleCategorySyntax},
+	"docker-image":             {"Docker actions must set runs.image", SeverityError, RuleCategorySyntax},
+	"composite-steps":          {"Composite actions must have steps", SeverityError, RuleCategorySyntax},
+	"workflow-trigger":         {"Workflows must have at least one trigger", SeverityError, RuleCategorySyntax},
+	"workflow-jobs":            {"Workflows must have at least one job", SeverityError, RuleCategorySyntax},
+	"job-runner":               {"Jobs must set runs-on or call a reusable workflow", SeverityError, RuleCategorySyntax},
+	"runs-on":                  {"runs-on must name labels or a runner group that some runner can match", SeverityError, RuleCategorySyntax},
+	"job-steps":                {"Jobs must not define an empty steps list", SeverityError, RuleCategorySyntax},
+	"step-uses-or-run":         {"Steps must have either uses or run", SeverityError, RuleCategorySyntax},
+	"docker-reference":         {"docker:// references must name a valid image", SeverityError, RuleCategorySyntax},
+	"concurrency":              {"concurrency must be a group name or a mapping with a group", SeverityError, RuleCategorySyntax},
+	"permissions":              {"permissions must use known scopes and access levels", SeverityError, RuleCategorySyntax},
+	"byte-order-mark":          {"Files should not start with a byte order mark", SeverityInfo, RuleCategoryStyle},
+	"crlf-line-endings":        {"Files should use LF line endings", SeverityInfo, RuleCategoryStyle},
+	"job-environment":          {"environment must be a name or a mapping with a name and url", SeverityError, RuleCategorySyntax},
+	"job-uses":                 {"Jobs calling a reusable workflow must reference a workflow file and only set caller keys", SeverityError, RuleCategorySyntax},
+	"job-secrets":              {"Only jobs calling a reusable workflow may pass secrets", SeverityError, RuleCategorySyntax},
+	"reusable-nesting":         {"Reusable workflows must not be nested more than four levels deep or call themselves", SeverityError, RuleCategoryCorrectness},
+	"reusable-secrets":         {"Calls must pass the secrets the reusable workflow requires, and only those", SeverityError, RuleCategoryCorrectness},
+	"workflow-run-trigger":     {"workflow_run triggers must list workflows and valid filters", SeverityError, RuleCategorySyntax},
+	"workflow-run-reference":   {"workflow_run triggers must reference existing workflows", SeverityError, RuleCategoryCorrectness},
+	"push-filters":             {"push branch, tag and path filters must not conflict", SeverityError, RuleCategorySyntax},
+	"release-types":            {"release triggers must only list known activity types", SeverityError, RuleCategorySyntax},
+	"activity-types":           {"Event activity types must be documented by GitHub", SeverityError, RuleCategorySyntax},
+	"path-filters":             {"Path filters must be valid and not combine paths with paths-ignore", SeverityError, RuleCategorySyntax},
+	"schedule-cron":            {"Schedules must use valid five-field cron expressions", SeverityError, RuleCategorySyntax},
+	"input-definition":         {"Inputs must be mappings with valid keys", SeverityError, RuleCategorySyntax},
+	"input-type":               {"Inputs must declare a type supported by the trigger", SeverityError, RuleCategorySyntax},
+	"input-default-type":       {"Input defaults must match the declared type", SeverityError, RuleCategoryCorrectness},
+	"choice-options":           {"Choice inputs must list options including the default", SeverityError, RuleCategorySyntax},
+	"call-input-type":          {"Inputs passed to reusable workflows must match the declared type", SeverityError, RuleCategoryCorrectness},
+	"expression-functions":     {"Expressions must call known functions, in the fields where they are available", SeverityError, RuleCategorySyntax},
+	"expression-contexts":      {"Expressions must read known contexts, in the fields where they are available", SeverityError, RuleCategorySyntax},
+	"constant-condition":       {"if conditions should not be strings that are always true", SeverityWarning, RuleCategoryCorrectness},
+	"deprecated-command":       {"Steps should not use the deprecated set-output, save-state, set-env and add-path commands", SeverityWarning, RuleCategoryMaintenance},
+	"missing-action-ref":       {"Actions and reusable workflows of other repositories must be pinned to a ref", SeverityWarning, RuleCategorySecurity},
+	"branch-pinned-action":     {"Actions should not be pinned to a branch", SeverityWarning, RuleCategorySecurity},
+	"sha-pinning":              {"Actions must be pinned to a commit SHA when the configuration requires it", SeverityWarning, RuleCategoryPolicy},
+	"disallowed-action":        {"Actions must belong to an owner allowed by the configuration", SeverityError, RuleCategoryPolicy},
+	"missing-timeout":          {"Jobs and long run steps must set timeout-minutes when the configuration requires it", SeverityWarning, RuleCategoryPolicy},
+	"ghes-compatibility":       {"Workflows must only use features of the GitHub Enterprise Server version set in the configuration", SeverityError, RuleCategoryPolicy},
+	"disallowed-runner":        {"Jobs must run on a runner allowed by the configuration", SeverityError, RuleCategoryPolicy},
+	"cache-keys":               {"actions/cache keys should hash their dependencies, restore keys should be prefixes of the key, and paths cached once per job", SeverityWarning, RuleCategoryCorrectness},
+	"docker-image-digest":      {"Docker images should be pinned to a digest", SeverityWarning, RuleCategorySecurity},
+	"deprecated-input":         {"Steps should not pass inputs the action deprecated", SeverityWarning, RuleCategoryMaintenance},
+	"unknown-input":            {"Steps should only pass inputs the action declares", SeverityWarning, RuleCategoryCorrectness},
+	"missing-required-input":   {"Steps must pass the inputs the action requires", SeverityWarning, RuleCategoryCorrectness},
+	"undefined-step-output":    {"Step output references must match outputs the step writes", SeverityWarning, RuleCategoryCorrectness},
+	"env-shadowing":            {"Job and step env should not silently redefine variables of an enclosing scope", SeverityInfo, RuleCategoryCorrectness},
+	"undefined-env":            {"Environment variable references must be defined", SeverityWarning, RuleCategoryCorrectness},
+	"matrix-fail-fast":         {"Matrix jobs should set fail-fast explicitly", SeverityInfo, RuleCategoryCorrectness},
+	"matrix-max-parallel":      {"Large matrices should cap max-parallel", SeverityInfo, RuleCategoryCorrectness},
+	"risky-continue-on-error":  {"Tests, security scans, deployments and jobs other jobs need should not continue on error", SeverityWarning, RuleCategoryCorrectness},
+	"matrix-hidden-failures":   {"Matrix jobs should not continue on error for every combination", SeverityWarning, RuleCategoryCorrectness},
+	"matrix-entries":           {"Matrix include and exclude entries must be mappings, and exclude entries must use the matrix dimensions", SeverityError, RuleCategorySyntax},
+	"matrix-exclude-unmatched": {"Matrix exclude entries should match at least one combination", SeverityWarning, RuleCategoryCorrectness},
+	"undefined-matrix-key":     {"matrix references must match keys of the job's matrix", SeverityWarning, RuleCategoryCorrectness},
+	"unused-input":             {"Action inputs should be read by the action", SeverityWarning, RuleCategoryMaintenance},
+	"dangling-output":          {"Outputs must map to an existing step or job output", SeverityWarning, RuleCategoryCorrectness},
+	"required-workflow":        {"Workflows required by organization rulesets must work in every repository", SeverityError, RuleCategoryPolicy},
+	"unconsumed-output":        {"Reusable workflow outputs should be read by a caller", SeverityWarning, RuleCategoryMaintenance},
+	"workflow-name-collision":  {"Workflows of a repository should have distinct names", SeverityWarning, RuleCategoryMaintenance},
+}
+
+// Rules returns the metadata of every built-in rule sorted by ID, for
+// listing rules and rendering their documentation
+func Rules() []Rule {
+	rules := make([]Rule, 0, len(ruleCatalog))
+	for id := range ruleCatalog {
+		rule, _ := LookupRule(id)
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules
+}
+
+// LookupRule returns the metadata of the rule with the given ID
+func LookupRule(id string) (Rule, bool) {
+	info, ok := ruleCatalog[id]
+	if !ok {
+		return Rule{}, false
+	}
+	fix := ruleFixes[id]
+	return Rule{
+		ID:          id,
+		Description: info.description,
+		Severity:    info.severity,
+		Category:    info.category,
+		DocURL:      RuleDocsURL + "#" + id,
+		Suggestion:  fix.suggestion,
+		Example:     fix.example,
+	}, true
+}
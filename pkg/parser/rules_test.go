@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// emittedRule matches the rule IDs passed to the finding helpers
+var emittedRule = regexp.MustCompile(`\b(?:newFinding|addIssue|addError)\("([a-z0-9-]+)"`)
+
+// TestRulesCatalog checks that every rule with guidance, and every rule the
+// package reports, is in the catalog and documented
+func TestRulesCatalog(t *testing.T) {
+	for id := range ruleFixes {
+		if _, ok := ruleCatalog[id]; !ok {
+			t.Errorf("rule %s is missing from the catalog", id)
+		}
+	}
+	sources, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, source := range sources {
+		if strings.HasSuffix(source, "_test.go") {
+			continue
+		}
+		data, err := os.ReadFile(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range emittedRule.FindAllStringSubmatch(string(data), -1) {
+			if _, ok := ruleCatalog[m[1]]; !ok {
+				t.Errorf("rule %s reported in %s is missing from the catalog", m[1], source)
+			}
+		}
+	}
+	docs, err := os.ReadFile("../../docs/rules.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules := Rules()
+	if len(rules) != len(ruleCatalog) {
+		t.Fatalf("Expected %d rules, got %d", len(ruleCatalog), len(rules))
+	}
+	if !sort.SliceIsSorted(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID }) {
+		t.Error("Expected rules sorted by ID")
+	}
+	for _, rule := range rules {
+		if rule.Description == "" || rule.Severity == "" || rule.Category == "" || rule.Suggestion == "" {
+			t.Errorf("Incomplete rule %+v", rule)
+		}
+		if !strings.Contains(string(docs), "\n## "+rule.ID+"\n") {
+			t.Errorf("rule %s is not documented in docs/rules.md", rule.ID)
+		}
+	}
+}
+
+func TestLookupRule(t *testing.T) {
+	rule, ok := LookupRule("branch-pinned-action")
+	if !ok {
+		t.Fatal("Expected branch-pinned-action to be found")
+	}
+	if rule.Severity != SeverityWarning || rule.Category != RuleCategorySecurity ||
+		rule.DocURL != RuleDocsURL+"#branch-pinned-action" || !strings.Contains(rule.Example, "actions/checkout") {
+		t.Errorf("Unexpected rule %+v", rule)
+	}
+	if _, ok := LookupRule("no-such-rule"); ok {
+		t.Error("Expected unknown rule not to be found")
+	}
+}
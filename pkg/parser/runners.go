@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+)
+
+// RunnerKind tells whether a RunnerUsage is a runner label or group
+type RunnerKind string
+
+// Kinds of runner usage
+const (
+	RunnerLabel RunnerKind = "label"
+	RunnerGroup RunnerKind = "group"
+)
+
+// RunnerJob is a job requesting a runner
+type RunnerJob struct {
+	File string `json:"file"`
+	Job  string `json:"job"`
+}
+
+// RunnerUsage lists the jobs requesting a runner label or group
+type RunnerUsage struct {
+	Kind RunnerKind `json:"kind"`
+	// Name is lower-cased for labels, which GitHub matches
+	// case-insensitively
+	Name string `json:"name"`
+	// Count is the number of jobs requesting the runner
+	Count int `json:"count"`
+	// Workflows are the sorted paths of the files of Jobs
+	Workflows []string    `json:"workflows"`
+	Jobs      []RunnerJob `json:"jobs"`
+}
+
+// RunnerInventory lists the runners requested by a set of workflows
+type RunnerInventory struct {
+	// Runners are sorted by decreasing Count, then with labels before
+	// groups and by name
+	Runners []RunnerUsage `json:"runners"`
+}
+
+// Runners builds the runner inventory of a directory of workflows, as
+// returned by ParseDir. Labels taken from a job's matrix are expanded, and
+// jobs calling a reusable workflow are skipped as they have no runner.
+func Runners(workflows map[string]*ActionFile) *RunnerInventory {
+	byKey := make(map[string]*RunnerUsage)
+	add := func(kind RunnerKind, name string, job RunnerJob) {
+		key := string(kind) + ":" + name
+		usage, ok := byKey[key]
+		if !ok {
+			usage = &RunnerUsage{Kind: kind, Name: name}
+			byKey[key] = usage
+		}
+		if n := len(usage.Jobs); n > 0 && usage.Jobs[n-1] == job {
+			return
+		}
+		usage.Count++
+		usage.Jobs = append(usage.Jobs, job)
+		if n := len(usage.Workflows); n == 0 || usage.Workflows[n-1] != job.File {
+			usage.Workflows = append(usage.Workflows, job.File)
+		}
+	}
+
+	for _, file := range sortedFiles(workflows) {
+		action := workflows[file]
+		for _, jobID := range sortedJobIDs(action) {
+			job := action.Jobs[jobID]
+			ref := RunnerJob{File: file, Job: jobID}
+			if m, ok := job.RunsOn.(map[string]interface{}); ok && m["group"] != nil {
+				add(RunnerGroup, fmt.Sprint(m["group"]), ref)
+			}
+			for _, label := range jobRunnerLabels(job) {
+				add(RunnerLabel, label, ref)
+			}
+		}
+	}
+
+	inventory := &RunnerInventory{Runners: make([]RunnerUsage, 0, len(byKey))}
+	for _, usage := range byKey {
+		inventory.Runners = append(inventory.Runners, *usage)
+	}
+	sort.Slice(inventory.Runners, func(i, j int) bool {
+		a, b := inventory.Runners[i], inventory.Runners[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Kind != b.Kind {
+			return a.Kind > b.Kind
+		}
+		return a.Name < b.Name
+	})
+	return inventory
+}
@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRunners(t *testing.T) {
+	set := WorkflowSet{
+		"ci.yml": mustParse(t, `
+on: push
+jobs:
+  build:
+    runs-on: ${{ matrix.os }}
+    strategy:
+      matrix:
+        os: [ubuntu-latest, Windows-Latest]
+    steps:
+      - run: make
+  lint:
+    runs-on: Ubuntu-Latest
+    steps:
+      - run: make lint
+  call:
+    uses: ./.github/workflows/release.yml
+`),
+		"release.yml": mustParse(t, `
+on: workflow_call
+jobs:
+  release:
+    runs-on:
+      group: large-runners
+      labels: [self-hosted, linux]
+    steps:
+      - run: make release
+`),
+	}
+
+	inventory := Runners(set)
+	want := []RunnerUsage{
+		{Kind: RunnerLabel, Name: "ubuntu-latest", Count: 2, Workflows: []string{"ci.yml"}, Jobs: []RunnerJob{{"ci.yml", "build"}, {"ci.yml", "lint"}}},
+		{Kind: RunnerLabel, Name: "linux", Count: 1, Workflows: []string{"release.yml"}, Jobs: []RunnerJob{{"release.yml", "release"}}},
+		{Kind: RunnerLabel, Name: "self-hosted", Count: 1, Workflows: []string{"release.yml"}, Jobs: []RunnerJob{{"release.yml", "release"}}},
+		{Kind: RunnerLabel, Name: "windows-latest", Count: 1, Workflows: []string{"ci.yml"}, Jobs: []RunnerJob{{"ci.yml", "build"}}},
+		{Kind: RunnerGroup, Name: "large-runners", Count: 1, Workflows: []string{"release.yml"}, Jobs: []RunnerJob{{"release.yml", "release"}}},
+	}
+	if !reflect.DeepEqual(inventory.Runners, want) {
+		t.Errorf("Runners() = %+v, want %+v", inventory.Runners, want)
+	}
+}
@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RunsOn is the runner requested by a job with 'runs-on'
+type RunsOn struct {
+	// Group is the runner group of the mapping form, which selects larger
+	// runners and organization runners
+	Group string `json:"group,omitempty"`
+	// Labels must all be carried by the runner
+	Labels []string `json:"labels,omitempty"`
+}
+
+// githubHostedLabels are the labels of the GitHub-hosted runners, including
+// the macOS larger runners, which are selected by label rather than by group
+var githubHostedLabels = map[string]bool{
+	"ubuntu-latest": true, "ubuntu-24.04": true, "ubuntu-22.04": true, "ubuntu-20.04": true,
+	"ubuntu-24.04-arm": true, "ubuntu-22.04-arm": true,
+	"windows-latest": true, "windows-2025": true, "windows-2022": true, "windows-2019": true,
+	"windows-11-arm": true,
+	"macos-latest":   true, "macos-15": true, "macos-14": true, "macos-13": true,
+	"macos-latest-large": true, "macos-15-large": true, "macos-14-large": true, "macos-13-large": true,
+	"macos-latest-xlarge": true, "macos-15-xlarge": true, "macos-14-xlarge": true, "macos-13-xlarge": true,
+}
+
+// IsGitHubHostedLabel reports whether a runner label selects a
+// GitHub-hosted runner, such as ubuntu-latest or macos-14-xlarge. Labels are
+// compared case-insensitively.
+func IsGitHubHostedLabel(label string) bool {
+	return githubHostedLabels[strings.ToLower(label)]
+}
+
+// ParseRunsOn interprets the 'runs-on' value of a job: a label, a list of
+// labels, or a mapping with 'group' and 'labels'. It returns nil if the job
+// does not set 'runs-on'.
+func ParseRunsOn(v interface{}) (*RunsOn, error) {
+	switch value := v.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		runsOn := &RunsOn{}
+		for _, key := range sortedWithKeys(value) {
+			switch key {
+			case "group":
+				group, ok := value[key].(string)
+				if !ok || strings.TrimSpace(group) == "" {
+					return nil, fmt.Errorf("runs-on group must be a non-empty string")
+				}
+				runsOn.Group = group
+			case "labels":
+				labels, err := stringList(value[key])
+				if err != nil {
+					return nil, fmt.Errorf("invalid runs-on labels: %w", err)
+				}
+				runsOn.Labels = labels
+			default:
+				return nil, fmt.Errorf("unknown runs-on key %q, expected group or labels", key)
+			}
+		}
+		if runsOn.Group == "" && len(runsOn.Labels) == 0 {
+			return nil, fmt.Errorf("runs-on must specify a group or labels")
+		}
+		return runsOn, checkRunnerLabels(runsOn.Labels)
+	default:
+		labels, err := stringList(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid runs-on: %w", err)
+		}
+		if len(labels) == 0 {
+			return nil, fmt.Errorf("runs-on must specify at least one label")
+		}
+		return &RunsOn{Labels: labels}, checkRunnerLabels(labels)
+	}
+}
+
+// checkRunnerLabels rejects empty labels
+func checkRunnerLabels(labels []string) error {
+	for _, label := range labels {
+		if strings.TrimSpace(label) == "" {
+			return fmt.Errorf("runs-on labels must not be empty")
+		}
+	}
+	return nil
+}
+
+// Conflicts returns the reasons GitHub cannot queue the job on any runner:
+// GitHub-hosted labels combined with a runner group, or with another
+// GitHub-hosted label. Labels computed by expressions are not checked.
+func (r *RunsOn) Conflicts() []string {
+	var hosted []string
+	for _, label := range r.Labels {
+		if len(ExtractExpressions(label)) == 0 && IsGitHubHostedLabel(label) {
+			hosted = append(hosted, label)
+		}
+	}
+	var conflicts []string
+	if r.Group != "" && len(ExtractExpressions(r.Group)) == 0 {
+		for _, label := range hosted {
+			conflicts = append(conflicts, fmt.Sprintf("GitHub-hosted label '%s' cannot be used with runner group '%s'; select larger runners by the group and their own labels", label, r.Group))
+		}
+	}
+	if len(hosted) > 1 {
+		conflicts = append(conflicts, fmt.Sprintf("GitHub-hosted labels %s select different runners and cannot be combined", strings.Join(hosted, ", ")))
+	}
+	return conflicts
+}
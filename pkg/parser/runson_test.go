@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRunsOn(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  *RunsOn
+	}{
+		{nil, nil},
+		{"ubuntu-latest", &RunsOn{Labels: []string{"ubuntu-latest"}}},
+		{[]interface{}{"self-hosted", "linux"}, &RunsOn{Labels: []string{"self-hosted", "linux"}}},
+		{map[string]interface{}{"group": "larger"}, &RunsOn{Group: "larger"}},
+		{map[string]interface{}{"group": "larger", "labels": "ubuntu-22.04-16core"},
+			&RunsOn{Group: "larger", Labels: []string{"ubuntu-22.04-16core"}}},
+	}
+	for _, tt := range tests {
+		got, err := ParseRunsOn(tt.value)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseRunsOn(%v) = %+v, %v; want %+v", tt.value, got, err, tt.want)
+		}
+	}
+
+	for _, invalid := range []interface{}{
+		map[string]interface{}{},
+		map[string]interface{}{"group": ""},
+		map[string]interface{}{"group": "larger", "label": "linux"},
+		map[string]interface{}{"labels": []interface{}{1}},
+		[]interface{}{},
+		[]interface{}{"linux", " "},
+		42,
+	} {
+		if _, err := ParseRunsOn(invalid); err == nil {
+			t.Errorf("Expected an error for %v", invalid)
+		}
+	}
+}
+
+func TestRunsOnConflicts(t *testing.T) {
+	tests := []struct {
+		runsOn RunsOn
+		want   int
+	}{
+		{RunsOn{Labels: []string{"ubuntu-latest"}}, 0},
+		{RunsOn{Labels: []string{"macos-14-xlarge"}}, 0},
+		{RunsOn{Group: "larger", Labels: []string{"ubuntu-22.04-16core"}}, 0},
+		{RunsOn{Group: "larger", Labels: []string{"Ubuntu-Latest"}}, 1},
+		{RunsOn{Group: "${{ vars.GROUP }}", Labels: []string{"ubuntu-latest"}}, 0},
+		{RunsOn{Labels: []string{"ubuntu-latest", "windows-latest"}}, 1},
+		{RunsOn{Labels: []string{"${{ matrix.os }}", "ubuntu-latest"}}, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.runsOn.Conflicts(); len(got) != tt.want {
+			t.Errorf("Conflicts(%+v) = %v, want %d conflicts", tt.runsOn, got, tt.want)
+		}
+	}
+}
+
+func TestValidateRunsOn(t *testing.T) {
+	action := mustParse(t, `
+on: push
+jobs:
+  hosted:
+    runs-on:
+      group: larger-runners
+      labels: [ubuntu-latest]
+    steps:
+      - run: make
+  typo:
+    runs-on:
+      groups: larger-runners
+    steps:
+      - run: make
+  ok:
+    runs-on:
+      group: larger-runners
+      labels: ubuntu-22.04-16core
+    steps:
+      - run: make
+`)
+	fields := map[string]string{}
+	for _, e := range NewValidator().Validate(action) {
+		if e.Rule == "runs-on" {
+			fields[e.Field] = e.Message
+		}
+	}
+	if len(fields) != 2 || !strings.Contains(fields["jobs.hosted.runs-on"], "runner group") ||
+		!strings.Contains(fields["jobs.typo.runs-on"], "groups") {
+		t.Errorf("Unexpected runs-on findings %v", fields)
+	}
+}
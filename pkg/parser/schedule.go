@@ -0,0 +1,355 @@
+package parser
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a parsed POSIX cron expression as used by the 'schedule'
+// event. Each field holds the values it matches.
+type CronSchedule struct {
+	Expr       string
+	Minutes    []int
+	Hours      []int
+	DaysOfMon  []int
+	Months     []int
+	DaysOfWeek []int
+
+	// restricted days of month and week combine with OR, as in cron
+	domRestricted bool
+	dowRestricted bool
+}
+
+// cronField describes the range and names of one cron field
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"", "JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}},
+}
+
+// ParseCron parses a five-field cron expression
+func ParseCron(expr string) (*CronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("cron expression must have 5 fields, got %d", len(fields))
+	}
+
+	values := make([][]int, len(fields))
+	for i, field := range fields {
+		v, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+
+	// 7 is an alias for Sunday
+	dow := make([]int, 0, len(values[4]))
+	seen := make(map[int]bool)
+	for _, d := range values[4] {
+		d %= 7
+		if !seen[d] {
+			seen[d] = true
+			dow = append(dow, d)
+		}
+	}
+	sort.Ints(dow)
+
+	return &CronSchedule{
+		Expr:          expr,
+		Minutes:       values[0],
+		Hours:         values[1],
+		DaysOfMon:     values[2],
+		Months:        values[3],
+		DaysOfWeek:    dow,
+		domRestricted: fields[2] != "*",
+		dowRestricted: fields[4] != "*",
+	}, nil
+}
+
+// parseCronField expands a single cron field into the sorted values it matches
+func parseCronField(field string, f cronField) ([]int, error) {
+	set := make(map[int]bool)
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid step in %s field: %q", f.name, part)
+			}
+			rng, step = part[:i], n
+		}
+
+		lo, hi := f.min, f.max
+		if rng != "*" {
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if lo, err = cronValue(bounds[0], f); err != nil {
+				return nil, err
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = cronValue(bounds[1], f); err != nil {
+					return nil, err
+				}
+			} else if step > 1 {
+				// "5/15" means starting at 5
+				hi = f.max
+			}
+			if hi < lo {
+				return nil, fmt.Errorf("invalid range in %s field: %q", f.name, part)
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+
+	values := make([]int, 0, len(set))
+	for v := range set {
+		values = append(values, v)
+	}
+	sort.Ints(values)
+	return values, nil
+}
+
+// cronValue parses a number or name within a cron field
+func cronValue(s string, f cronField) (int, error) {
+	for i, name := range f.names {
+		if name != "" && strings.EqualFold(s, name) {
+			return i, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < f.min || n > f.max {
+		return 0, fmt.Errorf("invalid value in %s field: %q", f.name, s)
+	}
+	return n, nil
+}
+
+// MatchesDay reports whether the schedule fires at some time on the given day
+func (c *CronSchedule) MatchesDay(day time.Time) bool {
+	if !containsInt(c.Months, int(day.Month())) {
+		return false
+	}
+	dom := containsInt(c.DaysOfMon, day.Day())
+	dow := containsInt(c.DaysOfWeek, int(day.Weekday()))
+	switch {
+	case c.domRestricted && c.dowRestricted:
+		return dom || dow
+	case c.domRestricted:
+		return dom
+	case c.dowRestricted:
+		return dow
+	default:
+		return true
+	}
+}
+
+// RunsPerDay is the number of times the schedule fires on a day it matches
+func (c *CronSchedule) RunsPerDay() int {
+	return len(c.Minutes) * len(c.Hours)
+}
+
+func containsInt(values []int, v int) bool {
+	i := sort.SearchInts(values, v)
+	return i < len(values) && values[i] == v
+}
+
+// ParseScheduleTrigger returns the cron expressions of the 'schedule' event
+func ParseScheduleTrigger(action *ActionFile) ([]string, error) {
+	on, ok := action.On.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	raw, ok := on["schedule"]
+	if !ok {
+		return nil, nil
+	}
+	entries, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("schedule must be a list, got %T", raw)
+	}
+
+	crons := make([]string, 0, len(entries))
+	for i, entry := range entries {
+		m, err := MapOfStringInterface(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule[%d]: %w", i, err)
+		}
+		cron, ok := m["cron"].(string)
+		if !ok {
+			return nil, fmt.Errorf("schedule[%d] must specify a 'cron' string", i)
+		}
+		crons = append(crons, cron)
+	}
+	return crons, nil
+}
+
+// ScheduleRef identifies a cron entry of a workflow
+type ScheduleRef struct {
+	File  string `json:"file"`
+	Index int    `json:"index"`
+	Cron  string `json:"cron"`
+}
+
+// Field returns the path of the entry within its workflow
+func (r ScheduleRef) Field() string {
+	return fmt.Sprintf("on.schedule[%d].cron", r.Index)
+}
+
+// ScheduleFinding is an issue found by AnalyzeSchedules
+type ScheduleFinding struct {
+	// Kind is one of "overlap", "frequency" or "congested"
+	Kind      string        `json:"kind"`
+	Schedules []ScheduleRef `json:"schedules"`
+	Message   string        `json:"message"`
+	// Suggestion is an alternative cron expression for the first schedule,
+	// if one applies
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// MaxScheduledRunsPerDay is the number of daily runs above which a schedule is
+// reported as unusually frequent
+const MaxScheduledRunsPerDay = 24
+
+// scheduleReferenceDay is the first day of the period over which schedules
+// are compared; a leap year starting on a Monday covers every day and weekday
+var scheduleReferenceDay = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// AnalyzeSchedules checks the cron schedules of a directory of workflows, as
+// returned by ParseDir. It reports workflows that fire at identical times,
+// schedules that fire more than MaxScheduledRunsPerDay times a day, and
+// schedules firing at minute 0, when GitHub's scheduler is most congested.
+// Findings suggest a jittered minute derived from the file name. Invalid
+// schedules are skipped; the Validator reports them.
+func AnalyzeSchedules(workflows map[string]*ActionFile) []ScheduleFinding {
+	type entry struct {
+		ref  ScheduleRef
+		cron *CronSchedule
+	}
+
+	files := make([]string, 0, len(workflows))
+	for file := range workflows {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	var entries []entry
+	for _, file := range files {
+		crons, err := ParseScheduleTrigger(workflows[file])
+		if err != nil {
+			continue
+		}
+		for i, expr := range crons {
+			cron, err := ParseCron(expr)
+			if err != nil {
+				continue
+			}
+			entries = append(entries, entry{ScheduleRef{File: file, Index: i, Cron: expr}, cron})
+		}
+	}
+
+	var findings []ScheduleFinding
+	for _, e := range entries {
+		if runs := e.cron.RunsPerDay(); runs > MaxScheduledRunsPerDay {
+			findings = append(findings, ScheduleFinding{
+				Kind:      "frequency",
+				Schedules: []ScheduleRef{e.ref},
+				Message:   fmt.Sprintf("'%s' runs %d times a day; consider a lower frequency", e.ref.Cron, runs),
+			})
+		}
+		if len(e.cron.Minutes) == 1 && e.cron.Minutes[0] == 0 {
+			findings = append(findings, ScheduleFinding{
+				Kind:       "congested",
+				Schedules:  []ScheduleRef{e.ref},
+				Message:    fmt.Sprintf("'%s' fires at the top of the hour, when scheduled runs are most likely to be delayed or dropped", e.ref.Cron),
+				Suggestion: jitterCron(e.ref),
+			})
+		}
+	}
+
+	// Group schedules that fire at the same time, across workflows
+	grouped := make([]bool, len(entries))
+	for i := range entries {
+		if grouped[i] {
+			continue
+		}
+		group := []ScheduleRef{entries[i].ref}
+		for j := i + 1; j < len(entries); j++ {
+			if !grouped[j] && entries[j].ref.File != entries[i].ref.File && schedulesOverlap(entries[i].cron, entries[j].cron) {
+				grouped[j] = true
+				group = append(group, entries[j].ref)
+			}
+		}
+		if len(group) > 1 {
+			findings = append(findings, ScheduleFinding{
+				Kind:       "overlap",
+				Schedules:  group,
+				Message:    fmt.Sprintf("%d workflows are scheduled at identical times", len(group)),
+				Suggestion: jitterCron(group[0]),
+			})
+		}
+	}
+
+	return findings
+}
+
+// schedulesOverlap reports whether two schedules fire at the same minute on
+// at least one day
+func schedulesOverlap(a, b *CronSchedule) bool {
+	if !intersects(a.Minutes, b.Minutes) || !intersects(a.Hours, b.Hours) {
+		return false
+	}
+	for day := scheduleReferenceDay; day.Year() == scheduleReferenceDay.Year(); day = day.AddDate(0, 0, 1) {
+		if a.MatchesDay(day) && b.MatchesDay(day) {
+			return true
+		}
+	}
+	return false
+}
+
+// intersects reports whether two sorted slices share a value
+func intersects(a, b []int) bool {
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			return true
+		case a[i] < b[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	return false
+}
+
+// jitterCron replaces the minute field of a schedule with a minute derived
+// from its file name, so that suggestions are stable and spread out
+func jitterCron(ref ScheduleRef) string {
+	fields := strings.Fields(ref.Cron)
+	if strings.ContainsAny(fields[0], "*/,-") {
+		return ""
+	}
+	h := fnv.New32a()
+	h.Write([]byte(ref.File))
+	minute := 1 + int(h.Sum32()%58)
+	if strconv.Itoa(minute) == fields[0] {
+		minute++
+	}
+	fields[0] = strconv.Itoa(minute)
+	return strings.Join(fields, " ")
+}
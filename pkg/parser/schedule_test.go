@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		expr    string
+		minutes int
+		hours   int
+		dow     []int
+		wantErr bool
+	}{
+		{expr: "0 0 * * *", minutes: 1, hours: 1, dow: []int{0, 1, 2, 3, 4, 5, 6}},
+		{expr: "*/15 9-17 * * MON-FRI", minutes: 4, hours: 9, dow: []int{1, 2, 3, 4, 5}},
+		{expr: "5/20 1,13 * * 0,7", minutes: 3, hours: 2, dow: []int{0}},
+		{expr: "0 0 * *", wantErr: true},
+		{expr: "60 0 * * *", wantErr: true},
+		{expr: "0 5-1 * * *", wantErr: true},
+		{expr: "*/0 * * * *", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			cron, err := ParseCron(tt.expr)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to parse: %v", err)
+			}
+			if len(cron.Minutes) != tt.minutes || len(cron.Hours) != tt.hours {
+				t.Errorf("Expected %d minutes and %d hours, got %v and %v", tt.minutes, tt.hours, cron.Minutes, cron.Hours)
+			}
+			if fmt.Sprint(cron.DaysOfWeek) != fmt.Sprint(tt.dow) {
+				t.Errorf("Expected days of week %v, got %v", tt.dow, cron.DaysOfWeek)
+			}
+		})
+	}
+}
+
+func TestCronMatchesDay(t *testing.T) {
+	// Restricted day of month and day of week combine with OR
+	cron, err := ParseCron("0 0 13 * FRI")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	friday := time.Date(2024, time.January, 5, 0, 0, 0, 0, time.UTC)
+	thirteenth := time.Date(2024, time.January, 13, 0, 0, 0, 0, time.UTC)
+	other := time.Date(2024, time.January, 8, 0, 0, 0, 0, time.UTC)
+	if !cron.MatchesDay(friday) || !cron.MatchesDay(thirteenth) || cron.MatchesDay(other) {
+		t.Errorf("Unexpected day matching for %s", cron.Expr)
+	}
+}
+
+func scheduledWorkflow(t *testing.T, crons ...string) *ActionFile {
+	doc := "on:\n  schedule:\n"
+	for _, c := range crons {
+		doc += fmt.Sprintf("    - cron: '%s'\n", c)
+	}
+	doc += "jobs:\n  run:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n"
+	return mustParse(t, doc)
+}
+
+func TestAnalyzeSchedules(t *testing.T) {
+	workflows := map[string]*ActionFile{
+		"nightly.yml":   scheduledWorkflow(t, "0 0 * * *"),
+		"cleanup.yml":   scheduledWorkflow(t, "0 0 * * 1"),
+		"poll.yml":      scheduledWorkflow(t, "3/5 * * * *"),
+		"monthly.yml":   scheduledWorkflow(t, "30 2 1 * *"),
+		"reports.yml":   scheduledWorkflow(t, "30 2 * * 6"),
+		"push-only.yml": mustParse(t, "on: push\njobs: {}\n"),
+	}
+
+	kinds := make(map[string][]ScheduleFinding)
+	for _, f := range AnalyzeSchedules(workflows) {
+		kinds[f.Kind] = append(kinds[f.Kind], f)
+	}
+
+	if len(kinds["congested"]) != 2 {
+		t.Errorf("Expected the two midnight schedules to be congested, got %v", kinds["congested"])
+	}
+	for _, f := range kinds["congested"] {
+		if f.Suggestion == "" || f.Suggestion[:2] == "0 " {
+			t.Errorf("Expected a jittered suggestion, got %q", f.Suggestion)
+		}
+	}
+
+	if len(kinds["frequency"]) != 1 || kinds["frequency"][0].Schedules[0].File != "poll.yml" {
+		t.Errorf("Expected poll.yml to be reported as frequent, got %v", kinds["frequency"])
+	}
+
+	// nightly and cleanup both fire on Monday midnight; monthly and reports
+	// meet on days where the 1st is a Saturday
+	if len(kinds["overlap"]) != 2 {
+		t.Fatalf("Expected 2 overlaps, got %v", kinds["overlap"])
+	}
+	first := kinds["overlap"][0].Schedules
+	if len(first) != 2 || first[0].File != "cleanup.yml" || first[1].File != "nightly.yml" {
+		t.Errorf("Unexpected overlap %v", first)
+	}
+}
+
+func TestAnalyzeSchedulesDisjoint(t *testing.T) {
+	workflows := map[string]*ActionFile{
+		"weekdays.yml": scheduledWorkflow(t, "17 3 * * 1-5"),
+		"weekend.yml":  scheduledWorkflow(t, "17 3 * * 0,6"),
+	}
+	if findings := AnalyzeSchedules(workflows); len(findings) != 0 {
+		t.Errorf("Expected no findings, got %v", findings)
+	}
+}
+
+func TestValidateSchedule(t *testing.T) {
+	errs := NewValidator().Validate(scheduledWorkflow(t, "0 0 * * *", "every day"))
+	if len(errs) != 1 || errs[0].Field != "on.schedule[1].cron" {
+		t.Errorf("Expected the invalid cron to be reported, got %v", errs)
+	}
+}
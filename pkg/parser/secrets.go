@@ -0,0 +1,175 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SecretUse is a reference to a secret in a workflow or action
+type SecretUse struct {
+	File string `json:"file"`
+	// Job is the job making the reference, empty outside of jobs
+	Job   string `json:"job,omitempty"`
+	Field string `json:"field"`
+	// ForwardedTo is the 'uses' value of the reusable workflow or action
+	// the secret is passed to through 'secrets', 'with' or the env of the
+	// step, empty if it is only used by the job itself
+	ForwardedTo string `json:"forwardedTo,omitempty"`
+	// ForwardedKind is the kind of ForwardedTo
+	ForwardedKind DependencyKind `json:"forwardedKind,omitempty"`
+	// ThirdParty is set when ForwardedTo is an action, reusable workflow or
+	// Docker image outside the repository and GitHub's own organizations
+	ThirdParty bool `json:"thirdParty,omitempty"`
+}
+
+// SecretUsage lists the references to one secret
+type SecretUsage struct {
+	Name string      `json:"name"`
+	Uses []SecretUse `json:"uses"`
+	// ForwardedToReusableWorkflow is set if any use passes the secret to a
+	// reusable workflow
+	ForwardedToReusableWorkflow bool `json:"forwardedToReusableWorkflow,omitempty"`
+	// ForwardedToThirdParty is set if any use is ThirdParty
+	ForwardedToThirdParty bool `json:"forwardedToThirdParty,omitempty"`
+}
+
+// SecretInventory lists the secrets used by a set of workflows and actions
+type SecretInventory struct {
+	// Secrets are sorted by name. Names are upper-cased, as GitHub treats
+	// them case-insensitively.
+	Secrets []SecretUsage `json:"secrets"`
+	// Inherit lists the jobs passing all secrets to a reusable workflow with
+	// 'secrets: inherit'
+	Inherit []SecretUse `json:"inherit,omitempty"`
+}
+
+// firstPartyOwners are the organizations of GitHub's own actions
+var firstPartyOwners = map[string]bool{"actions": true, "github": true}
+
+// jobFieldPattern splits a field path into the job ID, the step index and
+// the field within the job or step
+var jobFieldPattern = regexp.MustCompile(`^(?:jobs\.([^.\[]+)\.|runs\.)(?:steps\[(\d+)\]\.)?(.*)$`)
+
+// Secrets builds the secret inventory of a directory of workflows and
+// actions, as returned by ParseDir
+func Secrets(workflows map[string]*ActionFile) *SecretInventory {
+	byName := make(map[string]*SecretUsage)
+	inventory := &SecretInventory{Secrets: make([]SecretUsage, 0)}
+	for _, file := range sortedFiles(workflows) {
+		action := workflows[file]
+		var doc yaml.Node
+		if err := doc.Encode(action); err != nil {
+			// Decoded documents always encode
+			continue
+		}
+		walkScalars(&doc, "", func(field, value string) {
+			for _, ref := range ExtractContextReferences(value) {
+				if ref.Context != "secrets" || len(ref.Path) == 0 {
+					continue
+				}
+				name := strings.ToUpper(ref.Path[0])
+				usage, ok := byName[name]
+				if !ok {
+					usage = &SecretUsage{Name: name}
+					byName[name] = usage
+				}
+				use := secretUse(action, file, field)
+				usage.Uses = append(usage.Uses, use)
+				usage.ForwardedToReusableWorkflow = usage.ForwardedToReusableWorkflow || use.ForwardedKind == DependencyReusableWorkflow
+				usage.ForwardedToThirdParty = usage.ForwardedToThirdParty || use.ThirdParty
+			}
+		})
+		for _, jobID := range sortedJobIDs(action) {
+			job := action.Jobs[jobID]
+			if secrets, err := ParseJobSecrets(job); err == nil && secrets.Inherit {
+				field := fmt.Sprintf("jobs.%s.secrets", jobID)
+				inventory.Inherit = append(inventory.Inherit, secretUse(action, file, field))
+			}
+		}
+	}
+
+	for _, usage := range byName {
+		inventory.Secrets = append(inventory.Secrets, *usage)
+	}
+	sort.Slice(inventory.Secrets, func(i, j int) bool {
+		return inventory.Secrets[i].Name < inventory.Secrets[j].Name
+	})
+	return inventory
+}
+
+// secretUse describes a secret reference made by field of action
+func secretUse(action *ActionFile, file, field string) SecretUse {
+	use := SecretUse{File: file, Field: field}
+	m := jobFieldPattern.FindStringSubmatch(field)
+	if m == nil {
+		return use
+	}
+	use.Job = m[1]
+	rest := m[3]
+
+	var uses string
+	reusable := false
+	switch {
+	case m[2] != "":
+		steps := action.Runs.Steps
+		if use.Job != "" {
+			steps = action.Jobs[use.Job].Steps
+		}
+		i, err := strconv.Atoi(m[2])
+		if err == nil && i < len(steps) && (strings.HasPrefix(rest, "with.") || strings.HasPrefix(rest, "env.")) {
+			uses = steps[i].Uses
+		}
+	case use.Job != "" && (rest == "secrets" || strings.HasPrefix(rest, "secrets.") || strings.HasPrefix(rest, "with.")):
+		uses, reusable = action.Jobs[use.Job].Uses, true
+	}
+	if uses == "" {
+		return use
+	}
+
+	use.ForwardedTo = uses
+	switch {
+	case reusable:
+		use.ForwardedKind = DependencyReusableWorkflow
+	case strings.HasPrefix(uses, "./"):
+		use.ForwardedKind = DependencyLocal
+	case strings.HasPrefix(uses, "docker://"):
+		use.ForwardedKind = DependencyDocker
+	default:
+		use.ForwardedKind = DependencyAction
+	}
+	if !strings.HasPrefix(uses, "./") {
+		ref, ok := ParseActionRef(uses)
+		use.ThirdParty = !ok || !firstPartyOwners[strings.ToLower(ref.Owner)]
+	}
+	return use
+}
+
+// walkScalars calls fn with the field path and value of every scalar in a
+// YAML node tree, using the paths of collectPositions
+func walkScalars(node *yaml.Node, path string, fn func(field, value string)) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			walkScalars(child, path, fn)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			childPath := node.Content[i].Value
+			if path != "" {
+				childPath = path + "." + childPath
+			}
+			walkScalars(node.Content[i+1], childPath, fn)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			walkScalars(item, fmt.Sprintf("%s[%d]", path, i), fn)
+		}
+	case yaml.ScalarNode:
+		fn(path, node.Value)
+	}
+}
@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSecrets(t *testing.T) {
+	set := WorkflowSet{
+		"ci.yml": mustParse(t, `
+on: push
+env:
+  TOKEN: ${{ secrets.npm_token }}
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: npm publish
+        env:
+          NODE_AUTH_TOKEN: ${{ secrets.NPM_TOKEN }}
+      - uses: actions/upload-artifact@v4
+        with:
+          token: ${{ secrets.GITHUB_TOKEN }}
+      - uses: someone/deploy-action@v1
+        with:
+          key: ${{ secrets.DEPLOY_KEY }}
+  release:
+    uses: ./.github/workflows/release.yml
+    secrets:
+      key: ${{ secrets.DEPLOY_KEY }}
+  shared:
+    uses: org/shared/.github/workflows/notify.yml@main
+    secrets: inherit
+`),
+	}
+
+	inventory := Secrets(set)
+	want := []SecretUsage{
+		{
+			Name: "DEPLOY_KEY",
+			Uses: []SecretUse{
+				{File: "ci.yml", Job: "build", Field: "jobs.build.steps[2].with.key", ForwardedTo: "someone/deploy-action@v1", ForwardedKind: DependencyAction, ThirdParty: true},
+				{File: "ci.yml", Job: "release", Field: "jobs.release.secrets.key", ForwardedTo: "./.github/workflows/release.yml", ForwardedKind: DependencyReusableWorkflow},
+			},
+			ForwardedToReusableWorkflow: true,
+			ForwardedToThirdParty:       true,
+		},
+		{
+			Name: "GITHUB_TOKEN",
+			Uses: []SecretUse{
+				{File: "ci.yml", Job: "build", Field: "jobs.build.steps[1].with.token", ForwardedTo: "actions/upload-artifact@v4", ForwardedKind: DependencyAction},
+			},
+		},
+		{
+			Name: "NPM_TOKEN",
+			Uses: []SecretUse{
+				{File: "ci.yml", Job: "build", Field: "jobs.build.steps[0].env.NODE_AUTH_TOKEN"},
+				{File: "ci.yml", Field: "env.TOKEN"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(inventory.Secrets, want) {
+		t.Errorf("Secrets() = %+v, want %+v", inventory.Secrets, want)
+	}
+	wantInherit := []SecretUse{{
+		File: "ci.yml", Job: "shared", Field: "jobs.shared.secrets",
+		ForwardedTo: "org/shared/.github/workflows/notify.yml@main", ForwardedKind: DependencyReusableWorkflow, ThirdParty: true,
+	}}
+	if !reflect.DeepEqual(inventory.Inherit, wantInherit) {
+		t.Errorf("Secrets().Inherit = %+v, want %+v", inventory.Inherit, wantInherit)
+	}
+}
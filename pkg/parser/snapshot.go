@@ -0,0 +1,139 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// snapshotVersion is the format version of snapshot bundles
+const snapshotVersion = 1
+
+// Snapshot is a bundle of the actions and reusable workflows referenced by a
+// set of workflows, keyed by their 'uses' value. It implements Resolver, so
+// analyses can run offline against a previously captured bundle.
+type Snapshot struct {
+	Version    int                    `json:"version"`
+	CapturedAt time.Time              `json:"capturedAt"`
+	Entries    map[string]*ActionFile `json:"entries"`
+	// Missing lists references that could not be resolved when capturing
+	Missing []string `json:"missing,omitempty"`
+}
+
+// CaptureSnapshot resolves everything the workflows reference, including the
+// actions used by composite actions and reusable workflows in turn. Local
+// references are followed but not stored, since they are part of the
+// repository; references that fail with ErrNotFound are listed in Missing.
+func CaptureSnapshot(workflows map[string]*ActionFile, resolver Resolver) (*Snapshot, error) {
+	s := &Snapshot{
+		Version:    snapshotVersion,
+		CapturedAt: time.Now().UTC(),
+		Entries:    make(map[string]*ActionFile),
+	}
+	seen := make(map[string]bool)
+	var queue []string
+	enqueue := func(action *ActionFile) {
+		for _, uses := range collectUses(action) {
+			if !seen[uses] {
+				seen[uses] = true
+				queue = append(queue, uses)
+			}
+		}
+	}
+	for _, file := range sortedFiles(workflows) {
+		enqueue(workflows[file])
+	}
+
+	for len(queue) > 0 {
+		uses := queue[0]
+		queue = queue[1:]
+		action, err := resolver.Resolve(uses)
+		if errors.Is(err, ErrNotFound) {
+			s.Missing = append(s.Missing, uses)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", uses, err)
+		}
+		if !strings.HasPrefix(uses, "./") {
+			s.Entries[uses] = action
+		}
+		enqueue(action)
+	}
+	sort.Strings(s.Missing)
+	return s, nil
+}
+
+// Resolve implements Resolver
+func (s *Snapshot) Resolve(uses string) (*ActionFile, error) {
+	if action, ok := s.Entries[uses]; ok {
+		return action, nil
+	}
+	return nil, fmt.Errorf("%s is not in the snapshot: %w", uses, ErrNotFound)
+}
+
+// Write encodes the snapshot as JSON
+func (s *Snapshot) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(s); err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	return nil
+}
+
+// ReadSnapshot decodes a snapshot written by Snapshot.Write
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	var s Snapshot
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	if s.Version != snapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", s.Version)
+	}
+	if s.Entries == nil {
+		s.Entries = make(map[string]*ActionFile)
+	}
+	return &s, nil
+}
+
+// collectUses returns the actions and reusable workflows an action or
+// workflow references, sorted and without duplicates. Docker images are
+// skipped.
+func collectUses(action *ActionFile) []string {
+	set := make(map[string]bool)
+	add := func(uses string) {
+		if uses != "" && !strings.HasPrefix(uses, "docker://") {
+			set[uses] = true
+		}
+	}
+	for _, step := range action.Runs.Steps {
+		add(step.Uses)
+	}
+	for _, job := range action.Jobs {
+		add(job.Uses)
+		for _, step := range job.Steps {
+			add(step.Uses)
+		}
+	}
+
+	uses := make([]string, 0, len(set))
+	for u := range set {
+		uses = append(uses, u)
+	}
+	sort.Strings(uses)
+	return uses
+}
+
+func sortedFiles(workflows map[string]*ActionFile) []string {
+	files := make([]string, 0, len(workflows))
+	for file := range workflows {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files
+}
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCaptureSnapshot(t *testing.T) {
+	workflow := mustParse(t, `
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: ./.github/actions/setup
+      - uses: docker://alpine:3.19
+  deploy:
+    uses: octo/workflows/.github/workflows/deploy.yml@v1
+`)
+	resolver := mapResolver{
+		"./.github/actions/setup": mustParse(t, `
+runs:
+  using: composite
+  steps:
+    - uses: actions/setup-go@v5
+`),
+		"octo/workflows/.github/workflows/deploy.yml@v1": mustParse(t, `
+on: workflow_call
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: octo/private-action@v2
+`),
+		"actions/setup-go@v5": mustParse(t, "name: Setup Go\nruns:\n  using: node20\n  main: index.js\n"),
+		"actions/checkout@v4": mustParse(t, "name: Checkout\nruns:\n  using: node20\n  main: index.js\n"),
+	}
+
+	snapshot, err := CaptureSnapshot(map[string]*ActionFile{"ci.yml": workflow}, resolver)
+	if err != nil {
+		t.Fatalf("CaptureSnapshot failed: %v", err)
+	}
+	for _, uses := range []string{"octo/workflows/.github/workflows/deploy.yml@v1", "actions/setup-go@v5", "actions/checkout@v4"} {
+		if _, ok := snapshot.Entries[uses]; !ok {
+			t.Errorf("Expected %s in the snapshot", uses)
+		}
+	}
+	if _, ok := snapshot.Entries["./.github/actions/setup"]; ok {
+		t.Errorf("Local actions should not be stored")
+	}
+	if len(snapshot.Missing) != 1 || snapshot.Missing[0] != "octo/private-action@v2" {
+		t.Errorf("Expected octo/private-action@v2 to be missing, got %v", snapshot.Missing)
+	}
+
+	var buf bytes.Buffer
+	if err := snapshot.Write(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"capturedAt":`) {
+		t.Errorf("Expected the capture time under capturedAt, got:\n%s", buf.String())
+	}
+	loaded, err := ReadSnapshot(&buf)
+	if err != nil {
+		t.Fatalf("ReadSnapshot failed: %v", err)
+	}
+	action, err := loaded.Resolve("actions/checkout@v4")
+	if err != nil || action.Name != "Checkout" {
+		t.Errorf("Expected to resolve actions/checkout@v4 from the snapshot, got %v, %v", action, err)
+	}
+	if _, err := loaded.Resolve("actions/cache@v4"); err == nil {
+		t.Errorf("Expected error for reference outside the snapshot")
+	}
+}
@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// needsBuffer reports whether the options need the whole document in memory
+// before decoding: templates and includes rewrite it, positions are collected
+// from a second pass over it, and strict decoding and decoder hooks only
+// apply to the typed pass, which cannot keep the key order of matrices
+func (o *options) needsBuffer() bool {
+	return o.templateVars != nil || o.includes || o.positions || len(o.extensions) > 0 ||
+		o.strict || len(o.decoderHooks) > 0
+}
+
+// streamReader reads the document for decodeStream, failing once more than
+// limit bytes have been read if limit is positive. The YAML decoder only
+// keeps the message of read errors, so the error is kept for the caller.
+type streamReader struct {
+	r     io.Reader
+	limit int64
+	read  int64
+	err   error
+}
+
+// Read implements io.Reader
+func (s *streamReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	s.read += int64(n)
+	if s.limit > 0 && s.read > s.limit {
+		s.err = classify(ErrTooLarge, fmt.Errorf("file exceeds maximum size of %d bytes", s.limit))
+		return 0, s.err
+	}
+	if err != nil && err != io.EOF {
+		s.err = fmt.Errorf("failed to read data: %w", err)
+	}
+	return n, err
+}
+
+// streamedActionFile catches triggers stored under a boolean 'true' key,
+// which normalizeOnKey handles for buffered documents
+type streamedActionFile struct {
+	ActionFile `yaml:",inline"`
+	TrueKey    interface{} `yaml:"true,omitempty"`
+}
+
+// decodeStream decodes a document as it is read, without holding its bytes
+// in memory, enforcing the maximum file size and fixing its encoding on the
+// way
+func decodeStream(r io.Reader, o *options) (*ActionFile, error) {
+	source := &streamReader{r: r, limit: o.maxFileSize}
+	encoding := newEncodingReader(source)
+	defer encoding.release()
+
+	// The decoder builds the node tree of the document either way; keeping
+	// it lets the key order of matrices be read without a second pass
+	var node yaml.Node
+	if err := yaml.NewDecoder(encoding).Decode(&node); err != nil && err != io.EOF {
+		if source.err != nil {
+			return nil, source.err
+		}
+		return nil, classify(decodeErrorKind(err), fmt.Errorf("failed to unmarshal YAML: %w", err))
+	}
+	doc := new(streamedActionFile)
+	if node.Kind != 0 {
+		if err := node.Decode(doc); err != nil {
+			return nil, classify(decodeErrorKind(err), fmt.Errorf("failed to unmarshal YAML: %w", err))
+		}
+	}
+
+	action := &doc.ActionFile
+	if action.On == nil && doc.TrueKey != nil {
+		action.On = doc.TrueKey
+	}
+	if hasOrderedMatrix(action) {
+		action.matrixOrder = matrixKeyOrder(&node)
+	}
+	action.Diagnostics = encodingDiagnostics(encoding.bom, encoding.crlf)
+	return action, nil
+}
@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// chunkReader returns at most n bytes per Read, to exercise readers that
+// have to carry state between reads
+type chunkReader struct {
+	r io.Reader
+	n int
+}
+
+func (c chunkReader) Read(p []byte) (int, error) {
+	if len(p) > c.n {
+		p = p[:c.n]
+	}
+	return c.r.Read(p)
+}
+
+func TestParseStreamMaxFileSize(t *testing.T) {
+	doc := "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: " + strings.Repeat("x", 4096) + "\n"
+
+	for _, opts := range [][]Option{{WithMaxFileSize(1024)}, {WithMaxFileSize(1024), WithPositions()}} {
+		_, err := Parse(strings.NewReader(doc), opts...)
+		if !errors.Is(err, ErrTooLarge) {
+			t.Errorf("Expected ErrTooLarge, got %v", err)
+		}
+	}
+	if _, err := Parse(strings.NewReader(doc), WithMaxFileSize(int64(len(doc)))); err != nil {
+		t.Errorf("Expected document of exactly the maximum size to parse, got %v", err)
+	}
+}
+
+func TestEncodingReaderAcrossReads(t *testing.T) {
+	doc := "\xEF\xBB\xBFon: push\r\njobs:\r\n  build:\r\n    runs-on: ubuntu-latest\r\n"
+	reader := newEncodingReader(chunkReader{r: strings.NewReader(doc), n: 1})
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n"; string(data) != want {
+		t.Errorf("Expected %q, got %q", want, data)
+	}
+	if !reader.bom || !reader.crlf {
+		t.Errorf("Expected BOM and CRLF to be detected, got bom=%v crlf=%v", reader.bom, reader.crlf)
+	}
+}
+
+func TestParseStreamMatchesBuffered(t *testing.T) {
+	docs := []string{
+		"\xEF\xBB\xBFname: CI\r\non: push\r\njobs:\r\n  build:\r\n    runs-on: ubuntu-latest\r\n",
+		"true:\n  push:\n    branches: [main]\njobs:\n  build:\n    runs-on: ubuntu-latest\n",
+	}
+	for _, doc := range docs {
+		streamed, err := Parse(strings.NewReader(doc))
+		if err != nil {
+			t.Fatalf("Streaming parse failed: %v", err)
+		}
+		buffered, err := Parse(strings.NewReader(doc), WithPositions())
+		if err != nil {
+			t.Fatalf("Buffered parse failed: %v", err)
+		}
+		if !Equal(streamed, buffered) {
+			t.Errorf("Streaming and buffered parses differ: %v", Diff(streamed, buffered))
+		}
+		if len(streamed.Diagnostics) != len(buffered.Diagnostics) {
+			t.Errorf("Expected the same diagnostics, got %v and %v", streamed.Diagnostics, buffered.Diagnostics)
+		}
+		if streamed.On == nil {
+			t.Errorf("Expected triggers in %q", doc)
+		}
+	}
+}
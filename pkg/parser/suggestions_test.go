@@ -0,0 +1,58 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestRuleFixesCoverRules checks that every rule reported by the package
+// carries a suggestion
+func TestRuleFixesCoverRules(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pattern := regexp.MustCompile(`(?:addError|addIssue|newFinding)\("([a-z-]+)"`)
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range pattern.FindAllStringSubmatch(string(data), -1) {
+			if ruleFixes[m[1]].suggestion == "" {
+				t.Errorf("%s: rule %s has no suggestion", file, m[1])
+			}
+		}
+	}
+}
+
+func TestValidateSuggestions(t *testing.T) {
+	action := mustParse(t, `
+name: Broken
+on: push
+jobs:
+  build:
+    steps:
+      - run: echo hi
+`)
+	errs := NewValidator().Validate(action)
+	var found bool
+	for _, e := range errs {
+		if e.Rule != "job-runner" {
+			continue
+		}
+		found = true
+		if e.Suggestion == "" || !strings.Contains(e.Example, "runs-on:") {
+			t.Errorf("Expected suggestion and example, got %+v", e)
+		}
+	}
+	if !found {
+		t.Fatalf("Expected job-runner error, got %+v", errs)
+	}
+}
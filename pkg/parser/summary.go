@@ -0,0 +1,120 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MarkdownSummary renders findings and inventories as Markdown, suitable
+// for writing to $GITHUB_STEP_SUMMARY when running inside a workflow. The
+// findings section is always written; the other sections are left out when
+// empty.
+type MarkdownSummary struct {
+	// Title defaults to "GitHub Actions analysis"
+	Title        string
+	Findings     []LocatedFinding
+	Dependencies []Dependency
+	Permissions  *PermissionInventory
+}
+
+// Write writes the summary to w
+func (s *MarkdownSummary) Write(w io.Writer) error {
+	var b strings.Builder
+	title := s.Title
+	if title == "" {
+		title = "GitHub Actions analysis"
+	}
+	fmt.Fprintf(&b, "## %s\n\n", markdownText(title))
+
+	report := &Report{}
+	for _, f := range s.Findings {
+		report.Add(f.ValidationError)
+	}
+	fmt.Fprintf(&b, "### Findings\n\n**%s**\n\n", report)
+	if len(s.Findings) > 0 {
+		b.WriteString("| Severity | File | Line | Rule | Field | Message |\n|---|---|---|---|---|---|\n")
+		for _, f := range s.Findings {
+			line := ""
+			if f.Line > 0 {
+				line = fmt.Sprint(f.Line)
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n", f.Severity, markdownCode(f.File), line,
+				markdownCode(f.Rule), markdownCode(f.Field), markdownText(f.Message))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(s.Dependencies) > 0 {
+		b.WriteString("### Dependencies\n\n| Uses | Kind | Used in |\n|---|---|---|\n")
+		for _, dep := range s.Dependencies {
+			var files []string
+			for _, loc := range dep.Locations {
+				files = append(files, loc.File)
+			}
+			usedIn := make([]string, 0, len(files))
+			for _, file := range uniqueSorted(files) {
+				usedIn = append(usedIn, markdownCode(file))
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", markdownCode(dep.Uses), dep.Kind, strings.Join(usedIn, ", "))
+		}
+		b.WriteString("\n")
+	}
+
+	if s.Permissions != nil && len(s.Permissions.Workflows) > 0 {
+		b.WriteString("### Permissions\n\n")
+		if n := len(s.Permissions.UsingDefault); n > 0 {
+			files := make([]string, 0, n)
+			for _, file := range s.Permissions.UsingDefault {
+				files = append(files, markdownCode(file))
+			}
+			fmt.Fprintf(&b, "> [!WARNING]\n> Workflows relying on the default token permissions, which are write-all on older repositories: %s\n\n",
+				strings.Join(files, ", "))
+		}
+		b.WriteString("| Workflow | Job | Source | Permissions |\n|---|---|---|---|\n")
+		for _, workflow := range s.Permissions.Workflows {
+			for _, job := range workflow.Jobs {
+				fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", markdownCode(workflow.File), markdownCode(job.Job), job.Source, markdownText(permissionsText(job)))
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write summary: %w", err)
+	}
+	return nil
+}
+
+// permissionsText describes the permissions of a job in a table cell
+func permissionsText(job JobPermissions) string {
+	switch {
+	case job.Source == PermissionSourceDefault:
+		return "default"
+	case job.Permissions == nil:
+		return "invalid"
+	case job.Permissions.Shorthand != "":
+		return job.Permissions.Shorthand
+	case job.Permissions.IsEmpty():
+		return "none"
+	}
+	scopes := make([]string, 0, len(job.Permissions.Scopes))
+	for _, scope := range sortedKeys(job.Permissions.Scopes) {
+		scopes = append(scopes, scope+": "+job.Permissions.Scopes[scope])
+	}
+	return strings.Join(scopes, ", ")
+}
+
+// markdownText escapes text for a table cell
+func markdownText(s string) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "|", "\\|").Replace(s)
+}
+
+// markdownCode formats s as inline code in a table cell, or returns an
+// empty string
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + strings.ReplaceAll(markdownText(s), "`", "'") + "`"
+}
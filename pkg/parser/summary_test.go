@@ -0,0 +1,58 @@
+package parser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMarkdownSummary(t *testing.T) {
+	set := WorkflowSet{"ci.yml": mustParse(t, `
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+  deploy:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: read
+      id-token: write
+    steps:
+      - run: make deploy
+`)}
+	summary := &MarkdownSummary{
+		Findings: []LocatedFinding{
+			{File: "ci.yml", Line: 3, ValidationError: ValidationError{Field: "jobs.build", Message: "a | b", Severity: SeverityWarning, Rule: "rule"}},
+		},
+		Dependencies: Dependencies(set),
+		Permissions:  EffectivePermissions(set),
+	}
+	var buf bytes.Buffer
+	if err := summary.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"## GitHub Actions analysis\n",
+		"**0 errors, 1 warning, 0 infos**",
+		"| warning | `ci.yml` | 3 | `rule` | `jobs.build` | a \\| b |\n",
+		"| `actions/checkout@v4` | action | `ci.yml` |\n",
+		"> Workflows relying on the default token permissions, which are write-all on older repositories: `ci.yml`\n",
+		"| `ci.yml` | `build` | default | default |\n",
+		"| `ci.yml` | `deploy` | job | contents: read, id-token: write |\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected summary to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	if err := (&MarkdownSummary{Title: "Lint"}).Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "### Dependencies") || !strings.Contains(buf.String(), "## Lint") {
+		t.Errorf("Unexpected empty summary:\n%s", buf.String())
+	}
+}
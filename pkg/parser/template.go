@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// placeholderPattern matches a {{ name }} template placeholder. Matches
+// preceded by '$' are ${{ }} expressions and are skipped.
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_.-]*)\s*\}\}`)
+
+// Placeholder is a template placeholder found in a document
+type Placeholder struct {
+	Name   string `json:"name"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// TemplateError is returned when a document contains placeholders without a
+// value
+type TemplateError struct {
+	Unresolved []Placeholder
+}
+
+func (e *TemplateError) Error() string {
+	msgs := make([]string, len(e.Unresolved))
+	for i, p := range e.Unresolved {
+		msgs[i] = fmt.Sprintf("%s at %d:%d", p.Name, p.Line, p.Column)
+	}
+	return fmt.Sprintf("unresolved template placeholder(s): %s", strings.Join(msgs, ", "))
+}
+
+// FindPlaceholders lists the {{ name }} template placeholders of a document,
+// in order of appearance. ${{ }} expressions are not placeholders.
+func FindPlaceholders(data []byte) []Placeholder {
+	var placeholders []Placeholder
+	for _, m := range findPlaceholders(data) {
+		placeholders = append(placeholders, m.Placeholder)
+	}
+	return placeholders
+}
+
+// ExpandTemplate substitutes {{ name }} template placeholders with the given
+// values before the document is parsed, leaving ${{ }} expressions alone.
+// Placeholders without a value are left in place and returned.
+func ExpandTemplate(data []byte, values map[string]string) ([]byte, []Placeholder) {
+	var out []byte
+	var unresolved []Placeholder
+	last := 0
+	for _, m := range findPlaceholders(data) {
+		value, ok := values[m.Name]
+		if !ok {
+			unresolved = append(unresolved, m.Placeholder)
+			continue
+		}
+		out = append(out, data[last:m.start]...)
+		out = append(out, value...)
+		last = m.end
+	}
+	out = append(out, data[last:]...)
+	return out, unresolved
+}
+
+// placeholderMatch is a placeholder with its byte offsets
+type placeholderMatch struct {
+	Placeholder
+	start, end int
+}
+
+func findPlaceholders(data []byte) []placeholderMatch {
+	var matches []placeholderMatch
+	for _, loc := range placeholderPattern.FindAllSubmatchIndex(data, -1) {
+		if loc[0] > 0 && data[loc[0]-1] == '$' {
+			continue
+		}
+		line := 1 + strings.Count(string(data[:loc[0]]), "\n")
+		column := loc[0] - strings.LastIndexByte(string(data[:loc[0]]), '\n')
+		matches = append(matches, placeholderMatch{
+			Placeholder: Placeholder{Name: string(data[loc[2]:loc[3]]), Line: line, Column: column},
+			start:       loc[0],
+			end:         loc[1],
+		})
+	}
+	return matches
+}
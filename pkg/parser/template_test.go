@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const templateDoc = `name: {{ service }} CI
+on: push
+jobs:
+  build:
+    runs-on: {{runner}}
+    steps:
+      - run: echo ${{ github.sha }} {{ image.tag }}
+`
+
+func TestFindPlaceholders(t *testing.T) {
+	got := FindPlaceholders([]byte(templateDoc))
+	expected := []Placeholder{
+		{Name: "service", Line: 1, Column: 7},
+		{Name: "runner", Line: 5, Column: 14},
+		{Name: "image.tag", Line: 7, Column: 37},
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d placeholders, got %+v", len(expected), got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Expected %+v, got %+v", expected[i], got[i])
+		}
+	}
+}
+
+func TestExpandTemplate(t *testing.T) {
+	out, unresolved := ExpandTemplate([]byte(templateDoc), map[string]string{"service": "api", "runner": "ubuntu-latest"})
+	if len(unresolved) != 1 || unresolved[0].Name != "image.tag" {
+		t.Errorf("Expected image.tag to be unresolved, got %+v", unresolved)
+	}
+	if !strings.Contains(string(out), "name: api CI") || !strings.Contains(string(out), "runs-on: ubuntu-latest") {
+		t.Errorf("Placeholders not substituted:\n%s", out)
+	}
+	if !strings.Contains(string(out), "${{ github.sha }} {{ image.tag }}") {
+		t.Errorf("Expressions or unresolved placeholders changed:\n%s", out)
+	}
+}
+
+func TestParseWithTemplateVars(t *testing.T) {
+	values := map[string]string{"service": "api", "runner": "ubuntu-latest", "image.tag": "v1"}
+	action, err := Parse(strings.NewReader(templateDoc), WithTemplateVars(values))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if action.Name != "api CI" || action.Jobs["build"].RunsOn != "ubuntu-latest" {
+		t.Errorf("Unexpected result: name=%q runs-on=%v", action.Name, action.Jobs["build"].RunsOn)
+	}
+
+	delete(values, "runner")
+	_, err = Parse(strings.NewReader(templateDoc), WithTemplateVars(values))
+	var templateErr *TemplateError
+	if !errors.As(err, &templateErr) {
+		t.Fatalf("Expected TemplateError, got %v", err)
+	}
+	if len(templateErr.Unresolved) != 1 || templateErr.Unresolved[0].Name != "runner" {
+		t.Errorf("Expected runner to be unresolved, got %+v", templateErr.Unresolved)
+	}
+}
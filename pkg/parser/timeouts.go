@@ -0,0 +1,41 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultTimeoutMinutes is the timeout GitHub applies to jobs and steps
+// without timeout-minutes
+const defaultTimeoutMinutes = 360
+
+// lintTimeouts applies the timeout policy of the project configuration:
+// with RequireTimeouts it reports jobs without timeout-minutes, and with
+// TimeoutRunLines run steps of at least that many lines without their own.
+// Jobs calling reusable workflows cannot set a timeout and are skipped, as
+// are the steps of composite actions.
+func (l *Linter) lintTimeouts(action *ActionFile) {
+	c := l.opts.config
+	if c == nil || !c.RequireTimeouts && c.TimeoutRunLines <= 0 {
+		return
+	}
+	for _, jobID := range sortedJobIDs(action) {
+		job := action.Jobs[jobID]
+		if job.Uses != "" {
+			continue
+		}
+		if c.RequireTimeouts && job.TimeoutMin == 0 {
+			l.addIssue("missing-timeout", SeverityWarning, fmt.Sprintf("jobs.%s", jobID),
+				fmt.Sprintf("Job '%s' has no timeout-minutes and runs for up to %d minutes if it hangs", jobID, defaultTimeoutMinutes))
+		}
+		if c.TimeoutRunLines <= 0 {
+			continue
+		}
+		for i, step := range job.Steps {
+			if lines := strings.Count(strings.TrimRight(step.Run, "\n"), "\n") + 1; step.Run != "" && step.TimeoutMin == 0 && lines >= c.TimeoutRunLines {
+				l.addIssue("missing-timeout", SeverityWarning, fmt.Sprintf("jobs.%s.steps[%d]", jobID, i),
+					fmt.Sprintf("The %d-line run script has no timeout-minutes", lines))
+			}
+		}
+	}
+}
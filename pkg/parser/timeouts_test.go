@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLintTimeouts(t *testing.T) {
+	workflow := mustParse(t, `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: |
+          npm ci
+          npm run build
+          npm test
+      - run: npm run lint
+  deploy:
+    runs-on: ubuntu-latest
+    timeout-minutes: 30
+    steps:
+      - run: |
+          ./deploy.sh
+          ./smoke-test.sh
+          ./notify.sh
+      - timeout-minutes: 5
+        run: |
+          ./a.sh
+          ./b.sh
+          ./c.sh
+  call:
+    uses: ./.github/workflows/release.yml
+`)
+	lint := func(config *Config) []string {
+		var fields []string
+		for _, issue := range NewLinter(WithConfig(config)).Lint(workflow) {
+			if issue.Rule == "missing-timeout" {
+				fields = append(fields, issue.Field)
+			}
+		}
+		return fields
+	}
+
+	if fields := lint(nil); fields != nil {
+		t.Errorf("Expected no issues without a config, got %v", fields)
+	}
+	if fields, want := lint(&Config{RequireTimeouts: true}), []string{"jobs.build"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("Expected issues on %v, got %v", want, fields)
+	}
+	if fields, want := lint(&Config{TimeoutRunLines: 3}), []string{"jobs.build.steps[0]", "jobs.deploy.steps[0]"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("Expected issues on %v, got %v", want, fields)
+	}
+	if fields, want := lint(&Config{RequireTimeouts: true, TimeoutRunLines: 4}), []string{"jobs.build"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("Expected issues on %v, got %v", want, fields)
+	}
+}
@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"context"
+)
+
+// Tracer starts spans around the expensive operations of this package. It
+// mirrors the small subset of the OpenTelemetry trace API that is needed, so
+// an OpenTelemetry tracer can be plugged in with a thin adapter without this
+// module depending on OpenTelemetry:
+//
+//	type otelTracer struct{ t trace.Tracer }
+//
+//	func (o otelTracer) Start(ctx context.Context, name string) (context.Context, parser.Span) {
+//		ctx, span := o.t.Start(ctx, name)
+//		return ctx, otelSpan{span}
+//	}
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single traced operation started by a Tracer
+type Span interface {
+	// SetAttribute annotates the span with a key/value pair
+	SetAttribute(key string, value interface{})
+	// RecordError marks the span as failed
+	RecordError(err error)
+	// End completes the span
+	End()
+}
+
+// WithTracer reports spans for directory walks, file parsing, resolution
+// of actions and reusable workflows, and validation to t
+func WithTracer(t Tracer) Option {
+	return func(o *options) {
+		if t != nil {
+			o.tracer = t
+		}
+	}
+}
+
+// noopTracer is used when no Tracer has been configured
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(key string, value interface{}) {}
+func (noopSpan) RecordError(err error)                      {}
+func (noopSpan) End()                                       {}
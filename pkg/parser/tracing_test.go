@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// recordingTracer records the names of all spans started and ended
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordingSpan
+}
+
+type recordingSpan struct {
+	name       string
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	span := &recordingSpan{name: name, attributes: make(map[string]interface{})}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func (s *recordingSpan) SetAttribute(key string, value interface{}) { s.attributes[key] = value }
+func (s *recordingSpan) RecordError(err error)                      { s.err = err }
+func (s *recordingSpan) End()                                       { s.ended = true }
+
+func (t *recordingTracer) count(name string) int {
+	n := 0
+	for _, span := range t.spans {
+		if span.name == name {
+			n++
+		}
+	}
+	return n
+}
+
+func TestParseDirTracing(t *testing.T) {
+	tracer := &recordingTracer{}
+	result, err := ParseDir("testdata", WithTracer(tracer))
+	if err != nil {
+		t.Fatalf("Failed to parse directory: %v", err)
+	}
+
+	if tracer.count("parser.ParseDir") != 1 {
+		t.Errorf("Expected 1 ParseDir span, got %d", tracer.count("parser.ParseDir"))
+	}
+	if tracer.count("parser.ParseFile") != len(result) {
+		t.Errorf("Expected %d ParseFile spans, got %d", len(result), tracer.count("parser.ParseFile"))
+	}
+	for _, span := range tracer.spans {
+		if !span.ended {
+			t.Errorf("Expected span %s to be ended", span.name)
+		}
+	}
+	if tracer.spans[0].attributes["files"] != len(result) {
+		t.Errorf("Expected files attribute %d, got %v", len(result), tracer.spans[0].attributes["files"])
+	}
+}
+
+func TestValidateTracing(t *testing.T) {
+	tracer := &recordingTracer{}
+	NewValidator(WithTracer(tracer)).Validate(&ActionFile{Runs: RunsConfig{Using: "docker"}})
+
+	if tracer.count("parser.Validate") != 1 {
+		t.Fatalf("Expected 1 Validate span, got %d", tracer.count("parser.Validate"))
+	}
+	if tracer.spans[0].attributes["errors"] != 3 {
+		t.Errorf("Expected errors attribute 3, got %v", tracer.spans[0].attributes["errors"])
+	}
+
+	// A zero Validator must keep working without a tracer
+	var v Validator
+	v.Validate(&ActionFile{})
+}
+
+func TestResolveTracing(t *testing.T) {
+	workflow := mustParse(t, `
+on: push
+jobs:
+  deploy:
+    uses: octo/workflows/.github/workflows/deploy.yml@v1
+  release:
+    uses: octo/workflows/.github/workflows/release.yml@v1
+`)
+	resolver := mapResolver{
+		"octo/workflows/.github/workflows/deploy.yml@v1": mustParse(t, "on: workflow_call\njobs:\n  deploy:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make\n"),
+	}
+	tracer := &recordingTracer{}
+	NewValidator(WithResolver(resolver), WithTracer(tracer)).Validate(workflow)
+
+	resolved := make(map[interface{}]error)
+	for _, span := range tracer.spans {
+		if span.name == "parser.Resolve" {
+			if !span.ended {
+				t.Errorf("Expected span %s to be ended", span.name)
+			}
+			resolved[span.attributes["uses"]] = span.err
+		}
+	}
+	if err, ok := resolved["octo/workflows/.github/workflows/deploy.yml@v1"]; !ok || err != nil {
+		t.Errorf("Expected a successful span for deploy.yml, got %v", resolved)
+	}
+	if err := resolved["octo/workflows/.github/workflows/release.yml@v1"]; !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected a failed span for release.yml, got %v", resolved)
+	}
+}
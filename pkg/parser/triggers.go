@@ -0,0 +1,222 @@
+package parser
+
+import (
+	"fmt"
+)
+
+// WorkflowRunTrigger is the configuration of the 'workflow_run' event
+type WorkflowRunTrigger struct {
+	// Workflows lists the names of the workflows that trigger this one
+	Workflows      []string `yaml:"workflows,omitempty" json:"workflows,omitempty"`
+	Types          []string `yaml:"types,omitempty" json:"types,omitempty"`
+	Branches       []string `yaml:"branches,omitempty" json:"branches,omitempty"`
+	BranchesIgnore []string `yaml:"branches-ignore,omitempty" json:"branches-ignore,omitempty"`
+}
+
+// ParseWorkflowRunTrigger extracts the 'workflow_run' configuration of a
+// workflow. It returns nil if the workflow is not triggered by workflow_run.
+func ParseWorkflowRunTrigger(action *ActionFile) (*WorkflowRunTrigger, error) {
+	config, ok, err := triggerConfig(action, "workflow_run")
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	trigger := &WorkflowRunTrigger{}
+	fields := map[string]*[]string{
+		"workflows":       &trigger.Workflows,
+		"types":           &trigger.Types,
+		"branches":        &trigger.Branches,
+		"branches-ignore": &trigger.BranchesIgnore,
+	}
+	for key, dst := range fields {
+		if *dst, err = stringList(config[key]); err != nil {
+			return nil, fmt.Errorf("invalid workflow_run.%s: %w", key, err)
+		}
+	}
+	return trigger, nil
+}
+
+// PushTrigger is the configuration of the 'push' event
+type PushTrigger struct {
+	Branches       []string `yaml:"branches,omitempty" json:"branches,omitempty"`
+	BranchesIgnore []string `yaml:"branches-ignore,omitempty" json:"branches-ignore,omitempty"`
+	Tags           []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	TagsIgnore     []string `yaml:"tags-ignore,omitempty" json:"tags-ignore,omitempty"`
+	Paths          []string `yaml:"paths,omitempty" json:"paths,omitempty"`
+	PathsIgnore    []string `yaml:"paths-ignore,omitempty" json:"paths-ignore,omitempty"`
+}
+
+// ParsePushTrigger extracts the 'push' configuration of a workflow. It
+// returns nil if the workflow is not triggered by push.
+func ParsePushTrigger(action *ActionFile) (*PushTrigger, error) {
+	config, ok, err := triggerConfig(action, "push")
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	trigger := &PushTrigger{}
+	fields := map[string]*[]string{
+		"branches":        &trigger.Branches,
+		"branches-ignore": &trigger.BranchesIgnore,
+		"tags":            &trigger.Tags,
+		"tags-ignore":     &trigger.TagsIgnore,
+		"paths":           &trigger.Paths,
+		"paths-ignore":    &trigger.PathsIgnore,
+	}
+	for key, dst := range fields {
+		if *dst, err = stringList(config[key]); err != nil {
+			return nil, fmt.Errorf("invalid push.%s: %w", key, err)
+		}
+	}
+	return trigger, nil
+}
+
+// Conflicts returns the filter combinations GitHub rejects or never
+// applies: a filter combined with its '-ignore' counterpart, and path
+// filters on a workflow only triggered by tags, since paths are not
+// evaluated for tag pushes. Conflicts between paths and paths-ignore are
+// reported by the path filters.
+func (t *PushTrigger) Conflicts() []string {
+	var conflicts []string
+	if len(t.Branches) > 0 && len(t.BranchesIgnore) > 0 {
+		conflicts = append(conflicts, "Cannot use both 'branches' and 'branches-ignore'")
+	}
+	if len(t.Tags) > 0 && len(t.TagsIgnore) > 0 {
+		conflicts = append(conflicts, "Cannot use both 'tags' and 'tags-ignore'")
+	}
+	tagsOnly := (len(t.Tags) > 0 || len(t.TagsIgnore) > 0) && len(t.Branches) == 0 && len(t.BranchesIgnore) == 0
+	if tagsOnly && (len(t.Paths) > 0 || len(t.PathsIgnore) > 0) {
+		conflicts = append(conflicts, "Path filters are not evaluated for tag pushes, and the tag filters stop branch pushes from triggering the workflow")
+	}
+	return conflicts
+}
+
+// releaseTypes are the activity types of the 'release' event
+var releaseTypes = map[string]bool{
+	"published": true, "unpublished": true, "created": true, "edited": true,
+	"deleted": true, "prereleased": true, "released": true,
+}
+
+// ReleaseTrigger is the configuration of the 'release' event
+type ReleaseTrigger struct {
+	// Types are the activity types triggering the workflow; all types
+	// trigger it when empty
+	Types []string `yaml:"types,omitempty" json:"types,omitempty"`
+}
+
+// ParseReleaseTrigger extracts the 'release' configuration of a workflow.
+// It returns nil if the workflow is not triggered by release.
+func ParseReleaseTrigger(action *ActionFile) (*ReleaseTrigger, error) {
+	config, ok, err := triggerConfig(action, "release")
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	trigger := &ReleaseTrigger{}
+	if trigger.Types, err = stringList(config["types"]); err != nil {
+		return nil, fmt.Errorf("invalid release.types: %w", err)
+	}
+	return trigger, nil
+}
+
+// UnknownTypes returns the types GitHub never sends, in order. A workflow
+// listing only unknown types never runs.
+func (t *ReleaseTrigger) UnknownTypes() []string {
+	return unknownTypes(t.Types, releaseTypes)
+}
+
+// mergeGroupTypes are the activity types of the 'merge_group' event
+var mergeGroupTypes = map[string]bool{"checks_requested": true}
+
+// MergeGroupTrigger is the configuration of the 'merge_group' event, sent
+// when a pull request is added to a merge queue
+type MergeGroupTrigger struct {
+	// Types are the activity types triggering the workflow; all types
+	// trigger it when empty
+	Types []string `yaml:"types,omitempty" json:"types,omitempty"`
+}
+
+// ParseMergeGroupTrigger extracts the 'merge_group' configuration of a
+// workflow. It returns nil if the workflow is not triggered by merge_group.
+func ParseMergeGroupTrigger(action *ActionFile) (*MergeGroupTrigger, error) {
+	config, ok, err := triggerConfig(action, "merge_group")
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	trigger := &MergeGroupTrigger{}
+	if trigger.Types, err = stringList(config["types"]); err != nil {
+		return nil, fmt.Errorf("invalid merge_group.types: %w", err)
+	}
+	return trigger, nil
+}
+
+// UnknownTypes returns the types GitHub never sends, in order
+func (t *MergeGroupTrigger) UnknownTypes() []string {
+	return unknownTypes(t.Types, mergeGroupTypes)
+}
+
+// unknownTypes returns the types missing from the known activity types
+func unknownTypes(types []string, known map[string]bool) []string {
+	var unknown []string
+	for _, typ := range types {
+		if !known[typ] {
+			unknown = append(unknown, typ)
+		}
+	}
+	return unknown
+}
+
+// triggerConfig returns the configuration of an event in the 'on' section.
+// The boolean reports whether the event is present at all; events listed
+// without configuration yield an empty map.
+func triggerConfig(action *ActionFile, event string) (map[string]interface{}, bool, error) {
+	switch on := action.On.(type) {
+	case string:
+		return map[string]interface{}{}, on == event, nil
+	case []interface{}:
+		for _, e := range on {
+			if e == event {
+				return map[string]interface{}{}, true, nil
+			}
+		}
+	case map[string]interface{}:
+		raw, ok := on[event]
+		if !ok {
+			return nil, false, nil
+		}
+		config, err := MapOfStringInterface(raw)
+		if err != nil {
+			return nil, true, fmt.Errorf("invalid %s configuration: %w", event, err)
+		}
+		if config == nil {
+			config = map[string]interface{}{}
+		}
+		return config, true, nil
+	}
+	return nil, false, nil
+}
+
+// stringList converts a YAML string or sequence of strings into a slice
+func stringList(v interface{}) ([]string, error) {
+	switch value := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{value}, nil
+	case []string:
+		return value, nil
+	case []interface{}:
+		result := make([]string, 0, len(value))
+		for _, item := range value {
+			str, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected a string, got %T", item)
+			}
+			result = append(result, str)
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("expected a string or a list of strings, got %T", v)
+	}
+}
@@ -0,0 +1,255 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestParseWorkflowRunTrigger(t *testing.T) {
+	workflow := mustParse(t, `
+on:
+  workflow_run:
+    workflows: [CI, Release]
+    types: completed
+    branches: [main]
+jobs:
+  notify:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo done
+`)
+
+	trigger, err := ParseWorkflowRunTrigger(workflow)
+	if err != nil {
+		t.Fatalf("Failed to parse trigger: %v", err)
+	}
+	if trigger == nil {
+		t.Fatal("Expected a workflow_run trigger")
+	}
+	if len(trigger.Workflows) != 2 || trigger.Workflows[1] != "Release" {
+		t.Errorf("Unexpected workflows %v", trigger.Workflows)
+	}
+	if len(trigger.Types) != 1 || trigger.Types[0] != "completed" {
+		t.Errorf("Expected a single type to be accepted as a string, got %v", trigger.Types)
+	}
+	if len(trigger.Branches) != 1 || trigger.Branches[0] != "main" {
+		t.Errorf("Unexpected branches %v", trigger.Branches)
+	}
+
+	trigger, err = ParseWorkflowRunTrigger(mustParse(t, "on: push\njobs: {}\n"))
+	if err != nil || trigger != nil {
+		t.Errorf("Expected no trigger for push workflows, got %+v, %v", trigger, err)
+	}
+
+	if _, err := ParseWorkflowRunTrigger(mustParse(t, "on:\n  workflow_run:\n    workflows: [{a: b}]\n")); err == nil {
+		t.Error("Expected an error for non-string workflow names")
+	}
+}
+
+func TestValidateWorkflowRunTrigger(t *testing.T) {
+	workflow := mustParse(t, `
+on:
+  workflow_run:
+    types: [completed]
+    branches: [main]
+    branches-ignore: [dev]
+jobs:
+  notify:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo done
+`)
+
+	errs := NewValidator().Validate(workflow)
+	fields := make(map[string]bool)
+	for _, e := range errs {
+		fields[e.Field] = true
+	}
+	if !fields["on.workflow_run.workflows"] {
+		t.Errorf("Expected missing workflows to be reported, got %v", errs)
+	}
+	if !fields["on.workflow_run"] {
+		t.Errorf("Expected conflicting branch filters to be reported, got %v", errs)
+	}
+}
+
+func TestValidateDirWorkflowRunReferences(t *testing.T) {
+	workflows := map[string]*ActionFile{
+		"ci.yml": mustParse(t, `
+name: CI
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+`),
+		"lint.yml": mustParse(t, `
+on: push
+jobs:
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make lint
+`),
+		"deploy.yml": mustParse(t, `
+on:
+  workflow_run:
+    workflows: [CI, lint.yml, Release]
+    types: [completed]
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make deploy
+`),
+	}
+
+	v := NewValidator()
+	result := v.ValidateDir(workflows)
+	if len(result) != 1 {
+		t.Fatalf("Expected findings for deploy.yml only, got %v", result)
+	}
+	errs := result["deploy.yml"]
+	if len(errs) != 1 || errs[0].Field != "on.workflow_run.workflows[2]" {
+		t.Errorf("Expected the missing Release workflow to be reported, got %v", errs)
+	}
+	if v.IsValid() {
+		t.Error("Expected IsValid to reflect the directory findings")
+	}
+
+	v = NewValidator(WithConfig(&Config{Rules: map[string]string{"workflow-run-reference": SeverityOff}}))
+	if result := v.ValidateDir(workflows); len(result) != 0 {
+		t.Errorf("Expected the rule to be turned off by the config, got %v", result)
+	}
+	v = NewValidator(WithConfig(&Config{Rules: map[string]string{"workflow-run-reference": "warning"}}))
+	if errs := v.ValidateDir(workflows)["deploy.yml"]; len(errs) != 1 || errs[0].Severity != SeverityWarning {
+		t.Errorf("Expected the config to lower the severity, got %v", errs)
+	}
+}
+
+func TestParsePushTrigger(t *testing.T) {
+	trigger, err := ParsePushTrigger(mustParse(t, `
+on:
+  push:
+    branches: main
+    tags: ['v*']
+    tags-ignore: ['v*-rc*']
+    paths: ['src/**']
+jobs: {}
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse trigger: %v", err)
+	}
+	if len(trigger.Branches) != 1 || len(trigger.Tags) != 1 || trigger.TagsIgnore[0] != "v*-rc*" || trigger.Paths[0] != "src/**" {
+		t.Errorf("Unexpected trigger %+v", trigger)
+	}
+	if conflicts := trigger.Conflicts(); len(conflicts) != 1 || conflicts[0] != "Cannot use both 'tags' and 'tags-ignore'" {
+		t.Errorf("Expected the tag filters to conflict, got %v", conflicts)
+	}
+
+	trigger, err = ParsePushTrigger(mustParse(t, "on: [pull_request]\njobs: {}\n"))
+	if err != nil || trigger != nil {
+		t.Errorf("Expected no trigger for pull_request workflows, got %+v, %v", trigger, err)
+	}
+	if _, err := ParsePushTrigger(mustParse(t, "on:\n  push:\n    tags: {a: b}\n")); err == nil {
+		t.Error("Expected an error for a mapping of tags")
+	}
+}
+
+func TestValidatePushFilters(t *testing.T) {
+	tests := []struct {
+		name      string
+		push      string
+		conflicts int
+	}{
+		{"branches and tags", "branches: [main]\n    tags: ['v*']\n    paths: [src/**]", 0},
+		{"branches and branches-ignore", "branches: [main]\n    branches-ignore: [dev]", 1},
+		{"tags and paths", "tags: ['v*']\n    paths-ignore: [docs/**]", 1},
+		{"tags conflicts", "tags: ['v*']\n    tags-ignore: [v0*]\n    paths: [src/**]", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := mustParse(t, "on:\n  push:\n    "+tt.push+`
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+`)
+			var found int
+			for _, e := range NewValidator().Validate(workflow) {
+				if e.Rule == "push-filters" {
+					found++
+				}
+			}
+			if found != tt.conflicts {
+				t.Errorf("Expected %d push-filters findings, got %d", tt.conflicts, found)
+			}
+		})
+	}
+}
+
+func TestValidateReleaseTypes(t *testing.T) {
+	workflow := mustParse(t, `
+on:
+  release:
+    types: [published, publish]
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+`)
+	trigger, err := ParseReleaseTrigger(workflow)
+	if err != nil {
+		t.Fatalf("Failed to parse trigger: %v", err)
+	}
+	if unknown := trigger.UnknownTypes(); len(unknown) != 1 || unknown[0] != "publish" {
+		t.Errorf("Expected publish to be unknown, got %v", unknown)
+	}
+
+	var found []ValidationError
+	for _, e := range NewValidator().Validate(workflow) {
+		if e.Rule == "release-types" {
+			found = append(found, e)
+		}
+	}
+	if len(found) != 1 || found[0].Field != "on.release.types" {
+		t.Errorf("Expected the unknown type to be reported, got %v", found)
+	}
+
+	trigger, err = ParseReleaseTrigger(mustParse(t, "on: release\njobs: {}\n"))
+	if err != nil || trigger == nil || len(trigger.Types) != 0 {
+		t.Errorf("Expected a release trigger without types, got %+v, %v", trigger, err)
+	}
+}
+
+func TestValidateMergeGroupTrigger(t *testing.T) {
+	workflow := mustParse(t, `
+on:
+  merge_group:
+    types: [checks_requested, check_requested]
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+`)
+	trigger, err := ParseMergeGroupTrigger(workflow)
+	if err != nil || trigger == nil {
+		t.Fatalf("Failed to parse trigger: %+v, %v", trigger, err)
+	}
+	if len(trigger.Types) != 2 || trigger.Types[0] != "checks_requested" {
+		t.Errorf("Unexpected types %v", trigger.Types)
+	}
+
+	var found []ValidationError
+	for _, e := range NewValidator().Validate(workflow) {
+		if e.Rule == "activity-types" {
+			found = append(found, e)
+		}
+	}
+	if len(found) != 1 || found[0].Field != "on.merge_group.types" {
+		t.Errorf("Expected the unknown type to be reported, got %v", found)
+	}
+}
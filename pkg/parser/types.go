@@ -0,0 +1,148 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+)
+
+// Action is the metadata of an action, as found in action.yml. Unlike
+// ActionFile it has no workflow fields.
+type Action struct {
+	Name        string            `yaml:"name,omitempty" json:"name,omitempty"`
+	Description string            `yaml:"description,omitempty" json:"description,omitempty"`
+	Author      string            `yaml:"author,omitempty" json:"author,omitempty"`
+	Inputs      map[string]Input  `yaml:"inputs,omitempty" json:"inputs,omitempty"`
+	Outputs     map[string]Output `yaml:"outputs,omitempty" json:"outputs,omitempty"`
+	Runs        RunsConfig        `yaml:"runs,omitempty" json:"runs,omitempty"`
+	Branding    Branding          `yaml:"branding,omitempty" json:"branding,omitempty"`
+
+	// Type is the kind of action, one of the action FileTypes
+	Type FileType `yaml:"-" json:"-"`
+	// Positions, Extensions and Diagnostics are taken from the ActionFile
+	Positions   map[string]Position    `yaml:"-" json:"-"`
+	Extensions  map[string]interface{} `yaml:"-" json:"-"`
+	Diagnostics []ValidationError      `yaml:"-" json:"-"`
+}
+
+// Workflow is a workflow file. Unlike ActionFile it has no action metadata
+// fields.
+type Workflow struct {
+	Name        string                 `yaml:"name,omitempty" json:"name,omitempty"`
+	On          interface{}            `yaml:"on,omitempty" json:"on,omitempty"`
+	Jobs        map[string]Job         `yaml:"jobs,omitempty" json:"jobs,omitempty"`
+	Env         EnvMap                 `yaml:"env,omitempty" json:"env,omitempty"`
+	Defaults    map[string]interface{} `yaml:"defaults,omitempty" json:"defaults,omitempty"`
+	Permissions interface{}            `yaml:"permissions,omitempty" json:"permissions,omitempty"`
+	Concurrency interface{}            `yaml:"concurrency,omitempty" json:"concurrency,omitempty"`
+
+	// Reusable reports whether the workflow is triggered by workflow_call
+	Reusable bool `yaml:"-" json:"-"`
+	// Positions, Extensions, Diagnostics and Disabled are taken from the
+	// ActionFile
+	Positions   map[string]Position    `yaml:"-" json:"-"`
+	Extensions  map[string]interface{} `yaml:"-" json:"-"`
+	Diagnostics []ValidationError      `yaml:"-" json:"-"`
+	Disabled    bool                   `yaml:"-" json:"-"`
+}
+
+// ParseAction parses an action from an io.Reader like Parse, failing with
+// ErrNotAnAction if the document is not an action
+func ParseAction(r io.Reader, opts ...Option) (*Action, error) {
+	file, err := Parse(r, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return file.AsAction()
+}
+
+// ParseWorkflow parses a workflow from an io.Reader like Parse, failing with
+// ErrNotAWorkflow if the document is not a workflow
+func ParseWorkflow(r io.Reader, opts ...Option) (*Workflow, error) {
+	file, err := Parse(r, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return file.AsWorkflow()
+}
+
+// AsAction returns the action described by a, or ErrNotAnAction if
+// DetectType does not recognize a as an action. Maps and slices are shared
+// with a.
+func (a *ActionFile) AsAction() (*Action, error) {
+	t := DetectType(a)
+	if !t.IsAction() {
+		return nil, fmt.Errorf("%w: document is of type %s", ErrNotAnAction, t)
+	}
+	return &Action{
+		Name:        a.Name,
+		Description: a.Description,
+		Author:      a.Author,
+		Inputs:      a.Inputs,
+		Outputs:     a.Outputs,
+		Runs:        a.Runs,
+		Branding:    a.Branding,
+		Type:        t,
+		Positions:   a.Positions,
+		Extensions:  a.Extensions,
+		Diagnostics: a.Diagnostics,
+	}, nil
+}
+
+// AsWorkflow returns the workflow described by a, or ErrNotAWorkflow if
+// DetectType does not recognize a as a workflow. Maps and slices are shared
+// with a.
+func (a *ActionFile) AsWorkflow() (*Workflow, error) {
+	t := DetectType(a)
+	if !t.IsWorkflow() {
+		return nil, fmt.Errorf("%w: document is of type %s", ErrNotAWorkflow, t)
+	}
+	return &Workflow{
+		Name:        a.Name,
+		On:          a.On,
+		Jobs:        a.Jobs,
+		Env:         a.Env,
+		Defaults:    a.Defaults,
+		Permissions: a.Permissions,
+		Concurrency: a.Concurrency,
+		Reusable:    t == FileTypeReusableWorkflow,
+		Positions:   a.Positions,
+		Extensions:  a.Extensions,
+		Diagnostics: a.Diagnostics,
+		Disabled:    a.Disabled,
+	}, nil
+}
+
+// ActionFile returns the action in the raw form taken by the Validator,
+// the Linter and the other analyses. Maps and slices are shared with a.
+func (a *Action) ActionFile() *ActionFile {
+	return &ActionFile{
+		Name:        a.Name,
+		Description: a.Description,
+		Author:      a.Author,
+		Inputs:      a.Inputs,
+		Outputs:     a.Outputs,
+		Runs:        a.Runs,
+		Branding:    a.Branding,
+		Positions:   a.Positions,
+		Extensions:  a.Extensions,
+		Diagnostics: a.Diagnostics,
+	}
+}
+
+// ActionFile returns the workflow in the raw form taken by the Validator,
+// the Linter and the other analyses. Maps and slices are shared with w.
+func (w *Workflow) ActionFile() *ActionFile {
+	return &ActionFile{
+		Name:        w.Name,
+		On:          w.On,
+		Jobs:        w.Jobs,
+		Env:         w.Env,
+		Defaults:    w.Defaults,
+		Permissions: w.Permissions,
+		Concurrency: w.Concurrency,
+		Positions:   w.Positions,
+		Extensions:  w.Extensions,
+		Diagnostics: w.Diagnostics,
+		Disabled:    w.Disabled,
+	}
+}
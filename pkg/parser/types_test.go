@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAsAction(t *testing.T) {
+	file := mustParse(t, `
+name: Setup
+description: Sets things up
+inputs:
+  version:
+    description: Version
+runs:
+  using: node20
+  main: index.js
+`)
+	action, err := file.AsAction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if action.Type != FileTypeNodeAction || action.Runs.Main != "index.js" || action.Inputs["version"].Description != "Version" {
+		t.Errorf("AsAction() = %+v", action)
+	}
+	if back := action.ActionFile(); !reflect.DeepEqual(back, file) {
+		t.Errorf("ActionFile() = %+v, want %+v", back, file)
+	}
+	if _, err := file.AsWorkflow(); !errors.Is(err, ErrNotAWorkflow) {
+		t.Errorf("AsWorkflow() error = %v, want ErrNotAWorkflow", err)
+	}
+}
+
+func TestAsWorkflow(t *testing.T) {
+	file := mustParse(t, `
+name: CI
+on: workflow_call
+permissions: read-all
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+`)
+	workflow, err := file.AsWorkflow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !workflow.Reusable || workflow.Permissions != "read-all" || len(workflow.Jobs) != 1 {
+		t.Errorf("AsWorkflow() = %+v", workflow)
+	}
+	if back := workflow.ActionFile(); !reflect.DeepEqual(back, file) {
+		t.Errorf("ActionFile() = %+v, want %+v", back, file)
+	}
+	if _, err := file.AsAction(); !errors.Is(err, ErrNotAnAction) {
+		t.Errorf("AsAction() error = %v, want ErrNotAnAction", err)
+	}
+
+	// The workflow form has no action fields with confusing zero values
+	data, err := json.Marshal(workflow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), `"runs"`) || strings.Contains(string(data), `"branding"`) {
+		t.Errorf("json.Marshal(workflow) = %s, want no action fields", data)
+	}
+}
+
+func TestParseActionAndWorkflow(t *testing.T) {
+	const action = "name: a\ndescription: b\nruns:\n  using: docker\n  image: Dockerfile\n"
+	const workflow = "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n"
+
+	a, err := ParseAction(strings.NewReader(action))
+	if err != nil || a.Type != FileTypeDockerAction {
+		t.Errorf("ParseAction() = %+v, %v", a, err)
+	}
+	if _, err := ParseAction(strings.NewReader(workflow)); !errors.Is(err, ErrNotAnAction) || !strings.Contains(err.Error(), "workflow") {
+		t.Errorf("ParseAction(workflow) error = %v, want ErrNotAnAction naming the type", err)
+	}
+
+	w, err := ParseWorkflow(strings.NewReader(workflow))
+	if err != nil || w.Reusable || len(w.Jobs) != 1 {
+		t.Errorf("ParseWorkflow() = %+v, %v", w, err)
+	}
+	if _, err := ParseWorkflow(strings.NewReader(action)); !errors.Is(err, ErrNotAWorkflow) {
+		t.Errorf("ParseWorkflow(action) error = %v, want ErrNotAWorkflow", err)
+	}
+	if _, err := ParseWorkflow(strings.NewReader("jobs: [")); !errors.Is(err, ErrInvalidYAML) {
+		t.Errorf("ParseWorkflow(invalid) error = %v, want ErrInvalidYAML", err)
+	}
+}
@@ -0,0 +1,236 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// getInputPattern matches calls to the @actions/core input getters
+var getInputPattern = regexp.MustCompile("get(?:Boolean|Multiline)?Input\\(\\s*['\"`]([^'\"`]+)['\"`]")
+
+// inputEnvPattern matches the environment variables inputs are exposed as
+var inputEnvPattern = regexp.MustCompile(`\bINPUT_([A-Za-z0-9_-]+)`)
+
+// FindUnusedInputs reports inputs declared by a composite or JavaScript action
+// that are never read. dir is the directory containing action.yml and is used
+// to read the scripts of JavaScript actions; references are detected with
+// core.getInput heuristics, so dynamically computed names are missed. Other
+// kinds of actions, and JavaScript actions whose scripts are not available,
+// yield no findings.
+func FindUnusedInputs(action *ActionFile, dir string) ([]ValidationError, error) {
+	if len(action.Inputs) == 0 {
+		return nil, nil
+	}
+
+	used := make(map[string]bool)
+	switch action.Runs.Using {
+	case "composite":
+		collect := func(s string) string {
+			for _, ref := range ExtractContextReferences(s) {
+				if ref.Context == "inputs" {
+					used[strings.ToLower(ref.Path[0])] = true
+				}
+			}
+			return s
+		}
+		for _, step := range action.Runs.Steps {
+			mapStepStrings(step, collect)
+			collect(step.Uses)
+		}
+		for _, output := range action.Outputs {
+			collect(output.Value)
+		}
+	case "node16", "node20":
+		for _, script := range []string{action.Runs.Main, action.Runs.Pre, action.Runs.Post} {
+			if script == "" {
+				continue
+			}
+			data, err := readFile(dir, script)
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil, nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", script, err)
+			}
+			for _, m := range getInputPattern.FindAllStringSubmatch(string(data), -1) {
+				used[strings.ToLower(strings.ReplaceAll(m[1], " ", "_"))] = true
+			}
+			for _, m := range inputEnvPattern.FindAllStringSubmatch(string(data), -1) {
+				used[strings.ToLower(m[1])] = true
+			}
+		}
+	default:
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(action.Inputs))
+	for name := range action.Inputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var issues []ValidationError
+	for _, name := range names {
+		if used[strings.ToLower(strings.ReplaceAll(name, " ", "_"))] {
+			continue
+		}
+		issues = append(issues, newFinding("unused-input", SeverityWarning,
+			"inputs."+name, fmt.Sprintf("Input '%s' is declared but never used", name)))
+	}
+	return issues, nil
+}
+
+// FindDanglingOutputs reports outputs whose value references nothing, or
+// takes its value from a step or job that does not exist or does not define
+// the output. It checks composite action outputs, job outputs and the outputs
+// of reusable workflows.
+func FindDanglingOutputs(action *ActionFile) []ValidationError {
+	var issues []ValidationError
+	report := func(field, message string) {
+		issues = append(issues, newFinding("dangling-output", SeverityWarning, field, message))
+	}
+
+	// checkValue verifies that value reads from at least one of the given
+	// sources ("steps" or "jobs") and that every source it reads exists
+	checkValue := func(field, value, context string, exists func(ContextReference) string) {
+		if len(ExtractExpressions(value)) == 0 {
+			report(field, "Output value does not reference anything")
+			return
+		}
+		for _, ref := range ExtractContextReferences(value) {
+			if ref.Context != context {
+				continue
+			}
+			if problem := exists(ref); problem != "" {
+				report(field, problem)
+			}
+		}
+	}
+
+	stepExists := func(steps []Step) func(ContextReference) string {
+		ids := make(map[string]bool)
+		for _, step := range steps {
+			if step.ID != "" {
+				ids[step.ID] = true
+			}
+		}
+		return func(ref ContextReference) string {
+			if !ids[ref.Path[0]] {
+				return fmt.Sprintf("Output references step '%s', which does not exist", ref.Path[0])
+			}
+			return ""
+		}
+	}
+
+	if action.Runs.Using == "composite" {
+		exists := stepExists(action.Runs.Steps)
+		for _, name := range sortedOutputNames(action.Outputs) {
+			checkValue("outputs."+name, action.Outputs[name].Value, "steps", exists)
+		}
+	}
+
+	for _, jobID := range sortedJobIDs(action) {
+		job := action.Jobs[jobID]
+		exists := stepExists(job.Steps)
+		for _, name := range sortedKeys(job.Outputs) {
+			checkValue(fmt.Sprintf("jobs.%s.outputs.%s", jobID, name), job.Outputs[name], "steps", exists)
+		}
+	}
+
+	if outputs, err := ExtractOutputsFromWorkflowCall(action); err == nil {
+		exists := func(ref ContextReference) string {
+			job, ok := action.Jobs[ref.Path[0]]
+			if !ok {
+				return fmt.Sprintf("Output references job '%s', which does not exist", ref.Path[0])
+			}
+			if len(ref.Path) >= 3 && ref.Path[1] == "outputs" {
+				if _, ok := job.Outputs[ref.Path[2]]; !ok && job.Uses == "" {
+					return fmt.Sprintf("Output references output '%s' of job '%s', which does not define it", ref.Path[2], ref.Path[0])
+				}
+			}
+			return ""
+		}
+		for _, name := range sortedOutputNames(outputs) {
+			checkValue("on.workflow_call.outputs."+name, outputs[name].Value, "jobs", exists)
+		}
+	}
+
+	return issues
+}
+
+// FindUnconsumedOutputs reports reusable workflow outputs that no caller in
+// the directory reads. Workflows are keyed by path as returned by ParseDir;
+// callers are matched by the path suffix of their 'uses' value. Workflows
+// without callers in the directory are not reported, since they may be
+// called from other repositories.
+func FindUnconsumedOutputs(workflows map[string]*ActionFile) map[string][]ValidationError {
+	result := make(map[string][]ValidationError)
+	for file, workflow := range workflows {
+		outputs, err := ExtractOutputsFromWorkflowCall(workflow)
+		if err != nil || len(outputs) == 0 {
+			continue
+		}
+
+		called := false
+		consumed := make(map[string]bool)
+		for _, caller := range workflows {
+			for callID, job := range caller.Jobs {
+				uses := strings.SplitN(job.Uses, "@", 2)[0]
+				if !strings.HasPrefix(uses, "./") || !strings.HasSuffix(uses, "/"+toSlash(file)) {
+					continue
+				}
+				called = true
+				collectNeedsOutputs(caller, callID, consumed)
+			}
+		}
+		if !called {
+			continue
+		}
+
+		for _, name := range sortedOutputNames(outputs) {
+			if consumed[name] {
+				continue
+			}
+			result[file] = append(result[file], newFinding("unconsumed-output", SeverityWarning,
+				"on.workflow_call.outputs."+name, fmt.Sprintf("Output '%s' is not used by any caller", name)))
+		}
+	}
+	return result
+}
+
+// collectNeedsOutputs records the outputs of job callID read through
+// needs.<callID>.outputs by the other jobs of a workflow, or forwarded
+// through jobs.<callID>.outputs by its own workflow_call outputs
+func collectNeedsOutputs(workflow *ActionFile, callID string, consumed map[string]bool) {
+	collect := func(context string) func(string) string {
+		return func(s string) string {
+			for _, ref := range ExtractContextReferences(s) {
+				if ref.Context == context && len(ref.Path) >= 3 && ref.Path[0] == callID && ref.Path[1] == "outputs" {
+					consumed[ref.Path[2]] = true
+				}
+			}
+			return s
+		}
+	}
+	for _, job := range workflow.Jobs {
+		mapJobStrings(job, collect("needs"))
+	}
+	if outputs, err := ExtractOutputsFromWorkflowCall(workflow); err == nil {
+		for _, output := range outputs {
+			collect("jobs")(output.Value)
+		}
+	}
+}
+
+func sortedOutputNames(outputs map[string]Output) []string {
+	names := make([]string, 0, len(outputs))
+	for name := range outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,231 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindUnusedInputsComposite(t *testing.T) {
+	action := mustParse(t, `
+name: Build
+description: Builds the project
+inputs:
+  node-version:
+    description: Node.js version
+  command:
+    description: Build command
+  target:
+    description: Build target
+  legacy:
+    description: No longer read
+outputs:
+  target:
+    description: The build target
+    value: ${{ inputs.target }}
+runs:
+  using: composite
+  steps:
+    - uses: actions/setup-node@v4
+      with:
+        node-version: ${{ inputs.node-version }}
+    - run: ${{ inputs.command }}
+      shell: bash
+`)
+
+	issues, err := FindUnusedInputs(action, "")
+	if err != nil {
+		t.Fatalf("Failed to find unused inputs: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Field != "inputs.legacy" || issues[0].Rule != "unused-input" {
+		t.Errorf("Expected only 'legacy' to be reported, got %v", issues)
+	}
+}
+
+func TestFindUnusedInputsJavaScript(t *testing.T) {
+	dir := t.TempDir()
+	script := `
+const core = require('@actions/core');
+const token = core.getInput('github-token', { required: true });
+const dryRun = core.getBooleanInput("dry run");
+const legacy = process.env.INPUT_PATHS;
+`
+	if err := os.WriteFile(filepath.Join(dir, "index.js"), []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	action := &ActionFile{
+		Inputs: map[string]Input{
+			"github-token": {},
+			"dry run":      {},
+			"paths":        {},
+			"unused":       {},
+		},
+		Runs: RunsConfig{Using: "node20", Main: "index.js"},
+	}
+
+	issues, err := FindUnusedInputs(action, dir)
+	if err != nil {
+		t.Fatalf("Failed to find unused inputs: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Field != "inputs.unused" {
+		t.Errorf("Expected only 'unused' to be reported, got %v", issues)
+	}
+
+	// Without sources nothing can be said
+	issues, err = FindUnusedInputs(action, filepath.Join(dir, "missing"))
+	if err != nil || len(issues) != 0 {
+		t.Errorf("Expected no findings without sources, got %v, %v", issues, err)
+	}
+}
+
+func TestFindDanglingOutputs(t *testing.T) {
+	workflow := mustParse(t, `
+on:
+  workflow_call:
+    outputs:
+      version:
+        value: ${{ jobs.build.outputs.version }}
+      digest:
+        value: ${{ jobs.build.outputs.digest }}
+      report:
+        value: ${{ jobs.report.outputs.url }}
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    outputs:
+      version: ${{ steps.meta.outputs.version }}
+      sha: ${{ steps.checkout.outputs.sha }}
+      fixed: latest
+    steps:
+      - id: meta
+        run: echo "version=1.0" >> "$GITHUB_OUTPUT"
+`)
+
+	fields := make(map[string]bool)
+	for _, issue := range FindDanglingOutputs(workflow) {
+		if issue.Rule != "dangling-output" {
+			t.Errorf("Unexpected rule %s", issue.Rule)
+		}
+		fields[issue.Field] = true
+	}
+	want := []string{
+		"jobs.build.outputs.sha",
+		"jobs.build.outputs.fixed",
+		"on.workflow_call.outputs.digest",
+		"on.workflow_call.outputs.report",
+	}
+	if len(fields) != len(want) {
+		t.Errorf("Expected %v, got %v", want, fields)
+	}
+	for _, field := range want {
+		if !fields[field] {
+			t.Errorf("Expected %s to be reported, got %v", field, fields)
+		}
+	}
+}
+
+func TestFindDanglingOutputsComposite(t *testing.T) {
+	action := &ActionFile{
+		Outputs: map[string]Output{
+			"result": {Value: "${{ steps.main.outputs.result }}"},
+			"status": {Value: "${{ steps.missing.outputs.status }}"},
+		},
+		Runs: RunsConfig{Using: "composite", Steps: []Step{{ID: "main", Run: "echo", Shell: "bash"}}},
+	}
+	issues := FindDanglingOutputs(action)
+	if len(issues) != 1 || issues[0].Field != "outputs.status" {
+		t.Errorf("Expected only 'status' to be reported, got %v", issues)
+	}
+}
+
+func TestFindUnconsumedOutputs(t *testing.T) {
+	workflows := map[string]*ActionFile{
+		"build.yml": mustParse(t, `
+on:
+  workflow_call:
+    outputs:
+      version:
+        value: ${{ jobs.build.outputs.version }}
+      digest:
+        value: ${{ jobs.build.outputs.digest }}
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    outputs:
+      version: ${{ steps.meta.outputs.version }}
+      digest: ${{ steps.meta.outputs.digest }}
+    steps:
+      - id: meta
+        run: ./meta.sh
+`),
+		"release.yml": mustParse(t, `
+on: push
+jobs:
+  build:
+    uses: ./.github/workflows/build.yml
+  publish:
+    needs: build
+    runs-on: ubuntu-latest
+    steps:
+      - run: ./publish.sh ${{ needs.build.outputs.version }}
+`),
+		"library.yml": mustParse(t, `
+on:
+  workflow_call:
+    outputs:
+      unused:
+        value: ${{ jobs.x.outputs.y }}
+jobs:
+  x:
+    runs-on: ubuntu-latest
+    outputs:
+      y: ${{ steps.s.outputs.y }}
+    steps:
+      - id: s
+        run: echo
+`),
+	}
+
+	result := FindUnconsumedOutputs(workflows)
+	if len(result) != 1 {
+		t.Fatalf("Expected findings for build.yml only, got %v", result)
+	}
+	issues := result["build.yml"]
+	if len(issues) != 1 || issues[0].Field != "on.workflow_call.outputs.digest" || issues[0].Rule != "unconsumed-output" {
+		t.Errorf("Expected 'digest' to be reported, got %v", issues)
+	}
+}
+
+func TestFindUnconsumedOutputsForwarded(t *testing.T) {
+	workflows := map[string]*ActionFile{
+		"build.yml": mustParse(t, `
+on:
+  workflow_call:
+    outputs:
+      version:
+        value: ${{ jobs.build.outputs.version }}
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    outputs:
+      version: ${{ steps.meta.outputs.version }}
+    steps:
+      - id: meta
+        run: ./meta.sh
+`),
+		"pipeline.yml": mustParse(t, `
+on:
+  workflow_call:
+    outputs:
+      version:
+        value: ${{ jobs.build.outputs.version }}
+jobs:
+  build:
+    uses: ./.github/workflows/build.yml
+`),
+	}
+	if result := FindUnconsumedOutputs(workflows); len(result["build.yml"]) != 0 {
+		t.Errorf("Expected outputs forwarded by the caller to be consumed, got %v", result["build.yml"])
+	}
+}
@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// LocalUsage is a step or job using a local action or reusable workflow,
+// directly or through other local actions and reusable workflows
+type LocalUsage struct {
+	DependencyLocation
+	// Via lists the local actions and reusable workflows between File and
+	// the queried one, outermost first. It is empty for direct usage.
+	Via []string `json:"via,omitempty"`
+}
+
+// UsagesOf returns the steps and jobs of the set using the local action or
+// reusable workflow uses, such as "./.github/actions/setup-env", sorted by
+// file and field. Files that use it through a composite action or reusable
+// workflow of the set are included too. The set must be keyed by paths
+// relative to the repository root, i.e. come from ParseDir of the root.
+func (s WorkflowSet) UsagesOf(uses string) []LocalUsage {
+	// Local references of the set's files, e.g. "./.github/actions/setup-env"
+	// for .github/actions/setup-env/action.yml
+	keys := make(map[string]string, len(s))
+	for file := range s {
+		keys[file] = localFileKey(file)
+	}
+	usedBy := make(map[string][]DependencyLocation)
+	for _, dep := range Dependencies(s) {
+		if dep.Kind == DependencyLocal {
+			key := normalizeLocalUses(dep.Uses)
+			usedBy[key] = append(usedBy[key], dep.Locations...)
+		}
+	}
+
+	type pending struct {
+		key string
+		via []string
+	}
+	target := normalizeLocalUses(uses)
+	visited := map[string]bool{target: true}
+	queue := []pending{{key: target}}
+	var result []LocalUsage
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, loc := range usedBy[current.key] {
+			result = append(result, LocalUsage{DependencyLocation: loc, Via: current.via})
+			key := keys[loc.File]
+			if visited[key] {
+				continue
+			}
+			visited[key] = true
+			queue = append(queue, pending{key: key, via: append([]string{key}, current.via...)})
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].File != result[j].File {
+			return result[i].File < result[j].File
+		}
+		return result[i].Field < result[j].Field
+	})
+	return result
+}
+
+// localFileKey returns the local reference of the file at path: its
+// directory for action metadata files and the path itself otherwise
+func localFileKey(file string) string {
+	file = toSlash(file)
+	if base := path.Base(file); base == "action.yml" || base == "action.yaml" {
+		file = path.Dir(file)
+	}
+	return normalizeLocalUses(file)
+}
+
+// normalizeLocalUses cleans a local reference so that spellings such as
+// "./a/b/" and "a/b" compare equal
+func normalizeLocalUses(uses string) string {
+	return "./" + strings.TrimPrefix(path.Clean("/"+strings.TrimPrefix(uses, "./")), "/")
+}
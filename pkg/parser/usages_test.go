@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUsagesOf(t *testing.T) {
+	set := WorkflowSet{
+		".github/actions/setup-env/action.yml": mustParse(t, `
+name: Setup env
+runs:
+  using: composite
+  steps:
+    - run: echo setup
+      shell: bash
+`),
+		".github/actions/build/action.yaml": mustParse(t, `
+name: Build
+runs:
+  using: composite
+  steps:
+    - uses: ./.github/actions/setup-env/
+    - run: make
+      shell: bash
+`),
+		".github/workflows/reusable.yml": mustParse(t, `
+on: workflow_call
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: ./.github/actions/build
+`),
+		".github/workflows/ci.yml": mustParse(t, `
+on: push
+jobs:
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: ./.github/actions/setup-env
+  build:
+    uses: ./.github/workflows/reusable.yml
+`),
+	}
+
+	want := []LocalUsage{
+		{DependencyLocation: DependencyLocation{File: ".github/actions/build/action.yaml", Field: "runs.steps[0].uses"}},
+		{
+			DependencyLocation: DependencyLocation{File: ".github/workflows/ci.yml", Field: "jobs.build.uses"},
+			Via:                []string{"./.github/workflows/reusable.yml", "./.github/actions/build"},
+		},
+		{DependencyLocation: DependencyLocation{File: ".github/workflows/ci.yml", Field: "jobs.lint.steps[1].uses"}},
+		{
+			DependencyLocation: DependencyLocation{File: ".github/workflows/reusable.yml", Field: "jobs.build.steps[0].uses"},
+			Via:                []string{"./.github/actions/build"},
+		},
+	}
+	if got := set.UsagesOf("./.github/actions/setup-env"); !reflect.DeepEqual(got, want) {
+		t.Errorf("UsagesOf() = %+v, want %+v", got, want)
+	}
+	if got := set.UsagesOf("./.github/actions/unused"); got != nil {
+		t.Errorf("UsagesOf(unused) = %+v, want nil", got)
+	}
+}
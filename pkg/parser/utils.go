@@ -1,8 +1,12 @@
 package parser
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
 // StringOrStringSlice represents a field that can be either a string or a slice of strings
@@ -53,6 +57,60 @@ func (s *StringOrStringSlice) String() string {
 	return strings.Join(s.Values, ", ")
 }
 
+// EnvMap holds environment variables. Runners pass every value as a string,
+// so numbers, booleans and nulls, as in 'RETRIES: 3', are kept as written.
+type EnvMap map[string]string
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface
+func (e *EnvMap) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: env must be a mapping", node.Line)
+	}
+	env := make(EnvMap, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if value.Kind == yaml.AliasNode {
+			value = value.Alias
+		}
+		if value.Kind != yaml.ScalarNode {
+			return fmt.Errorf("line %d: env value for %q must be a string, number or boolean", value.Line, key.Value)
+		}
+		if value.Tag == "!!null" {
+			env[key.Value] = ""
+			continue
+		}
+		env[key.Value] = value.Value
+	}
+	*e = env
+	return nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (e *EnvMap) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	env := make(EnvMap, len(raw))
+	for key, value := range raw {
+		var str string
+		switch {
+		case bytes.Equal(value, []byte("null")):
+		case len(value) > 0 && value[0] == '"':
+			if err := json.Unmarshal(value, &str); err != nil {
+				return err
+			}
+		case len(value) > 0 && (value[0] == '{' || value[0] == '['):
+			return fmt.Errorf("env value for %q must be a string, number or boolean", key)
+		default:
+			str = string(value)
+		}
+		env[key] = str
+	}
+	*e = env
+	return nil
+}
+
 // MapOfStringInterface converts a YAML map to map[string]interface{}
 func MapOfStringInterface(v interface{}) (map[string]interface{}, error) {
 	switch value := v.(type) {
@@ -80,6 +138,8 @@ func MapOfStringString(v interface{}) (map[string]string, error) {
 	switch value := v.(type) {
 	case map[string]string:
 		return value, nil
+	case EnvMap:
+		return value, nil
 	case map[string]interface{}:
 		result := make(map[string]string)
 		for k, v := range value {
@@ -170,8 +230,14 @@ func ExtractInputsFromWorkflowCall(action *ActionFile) (map[string]Input, error)
 			if required, ok := inputDef["required"].(bool); ok {
 				input.Required = required
 			}
-			if defaultVal, ok := inputDef["default"].(string); ok {
+			switch defaultVal := inputDef["default"].(type) {
+			case string:
 				input.Default = defaultVal
+			case bool, int, float64:
+				input.Default = fmt.Sprint(defaultVal)
+			}
+			if typ, ok := inputDef["type"].(string); ok {
+				input.Type = typ
 			}
 
 			inputs[name] = input
@@ -227,3 +293,79 @@ func ExtractOutputsFromWorkflowCall(action *ActionFile) (map[string]Output, erro
 
 	return outputs, nil
 }
+
+// Secret defines a secret accepted by a reusable workflow
+type Secret struct {
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	Required    bool   `yaml:"required,omitempty" json:"required,omitempty"`
+}
+
+// ExtractSecretsFromWorkflowCall extracts secret definitions from a reusable workflow
+func ExtractSecretsFromWorkflowCall(action *ActionFile) (map[string]Secret, error) {
+	secrets := make(map[string]Secret)
+
+	switch on := action.On.(type) {
+	case map[string]interface{}:
+		workflowCall, ok := on["workflow_call"]
+		if !ok {
+			return nil, nil
+		}
+
+		workflowCallMap, err := MapOfStringInterface(workflowCall)
+		if err != nil {
+			return nil, err
+		}
+
+		secretsMap, err := MapOfStringInterface(workflowCallMap["secrets"])
+		if err != nil {
+			return nil, err
+		}
+
+		for name, def := range secretsMap {
+			secretDef, err := MapOfStringInterface(def)
+			if err != nil {
+				return nil, err
+			}
+
+			secret := Secret{}
+			if desc, ok := secretDef["description"].(string); ok {
+				secret.Description = desc
+			}
+			if required, ok := secretDef["required"].(bool); ok {
+				secret.Required = required
+			}
+
+			secrets[name] = secret
+		}
+	}
+
+	return secrets, nil
+}
+
+// JobSecrets is the 'secrets' value of a job calling a reusable workflow
+type JobSecrets struct {
+	// Inherit is set for 'secrets: inherit', which passes all secrets of the
+	// caller
+	Inherit bool
+	// Values holds explicitly passed secrets
+	Values map[string]string
+}
+
+// ParseJobSecrets interprets the 'secrets' value of a job
+func ParseJobSecrets(job Job) (JobSecrets, error) {
+	switch value := job.Secrets.(type) {
+	case nil:
+		return JobSecrets{}, nil
+	case string:
+		if value != "inherit" {
+			return JobSecrets{}, fmt.Errorf("secrets must be 'inherit' or a map, got %q", value)
+		}
+		return JobSecrets{Inherit: true}, nil
+	default:
+		values, err := MapOfStringString(value)
+		if err != nil {
+			return JobSecrets{}, fmt.Errorf("invalid secrets: %w", err)
+		}
+		return JobSecrets{Values: values}, nil
+	}
+}
@@ -2,28 +2,57 @@ package parser
 
 import (
 	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Severity indicates how serious a validation or lint finding is
+type Severity string
+
+const (
+	// SeverityError marks findings that GitHub would reject
+	SeverityError Severity = "error"
+	// SeverityWarning marks findings that work but are likely mistakes
+	SeverityWarning Severity = "warning"
+	// SeverityInfo marks purely informational findings
+	SeverityInfo Severity = "info"
 )
 
 // ValidationError represents an error found during validation
 type ValidationError struct {
-	Field   string
-	Message string
+	Field    string   `json:"field"`
+	Message  string   `json:"message"`
+	Severity Severity `json:"severity,omitempty"`
+	Rule     string   `json:"rule,omitempty"`
+	// Suggestion tells how to fix the problem
+	Suggestion string `json:"suggestion,omitempty"`
+	// Example is a YAML snippet showing a correct configuration
+	Example string `json:"example,omitempty"`
 }
 
 // Validator validates an ActionFile to ensure it meets GitHub's requirements
 type Validator struct {
 	errors []ValidationError
+	opts   *options
 }
 
 // NewValidator creates a new Validator
-func NewValidator() *Validator {
+func NewValidator(opts ...Option) *Validator {
 	return &Validator{
 		errors: make([]ValidationError, 0),
+		opts:   newOptions(opts),
 	}
 }
 
 // Validate checks if an ActionFile is valid according to GitHub's requirements
 func (v *Validator) Validate(action *ActionFile) []ValidationError {
+	if v.opts == nil {
+		v.opts = newOptions(nil)
+	}
+	_, span := v.opts.tracer.Start(v.opts.ctx, "parser.Validate")
+	defer span.End()
+
 	v.errors = make([]ValidationError, 0)
 
 	// Check action metadata for composite or Docker actions
@@ -35,7 +64,10 @@ func (v *Validator) Validate(action *ActionFile) []ValidationError {
 	if action.Jobs != nil {
 		v.validateWorkflow(action)
 	}
+	v.validateExpressions(action)
 
+	v.errors = v.opts.config.Apply(v.errors)
+	span.SetAttribute("errors", len(v.errors))
 	return v.errors
 }
 
@@ -43,33 +75,33 @@ func (v *Validator) Validate(action *ActionFile) []ValidationError {
 func (v *Validator) validateActionMetadata(action *ActionFile) {
 	// Name is required
 	if action.Name == "" {
-		v.addError("name", "Action name is required")
+		v.addError("action-name", "name", "Action name is required")
 	}
 
 	// Description is required
 	if action.Description == "" {
-		v.addError("description", "Action description is required")
+		v.addError("action-description", "description", "Action description is required")
 	}
 
 	// Validate runs configuration
 	if action.Runs.Using == "" {
-		v.addError("runs.using", "Action must specify 'using' field")
+		v.addError("action-runs-using", "runs.using", "Action must specify 'using' field")
 	} else {
 		switch action.Runs.Using {
 		case "node16", "node20":
 			if action.Runs.Main == "" {
-				v.addError("runs.main", "JavaScript actions require a 'main' entry point")
+				v.addError("javascript-main", "runs.main", "JavaScript actions require a 'main' entry point")
 			}
 		case "docker":
 			if action.Runs.Image == "" && action.Runs.Using == "docker" {
-				v.addError("runs.image", "Docker actions require an 'image' to use")
+				v.addError("docker-image", "runs.image", "Docker actions require an 'image' to use")
 			}
 		case "composite":
 			if len(action.Runs.Steps) == 0 {
-				v.addError("runs.steps", "Composite actions require at least one step")
+				v.addError("composite-steps", "runs.steps", "Composite actions require at least one step")
 			}
 		default:
-			v.addError("runs.using", fmt.Sprintf("Unsupported action type: %s", action.Runs.Using))
+			v.addError("action-runs-using", "runs.using", fmt.Sprintf("Unsupported action type: %s", action.Runs.Using))
 		}
 	}
 }
@@ -78,40 +110,250 @@ func (v *Validator) validateActionMetadata(action *ActionFile) {
 func (v *Validator) validateWorkflow(action *ActionFile) {
 	// On trigger is required
 	if action.On == nil {
-		v.addError("on", "Workflow must have at least one trigger")
+		v.addError("workflow-trigger", "on", "Workflow must have at least one trigger")
+	}
+
+	v.validateTriggers(action)
+	v.validateInputTypes(action)
+	if _, err := ParseConcurrency(action.Concurrency); err != nil {
+		v.addError("concurrency", "concurrency", err.Error())
+	}
+	if _, err := ParsePermissions(action.Permissions); err != nil {
+		v.addError("permissions", "permissions", err.Error())
 	}
 
 	// Validate jobs
 	if len(action.Jobs) == 0 {
-		v.addError("jobs", "Workflow must have at least one job")
+		v.addError("workflow-jobs", "jobs", "Workflow must have at least one job")
 	}
 
-	for jobID, job := range action.Jobs {
+	for _, jobID := range sortedJobIDs(action) {
+		job := action.Jobs[jobID]
 		// Either 'runs-on' or 'uses' is required for a job
 		if job.RunsOn == nil && job.Uses == "" {
-			v.addError(fmt.Sprintf("jobs.%s", jobID), "Job must specify either 'runs-on' or 'uses'")
+			v.addError("job-runner", fmt.Sprintf("jobs.%s", jobID), "Job must specify either 'runs-on' or 'uses'")
+		}
+		if runsOn, err := ParseRunsOn(job.RunsOn); err != nil {
+			v.addError("runs-on", fmt.Sprintf("jobs.%s.runs-on", jobID), err.Error())
+		} else if runsOn != nil {
+			for _, conflict := range runsOn.Conflicts() {
+				v.addError("runs-on", fmt.Sprintf("jobs.%s.runs-on", jobID), conflict)
+			}
 		}
 
 		// Validate steps if defined
 		if job.Steps != nil && len(job.Steps) == 0 {
-			v.addError(fmt.Sprintf("jobs.%s.steps", jobID), "Job must have at least one step if steps are defined")
+			v.addError("job-steps", fmt.Sprintf("jobs.%s.steps", jobID), "Job must have at least one step if steps are defined")
 		}
 
 		// Validate steps
 		for i, step := range job.Steps {
 			if step.Uses == "" && step.Run == "" {
-				v.addError(fmt.Sprintf("jobs.%s.steps[%d]", jobID, i), "Step must have either 'uses' or 'run'")
+				v.addError("step-uses-or-run", fmt.Sprintf("jobs.%s.steps[%d]", jobID, i), "Step must have either 'uses' or 'run'")
 			}
+			if strings.HasPrefix(step.Uses, "docker://") {
+				if _, err := ParseImageRef(step.Uses); err != nil {
+					v.addError("docker-reference", fmt.Sprintf("jobs.%s.steps[%d].uses", jobID, i), err.Error())
+				}
+			}
+		}
+
+		v.validateMatrix(jobID, job)
+
+		if _, err := ParseJobEnvironment(job); err != nil {
+			v.addError("job-environment", fmt.Sprintf("jobs.%s.environment", jobID), err.Error())
+		}
+		if _, err := ParseConcurrency(job.ConcurrencyKey); err != nil {
+			v.addError("concurrency", fmt.Sprintf("jobs.%s.concurrency", jobID), err.Error())
+		}
+		if _, err := ParsePermissions(job.Permissions); err != nil {
+			v.addError("permissions", fmt.Sprintf("jobs.%s.permissions", jobID), err.Error())
+		}
+
+		v.validateJobUses(jobID, job)
+
+		var called *ActionFile
+		if job.Uses != "" {
+			called = v.resolve(job.Uses)
+		}
+		v.validateJobSecrets(jobID, job, called)
+		if called != nil {
+			v.validateCallInputs(jobID, job, called)
 		}
+		v.validateNesting(jobID, job, called)
+	}
+}
+
+// resolve loads a reusable workflow through the configured resolver,
+// returning nil if there is no resolver or the workflow cannot be loaded
+func (v *Validator) resolve(uses string) *ActionFile {
+	if v.opts.resolver == nil {
+		return nil
+	}
+	called, err := v.opts.resolve(v.opts.resolver, uses)
+	if err != nil {
+		return nil
+	}
+	return called
+}
+
+// validateJobSecrets validates the secrets passed to a reusable workflow and,
+// when the called workflow could be resolved, checks them against the
+// secrets it declares
+func (v *Validator) validateJobSecrets(jobID string, job Job, called *ActionFile) {
+	field := fmt.Sprintf("jobs.%s.secrets", jobID)
+	secrets, err := ParseJobSecrets(job)
+	if err != nil {
+		v.addError("job-secrets", field, err.Error())
+		return
+	}
+	if job.Secrets == nil {
+		return
+	}
+	if job.Uses == "" {
+		v.addError("job-secrets", field, "Secrets can only be passed to jobs calling a reusable workflow")
+		return
+	}
+	if secrets.Inherit || called == nil {
+		return
+	}
+
+	declared, err := ExtractSecretsFromWorkflowCall(called)
+	if err != nil {
+		return
+	}
+	for _, name := range sortedSecretNames(declared) {
+		if _, ok := secrets.Values[name]; !ok && declared[name].Required {
+			v.addError("reusable-secrets", field, fmt.Sprintf("Required secret '%s' of %s is not passed", name, job.Uses))
+		}
+	}
+	for _, name := range sortedKeys(secrets.Values) {
+		if _, ok := declared[name]; !ok {
+			v.addError("reusable-secrets", fmt.Sprintf("%s.%s", field, name), fmt.Sprintf("Secret '%s' is not defined by %s", name, job.Uses))
+		}
+	}
+}
+
+func sortedSecretNames(secrets map[string]Secret) []string {
+	names := make([]string, 0, len(secrets))
+	for name := range secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateTriggers validates the configuration of typed triggers
+func (v *Validator) validateTriggers(action *ActionFile) {
+	workflowRun, err := ParseWorkflowRunTrigger(action)
+	if err != nil {
+		v.addError("workflow-run-trigger", "on.workflow_run", err.Error())
+	} else if workflowRun != nil {
+		if len(workflowRun.Workflows) == 0 {
+			v.addError("workflow-run-trigger", "on.workflow_run.workflows", "workflow_run must specify at least one workflow")
+		}
+		if len(workflowRun.Branches) > 0 && len(workflowRun.BranchesIgnore) > 0 {
+			v.addError("workflow-run-trigger", "on.workflow_run", "Cannot use both 'branches' and 'branches-ignore'")
+		}
+	}
+
+	push, err := ParsePushTrigger(action)
+	if err != nil {
+		v.addError("push-filters", "on.push", err.Error())
+	} else if push != nil {
+		for _, conflict := range push.Conflicts() {
+			v.addError("push-filters", "on.push", conflict)
+		}
+	}
+
+	release, err := ParseReleaseTrigger(action)
+	if err != nil {
+		v.addError("release-types", "on.release", err.Error())
+	} else if release != nil {
+		for _, typ := range release.UnknownTypes() {
+			v.addError("release-types", "on.release.types", fmt.Sprintf("Unknown release type '%s', expected one of %s", typ, strings.Join(sortedSet(releaseTypes), ", ")))
+		}
+	}
+
+	v.validateActivityTypes(action)
+
+	filters, err := ParsePathFilters(action)
+	if err != nil {
+		v.addError("path-filters", "on", err.Error())
+	}
+	for _, event := range pathFilterEvents {
+		filter, ok := filters[event]
+		if !ok {
+			continue
+		}
+		if len(filter.Paths) > 0 && len(filter.PathsIgnore) > 0 {
+			v.addError("path-filters", "on."+event, "Cannot use both 'paths' and 'paths-ignore'")
+		}
+		for _, pattern := range append(filter.Paths, filter.PathsIgnore...) {
+			if _, err := pathPatternRegexp(strings.TrimPrefix(pattern, "!")); err != nil {
+				v.addError("path-filters", "on."+event, fmt.Sprintf("Invalid path pattern: %v", err))
+			}
+		}
+	}
+
+	crons, err := ParseScheduleTrigger(action)
+	if err != nil {
+		v.addError("schedule-cron", "on.schedule", err.Error())
+	}
+	for i, cron := range crons {
+		if _, err := ParseCron(cron); err != nil {
+			v.addError("schedule-cron", fmt.Sprintf("on.schedule[%d].cron", i), fmt.Sprintf("Invalid cron expression: %v", err))
+		}
+	}
+}
+
+// ValidateDir validates the workflows of a directory, as returned by ParseDir,
+// and checks references between them. Only files with findings are included
+// in the result.
+func (v *Validator) ValidateDir(workflows map[string]*ActionFile) map[string][]ValidationError {
+	result := make(map[string][]ValidationError)
+
+	// A workflow without a name is displayed, and referenced, by its path
+	names := make(map[string]bool)
+	for file, workflow := range workflows {
+		if workflow.Name != "" {
+			names[workflow.Name] = true
+		} else {
+			names[file] = true
+			names[path.Base(file)] = true
+		}
+	}
+
+	for file, workflow := range workflows {
+		errs := v.Validate(workflow)
+
+		if workflowRun, err := ParseWorkflowRunTrigger(workflow); err == nil && workflowRun != nil {
+			for i, name := range workflowRun.Workflows {
+				if !names[name] {
+					errs = append(errs, newFinding("workflow-run-reference", SeverityError,
+						fmt.Sprintf("on.workflow_run.workflows[%d]", i),
+						fmt.Sprintf("Workflow '%s' does not exist in this directory", name)))
+				}
+			}
+		}
+
+		errs = v.opts.config.Apply(errs)
+		if len(errs) > 0 {
+			result[file] = errs
+		}
+	}
+
+	// Keep IsValid meaningful for the directory as a whole
+	v.errors = make([]ValidationError, 0)
+	for _, errs := range result {
+		v.errors = append(v.errors, errs...)
 	}
+	return result
 }
 
 // addError adds a validation error to the list
-func (v *Validator) addError(field, message string) {
-	v.errors = append(v.errors, ValidationError{
-		Field:   field,
-		Message: message,
-	})
+func (v *Validator) addError(rule, field, message string) {
+	v.errors = append(v.errors, newFinding(rule, SeverityError, field, message))
 }
 
 // IsValid returns true if there are no validation errors
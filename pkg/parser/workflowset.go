@@ -0,0 +1,249 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// WorkflowSet is a set of workflows and actions keyed by their path, as
+// returned by ParseDir. It is a plain map, so it can be ranged over and
+// passed to the directory-level analyses; Index answers queries over it.
+type WorkflowSet map[string]*ActionFile
+
+// Paths returns the paths of the set in sorted order
+func (s WorkflowSet) Paths() []string {
+	return sortedFiles(s)
+}
+
+// Index returns an index over the set. Each lookup table is built on the
+// first query that needs it, so keep the index to reuse them. The set must
+// not be modified while the index is in use.
+func (s WorkflowSet) Index() *WorkflowIndex {
+	return &WorkflowIndex{set: s}
+}
+
+// WorkflowIndex looks up the files of a WorkflowSet by trigger event,
+// action, runner label and secret. It is safe for concurrent use.
+type WorkflowIndex struct {
+	set     WorkflowSet
+	events  lazyIndex
+	actions lazyIndex
+	runners lazyIndex
+	secrets lazyIndex
+}
+
+// ByEvent returns the sorted paths of the workflows triggered by event,
+// e.g. "push"
+func (x *WorkflowIndex) ByEvent(event string) []string {
+	return x.events.get(x.set, workflowEvents)[event]
+}
+
+// ByAction returns the sorted paths of the files using an action, reusable
+// workflow or Docker image. A query without a ref, such as
+// "actions/checkout", matches every ref; "actions/checkout@v4" and
+// "docker://alpine:3" match exactly. Names are compared case-insensitively.
+func (x *WorkflowIndex) ByAction(uses string) []string {
+	index := x.actions.get(x.set, collectAllUses)
+	exact := strings.Contains(uses, "@") || strings.HasPrefix(uses, "docker://")
+	var files []string
+	for key, paths := range index {
+		name := key
+		if !exact && !strings.HasPrefix(key, "docker://") {
+			name = strings.SplitN(key, "@", 2)[0]
+		}
+		if strings.EqualFold(name, uses) {
+			files = append(files, paths...)
+		}
+	}
+	return uniqueSorted(files)
+}
+
+// ByRunner returns the sorted paths of the workflows with a job requesting
+// a runner label, e.g. "ubuntu-latest". Labels are compared
+// case-insensitively, and labels taken from the job's matrix are expanded.
+func (x *WorkflowIndex) ByRunner(label string) []string {
+	return x.runners.get(x.set, workflowRunnerLabels)[strings.ToLower(label)]
+}
+
+// BySecret returns the sorted paths of the files referencing a secret
+// through the secrets context. Names are compared case-insensitively.
+func (x *WorkflowIndex) BySecret(name string) []string {
+	return x.secrets.get(x.set, secretNames)[strings.ToUpper(name)]
+}
+
+// Events returns the trigger events used by the set in sorted order
+func (x *WorkflowIndex) Events() []string {
+	return x.events.get(x.set, workflowEvents).keys()
+}
+
+// Actions returns the 'uses' values of the set in sorted order
+func (x *WorkflowIndex) Actions() []string {
+	return x.actions.get(x.set, collectAllUses).keys()
+}
+
+// RunnerLabels returns the lower-cased runner labels of the set in sorted
+// order
+func (x *WorkflowIndex) RunnerLabels() []string {
+	return x.runners.get(x.set, workflowRunnerLabels).keys()
+}
+
+// Secrets returns the upper-cased names of the secrets referenced by the
+// set in sorted order
+func (x *WorkflowIndex) Secrets() []string {
+	return x.secrets.get(x.set, secretNames).keys()
+}
+
+// fileIndex maps a key to the sorted paths of the files having it
+type fileIndex map[string][]string
+
+func (f fileIndex) keys() []string {
+	keys := make([]string, 0, len(f))
+	for key := range f {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// lazyIndex is a fileIndex built on first use
+type lazyIndex struct {
+	once  sync.Once
+	index fileIndex
+}
+
+// get builds the index from the keys of each file if needed and returns it
+func (l *lazyIndex) get(set WorkflowSet, keysOf func(*ActionFile) []string) fileIndex {
+	l.once.Do(func() {
+		l.index = make(fileIndex)
+		for _, path := range sortedFiles(set) {
+			for _, key := range uniqueSorted(keysOf(set[path])) {
+				l.index[key] = append(l.index[key], path)
+			}
+		}
+	})
+	return l.index
+}
+
+// workflowEvents returns the events triggering a workflow
+func workflowEvents(action *ActionFile) []string {
+	events, ok := eventMap(action.On).(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	keys := make([]string, 0, len(events))
+	for event := range events {
+		keys = append(keys, event)
+	}
+	return keys
+}
+
+// collectAllUses returns the 'uses' values of an action or workflow,
+// including Docker images
+func collectAllUses(action *ActionFile) []string {
+	var uses []string
+	for _, step := range action.Runs.Steps {
+		uses = append(uses, step.Uses)
+	}
+	for _, job := range action.Jobs {
+		uses = append(uses, job.Uses)
+		for _, step := range job.Steps {
+			uses = append(uses, step.Uses)
+		}
+	}
+	result := uses[:0]
+	for _, u := range uses {
+		if u != "" {
+			result = append(result, u)
+		}
+	}
+	return result
+}
+
+// matrixLabelPattern matches a runner label taken from the job's matrix
+var matrixLabelPattern = regexp.MustCompile(`^\$\{\{\s*matrix\.([A-Za-z_][\w-]*)\s*\}\}$`)
+
+// workflowRunnerLabels returns the lower-cased runner labels requested by
+// the jobs of a workflow
+func workflowRunnerLabels(action *ActionFile) []string {
+	var labels []string
+	for _, job := range action.Jobs {
+		labels = append(labels, jobRunnerLabels(job)...)
+	}
+	return labels
+}
+
+// jobRunnerLabels returns the lower-cased runner labels requested by a job,
+// expanding labels taken from its matrix
+func jobRunnerLabels(job Job) []string {
+	var labels []string
+	combinations, _ := MatrixCombinations(job.Strategy)
+	for _, label := range runnerLabels(job.RunsOn) {
+		m := matrixLabelPattern.FindStringSubmatch(label)
+		if m == nil || len(combinations) == 0 {
+			labels = append(labels, strings.ToLower(label))
+			continue
+		}
+		for _, combination := range combinations {
+			for _, value := range runnerLabels(combination[m[1]]) {
+				labels = append(labels, strings.ToLower(value))
+			}
+		}
+	}
+	return labels
+}
+
+// runnerLabels returns the labels of a 'runs-on' value: a label, a list of
+// labels, or a mapping with 'labels'
+func runnerLabels(v interface{}) []string {
+	switch value := v.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		labels, _ := stringList(value["labels"])
+		return labels
+	case []interface{}:
+		labels := make([]string, 0, len(value))
+		for _, label := range value {
+			labels = append(labels, fmt.Sprint(label))
+		}
+		return labels
+	}
+	return []string{fmt.Sprint(v)}
+}
+
+// secretNames returns the upper-cased names of the secrets an action or
+// workflow references through the secrets context
+func secretNames(action *ActionFile) []string {
+	var names []string
+	collect := func(s string) string {
+		for _, ref := range ExtractContextReferences(s) {
+			if ref.Context == "secrets" && len(ref.Path) > 0 {
+				names = append(names, strings.ToUpper(ref.Path[0]))
+			}
+		}
+		return s
+	}
+	mapStrings(action.Env, collect)
+	for _, step := range action.Runs.Steps {
+		mapStepStrings(step, collect)
+	}
+	for _, job := range action.Jobs {
+		mapJobStrings(job, collect)
+	}
+	return names
+}
+
+// uniqueSorted sorts values and removes duplicates in place
+func uniqueSorted(values []string) []string {
+	sort.Strings(values)
+	result := values[:0]
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWorkflowIndex(t *testing.T) {
+	set := WorkflowSet{
+		"ci.yml": mustParse(t, `
+on: [push, pull_request]
+jobs:
+  test:
+    runs-on: ${{ matrix.os }}
+    strategy:
+      matrix:
+        os: [ubuntu-latest, windows-latest]
+    steps:
+      - uses: actions/checkout@v4
+      - run: make test
+        env:
+          TOKEN: ${{ secrets.npm_token }}
+`),
+		"release.yml": mustParse(t, `
+on:
+  release:
+    types: [published]
+jobs:
+  publish:
+    runs-on: [self-hosted, Linux]
+    steps:
+      - uses: actions/checkout@v3
+      - uses: docker://alpine:3
+        with:
+          args: ${{ secrets.NPM_TOKEN }} ${{ secrets.DEPLOY_KEY }}
+  notify:
+    uses: org/shared/.github/workflows/notify.yml@main
+    secrets:
+      webhook: ${{ secrets.SLACK_WEBHOOK }}
+`),
+	}
+	index := set.Index()
+
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{"ByEvent push", index.ByEvent("push"), []string{"ci.yml"}},
+		{"ByEvent release", index.ByEvent("release"), []string{"release.yml"}},
+		{"ByEvent unknown", index.ByEvent("schedule"), nil},
+		{"ByAction without ref", index.ByAction("Actions/Checkout"), []string{"ci.yml", "release.yml"}},
+		{"ByAction with ref", index.ByAction("actions/checkout@v3"), []string{"release.yml"}},
+		{"ByAction docker", index.ByAction("docker://alpine:3"), []string{"release.yml"}},
+		{"ByAction reusable workflow", index.ByAction("org/shared/.github/workflows/notify.yml"), []string{"release.yml"}},
+		{"ByRunner matrix", index.ByRunner("windows-latest"), []string{"ci.yml"}},
+		{"ByRunner list", index.ByRunner("linux"), []string{"release.yml"}},
+		{"BySecret", index.BySecret("NPM_TOKEN"), []string{"ci.yml", "release.yml"}},
+		{"BySecret job secrets", index.BySecret("slack_webhook"), []string{"release.yml"}},
+		{"Events", index.Events(), []string{"pull_request", "push", "release"}},
+		{"RunnerLabels", index.RunnerLabels(), []string{"linux", "self-hosted", "ubuntu-latest", "windows-latest"}},
+		{"Secrets", index.Secrets(), []string{"DEPLOY_KEY", "NPM_TOKEN", "SLACK_WEBHOOK"}},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestParseDirReturnsWorkflowSet(t *testing.T) {
+	dir := t.TempDir()
+	workflow := "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n"
+	for _, name := range []string{"b.yml", "a.yml"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(workflow), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	set, err := ParseDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := set.Paths(), []string{"a.yml", "b.yml"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Paths() = %v, want %v", got, want)
+	}
+	if got, want := set.Index().ByRunner("ubuntu-latest"), []string{"a.yml", "b.yml"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ByRunner() = %v, want %v", got, want)
+	}
+}
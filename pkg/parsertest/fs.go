@@ -0,0 +1,54 @@
+//go:build !js
+
+package parsertest
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/scagogogo/github-action-parser/pkg/parser"
+)
+
+// ParseFile parses the file at path, failing the test if it cannot be
+// parsed
+func ParseFile(t testing.TB, path string, opts ...parser.Option) *parser.ActionFile {
+	t.Helper()
+	action, err := parser.ParseFile(path, opts...)
+	if err != nil {
+		t.Fatalf("failed to parse %s: %v", path, err)
+	}
+	return action
+}
+
+// AssertGoldenFile parses the file at path and compares it with the golden
+// file
+func AssertGoldenFile(t testing.TB, path, golden string, opts ...parser.Option) {
+	t.Helper()
+	AssertGolden(t, ParseFile(t, path, opts...), golden)
+}
+
+// AssertGoldenDir parses the workflows and actions of dir with ParseDir and
+// compares each with the golden file at the same relative path in
+// goldenDir, with a .json extension, in a subtest named after the path.
+// Files that fail to parse with WithContinueOnError are reported as errors
+// without stopping the other comparisons.
+func AssertGoldenDir(t *testing.T, dir, goldenDir string, opts ...parser.Option) {
+	t.Helper()
+	files, err := parser.ParseDir(dir, opts...)
+	var dirErr *parser.DirError
+	switch {
+	case errors.As(err, &dirErr):
+		for _, fileErr := range dirErr.Errors {
+			t.Errorf("failed to parse %v", fileErr)
+		}
+	case err != nil:
+		t.Fatalf("failed to parse %s: %v", dir, err)
+	}
+	for _, path := range files.Paths() {
+		action := files[path]
+		t.Run(filepath.ToSlash(path), func(t *testing.T) {
+			AssertGolden(t, action, filepath.Join(goldenDir, path+".json"))
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Package parsertest helps tools built on the parser write regression tests
+// over real workflows: parsed files are compared with golden JSON snapshots
+// checked in next to the tests.
+//
+// Run the tests with PARSERTEST_UPDATE=1 to write the snapshots instead,
+// after checking that the changes to the parsed files are intended.
+package parsertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scagogogo/github-action-parser/pkg/parser"
+)
+
+// UpdateEnv is the environment variable which, set to 1 or true, makes the
+// assertions write golden files instead of comparing with them
+const UpdateEnv = "PARSERTEST_UPDATE"
+
+// Updating reports whether golden files are written instead of compared
+func Updating() bool {
+	switch strings.ToLower(os.Getenv(UpdateEnv)) {
+	case "1", "true":
+		return true
+	}
+	return false
+}
+
+// Snapshot returns the golden JSON form of a parsed file. Maps are written
+// with sorted keys, so snapshots are reproducible; positions and
+// diagnostics are not included.
+func Snapshot(action *parser.ActionFile) ([]byte, error) {
+	data, err := json.MarshalIndent(action, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// AssertGolden compares the snapshot of action with the golden file, or
+// writes the golden file when updating
+func AssertGolden(t testing.TB, action *parser.ActionFile, golden string) {
+	t.Helper()
+	got, err := Snapshot(action)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Updating() {
+		if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+			t.Fatalf("failed to create golden directory: %v", err)
+		}
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatalf("failed to write golden file: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("golden file %s does not exist; run the test with %s=1 to create it", golden, UpdateEnv)
+	}
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if diff := firstDifference(want, got); diff != "" {
+		t.Errorf("snapshot differs from %s (run with %s=1 to update):\n%s", golden, UpdateEnv, diff)
+	}
+}
+
+// firstDifference describes the first line at which got differs from want,
+// or returns "" if they are equal
+func firstDifference(want, got []byte) string {
+	if bytes.Equal(want, got) {
+		return ""
+	}
+	wantLines := strings.Split(string(want), "\n")
+	gotLines := strings.Split(string(got), "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return fmt.Sprintf("line %d:\n- %s\n+ %s", i+1, w, g)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,71 @@
+package parsertest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scagogogo/github-action-parser/pkg/parser"
+)
+
+// recorder records the failures reported to it
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertGoldenDir(t *testing.T) {
+	AssertGoldenDir(t, "testdata/workflows", "testdata/golden")
+}
+
+func TestAssertGoldenUpdate(t *testing.T) {
+	golden := filepath.Join(t.TempDir(), "nested", "ci.json")
+	action := ParseFile(t, "testdata/workflows/ci.yml")
+
+	t.Setenv(UpdateEnv, "1")
+	AssertGolden(t, action, golden)
+	data, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("Expected the golden file to be written: %v", err)
+	}
+	if want, _ := Snapshot(action); string(data) != string(want) {
+		t.Errorf("Unexpected golden file %s", data)
+	}
+
+	t.Setenv(UpdateEnv, "")
+	AssertGoldenFile(t, "testdata/workflows/ci.yml", golden)
+
+	action.Name = "Renamed"
+	r := &recorder{TB: t}
+	AssertGolden(r, action, golden)
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], `+   "name": "Renamed"`) {
+		t.Errorf("Expected the changed line to be reported, got %q", r.errors)
+	}
+}
+
+func TestSnapshotReproducible(t *testing.T) {
+	action, err := parser.Parse(strings.NewReader("on: push\nenv:\n  B: b\n  A: a\njobs:\n  z:\n    runs-on: x\n  a:\n    runs-on: y\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := Snapshot(action)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if data, _ := Snapshot(action); string(data) != string(first) {
+			t.Fatalf("Snapshot is not reproducible:\n%s\n%s", first, data)
+		}
+	}
+	if strings.Index(string(first), `"A"`) > strings.Index(string(first), `"B"`) {
+		t.Errorf("Expected sorted keys, got %s", first)
+	}
+}
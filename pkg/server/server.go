@@ -0,0 +1,166 @@
+// Package server exposes the parser, validator, linter and job graph over HTTP.
+//
+// Every endpoint accepts a GitHub Action or Workflow YAML document as the POST
+// body and responds with JSON:
+//
+//	POST /parse     the parsed ActionFile
+//	POST /validate  validation errors
+//	POST /lint      lint issues
+//	POST /graph     the job dependency graph
+//	GET  /healthz   liveness probe
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/scagogogo/github-action-parser/pkg/parser"
+)
+
+const (
+	// DefaultMaxBodySize is the default maximum request body size in bytes
+	DefaultMaxBodySize int64 = 1 << 20
+	// DefaultMaxConcurrency is the default number of requests processed at once
+	DefaultMaxConcurrency = 16
+)
+
+// Server is an http.Handler serving the parser endpoints
+type Server struct {
+	maxBodySize int64
+	sem         chan struct{}
+	mux         *http.ServeMux
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithMaxBodySize limits the size of request bodies, larger requests are
+// rejected with 413 Request Entity Too Large
+func WithMaxBodySize(n int64) Option {
+	return func(s *Server) {
+		s.maxBodySize = n
+	}
+}
+
+// WithMaxConcurrency limits the number of requests processed at once, requests
+// beyond the limit wait until a slot frees up or the client goes away
+func WithMaxConcurrency(n int) Option {
+	return func(s *Server) {
+		if n > 0 {
+			s.sem = make(chan struct{}, n)
+		}
+	}
+}
+
+// ValidateResponse is the response body of the /validate endpoint
+type ValidateResponse struct {
+	Valid  bool                     `json:"valid"`
+	Errors []parser.ValidationError `json:"errors"`
+}
+
+// LintResponse is the response body of the /lint endpoint
+type LintResponse struct {
+	Issues []parser.ValidationError `json:"issues"`
+}
+
+// ErrorResponse is the response body for failed requests
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// New creates a new Server
+func New(opts ...Option) *Server {
+	s := &Server{
+		maxBodySize: DefaultMaxBodySize,
+		sem:         make(chan struct{}, DefaultMaxConcurrency),
+		mux:         http.NewServeMux(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.mux.HandleFunc("/parse", s.handle(s.parse))
+	s.mux.HandleFunc("/validate", s.handle(s.validate))
+	s.mux.HandleFunc("/lint", s.handle(s.lint))
+	s.mux.HandleFunc("/graph", s.handle(s.graph))
+	s.mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	return s
+}
+
+// ServeHTTP implements the http.Handler interface
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handle wraps an endpoint with method checking, concurrency limiting and
+// request parsing
+func (s *Server) handle(fn func(*parser.ActionFile) (int, interface{})) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSON(w, http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"})
+			return
+		}
+
+		select {
+		case s.sem <- struct{}{}:
+			defer func() { <-s.sem }()
+		case <-r.Context().Done():
+			writeJSON(w, http.StatusServiceUnavailable, ErrorResponse{Error: "server busy"})
+			return
+		}
+
+		action, err := parser.Parse(http.MaxBytesReader(w, r.Body, s.maxBodySize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeJSON(w, http.StatusRequestEntityTooLarge, ErrorResponse{Error: "request body too large"})
+				return
+			}
+			if errors.Is(err, parser.ErrInvalidYAML) {
+				writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+				return
+			}
+			writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		status, body := fn(action)
+		writeJSON(w, status, body)
+	}
+}
+
+func (s *Server) parse(action *parser.ActionFile) (int, interface{}) {
+	return http.StatusOK, action
+}
+
+func (s *Server) validate(action *parser.ActionFile) (int, interface{}) {
+	errs := parser.NewValidator().Validate(action)
+	return http.StatusOK, ValidateResponse{Valid: len(errs) == 0, Errors: errs}
+}
+
+func (s *Server) lint(action *parser.ActionFile) (int, interface{}) {
+	return http.StatusOK, LintResponse{Issues: parser.NewLinter().Lint(action)}
+}
+
+func (s *Server) graph(action *parser.ActionFile) (int, interface{}) {
+	graph, err := parser.BuildJobGraph(action)
+	if errors.Is(err, parser.ErrNotAWorkflow) {
+		return http.StatusUnprocessableEntity, ErrorResponse{Error: err.Error()}
+	}
+	if err != nil {
+		return http.StatusInternalServerError, ErrorResponse{Error: err.Error()}
+	}
+	return http.StatusOK, graph
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func post(t *testing.T, s *Server, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+func readTestdata(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile("../parser/testdata/" + name)
+	if err != nil {
+		t.Fatalf("Failed to read test file: %v", err)
+	}
+	return string(data)
+}
+
+func TestParseEndpoint(t *testing.T) {
+	rec := post(t, New(), "/parse", readTestdata(t, "action.yml"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body["name"] != "Example GitHub Action" {
+		t.Errorf("Expected name 'Example GitHub Action', got '%v'", body["name"])
+	}
+}
+
+func TestValidateEndpoint(t *testing.T) {
+	rec := post(t, New(), "/validate", "runs:\n  using: docker\n")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var body ValidateResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if body.Valid {
+		t.Errorf("Expected action to be invalid")
+	}
+	if len(body.Errors) != 3 {
+		t.Errorf("Expected 3 validation errors, got %d: %v", len(body.Errors), body.Errors)
+	}
+}
+
+func TestLintEndpoint(t *testing.T) {
+	rec := post(t, New(), "/lint", readTestdata(t, "action.yml"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var body LintResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(body.Issues) == 0 {
+		t.Errorf("Expected lint issues for deprecated set-output commands")
+	}
+}
+
+func TestGraphEndpoint(t *testing.T) {
+	s := New()
+
+	rec := post(t, s, "/graph", readTestdata(t, "workflow.yml"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	var body struct {
+		Nodes []map[string]interface{} `json:"nodes"`
+		Edges []map[string]interface{} `json:"edges"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(body.Nodes) != 4 || len(body.Edges) != 3 {
+		t.Errorf("Expected 4 nodes and 3 edges, got %d and %d", len(body.Nodes), len(body.Edges))
+	}
+
+	rec = post(t, s, "/graph", readTestdata(t, "action.yml"))
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status 422 for an action file, got %d", rec.Code)
+	}
+}
+
+func TestRequestLimits(t *testing.T) {
+	s := New(WithMaxBodySize(16))
+
+	rec := post(t, s, "/parse", readTestdata(t, "action.yml"))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got %d", rec.Code)
+	}
+
+	rec = post(t, s, "/parse", "name: [unclosed")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid YAML, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/parse", nil)
+	get := httptest.NewRecorder()
+	s.ServeHTTP(get, req)
+	if get.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405, got %d", get.Code)
+	}
+}